@@ -0,0 +1,109 @@
+package main
+
+import (
+	"log"
+	"sync"
+
+	"google.golang.org/genai"
+)
+
+// tokenBudget adds up the Gemini tokens each stage of a run uses (classifier, data chart
+// planning, outline, palette, quote, policy review) against --token-budget. Required
+// stages always run; optional ones ask allow first and are skipped when their estimate
+// would take the run over the limit. An allowed stage's estimate stays reserved until its
+// usage is added or it is released, so stages allowed one after another and then run side
+// by side can't overshoot together. A nil *tokenBudget only discards usage, so helpers can
+// take one unconditionally.
+type tokenBudget struct {
+	limit int32 // 0 = unlimited
+
+	mu       sync.Mutex // the extras (palette, quote, risks, Q&A) run side by side
+	used     int32
+	reserved map[string]int32 // estimates of allowed stages not yet added
+	stages   map[string]int32
+	skipped  []string
+}
+
+func newTokenBudget(limit int32) *tokenBudget {
+	return &tokenBudget{limit: limit, reserved: map[string]int32{}, stages: map[string]int32{}}
+}
+
+// add records res's usage under stage, settling the stage's reservation: the estimate is
+// replaced by what the call used. A response without usage metadata counts nothing.
+func (b *tokenBudget) add(stage string, res *genai.GenerateContentResponse) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.reserved, stage)
+	if res == nil || res.UsageMetadata == nil {
+		return
+	}
+	n := res.UsageMetadata.TotalTokenCount
+	b.used += n
+	b.stages[stage] += n
+}
+
+// release drops what is left of stage's reservation, for a call that failed before its
+// usage was added.
+func (b *tokenBudget) release(stage string) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.reserved, stage)
+}
+
+// allow reports whether an optional stage expected to use about estimate tokens fits in
+// what is left after the usage and reservations so far, and reserves it if so. It records
+// the stage as skipped, with a warning, when it doesn't fit.
+func (b *tokenBudget) allow(stage string, estimate int32) bool {
+	if b == nil || b.limit == 0 {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var pending int32
+	for _, n := range b.reserved {
+		pending += n
+	}
+	if b.used+pending+estimate <= b.limit {
+		b.reserved[stage] += estimate
+		return true
+	}
+	b.skipped = append(b.skipped, stage)
+	log.Printf("warning: skipping %s: about %d more tokens would exceed --token-budget %d (%d used, %d reserved)", stage, estimate, b.limit, b.used, pending)
+	return false
+}
+
+// exhausted reports whether the run has used up its budget.
+func (b *tokenBudget) exhausted() bool {
+	if b == nil || b.limit == 0 {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.used >= b.limit
+}
+
+// report fills meta's run-wide usage: the total, each stage's share, and skipped stages.
+func (b *tokenBudget) report(meta *Meta) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	meta.RunTokens = b.used
+	if len(b.stages) > 0 {
+		meta.StageTokens = b.stages
+	}
+	meta.SkippedStages = b.skipped
+}
+
+// estimateTokens approximates the tokens text takes in a prompt: about four characters
+// each, plus reply tokens for the reply.
+func estimateTokens(text string, reply int32) int32 {
+	return int32(len(text)/4) + reply
+}
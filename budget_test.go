@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"google.golang.org/genai"
+)
+
+func usageOf(n int32) *genai.GenerateContentResponse {
+	return &genai.GenerateContentResponse{UsageMetadata: &genai.GenerateContentResponseUsageMetadata{TotalTokenCount: n}}
+}
+
+func TestTokenBudget_ConcurrentAllow(t *testing.T) {
+	b := newTokenBudget(1000)
+	b.add("outline", usageOf(400))
+
+	// Ten stages of 200 each ask at once: only three fit in the 600 left
+	var allowed atomic.Int32
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if b.allow(fmt.Sprintf("stage %d", i), 200) {
+				allowed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := allowed.Load(); got != 3 {
+		t.Fatalf("%d stages allowed, want 3: allowed stages must reserve their estimate", got)
+	}
+	var meta Meta
+	b.report(&meta)
+	if len(meta.SkippedStages) != 7 {
+		t.Errorf("skipped %q, want 7 stages", meta.SkippedStages)
+	}
+}
+
+func TestTokenBudget_Settle(t *testing.T) {
+	b := newTokenBudget(1000)
+	if !b.allow("palette", 300) || !b.allow("quote", 300) {
+		t.Fatal("want both allowed")
+	}
+	if b.allow("risks", 500) {
+		t.Fatal("risks allowed over the reservations")
+	}
+
+	// The palette used less than estimated and the quote failed: both free their rest
+	b.add("palette", usageOf(100))
+	b.release("quote")
+	if !b.allow("qa", 800) {
+		t.Error("qa not allowed in the 900 left after settling")
+	}
+	b.release("qa")
+	var meta Meta
+	b.report(&meta)
+	if meta.RunTokens != 100 || meta.StageTokens["palette"] != 100 {
+		t.Errorf("run tokens = %d, stages %v; want only the palette's 100 counted", meta.RunTokens, meta.StageTokens)
+	}
+}
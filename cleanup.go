@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"gogemini-practices/internal/audit"
+	"gogemini-practices/internal/charts"
+	"gogemini-practices/internal/decklock"
+	"gogemini-practices/internal/presentation"
+
+	"google.golang.org/api/sheets/v4"
+	"google.golang.org/api/slides/v1"
+)
+
+// runCleanup implements the cleanup command: it removes agent-generated slides from each
+// --presentation-id deck and the agent's data tabs and chart sheets from --sheet-id,
+// without calling Gemini.
+func runCleanup(args []string) error {
+	fs := flag.NewFlagSet("cleanup", flag.ExitOnError)
+	var presentationIDs stringList
+	fs.Var(&presentationIDs, "presentation-id", "Google Slides presentation ID to remove generated slides from (repeatable)")
+	sheetID := fs.String("sheet-id", profileValue("SHEET_ID"), "Google Sheets spreadsheet ID to remove generated data tabs and chart sheets from")
+	lockDir := fs.String("lock-dir", os.Getenv("DECK_LOCK_DIR"), "Directory of the per-deck lock files shared with build runs (default a per-user cache directory)")
+	auditLogPath := fs.String("audit-log", firstNonEmpty(os.Getenv("AUDIT_LOG"), defaultAuditLog()), "JSON Lines file recording every object and tab cleanup deletes (empty disables)")
+	lockWait := fs.Duration("lock-wait", 0, "How long to wait for a deck a build is writing before skipping it")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(presentationIDs) == 0 {
+		presentationIDs = profileIDs("PRESENTATION_ID")
+	}
+	if len(presentationIDs) == 0 && *sheetID == "" {
+		return fmt.Errorf("cleanup needs --presentation-id or --sheet-id")
+	}
+
+	credsPath := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	if credsPath == "" {
+		return fmt.Errorf("GOOGLE_APPLICATION_CREDENTIALS not set")
+	}
+	credsBytes, err := os.ReadFile(credsPath)
+	if err != nil {
+		return fmt.Errorf("read creds: %w", err)
+	}
+	auditLog, err := audit.New(*auditLogPath, "cleanup")
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	opts, err := clientOptions(ctx, credsBytes, os.Getenv("GOOGLE_IMPERSONATE_USER"), nil, slides.PresentationsScope, sheets.SpreadsheetsScope)
+	if err != nil {
+		return err
+	}
+
+	var slidesAPI presentation.SlidesAPI
+	if len(presentationIDs) > 0 {
+		slidesSvc, err := slides.NewService(ctx, opts...)
+		if err != nil {
+			return fmt.Errorf("slides.NewService: %w", err)
+		}
+		slidesAPI = auditLog.Slides(presentation.NewSlidesAPI(slidesSvc), "")
+	}
+	var sheetsAPI charts.SheetsAPI
+	if *sheetID != "" {
+		sheetsSvc, err := sheets.NewService(ctx, opts...)
+		if err != nil {
+			return fmt.Errorf("sheets.NewService: %w", err)
+		}
+		sheetsAPI = auditLog.Sheets(charts.NewSheetsAPI(sheetsSvc), "")
+	}
+	err = cleanGenerated(ctx, slidesAPI, sheetsAPI, presentationIDs, *sheetID, decklock.Options{Dir: *lockDir, Wait: *lockWait})
+	if err := auditLog.Err(); err != nil {
+		log.Printf("warning: some deletions weren't recorded: %v", err)
+	}
+	return err
+}
+
+// cleanGenerated removes the generated slides and elements of each deck in ids through
+// slidesAPI, and the generated data tabs and chart sheets of sheetID through sheetsAPI.
+// A deck a build holds locked is skipped. Failures are logged and the rest still run; the
+// error only says that something failed.
+func cleanGenerated(ctx context.Context, slidesAPI presentation.SlidesAPI, sheetsAPI charts.SheetsAPI, ids []string, sheetID string, lockOpts decklock.Options) error {
+	var failed bool
+	for _, id := range ids {
+		lock, err := decklock.Acquire(ctx, id, lockOpts)
+		if err != nil {
+			log.Printf("%s: skipped: %v", id, err)
+			failed = true
+			continue
+		}
+		n, err := presentation.DeleteGenerated(ctx, slidesAPI, id)
+		lock.Release()
+		if err != nil {
+			log.Printf("%s: %v", id, err)
+			failed = true
+			continue
+		}
+		log.Printf("%s: deleted %d generated slides and elements", id, n)
+	}
+	if sheetID != "" {
+		if err := charts.CleanupSpreadsheetForCharts(ctx, sheetsAPI, sheetID); err != nil {
+			log.Printf("%s: %v", sheetID, err)
+			failed = true
+		} else {
+			log.Printf("%s: deleted generated data tabs and chart sheets", sheetID)
+		}
+	}
+	if failed {
+		return fmt.Errorf("cleanup did not finish; see the errors above")
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"gogemini-practices/internal/decklock"
+	"gogemini-practices/internal/fakeapi"
+
+	"google.golang.org/api/sheets/v4"
+	"google.golang.org/api/slides/v1"
+)
+
+func TestCleanGenerated(t *testing.T) {
+	ctx := context.Background()
+	lockOpts := decklock.Options{Dir: t.TempDir()}
+	slidesAPI := &fakeapi.Slides{Presentation: &slides.Presentation{Slides: []*slides.Page{
+		{ObjectId: "auto_slide_0_run1"},
+		{ObjectId: "user_slide", PageElements: []*slides.PageElement{{ObjectId: "auto_footer_user_slide"}, {ObjectId: "user_box"}}},
+	}}}
+	sheetsAPI := &fakeapi.Sheets{Spreadsheet: &sheets.Spreadsheet{Sheets: []*sheets.Sheet{
+		{Properties: &sheets.SheetProperties{SheetId: 0, Title: "Budget", SheetType: "GRID"}},
+		{Properties: &sheets.SheetProperties{SheetId: 7, Title: "Data_1", SheetType: "GRID"}},
+	}}}
+
+	// A build holds the second deck: it is skipped and the run reports a failure
+	held, err := decklock.Acquire(ctx, "deck-busy", lockOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer held.Release()
+
+	err = cleanGenerated(ctx, slidesAPI, sheetsAPI, []string{"deck-a", "deck-busy"}, "sheet-1", lockOpts)
+	if err == nil || !strings.Contains(err.Error(), "did not finish") {
+		t.Errorf("cleanGenerated() error = %v, want the skipped deck reported", err)
+	}
+	var deleted []string
+	for _, r := range slidesAPI.Requests() {
+		deleted = append(deleted, r.DeleteObject.ObjectId)
+	}
+	if strings.Join(deleted, " ") != "auto_slide_0_run1 auto_footer_user_slide" {
+		t.Errorf("deleted %q, want only the generated slide and element, and only once", deleted)
+	}
+	if len(sheetsAPI.Batches) != 1 || sheetsAPI.Batches[0].Requests[0].DeleteSheet == nil || sheetsAPI.Batches[0].Requests[0].DeleteSheet.SheetId != 7 {
+		t.Errorf("sheet batches = %+v, want the Data_1 tab deleted", sheetsAPI.Batches)
+	}
+}
+
+func TestCleanGenerated_Failures(t *testing.T) {
+	ctx := context.Background()
+	lockOpts := decklock.Options{Dir: t.TempDir()}
+	if err := cleanGenerated(ctx, &fakeapi.Slides{Err: errors.New("403")}, nil, []string{"deck-a"}, "", lockOpts); err == nil {
+		t.Error("cleanGenerated() with a failing deck: want an error")
+	}
+	if err := cleanGenerated(ctx, nil, &fakeapi.Sheets{Err: errors.New("404")}, nil, "sheet-1", lockOpts); err == nil {
+		t.Error("cleanGenerated() with a failing spreadsheet: want an error")
+	}
+	if err := cleanGenerated(ctx, &fakeapi.Slides{}, nil, []string{"deck-a"}, "", lockOpts); err != nil {
+		t.Errorf("cleanGenerated() of a deck with nothing generated = %v, want nil", err)
+	}
+}
+
+func TestRunCleanup_Args(t *testing.T) {
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", "")
+	t.Setenv("PRESENTATION_ID", "prod-deck") // only a profile's deck is a default
+	profileVars = nil
+
+	if err := runCleanup([]string{"--audit-log", ""}); err == nil || !strings.Contains(err.Error(), "needs --presentation-id or --sheet-id") {
+		t.Errorf("runCleanup() without a target = %v, want the missing target reported", err)
+	}
+	if err := runCleanup([]string{"--presentation-id", "deck-a", "--audit-log", ""}); err == nil || !strings.Contains(err.Error(), "GOOGLE_APPLICATION_CREDENTIALS") {
+		t.Errorf("runCleanup() without credentials = %v, want them reported", err)
+	}
+}
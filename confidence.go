@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strings"
+)
+
+// confidencePrompt asks the outline model how sure it is of each topic and dataset.
+const confidencePrompt = "CONFIDENCE RULES:\n" +
+	"- Give each topic 'confidence' from 0 to 1: how sure you are that its facts and figures are accurate (1 = well-established or taken from the brief, 0.5 = plausible but recalled or approximate, below 0.3 = guessed).\n" +
+	"- Set 'needs_verification' true when a figure, date, name, or claim should be checked before presenting; omit it otherwise.\n" +
+	"- Give a dataset its own 'confidence' and 'needs_verification' for its numbers, which are often less certain than the summary.\n\n"
+
+// sanitizeConfidence keeps confidences in [0, 1]: a percentage (1-100) is scaled down and
+// anything else out of range is dropped. A dataset that holds real data (a data_ref the
+// warehouse, sheet, web, or file data replaced) carries no model estimate.
+func sanitizeConfidence(t *TopicSummary) {
+	t.Confidence = unitConfidence(t.Confidence)
+	if d := t.Dataset; d != nil {
+		d.Confidence = unitConfidence(d.Confidence)
+		if dataSource(*t) != "model-estimated" {
+			d.Confidence, d.NeedsVerification = 0, false
+		}
+	}
+}
+
+func unitConfidence(c float64) float64 {
+	switch {
+	case math.IsNaN(c) || c < 0 || c > 100:
+		return 0
+	case c > 1:
+		return c / 100
+	}
+	return c
+}
+
+// lowConfidence reports whether a topic's chart shows model figures to mark as an
+// estimate with --estimate-badge: ones flagged for verification, or with a confidence (the
+// dataset's, else the topic's) under threshold. A confidence the model left out doesn't
+// count as low.
+func lowConfidence(t TopicSummary, threshold float64) bool {
+	d := t.Dataset
+	if threshold <= 0 || d == nil || dataSource(t) != "model-estimated" {
+		return false
+	}
+	c := d.Confidence
+	if c == 0 {
+		c = t.Confidence
+	}
+	return d.NeedsVerification || c > 0 && c < threshold
+}
+
+// logVerification lists the topics the model flagged for checking, so they stand out in
+// the run's log as well as in the JSON.
+func logVerification(topics []TopicSummary) {
+	var flagged []string
+	for _, t := range topics {
+		if t.NeedsVerification || t.Dataset != nil && t.Dataset.NeedsVerification {
+			flagged = append(flagged, fmt.Sprintf("%q", t.Topic))
+		}
+	}
+	if len(flagged) > 0 {
+		log.Printf("needs verification before presenting: %s", strings.Join(flagged, ", "))
+	}
+}
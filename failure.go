@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"gogemini-practices/internal/decklock"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/genai"
+)
+
+// Failure classes a run can end with. Each exits with its own code and is printed as the
+// JSON output's error.class, so wrappers and CI jobs can branch on what went wrong; any
+// other failure exits 1 as "internal".
+var (
+	ErrInvalidInput = errors.New("invalid input")
+	ErrAuth         = errors.New("authentication failed")
+	ErrQuota        = errors.New("quota exhausted")
+	ErrModelOutput  = errors.New("unusable model output")
+	ErrSlidesAPI    = errors.New("slides API error")
+	ErrLocked       = decklock.ErrLocked // another run is writing the deck
+)
+
+// failureClasses maps each class to its name and exit code, checked in order. Code 2 is
+// also what the flag package exits with on a bad flag.
+var failureClasses = []struct {
+	err   error
+	class string
+	code  int
+}{
+	{ErrInvalidInput, "invalid_input", 2},
+	{ErrAuth, "auth", 3},
+	{ErrQuota, "quota", 4},
+	{ErrModelOutput, "model_output", 5},
+	{ErrSlidesAPI, "slides_api", 6},
+	{ErrLocked, "locked", 7},
+}
+
+// ErrorInfo is the error object of the JSON output of a failed run.
+type ErrorInfo struct {
+	Class    string `json:"class"`
+	Message  string `json:"message"`
+	ExitCode int    `json:"exit_code"`
+}
+
+// classify names err's failure class. An auth or quota error from a Google API or Gemini
+// takes precedence over the class it was wrapped in: a Slides call refused for quota
+// reports quota, not slides_api.
+func classify(err error) ErrorInfo {
+	api := apiClass(err)
+	for _, c := range failureClasses {
+		if api == c.err || errors.Is(err, c.err) {
+			return ErrorInfo{Class: c.class, Message: err.Error(), ExitCode: c.code}
+		}
+	}
+	return ErrorInfo{Class: "internal", Message: err.Error(), ExitCode: 1}
+}
+
+// apiClass returns ErrAuth or ErrQuota when err carries an API or token error of that kind,
+// otherwise nil.
+func apiClass(err error) error {
+	var gerr *googleapi.Error
+	if errors.As(err, &gerr) {
+		switch gerr.Code {
+		case http.StatusUnauthorized:
+			return ErrAuth
+		case http.StatusTooManyRequests:
+			return ErrQuota
+		case http.StatusForbidden:
+			for _, item := range gerr.Errors {
+				// rateLimitExceeded, userRateLimitExceeded, dailyLimitExceeded, quotaExceeded
+				if strings.HasSuffix(item.Reason, "LimitExceeded") || item.Reason == "quotaExceeded" {
+					return ErrQuota
+				}
+			}
+			return ErrAuth
+		}
+	}
+	var aerr genai.APIError
+	if errors.As(err, &aerr) {
+		switch {
+		case aerr.Code == http.StatusUnauthorized || aerr.Code == http.StatusForbidden:
+			return ErrAuth
+		case aerr.Code == http.StatusTooManyRequests || aerr.Status == "RESOURCE_EXHAUSTED":
+			return ErrQuota
+		case aerr.Code == http.StatusBadRequest && strings.Contains(aerr.Message, "API key"):
+			return ErrAuth // Gemini answers an invalid API key with 400 API_KEY_INVALID
+		}
+	}
+	var rerr *oauth2.RetrieveError
+	if errors.As(err, &rerr) {
+		return ErrAuth
+	}
+	return nil
+}
+
+// exitClass names the failure class of a build that exited with code, for runBuild's error.
+func exitClass(code int) string {
+	for _, c := range failureClasses {
+		if c.code == code {
+			return c.class
+		}
+	}
+	return "internal"
+}
+
+// fail ends the run on err: it logs err, prints out (or, before there is any output, just
+// the error) with err's ErrorInfo as JSON, and exits with its class's code.
+func fail(out *Response, err error) {
+	info := classify(err)
+	log.Printf("%s: %v", info.Class, err)
+	if b, err := failureJSON(out, info); err == nil {
+		fmt.Println(string(b))
+	}
+	os.Exit(info.ExitCode)
+}
+
+// failureJSON is the JSON output of a run failing with info: out with its error set, or
+// just the error before there is any output.
+func failureJSON(out *Response, info ErrorInfo) ([]byte, error) {
+	var v any = struct {
+		Error *ErrorInfo `json:"error"`
+	}{&info}
+	if out != nil {
+		out.Error = &info
+		v = out
+	}
+	return json.MarshalIndent(v, "", "  ")
+}
+
+// invalidInput is fmt.Errorf for bad flags or input files: the error wraps ErrInvalidInput
+// as well as any %w argument.
+func invalidInput(format string, args ...any) error {
+	return fmt.Errorf("%w: "+format, append([]any{ErrInvalidInput}, args...)...)
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/genai"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantClass string
+		wantCode  int
+	}{
+		{"invalid input", invalidInput("--layout: %w", errors.New("bad anchor")), "invalid_input", 2},
+		{"auth inside slides error", fmt.Errorf("%w: deck: WriteDeck: %w", ErrSlidesAPI, &googleapi.Error{Code: http.StatusUnauthorized}), "auth", 3},
+		{"quota inside slides error", fmt.Errorf("%w: deck: WriteDeck: %w", ErrSlidesAPI, &googleapi.Error{Code: http.StatusTooManyRequests}), "quota", 4},
+		{"rate limit 403 inside slides error", fmt.Errorf("%w: %w", ErrSlidesAPI, &googleapi.Error{Code: http.StatusForbidden, Errors: []googleapi.ErrorItem{{Reason: "userRateLimitExceeded"}}}), "quota", 4},
+		{"permission 403 inside slides error", fmt.Errorf("%w: %w", ErrSlidesAPI, &googleapi.Error{Code: http.StatusForbidden, Errors: []googleapi.ErrorItem{{Reason: "forbidden"}}}), "auth", 3},
+		{"gemini quota", fmt.Errorf("outline: %w", genai.APIError{Code: http.StatusTooManyRequests, Status: "RESOURCE_EXHAUSTED"}), "quota", 4},
+		{"gemini bad key", genai.APIError{Code: http.StatusBadRequest, Message: "API key not valid"}, "auth", 3},
+		{"token exchange", fmt.Errorf("%w: %w", ErrSlidesAPI, &oauth2.RetrieveError{}), "auth", 3},
+		{"model output", fmt.Errorf("%w: no topics", ErrModelOutput), "model_output", 5},
+		{"slides error", fmt.Errorf("%w: deck: %w", ErrSlidesAPI, &googleapi.Error{Code: http.StatusInternalServerError}), "slides_api", 6},
+		{"locked", fmt.Errorf("deck: %w", ErrLocked), "locked", 7},
+		{"unknown", errors.New("disk full"), "internal", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := classify(tt.err)
+			if info.Class != tt.wantClass || info.ExitCode != tt.wantCode {
+				t.Fatalf("classify(%v) = %s/%d, want %s/%d", tt.err, info.Class, info.ExitCode, tt.wantClass, tt.wantCode)
+			}
+			if info.Message != tt.err.Error() {
+				t.Errorf("message = %q, want %q", info.Message, tt.err.Error())
+			}
+
+			for _, out := range []*Response{nil, {Topics: []TopicSummary{{Topic: "a"}}}} {
+				b, err := failureJSON(out, info)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var got struct {
+					Topics []TopicSummary `json:"topics"`
+					Error  *struct {
+						Class    string `json:"class"`
+						Message  string `json:"message"`
+						ExitCode int    `json:"exit_code"`
+					} `json:"error"`
+				}
+				if err := json.Unmarshal(b, &got); err != nil {
+					t.Fatal(err)
+				}
+				if got.Error == nil || got.Error.Class != tt.wantClass || got.Error.ExitCode != tt.wantCode || got.Error.Message != tt.err.Error() {
+					t.Errorf("JSON error = %+v, want class %s, exit code %d, message %q", got.Error, tt.wantClass, tt.wantCode, tt.err.Error())
+				}
+				if out != nil && len(got.Topics) != 1 {
+					t.Errorf("JSON output lost the run's topics: %s", b)
+				}
+			}
+		})
+	}
+}
+
+func TestExitClass(t *testing.T) {
+	for code, want := range map[int]string{2: "invalid_input", 3: "auth", 4: "quota", 5: "model_output", 6: "slides_api", 7: "locked", 1: "internal", 42: "internal"} {
+		if got := exitClass(code); got != want {
+			t.Errorf("exitClass(%d) = %q, want %q", code, got, want)
+		}
+	}
+}
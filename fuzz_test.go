@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestExtractJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"bare", `{"a":1}`, `{"a":1}`},
+		{"fenced", "```json\n[1, 2]\n```", `[1, 2]`},
+		{"prose around", "Here you go: {\"a\":1} Hope it helps!", `{"a":1}`},
+		{"citation before", "Per [1], the result is {\"a\":[1]}.", `{"a":[1]}`},
+		{"braces in strings", `{"s":"a } or ] and \" {"}`, `{"s":"a } or ] and \" {"}`},
+		{"trailing bracket in prose", "{\"a\":1} (see [note])", `{"a":1}`},
+		{"nested fence in value", "```json\n{\"code\":\"```go\\nx\\n```\"}\n```", "{\"code\":\"```go\\nx\\n```\"}"},
+		{"example then answer", "Format: {}\n```json\n{\"topics\":[{\"topic\":\"x\"}]}\n```", `{"topics":[{"topic":"x"}]}`},
+		{"invalid falls back", "```json\n{\"a\": 1,}\n```", `{"a": 1,}`},
+		{"no json", "sorry", "sorry"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractJSON(tt.in); got != tt.want {
+				t.Errorf("extractJSON(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+// FuzzExtractJSON checks that extractJSON never panics and that a reply holding one
+// valid JSON value, however it is wrapped, gives that value back.
+func FuzzExtractJSON(f *testing.F) {
+	for _, seed := range []string{
+		`{"a":1}`,
+		"```json\n[{\"topic\":\"x\",\"summary\":\"• a\"}]\n```",
+		`Sure! {"s":"} ] \" {"} done`,
+		"```\n```json\n{}\n```\n```",
+		"[[[[",
+		`{"a":"\`,
+		"prose [1] then {\"b\":[]}",
+	} {
+		f.Add(seed, "Here is the JSON:\n```json\n", "\n```")
+	}
+	f.Fuzz(func(t *testing.T, value, prefix, suffix string) {
+		extractJSON(value)
+		extractJSON(prefix + value + suffix)
+		if !json.Valid([]byte(value)) || strings.ContainsAny(prefix+suffix, "[]{}\"") {
+			return
+		}
+		v := strings.TrimSpace(value)
+		if v == "" || v[0] != '{' && v[0] != '[' {
+			return
+		}
+		if got := extractJSON(prefix + value + suffix); got != v {
+			t.Fatalf("extractJSON(%q + %q + %q) = %q, want %q", prefix, value, suffix, got, v)
+		}
+	})
+}
+
+// FuzzSanitizeDataset feeds arbitrary topic JSON through sanitizeDataset and checks that
+// whatever dataset survives is safe to chart.
+func FuzzSanitizeDataset(f *testing.F) {
+	for _, seed := range []string{
+		`{"dataset":{"title":"Revenue","unit":"billion USD","type":"timeseries","points":[{"label":"2023","value":1.2},{"label":"2024","value":"$1.5B"}]}}`,
+		`{"dataset":{"series":["a","b"],"points":[{"label":"x","values":[1,2]},{"label":"y","values":[3]},{"label":" ","value":4}]}}`,
+		`{"dataset":{"series":["only"],"log_scale":true,"axis_min":5,"points":[{"label":"x","values":["-1e400"]}]}}`,
+		`{"dataset":{"unit":"%","points":[{"label":"x","value":"12 million"},{"label":"y","value":null}]}}`,
+		`{"dataset":{"points":[]}}`,
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, data string) {
+		var topic TopicSummary
+		if json.Unmarshal([]byte(data), &topic) != nil {
+			return
+		}
+		sanitizeDataset(&topic)
+		d := topic.Dataset
+		if d == nil {
+			return
+		}
+		if len(d.Points) == 0 || len(d.Points) > 20 {
+			t.Fatalf("%d points survived", len(d.Points))
+		}
+		switch d.Type {
+		case "timeseries", "category", "comparison", "composition":
+		default:
+			t.Fatalf("type %q survived", d.Type)
+		}
+		if len(d.Series) == 1 || len(d.Series) > 6 {
+			t.Fatalf("%d series survived", len(d.Series))
+		}
+		lowest := math.Inf(1)
+		for _, p := range d.Points {
+			if strings.TrimSpace(p.Label) == "" || p.Label != strings.TrimSpace(p.Label) {
+				t.Fatalf("label %q survived", p.Label)
+			}
+			if len(p.Values) != len(d.Series) {
+				t.Fatalf("point %q has %d values for %d series", p.Label, len(p.Values), len(d.Series))
+			}
+			for _, v := range append([]float64{p.Value}, p.Values...) {
+				if math.IsNaN(v) || math.IsInf(v, 0) {
+					t.Fatalf("point %q kept value %v", p.Label, v)
+				}
+				lowest = math.Min(lowest, v)
+			}
+		}
+		if d.AxisMin != nil && *d.AxisMin > lowest {
+			t.Fatalf("axis minimum %v cuts off %v", *d.AxisMin, lowest)
+		}
+		if d.LogScale && lowest <= 0 {
+			t.Fatalf("log scale kept over %v", lowest)
+		}
+	})
+}
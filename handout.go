@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+	"google.golang.org/api/drive/v3"
+
+	"gogemini-practices/internal/charts"
+	"gogemini-practices/internal/driveupload"
+	"gogemini-practices/internal/handout"
+	"gogemini-practices/internal/palette"
+)
+
+// Export is where a document export of the plan went, with --script, --handout, or their
+// -doc variants.
+type Export struct {
+	Path   string `json:"path,omitempty"`
+	DocID  string `json:"doc_id,omitempty"`
+	DocURL string `json:"doc_url,omitempty"`
+	Error  string `json:"error,omitempty"` // why the Google Doc couldn't be created
+}
+
+// chartImage renders a topic's chart for a handout page and returns where it went, or nil
+// for a topic without one. Failures are logged and leave the page without a chart.
+type chartImage func(i int, ds charts.DatasetSpec) *handout.Image
+
+// buildHandout turns the plan into a handout: a page per topic with the full summary, its
+// steps, headline figure, and example code, the chart drawn by chart, and the topic's sources.
+func buildHandout(title, audience string, topics []TopicSummary, pal *palette.Palette, chart chartImage) handout.Handout {
+	h := handout.Handout{Title: title}
+	if audience != "" {
+		h.Subtitle = "Handout for " + audience
+	}
+	for i, t := range topics {
+		p := handout.Page{Title: t.Topic, Summary: t.Summary, Steps: t.Steps}
+		if t.Stat != nil {
+			p.Stat = strings.TrimSpace(fmt.Sprintf("%g %s", t.Stat.Value, t.Stat.Unit))
+			if c := strings.TrimSpace(t.Stat.Caption); c != "" {
+				p.Stat += " — " + c
+			}
+		}
+		if t.Code != nil {
+			p.Code, p.Lang = t.Code.Source, t.Code.Language
+		}
+		if t.Dataset != nil && len(t.Dataset.Points) > 0 && chart != nil {
+			p.Chart = chart(i, handoutChart(t, pal))
+		}
+		p.Sources = topicSources(t)
+		h.Pages = append(h.Pages, p)
+	}
+	return h
+}
+
+// handoutChart is the dataset as RenderPNG draws it.
+func handoutChart(t TopicSummary, pal *palette.Palette) charts.DatasetSpec {
+	d := t.Dataset
+	ds := charts.DatasetSpec{Title: d.Title, Unit: d.Unit, Type: d.Type, Series: d.Series, LogScale: d.LogScale, MinValue: d.AxisMin, Source: dataSource(t)}
+	ds.Stacked = charts.StackedType(d.Type, d.Stack, len(d.Series))
+	if hint, err := charts.ParseTrend(d.Trend); err == nil {
+		ds.Trend = hint
+	}
+	if pal != nil {
+		ds.SeriesColors = pal.SeriesColors()
+	}
+	for _, p := range d.Points {
+		ds.Points = append(ds.Points, charts.Point{Label: p.Label, Value: p.Value, Values: p.Values})
+	}
+	return ds
+}
+
+// topicSources lists where a topic's content came from: its real data, links in its
+// summary, and its chosen image. Model-estimated figures are listed as such.
+func topicSources(t TopicSummary) []handout.Source {
+	var out []handout.Source
+	if t.Dataset != nil && len(t.Dataset.Points) > 0 {
+		if src := dataSource(t); src == "model-estimated" {
+			out = append(out, handout.Source{Label: "Chart figures: model-estimated, not verified"})
+		} else {
+			out = append(out, handout.Source{Label: "Chart data: " + src})
+		}
+	}
+	out = append(out, handout.LinkSources(t.Summary)...)
+	if t.ImageURL != "" {
+		out = append(out, handout.Source{Label: "Image", URL: t.ImageURL})
+	} else if img := t.Image; img != nil && img.Chosen != "" {
+		page := img.Chosen
+		for _, c := range img.Candidates {
+			if c.URL == img.Chosen && c.Source != "" {
+				page = c.Source
+			}
+		}
+		out = append(out, handout.Source{Label: "Image", URL: page})
+	}
+	return out
+}
+
+// handoutChartFile renders charts next to a Markdown handout, in <name>-charts/, and links
+// them by relative path.
+func handoutChartFile(mdPath string) chartImage {
+	dir := strings.TrimSuffix(mdPath, filepath.Ext(mdPath)) + "-charts"
+	return func(i int, ds charts.DatasetSpec) *handout.Image {
+		data, err := charts.RenderPNG(ds, charts.RenderWidth, charts.RenderHeight)
+		if err == nil {
+			err = os.MkdirAll(dir, 0o755)
+		}
+		name := fmt.Sprintf("%d-%s.png", i+1, charts.Slug(ds.Title))
+		if err == nil {
+			err = os.WriteFile(filepath.Join(dir, name), data, 0o644)
+		}
+		if err != nil {
+			log.Printf("warning: handout chart for topic %d: %v", i+1, err)
+			return nil
+		}
+		return &handout.Image{Src: filepath.ToSlash(filepath.Join(filepath.Base(dir), name)), Alt: ds.Title}
+	}
+}
+
+// handoutChartDrive renders charts for a handout Doc and hosts them on Drive, where the
+// Doc import can fetch them, like the deck's fallback chart images.
+func handoutChartDrive(ctx context.Context, driveSvc *drive.Service) chartImage {
+	return func(i int, ds charts.DatasetSpec) *handout.Image {
+		data, err := charts.RenderPNG(ds, charts.RenderWidth, charts.RenderHeight)
+		var url string
+		if err == nil {
+			url, err = driveupload.UploadPublicImage(ctx, driveSvc, "handout-chart-"+charts.Slug(ds.Title)+"-"+uuid.New().String()[:8]+".png", "image/png", data)
+		}
+		if err != nil {
+			log.Printf("warning: handout chart for topic %d: %v", i+1, err)
+			return nil
+		}
+		return &handout.Image{Src: url, Alt: ds.Title}
+	}
+}
+
+// writeHandout saves the handout as Markdown at path, with its charts beside it.
+func writeHandout(path, title, audience string, topics []TopicSummary, pal *palette.Palette) error {
+	h := buildHandout(title, audience, topics, pal, handoutChartFile(path))
+	if err := os.WriteFile(path, []byte(h.Markdown()), 0o644); err != nil {
+		return fmt.Errorf("write handout: %w", err)
+	}
+	return nil
+}
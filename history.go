@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gogemini-practices/internal/presentation"
+)
+
+// runRecord is one run history entry: the deck a run wrote charts for, the spreadsheet they
+// went to, and the run ID its data tabs there start with ("<run_id>-<n>-<slug>").
+type runRecord struct {
+	Time           time.Time `json:"time"`
+	RunID          string    `json:"run_id"`
+	PresentationID string    `json:"presentation_id"`
+	SheetID        string    `json:"sheet_id"`
+	Topic          int       `json:"topic,omitempty"` // 1-based topic a --regen-topic run replaced
+}
+
+// defaultRunHistory is the per-user run history file, or "" without a config directory.
+func defaultRunHistory() string {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(base, "gogemini-slides", "runs.jsonl")
+}
+
+// defaultAuditLog is the per-user audit log of deletions, or "" without a config directory.
+func defaultAuditLog() string {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(base, "gogemini-slides", "audit.jsonl")
+}
+
+// appendRunHistory appends rec to the JSON Lines history at path, creating it if needed.
+func appendRunHistory(path string, rec runRecord) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create run history dir: %w", err)
+	}
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open run history: %w", err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("write run history: %w", err)
+	}
+	return f.Close()
+}
+
+// defaultManifestDir is the per-user deck manifest directory, or "" without a config
+// directory.
+func defaultManifestDir() string {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(base, "gogemini-slides", "manifests")
+}
+
+// saveManifest writes m to <dir>/<presentation ID>.json. After a --regen-topic run (topic
+// > 0), m lists only that topic's objects: they replace the topic's entries in the saved
+// manifest, and the rest are kept.
+func saveManifest(dir string, m presentation.Manifest, topic int) error {
+	path := filepath.Join(dir, m.PresentationID+".json")
+	if topic > 0 {
+		var prev presentation.Manifest
+		if b, err := os.ReadFile(path); err == nil && json.Unmarshal(b, &prev) == nil {
+			objects := m.Objects
+			m.Objects = nil
+			for _, o := range prev.Objects {
+				if o.Topic != topic {
+					m.Objects = append(m.Objects, o)
+				}
+			}
+			m.Objects = append(m.Objects, objects...)
+		}
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("create manifest dir: %w", err)
+	}
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(b, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write manifest: %w", err)
+	}
+	return nil
+}
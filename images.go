@@ -0,0 +1,422 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gogemini-practices/internal/charts"
+	"gogemini-practices/internal/driveupload"
+	"gogemini-practices/internal/fallbackimg"
+	"gogemini-practices/internal/hotlink"
+	"gogemini-practices/internal/icons"
+	"gogemini-practices/internal/imagesearch"
+	"gogemini-practices/internal/llm"
+	"gogemini-practices/internal/moderation"
+	"gogemini-practices/internal/phash"
+	"gogemini-practices/internal/pipeline"
+	"gogemini-practices/internal/svgraster"
+	"gogemini-practices/internal/watermark"
+
+	"github.com/google/uuid"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/vision/v1"
+	"google.golang.org/genai"
+)
+
+// imagePicker selects one image per topic from ranked CSE candidates, falling through to
+// the next candidate when one is unreachable, rejected by moderation, a near-duplicate of
+// an image already placed on another topic, or fails post-processing. pick is safe for
+// concurrent use.
+type imagePicker struct {
+	cseKey     string
+	cseCX      string
+	search     imagesearch.Options
+	defaultURL string
+	driveSvc   *drive.Service
+	visionSvc  *vision.Service
+	modLevel   moderation.Level
+	wm         watermark.Options
+	dedupe     bool
+	embed      imagesearch.Embedder // nil ranks candidates by query word matches alone
+	hotlink    *hotlink.Detector    // nil links every image as found
+	fallback   *fallbackimg.Pool    // nil or empty uses defaultURL for every topic
+	mu         sync.Mutex
+	seen       []uint64 // perceptual hashes of images already chosen in this run; guarded by mu
+}
+
+// pick returns the image URL to insert for the topic, searched by its image query, and
+// true, or a fallback image and false when the search fails or finds nothing usable. With
+// an embedder, candidates are tried in order of their similarity to the topic's title and
+// summary.
+func (p *imagePicker) pick(ctx context.Context, topic, title, summary string) (string, bool) {
+	cands, err := imagesearch.SearchImages(ctx, p.cseKey, p.cseCX, topic, p.search)
+	if err != nil {
+		log.Printf("warning: image search for %q: %v", topic, err)
+		return p.fallbackURL(title), false
+	}
+	if p.embed != nil {
+		if cands, err = imagesearch.RankBySimilarity(ctx, cands, title+"\n"+summary, p.embed); err != nil {
+			log.Printf("warning: ranking images for %q by word matches: %v", topic, err)
+		}
+	}
+	for _, c := range cands {
+		imgURL, ct := validateImageURL(ctx, c.Link, "")
+		if imgURL == "" {
+			continue
+		}
+		// Reject adult/violent/racy images regardless of the CSE safe setting
+		verdict, err := moderation.CheckURL(ctx, p.visionSvc, imgURL, p.modLevel)
+		if err != nil {
+			log.Printf("warning: moderation for %q: %v", topic, err)
+		}
+		if !verdict.Allowed {
+			log.Printf("image %s for %q rejected by moderation (%s)", imgURL, topic, verdict.Reason)
+			continue
+		}
+		// Slides fetches images with a Referer of its own, which some hosts refuse
+		var hot hotlink.Result
+		if p.hotlink != nil {
+			if hot, err = p.hotlink.Check(ctx, imgURL, c.Image.ContextLink); err != nil {
+				log.Printf("warning: image %s for %q: %v", imgURL, topic, err)
+				continue
+			}
+			if hot.Protected {
+				log.Printf("image host of %s for %q refuses other sites; re-hosting it on Drive", imgURL, topic)
+			}
+		}
+		if p.dedupe && p.isDuplicate(ctx, imgURL, hot.Referer, topic) {
+			continue
+		}
+		processed, err := processImage(ctx, p.driveSvc, imgURL, ct, p.wm, hot)
+		if err != nil {
+			log.Printf("warning: image processing for %q: %v", topic, err)
+			continue
+		}
+		return processed, true
+	}
+	return p.fallbackURL(title), false
+}
+
+// fallbackURL returns the image for a topic whose search found nothing usable: the next
+// image of the fallback pool, or the default URL without one.
+func (p *imagePicker) fallbackURL(title string) string {
+	if u := p.fallback.Pick(title); u != "" {
+		return u
+	}
+	return p.defaultURL
+}
+
+// isDuplicate hashes the image and reports whether it is a near-duplicate of one already
+// chosen; unique images are recorded. Images that cannot be hashed (e.g. SVG) count as unique.
+// With topics picked concurrently, the first of two near-duplicates to be hashed keeps it.
+func (p *imagePicker) isDuplicate(ctx context.Context, imageURL, referer, topic string) bool {
+	data, _, err := fetchImageBytes(ctx, imageURL, referer)
+	if err != nil {
+		return false
+	}
+	h, err := phash.Compute(data)
+	if err != nil {
+		return false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, prev := range p.seen {
+		if phash.Distance(h, prev) <= phash.NearDuplicate {
+			log.Printf("image %s for %q is a near-duplicate of an earlier slide; trying next candidate", imageURL, topic)
+			return true
+		}
+	}
+	p.seen = append(p.seen, h)
+	return false
+}
+
+// iconCache rasterizes and uploads each icon once per run, however many topics and targets
+// use it. url is safe for concurrent use.
+type iconCache struct {
+	mu   sync.Mutex
+	urls map[string]*iconEntry
+}
+
+type iconEntry struct {
+	once sync.Once
+	url  string
+}
+
+// url returns the Drive URL of the named icon, or "" when it could not be made.
+func (c *iconCache) url(ctx context.Context, driveSvc *drive.Service, name, topic string) string {
+	c.mu.Lock()
+	if c.urls == nil {
+		c.urls = map[string]*iconEntry{}
+	}
+	e, ok := c.urls[name]
+	if !ok {
+		e = &iconEntry{}
+		c.urls[name] = e
+	}
+	c.mu.Unlock()
+
+	e.once.Do(func() {
+		u, err := processImage(ctx, driveSvc, icons.URL(name), "", watermark.Options{}, hotlink.Result{})
+		if err != nil {
+			log.Printf("warning: icon %q for %q: %v", name, topic, err)
+		}
+		e.url = u
+	})
+	return e.url
+}
+
+// validateImageURL checks URL is HTTPS and reachable (HEAD), otherwise returns default.
+// The reported Content-Type is returned alongside so callers can detect formats Slides rejects.
+func validateImageURL(ctx context.Context, imageURL, defaultURL string) (string, string) {
+	if !strings.HasPrefix(strings.ToLower(imageURL), "https://") {
+		return defaultURL, ""
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, imageURL, nil)
+	if err != nil {
+		return defaultURL, ""
+	}
+	httpClient := &http.Client{Timeout: 5 * time.Second}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return defaultURL, ""
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return defaultURL, ""
+	}
+	ct := strings.ToLower(resp.Header.Get("Content-Type"))
+	if !strings.HasPrefix(ct, "image/") && ct != "" {
+		return defaultURL, ""
+	}
+	return imageURL, ct
+}
+
+// processImage makes an image URL insertable and applies post-processing: SVGs are
+// rasterized to PNG (Slides CreateImage rejects them) and the watermark, if enabled, is
+// composited on. Processed images, and images from a host that refuses other sites (see
+// hot), are uploaded to Drive and the Drive URL is returned; images needing none of this
+// are returned unchanged without being downloaded.
+func processImage(ctx context.Context, driveSvc *drive.Service, imageURL, contentType string, wm watermark.Options, hot hotlink.Result) (string, error) {
+	if !svgraster.IsSVGURL(imageURL) && !svgraster.IsSVG(contentType, nil) && !wm.Enabled() && !hot.Protected {
+		return imageURL, nil
+	}
+	if driveSvc == nil {
+		return "", fmt.Errorf("drive service unavailable")
+	}
+	data, ct, err := fetchImageBytes(ctx, imageURL, hot.Referer)
+	if err != nil {
+		return "", err
+	}
+	isSVG := svgraster.IsSVG(ct, data)
+	if !isSVG && !wm.Enabled() {
+		if !hot.Protected {
+			return imageURL, nil
+		}
+		if ct == "" {
+			ct = http.DetectContentType(data)
+		}
+		return driveupload.UploadPublicImage(ctx, driveSvc, "slide-image-"+uuid.New().String()[:8], ct, data)
+	}
+	if isSVG {
+		if data, err = svgraster.ToPNG(data, svgraster.DefaultSize); err != nil {
+			return "", err
+		}
+	}
+	if wm.Enabled() {
+		if data, err = watermark.Apply(data, wm); err != nil {
+			return "", err
+		}
+	}
+	return driveupload.UploadPublicImage(ctx, driveSvc, "slide-image-"+uuid.New().String()[:8]+".png", "image/png", data)
+}
+
+// hostFallbackImages turns fallback pool entries into image URLs: local files are
+// uploaded to Drive, and URLs are kept when they pass the HTTPS HEAD check. Entries that
+// fail are logged and left out.
+func hostFallbackImages(ctx context.Context, driveSvc *drive.Service, entries []string) []string {
+	var urls []string
+	for _, e := range entries {
+		if !fallbackimg.IsLocal(e) {
+			if u, _ := validateImageURL(ctx, e, ""); u != "" {
+				urls = append(urls, u)
+			} else {
+				log.Printf("warning: fallback image %s is not a reachable HTTPS image; skipping it", e)
+			}
+			continue
+		}
+		data, err := os.ReadFile(e)
+		if err == nil {
+			var u string
+			if u, err = driveupload.UploadPublicImage(ctx, driveSvc, "fallback-"+filepath.Base(e), http.DetectContentType(data), data); err == nil {
+				urls = append(urls, u)
+				continue
+			}
+		}
+		log.Printf("warning: fallback image %s: %v; skipping it", e, err)
+	}
+	return urls
+}
+
+// renderChartImage draws the dataset locally and hosts it on Drive so Slides can fetch it.
+func renderChartImage(ctx context.Context, driveSvc *drive.Service, ds charts.DatasetSpec) (string, error) {
+	data, err := charts.RenderPNG(ds, charts.RenderWidth, charts.RenderHeight)
+	if err != nil {
+		return "", err
+	}
+	return driveupload.UploadPublicImage(ctx, driveSvc, "chart-"+charts.Slug(ds.Title)+"-"+uuid.New().String()[:8]+".png", "image/png", data)
+}
+
+// fetchImageBytes downloads an image (capped at 10 MB) with referer, if any, and returns
+// its bytes and Content-Type.
+func fetchImageBytes(ctx context.Context, imageURL, referer string) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if referer != "" {
+		req.Header.Set("Referer", referer)
+	}
+	httpClient := &http.Client{Timeout: 10 * time.Second}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("fetch image: http %d", resp.StatusCode)
+	}
+	const maxImageBytes = 10 << 20
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageBytes))
+	if err != nil {
+		return nil, "", fmt.Errorf("read image: %w", err)
+	}
+	return data, resp.Header.Get("Content-Type"), nil
+}
+
+// watermarkOptions builds overlay options from flags; both logo and text empty disables watermarking.
+func watermarkOptions(logoPath, text, position string) (watermark.Options, error) {
+	pos, err := watermark.ParsePosition(position)
+	if err != nil {
+		return watermark.Options{}, err
+	}
+	opts := watermark.Options{Text: text, Position: pos}
+	if logoPath != "" {
+		logo, err := os.ReadFile(logoPath)
+		if err != nil {
+			return watermark.Options{}, fmt.Errorf("read logo: %w", err)
+		}
+		opts.Logo = logo
+	}
+	return opts, nil
+}
+
+// geminiEmbedder embeds texts with a Gemini embedding model, all in one request.
+func geminiEmbedder(client *llm.Client, model string) imagesearch.Embedder {
+	return func(ctx context.Context, texts []string) ([][]float32, error) {
+		contents := make([]*genai.Content, len(texts))
+		for i, t := range texts {
+			contents[i] = genai.NewContentFromText(t, genai.RoleUser)
+		}
+		res, err := client.EmbedContent(ctx, model, contents, &genai.EmbedContentConfig{TaskType: "SEMANTIC_SIMILARITY"})
+		if err != nil {
+			return nil, err
+		}
+		vecs := make([][]float32, len(res.Embeddings))
+		for i, e := range res.Embeddings {
+			vecs[i] = e.Values
+		}
+		return vecs, nil
+	}
+}
+
+// galleryCandidates is how many search results a plan's image gallery lists per topic.
+const galleryCandidates = 3
+
+// addImageGallery searches images for every topic, up to workers at once, and lists the
+// top results in its image plan, the first of them as chosen. Only Custom Search (and the
+// embedder) is called: candidates are not checked, moderated, or rehosted until a build.
+// A topic whose search fails keeps a plan without candidates.
+func addImageGallery(ctx context.Context, topics []TopicSummary, cseKey, cseCX string, search imagesearch.Options, embed imagesearch.Embedder, workers int) {
+	_ = pipeline.Run(ctx, len(topics), workers, func(ctx context.Context, i int) error {
+		t := &topics[i]
+		if t.Image.Strategy != strategySearch || t.ImageURL != "" {
+			return nil
+		}
+		cands, err := imagesearch.SearchImages(ctx, cseKey, cseCX, t.Image.Query, search)
+		if err != nil {
+			log.Printf("warning: image gallery for %q: %v", t.Topic, err)
+			return nil
+		}
+		if embed != nil {
+			if cands, err = imagesearch.RankBySimilarity(ctx, cands, t.Topic+"\n"+t.Summary, embed); err != nil {
+				log.Printf("warning: ranking images for %q by word matches: %v", t.Topic, err)
+			}
+		}
+		for _, c := range cands[:min(len(cands), galleryCandidates)] {
+			score := float64(c.Score)
+			if embed != nil && err == nil {
+				score = c.Similarity
+			}
+			t.Image.Candidates = append(t.Image.Candidates, ImageCandidate{URL: c.Link, Thumbnail: c.Image.ThumbnailLink, Source: c.Image.ContextLink, Score: score})
+		}
+		t.Image.Chosen = t.Image.Candidates[0].URL
+		return nil
+	})
+}
+
+// chosenImage returns the image a plan built with --plan chose for t, once it passes the
+// HTTPS HEAD check, or "" to search as usual. With hotlinks, an image from a host that
+// refuses other sites is re-hosted on Drive first.
+func chosenImage(ctx context.Context, t TopicSummary, hotlinks *hotlink.Detector, driveSvc *drive.Service) string {
+	if t.Image == nil || t.Image.Chosen == "" {
+		return ""
+	}
+	var page string
+	for _, c := range t.Image.Candidates {
+		if c.URL == t.Image.Chosen {
+			page = c.Source
+		}
+	}
+	imgURL, err := checkedImage(ctx, t.Image.Chosen, page, hotlinks, driveSvc)
+	if err != nil {
+		log.Printf("warning: chosen image %s for %q: %v; searching instead", t.Image.Chosen, t.Topic, err)
+		return ""
+	}
+	return imgURL
+}
+
+// pinnedImage returns t's pinned ImageURL, checked like a chosen image, or "" when it
+// fails: a pinned topic is never searched, so it is left without an image instead.
+func pinnedImage(ctx context.Context, t TopicSummary, hotlinks *hotlink.Detector, driveSvc *drive.Service) string {
+	imgURL, err := checkedImage(ctx, t.ImageURL, "", hotlinks, driveSvc)
+	if err != nil {
+		log.Printf("warning: pinned image %s for %q: %v; leaving the slide without an image", t.ImageURL, t.Topic, err)
+		return ""
+	}
+	return imgURL
+}
+
+// checkedImage returns imageURL once it passes the HTTPS HEAD check, re-hosted on Drive
+// when hotlinks finds that its host, seen from page, refuses other sites.
+func checkedImage(ctx context.Context, imageURL, page string, hotlinks *hotlink.Detector, driveSvc *drive.Service) (string, error) {
+	imgURL, ct := validateImageURL(ctx, imageURL, "")
+	if imgURL == "" {
+		return "", fmt.Errorf("unreachable or not an image")
+	}
+	if hotlinks == nil {
+		return imgURL, nil
+	}
+	hot, err := hotlinks.Check(ctx, imgURL, page)
+	if err == nil && hot.Protected {
+		imgURL, err = processImage(ctx, driveSvc, imgURL, ct, watermark.Options{}, hot)
+	}
+	return imgURL, err
+}
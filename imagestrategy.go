@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gogemini-practices/internal/driveupload"
+	"gogemini-practices/internal/picturegen"
+	"gogemini-practices/internal/watermark"
+
+	"github.com/google/uuid"
+	"google.golang.org/api/drive/v3"
+)
+
+// Image strategies: how a topic gets its image. Auto is only a --image-strategy value; it
+// lets the outline model choose search, generate, or none for each topic.
+const (
+	strategySearch   = "search"
+	strategyGenerate = "generate"
+	strategyNone     = "none"
+	strategyAuto     = "auto"
+)
+
+// parseImageStrategy validates the --image-strategy flag.
+func parseImageStrategy(s string) (string, error) {
+	switch v := strings.ToLower(strings.TrimSpace(s)); v {
+	case "":
+		return strategySearch, nil
+	case strategySearch, strategyGenerate, strategyNone, strategyAuto:
+		return v, nil
+	}
+	return "", fmt.Errorf("unknown image strategy %q (search|generate|none|auto)", s)
+}
+
+// imageStrategyPrompt asks the outline model for each topic's image strategy, with
+// --image-strategy auto.
+const imageStrategyPrompt = "\n\nIMAGE STRATEGY: Add \"image_strategy\" to each topic: \"search\" when a real photograph fits it (a place, product, person, event, or physical object), \"generate\" when an abstract idea is better shown by an illustrative graphic (a process, concept, metaphor, or something that can't be photographed), or \"none\" when no image would help (e.g. a code or agenda topic)."
+
+// sanitizeImageStrategy drops a topic's strategy, model-written or in its image plan, that
+// isn't search, generate, or none, so the flag applies instead.
+func sanitizeImageStrategy(t *TopicSummary) {
+	valid := func(s string) string {
+		switch s = strings.ToLower(strings.TrimSpace(s)); s {
+		case "", strategySearch, strategyGenerate, strategyNone:
+			return s
+		}
+		log.Printf("warning: ignoring image strategy %q for %q", s, t.Topic)
+		return ""
+	}
+	t.ImageStrategy = valid(t.ImageStrategy)
+	if t.Image != nil {
+		t.Image.Strategy = valid(t.Image.Strategy)
+	}
+}
+
+// sanitizeImagePin drops a plan's pinned image that isn't an https URL, which Slides can't
+// fetch, and the pin of a topic that is also set to have no image.
+func sanitizeImagePin(t *TopicSummary) {
+	t.ImageURL = strings.TrimSpace(t.ImageURL)
+	switch {
+	case t.ImageURL == "":
+	case t.NoImage:
+		log.Printf("warning: %q has no_image set; ignoring its image_url", t.Topic)
+		t.ImageURL = ""
+	case !strings.HasPrefix(strings.ToLower(t.ImageURL), "https://"):
+		log.Printf("warning: ignoring image_url %q for %q: not an https URL", t.ImageURL, t.Topic)
+		t.ImageURL = ""
+	}
+}
+
+// dropModelPins clears the image pins of topics the model wrote: only a plan's author pins
+// images, not the model.
+func dropModelPins(topics []TopicSummary) {
+	for i := range topics {
+		topics[i].ImageURL, topics[i].NoImage = "", false
+	}
+}
+
+// parseTopicImages reads --topic-image values, N=URL or N=none, into the pinned image of
+// each 1-based topic number, with noImagePin for none. A later value for a topic wins.
+func parseTopicImages(values []string) (map[int]string, error) {
+	pins := map[int]string{}
+	for _, v := range values {
+		n, target, ok := strings.Cut(v, "=")
+		num, err := strconv.Atoi(strings.TrimSpace(n))
+		if !ok || err != nil || num < 1 {
+			return nil, fmt.Errorf("%q must be N=URL or N=none with N a topic number from 1", v)
+		}
+		target = strings.TrimSpace(target)
+		switch {
+		case strings.EqualFold(target, strategyNone):
+			target = noImagePin
+		case !strings.HasPrefix(strings.ToLower(target), "https://"):
+			return nil, fmt.Errorf("%q: the image must be an https URL or none", v)
+		}
+		pins[num] = target
+	}
+	return pins, nil
+}
+
+// noImagePin is a --topic-image pin that leaves the topic without an image.
+const noImagePin = ""
+
+// applyTopicImages pins the --topic-image images onto the topics, over what a plan says.
+// Pins for topics the deck doesn't have are logged and ignored.
+func applyTopicImages(topics []TopicSummary, pins map[int]string) {
+	for n, url := range pins {
+		if n > len(topics) {
+			log.Printf("warning: --topic-image %d: the deck has only %d topics", n, len(topics))
+			continue
+		}
+		t := &topics[n-1]
+		t.ImageURL, t.NoImage = url, url == noImagePin
+	}
+}
+
+// topicImageStrategy resolves a topic's image strategy: none for a topic set to have no
+// image, then its image plan's, when a plan built with --plan sets one, then the flag,
+// where auto takes the model's choice and falls back to search. A pinned ImageURL
+// replaces whichever it is.
+func topicImageStrategy(t TopicSummary, flagStrategy string) string {
+	if t.NoImage {
+		return strategyNone
+	}
+	if t.Image != nil && t.Image.Strategy != "" {
+		return t.Image.Strategy
+	}
+	if flagStrategy != strategyAuto {
+		return flagStrategy
+	}
+	if t.ImageStrategy != "" {
+		return t.ImageStrategy
+	}
+	return strategySearch
+}
+
+// imageGenerator makes an illustrative image for topics with the generate strategy and
+// hosts it on Drive. generate is safe for concurrent use.
+type imageGenerator struct {
+	apiKey   string
+	cache    *picturegen.Cache // nil generates every image anew
+	style    picturegen.Style
+	subject  string   // the deck's subject, keeps images on theme
+	mood     string   // the deck's tone
+	colors   []string // the palette's colors, if any
+	driveSvc *drive.Service
+	wm       watermark.Options
+}
+
+// generatedImageConfig frames generated images like the 4:3 title slide image.
+var generatedImageConfig = picturegen.ImageConfig{AspectRatio: picturegen.AspectStandard, Width: 1200}
+
+// generate returns the Drive URL of an image generated for the topic.
+func (g *imageGenerator) generate(ctx context.Context, t TopicSummary) (string, error) {
+	if g.driveSvc == nil {
+		return "", fmt.Errorf("drive service unavailable")
+	}
+	prompt, err := picturegen.PromptSpec{Style: g.style, Subject: t.Topic, Context: g.subject, Mood: g.mood, ColorHints: g.colors}.Build()
+	if err != nil {
+		return "", err
+	}
+	var img *picturegen.GeneratedImage
+	if g.cache != nil {
+		img, err = g.cache.GenerateImage(ctx, prompt, g.apiKey, generatedImageConfig)
+	} else {
+		img, err = picturegen.GenerateImage(ctx, prompt, g.apiKey, generatedImageConfig)
+	}
+	if err != nil {
+		return "", err
+	}
+	data, ct := img.Data, http.DetectContentType(img.Data)
+	if g.wm.Enabled() {
+		if data, err = watermark.Apply(data, g.wm); err != nil {
+			return "", err
+		}
+		ct = "image/png"
+	}
+	return driveupload.UploadPublicImage(ctx, g.driveSvc, "generated-"+uuid.New().String()[:8], ct, data)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTopicImages(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []string
+		want    map[int]string
+		wantErr bool
+	}{
+		{"url", []string{"2=https://example.com/a.png"}, map[int]string{2: "https://example.com/a.png"}, false},
+		{"none", []string{"1=none", "3= NONE "}, map[int]string{1: noImagePin, 3: noImagePin}, false},
+		{"later wins", []string{"1=https://example.com/a.png", "1=none", "2=none", "2=https://example.com/b.png"}, map[int]string{1: noImagePin, 2: "https://example.com/b.png"}, false},
+		{"no values", nil, map[int]string{}, false},
+		{"topic zero", []string{"0=none"}, nil, true},
+		{"not a number", []string{"two=none"}, nil, true},
+		{"no equals", []string{"https://example.com/a.png"}, nil, true},
+		{"http url", []string{"1=http://example.com/a.png"}, nil, true},
+		{"file path", []string{"1=/tmp/a.png"}, nil, true},
+		{"empty target", []string{"1="}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTopicImages(tt.values)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTopicImages(%q) error = %v, wantErr %v", tt.values, err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseTopicImages(%q) = %v, want %v", tt.values, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyTopicImages(t *testing.T) {
+	topics := []TopicSummary{
+		{Topic: "a", ImageURL: "https://example.com/plan.png"},
+		{Topic: "b"},
+		{Topic: "c", NoImage: true},
+	}
+	applyTopicImages(topics, map[int]string{
+		1: noImagePin,
+		2: "https://example.com/b.png",
+		3: "https://example.com/c.png",
+		4: "https://example.com/past-the-end.png",
+	})
+	want := []TopicSummary{
+		{Topic: "a", NoImage: true},
+		{Topic: "b", ImageURL: "https://example.com/b.png"},
+		{Topic: "c", ImageURL: "https://example.com/c.png"},
+	}
+	if !reflect.DeepEqual(topics, want) {
+		t.Errorf("applyTopicImages() = %+v, want %+v", topics, want)
+	}
+}
+
+func TestSanitizeImagePin(t *testing.T) {
+	tests := []struct {
+		name string
+		in   TopicSummary
+		want TopicSummary
+	}{
+		{"https kept", TopicSummary{ImageURL: " https://example.com/a.png "}, TopicSummary{ImageURL: "https://example.com/a.png"}},
+		{"uppercase scheme", TopicSummary{ImageURL: "HTTPS://example.com/a.png"}, TopicSummary{ImageURL: "HTTPS://example.com/a.png"}},
+		{"http dropped", TopicSummary{ImageURL: "http://example.com/a.png"}, TopicSummary{}},
+		{"data URL dropped", TopicSummary{ImageURL: "data:image/png;base64,AAAA"}, TopicSummary{}},
+		{"no image wins", TopicSummary{ImageURL: "https://example.com/a.png", NoImage: true}, TopicSummary{NoImage: true}},
+		{"no pin", TopicSummary{}, TopicSummary{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.in
+			sanitizeImagePin(&got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sanitizeImagePin(%+v) = %+v, want %+v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDropModelPins(t *testing.T) {
+	topics := []TopicSummary{
+		{Topic: "a", ImageURL: "https://example.com/a.png"},
+		{Topic: "b", NoImage: true},
+		{Topic: "c"},
+	}
+	dropModelPins(topics)
+	for _, tp := range topics {
+		if tp.ImageURL != "" || tp.NoImage {
+			t.Errorf("%s: pin kept: %+v", tp.Topic, tp)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+// Package audit appends every destructive Slides and Sheets call a run makes (object and
+// sheet deletions, value clears) to a JSON Lines file, so a deletion in a shared deck or
+// spreadsheet can be traced to the run that made it. It wraps the presentation and charts
+// APIs, so the writers themselves don't change.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"gogemini-practices/internal/charts"
+	"gogemini-practices/internal/presentation"
+
+	"google.golang.org/api/sheets/v4"
+	"google.golang.org/api/slides/v1"
+)
+
+// Entry is one destructive operation. A batch that deletes several objects gets one entry
+// per object, all with the batch's outcome.
+type Entry struct {
+	Time    time.Time `json:"time"`
+	Command string    `json:"command"` // build or cleanup
+	RunID   string    `json:"run_id,omitempty"`
+	Service string    `json:"service"` // slides or sheets
+	FileID  string    `json:"file_id"` // presentation or spreadsheet ID
+	Op      string    `json:"op"`      // DeleteObject, DeleteSheet, DeleteNamedRange, DeleteEmbeddedObject, ClearValues
+	Target  string    `json:"target"`  // object ID, sheet ID, named range ID, or A1 range
+	Error   string    `json:"error,omitempty"`
+}
+
+// Log appends entries to a file. It is safe for concurrent use; a nil *Log records nothing.
+type Log struct {
+	path    string
+	command string
+	mu      sync.Mutex
+	err     error // first failed write
+}
+
+// New returns a Log appending to path for command, after checking that the file can be
+// written, so a run doesn't delete anything it can't record. An empty path gives a nil Log.
+func New(path, command string) (*Log, error) {
+	if path == "" {
+		return nil, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create audit log dir: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open audit log: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("open audit log: %w", err)
+	}
+	return &Log{path: path, command: command}, nil
+}
+
+// Err returns the first entry write that failed. A failed write doesn't fail the call it
+// records, which has already happened.
+func (l *Log) Err() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.err
+}
+
+// Write appends entries, one JSON line each.
+func (l *Log) Write(entries []Entry) error {
+	if l == nil || len(entries) == 0 {
+		return nil
+	}
+	var buf []byte
+	for _, e := range entries {
+		e.Command = l.command
+		line, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		buf = append(append(buf, line...), '\n')
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	err := l.append(buf)
+	if err != nil && l.err == nil {
+		l.err = err
+	}
+	return err
+}
+
+func (l *Log) append(buf []byte) error {
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open audit log: %w", err)
+	}
+	if _, err := f.Write(buf); err != nil {
+		f.Close()
+		return fmt.Errorf("write audit log: %w", err)
+	}
+	return f.Close()
+}
+
+// record writes the entries with the call's outcome and returns that outcome.
+func (l *Log) record(entries []Entry, callErr error) error {
+	now := time.Now().UTC()
+	for i := range entries {
+		entries[i].Time = now
+		if callErr != nil {
+			entries[i].Error = callErr.Error()
+		}
+	}
+	_ = l.Write(entries) // kept in l.err
+	return callErr
+}
+
+// Slides wraps api so its destructive requests are logged under runID. A nil Log returns
+// api unchanged.
+func (l *Log) Slides(api presentation.SlidesAPI, runID string) presentation.SlidesAPI {
+	if l == nil || api == nil {
+		return api
+	}
+	return slidesAPI{api, l, runID}
+}
+
+type slidesAPI struct {
+	presentation.SlidesAPI
+	log   *Log
+	runID string
+}
+
+func (a slidesAPI) BatchUpdate(ctx context.Context, presentationID string, requests []*slides.Request) error {
+	var entries []Entry
+	for _, r := range requests {
+		if r.DeleteObject != nil {
+			entries = append(entries, Entry{RunID: a.runID, Service: "slides", FileID: presentationID, Op: "DeleteObject", Target: r.DeleteObject.ObjectId})
+		}
+	}
+	err := a.SlidesAPI.BatchUpdate(ctx, presentationID, requests)
+	if len(entries) == 0 {
+		return err
+	}
+	return a.log.record(entries, err)
+}
+
+// Sheets wraps api so its destructive requests and clears are logged under runID. A nil
+// Log returns api unchanged.
+func (l *Log) Sheets(api charts.SheetsAPI, runID string) charts.SheetsAPI {
+	if l == nil || api == nil {
+		return api
+	}
+	return sheetsAPI{api, l, runID}
+}
+
+type sheetsAPI struct {
+	charts.SheetsAPI
+	log   *Log
+	runID string
+}
+
+func (a sheetsAPI) BatchUpdate(ctx context.Context, spreadsheetID string, req *sheets.BatchUpdateSpreadsheetRequest) (*sheets.BatchUpdateSpreadsheetResponse, error) {
+	var entries []Entry
+	add := func(op, target string) {
+		entries = append(entries, Entry{RunID: a.runID, Service: "sheets", FileID: spreadsheetID, Op: op, Target: target})
+	}
+	for _, r := range req.Requests {
+		switch {
+		case r.DeleteSheet != nil:
+			add("DeleteSheet", strconv.FormatInt(r.DeleteSheet.SheetId, 10))
+		case r.DeleteNamedRange != nil:
+			add("DeleteNamedRange", r.DeleteNamedRange.NamedRangeId)
+		case r.DeleteEmbeddedObject != nil:
+			add("DeleteEmbeddedObject", strconv.FormatInt(r.DeleteEmbeddedObject.ObjectId, 10))
+		}
+	}
+	res, err := a.SheetsAPI.BatchUpdate(ctx, spreadsheetID, req)
+	if len(entries) == 0 {
+		return res, err
+	}
+	return res, a.log.record(entries, err)
+}
+
+func (a sheetsAPI) ClearValues(ctx context.Context, spreadsheetID string, ranges []string) error {
+	entries := make([]Entry, len(ranges))
+	for i, rng := range ranges {
+		entries[i] = Entry{RunID: a.runID, Service: "sheets", FileID: spreadsheetID, Op: "ClearValues", Target: rng}
+	}
+	return a.log.record(entries, a.SheetsAPI.ClearValues(ctx, spreadsheetID, ranges))
+}
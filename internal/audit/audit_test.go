@@ -0,0 +1,94 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gogemini-practices/internal/fakeapi"
+
+	"google.golang.org/api/sheets/v4"
+	"google.golang.org/api/slides/v1"
+)
+
+func TestLog(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "logs", "audit.jsonl")
+	l, err := New(path, "build")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	sl := l.Slides(&fakeapi.Slides{}, "run1")
+	if err := sl.BatchUpdate(ctx, "deck", []*slides.Request{
+		{CreateSlide: &slides.CreateSlideRequest{ObjectId: "auto_new"}},
+		{DeleteObject: &slides.DeleteObjectRequest{ObjectId: "auto_old"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	failing := l.Slides(&fakeapi.Slides{Err: errors.New("403")}, "run1")
+	if err := failing.BatchUpdate(ctx, "deck", []*slides.Request{{DeleteObject: &slides.DeleteObjectRequest{ObjectId: "auto_kept"}}}); err == nil {
+		t.Fatal("want the call's error")
+	}
+	sh := l.Sheets(&fakeapi.Sheets{}, "run1")
+	if _, err := sh.BatchUpdate(ctx, "sheet", &sheets.BatchUpdateSpreadsheetRequest{Requests: []*sheets.Request{
+		{DeleteNamedRange: &sheets.DeleteNamedRangeRequest{NamedRangeId: "nr1"}},
+		{DeleteSheet: &sheets.DeleteSheetRequest{SheetId: 7}},
+		{AddSheet: &sheets.AddSheetRequest{Properties: &sheets.SheetProperties{Title: "x"}}},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sh.ClearValues(ctx, "sheet", []string{"'run1-1-sales'!A:Z"}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Entry{
+		{Command: "build", RunID: "run1", Service: "slides", FileID: "deck", Op: "DeleteObject", Target: "auto_old"},
+		{Command: "build", RunID: "run1", Service: "slides", FileID: "deck", Op: "DeleteObject", Target: "auto_kept", Error: "403"},
+		{Command: "build", RunID: "run1", Service: "sheets", FileID: "sheet", Op: "DeleteNamedRange", Target: "nr1"},
+		{Command: "build", RunID: "run1", Service: "sheets", FileID: "sheet", Op: "DeleteSheet", Target: "7"},
+		{Command: "build", RunID: "run1", Service: "sheets", FileID: "sheet", Op: "ClearValues", Target: "'run1-1-sales'!A:Z"},
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var got []Entry
+	for sc := bufio.NewScanner(f); sc.Scan(); {
+		var e Entry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			t.Fatalf("line %q: %v", sc.Text(), err)
+		}
+		if e.Time.IsZero() {
+			t.Errorf("entry %+v has no time", e)
+		}
+		got = append(got, e)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d entries, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		got[i].Time = want[i].Time
+		if got[i] != want[i] {
+			t.Errorf("entry %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if err := l.Err(); err != nil {
+		t.Errorf("Err() = %v", err)
+	}
+
+	var none *Log
+	if api := none.Slides(&fakeapi.Slides{}, "run1"); api == nil {
+		t.Error("nil Log: want the API unwrapped")
+	} else if _, ok := api.(*fakeapi.Slides); !ok {
+		t.Errorf("nil Log wrapped the API: %T", api)
+	}
+	if l, err := New("", "build"); l != nil || err != nil {
+		t.Errorf("New(\"\") = %v, %v; want nil, nil", l, err)
+	}
+}
@@ -0,0 +1,255 @@
+// Package bqdata runs BigQuery SQL and turns the results into chart datasets, so charts
+// show warehouse numbers while the model writes the narrative around them.
+package bqdata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"google.golang.org/api/bigquery/v2"
+)
+
+// MaxRows is the most result rows a dataset keeps; LIMIT the query to choose which.
+const MaxRows = 20
+
+// Query is one SQL query and how to chart its result. Title, Unit, and Type are optional:
+// the title defaults to the first value column's name and the type is derived from the
+// label column (see ToDataset).
+type Query struct {
+	Title string `json:"title,omitempty"`
+	Unit  string `json:"unit,omitempty"`
+	Type  string `json:"type,omitempty"` // timeseries | category | comparison | composition
+	SQL   string `json:"sql"`
+}
+
+// LoadQueries reads a JSON array of Query, as written for --bq-queries.
+func LoadQueries(path string) ([]Query, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read queries: %w", err)
+	}
+	var qs []Query
+	if err := json.Unmarshal(b, &qs); err != nil {
+		return nil, fmt.Errorf("parse queries %s: %w", path, err)
+	}
+	for i, q := range qs {
+		if strings.TrimSpace(q.SQL) == "" {
+			return nil, fmt.Errorf("queries %s: entry %d has no sql", path, i+1)
+		}
+	}
+	return qs, nil
+}
+
+// Column is a result column and its BigQuery type (STRING, INT64, DATE, ...).
+type Column struct {
+	Name string
+	Type string
+}
+
+// Table is a query result. Cells are BigQuery's string encoding of each value; nil is NULL.
+type Table struct {
+	Columns []Column
+	Rows    [][]*string
+}
+
+// QueryAPI is the BigQuery call the connector makes. NewQueryAPI adapts the generated client.
+type QueryAPI interface {
+	Query(ctx context.Context, projectID, sql string) (*Table, error)
+}
+
+// NewQueryAPI wraps a BigQuery service; a nil service gives a nil QueryAPI.
+func NewQueryAPI(svc *bigquery.Service) QueryAPI {
+	if svc == nil {
+		return nil
+	}
+	return queryService{svc}
+}
+
+type queryService struct{ svc *bigquery.Service }
+
+// Query runs sql as a standard SQL query billed to projectID and waits for the result.
+func (s queryService) Query(ctx context.Context, projectID, sql string) (*Table, error) {
+	useLegacy := false
+	resp, err := s.svc.Jobs.Query(projectID, &bigquery.QueryRequest{
+		Query:        sql,
+		UseLegacySql: &useLegacy,
+		MaxResults:   MaxRows + 1,
+		TimeoutMs:    30000,
+	}).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+	schema, rows, complete, job := resp.Schema, resp.Rows, resp.JobComplete, resp.JobReference
+	for !complete {
+		if job == nil {
+			return nil, fmt.Errorf("query did not complete and has no job to wait on")
+		}
+		r, err := s.svc.Jobs.GetQueryResults(projectID, job.JobId).Location(job.Location).MaxResults(MaxRows + 1).TimeoutMs(30000).Context(ctx).Do()
+		if err != nil {
+			return nil, err
+		}
+		schema, rows, complete = r.Schema, r.Rows, r.JobComplete
+	}
+	if schema == nil {
+		return nil, fmt.Errorf("query returned no schema")
+	}
+
+	t := &Table{}
+	for _, f := range schema.Fields {
+		if f.Mode == "REPEATED" || f.Type == "RECORD" || f.Type == "STRUCT" {
+			return nil, fmt.Errorf("column %q is %s %s; select scalar columns", f.Name, f.Mode, f.Type)
+		}
+		t.Columns = append(t.Columns, Column{Name: f.Name, Type: f.Type})
+	}
+	for _, r := range rows {
+		row := make([]*string, len(t.Columns))
+		for i, c := range r.F {
+			if i < len(row) {
+				if v, ok := c.V.(string); ok {
+					row[i] = &v
+				}
+			}
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	return t, nil
+}
+
+// Point is one labeled row; Values has one entry per value column.
+type Point struct {
+	Label  string
+	Values []float64
+}
+
+// Dataset is a query result shaped for a chart.
+type Dataset struct {
+	Title  string
+	Unit   string
+	Type   string
+	Series []string // value column names when there are several
+	Points []Point
+}
+
+// numericTypes are the column types that chart as values.
+var numericTypes = map[string]bool{"INTEGER": true, "INT64": true, "FLOAT": true, "FLOAT64": true, "NUMERIC": true, "BIGNUMERIC": true}
+
+// ToDataset charts a result: the first column holds the labels and every other column must
+// be numeric, one series each. Rows with a NULL cell are skipped and at most MaxRows are
+// kept. Without q.Type, DATE, DATETIME, and TIMESTAMP labels make a timeseries and anything
+// else a category chart.
+func ToDataset(t *Table, q Query) (Dataset, error) {
+	if len(t.Columns) < 2 {
+		return Dataset{}, fmt.Errorf("want a label column and at least one value column, got %d columns", len(t.Columns))
+	}
+	ds := Dataset{Title: strings.TrimSpace(q.Title), Unit: strings.TrimSpace(q.Unit), Type: strings.TrimSpace(q.Type)}
+	values := t.Columns[1:]
+	for _, c := range values {
+		if !numericTypes[c.Type] {
+			return Dataset{}, fmt.Errorf("column %q is %s; columns after the label must be numeric", c.Name, c.Type)
+		}
+	}
+	if len(values) > 1 {
+		for _, c := range values {
+			ds.Series = append(ds.Series, humanize(c.Name))
+		}
+	}
+	if ds.Title == "" {
+		ds.Title = humanize(values[0].Name)
+	}
+	labelType := t.Columns[0].Type
+	if ds.Type == "" {
+		ds.Type = "category"
+		if labelType == "DATE" || labelType == "DATETIME" || labelType == "TIMESTAMP" {
+			ds.Type = "timeseries"
+		}
+	}
+
+rows:
+	for _, row := range t.Rows {
+		if len(ds.Points) == MaxRows {
+			break
+		}
+		if len(row) < len(t.Columns) || row[0] == nil {
+			continue
+		}
+		p := Point{Label: formatLabel(*row[0], labelType)}
+		for i := range values {
+			cell := row[i+1]
+			if cell == nil {
+				continue rows
+			}
+			v, err := strconv.ParseFloat(*cell, 64)
+			if err != nil {
+				return Dataset{}, fmt.Errorf("column %q: %w", values[i].Name, err)
+			}
+			p.Values = append(p.Values, v)
+		}
+		ds.Points = append(ds.Points, p)
+	}
+	if len(ds.Points) == 0 {
+		return Dataset{}, fmt.Errorf("no rows with a label and values")
+	}
+	return ds, nil
+}
+
+// formatLabel shortens date-like labels: TIMESTAMPs arrive as epoch seconds and DATETIMEs
+// at midnight keep only the date.
+func formatLabel(v, typ string) string {
+	switch typ {
+	case "TIMESTAMP":
+		if secs, err := strconv.ParseFloat(v, 64); err == nil {
+			t := time.Unix(0, int64(secs*1e9)).UTC()
+			if t.Equal(t.Truncate(24 * time.Hour)) {
+				return t.Format(time.DateOnly)
+			}
+			return t.Format("2006-01-02 15:04")
+		}
+	case "DATETIME":
+		return strings.TrimSuffix(strings.TrimSuffix(v, ".000000"), "T00:00:00")
+	}
+	return strings.TrimSpace(v)
+}
+
+// humanize turns a column name like weekly_revenue into "Weekly revenue".
+func humanize(name string) string {
+	s := strings.TrimSpace(strings.ReplaceAll(name, "_", " "))
+	if s == "" {
+		return name
+	}
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[size:]
+}
+
+// Describe is the dataset as compact text for a prompt, e.g.
+// `Weekly revenue (USD, timeseries): 2026-10-05=12000; 2026-10-12=13500`.
+func (d Dataset) Describe() string {
+	var b strings.Builder
+	b.WriteString(d.Title)
+	b.WriteString(" (")
+	if d.Unit != "" {
+		b.WriteString(d.Unit + ", ")
+	}
+	b.WriteString(d.Type)
+	if len(d.Series) > 0 {
+		b.WriteString("; series " + strings.Join(d.Series, " / "))
+	}
+	b.WriteString("): ")
+	for i, p := range d.Points {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		vals := make([]string, len(p.Values))
+		for j, v := range p.Values {
+			vals[j] = strconv.FormatFloat(v, 'g', -1, 64)
+		}
+		b.WriteString(p.Label + "=" + strings.Join(vals, "/"))
+	}
+	return b.String()
+}
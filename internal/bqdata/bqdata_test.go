@@ -0,0 +1,152 @@
+package bqdata
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/bigquery/v2"
+	"google.golang.org/api/option"
+)
+
+func str(s string) *string { return &s }
+
+func TestToDataset(t *testing.T) {
+	weekly := &Table{
+		Columns: []Column{{"week", "DATE"}, {"weekly_revenue", "INT64"}},
+		Rows:    [][]*string{{str("2026-10-05"), str("12000")}, {str("2026-10-12"), nil}, {str("2026-10-19"), str("13500")}},
+	}
+	tests := []struct {
+		name    string
+		table   *Table
+		query   Query
+		want    Dataset
+		wantErr bool
+	}{
+		{
+			name:  "date labels make a timeseries; NULL rows are skipped",
+			table: weekly,
+			query: Query{Unit: "USD"},
+			want: Dataset{Title: "Weekly revenue", Unit: "USD", Type: "timeseries", Points: []Point{
+				{Label: "2026-10-05", Values: []float64{12000}},
+				{Label: "2026-10-19", Values: []float64{13500}},
+			}},
+		},
+		{
+			name: "several value columns become series",
+			table: &Table{
+				Columns: []Column{{"region", "STRING"}, {"orders_2025", "INT64"}, {"orders_2026", "FLOAT64"}},
+				Rows:    [][]*string{{str("EMEA"), str("10"), str("12.5")}, {nil, str("1"), str("2")}},
+			},
+			query: Query{Title: "Orders by region", Type: "comparison"},
+			want: Dataset{Title: "Orders by region", Type: "comparison", Series: []string{"Orders 2025", "Orders 2026"}, Points: []Point{
+				{Label: "EMEA", Values: []float64{10, 12.5}},
+			}},
+		},
+		{
+			name: "timestamps at midnight keep the date",
+			table: &Table{
+				Columns: []Column{{"day", "TIMESTAMP"}, {"users", "INTEGER"}},
+				Rows:    [][]*string{{str("1.7604864E9"), str("5")}},
+			},
+			want: Dataset{Title: "Users", Type: "timeseries", Points: []Point{{Label: "2025-10-15", Values: []float64{5}}}},
+		},
+		{
+			name:    "text value column",
+			table:   &Table{Columns: []Column{{"a", "STRING"}, {"b", "STRING"}}, Rows: [][]*string{{str("x"), str("y")}}},
+			wantErr: true,
+		},
+		{
+			name:    "one column",
+			table:   &Table{Columns: []Column{{"a", "INT64"}}},
+			wantErr: true,
+		},
+		{
+			name:    "no usable rows",
+			table:   &Table{Columns: []Column{{"a", "STRING"}, {"b", "INT64"}}, Rows: [][]*string{{str("x"), nil}}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ToDataset(tt.table, tt.query)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ToDataset() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ToDataset() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToDataset_CapsRows(t *testing.T) {
+	table := &Table{Columns: []Column{{"n", "STRING"}, {"v", "INT64"}}}
+	for i := 0; i < MaxRows+5; i++ {
+		table.Rows = append(table.Rows, []*string{str("x"), str("1")})
+	}
+	ds, err := ToDataset(table, Query{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ds.Points) != MaxRows {
+		t.Errorf("got %d points, want %d", len(ds.Points), MaxRows)
+	}
+}
+
+func TestDataset_Describe(t *testing.T) {
+	ds := Dataset{Title: "Orders", Unit: "k", Type: "comparison", Series: []string{"2025", "2026"}, Points: []Point{{Label: "EMEA", Values: []float64{10, 12.5}}, {Label: "US", Values: []float64{8, 9}}}}
+	want := "Orders (k, comparison; series 2025 / 2026): EMEA=10/12.5; US=8/9"
+	if got := ds.Describe(); got != want {
+		t.Errorf("Describe() = %q, want %q", got, want)
+	}
+}
+
+// TestQueryAPI_WaitsForJob serves a query that is still running on the first response and
+// checks the adapter polls the job for its rows.
+func TestQueryAPI_WaitsForJob(t *testing.T) {
+	var polled bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/projects/p1/queries"):
+			var req bigquery.QueryRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			if req.UseLegacySql == nil || *req.UseLegacySql {
+				t.Error("query should use standard SQL")
+			}
+			json.NewEncoder(w).Encode(bigquery.QueryResponse{JobComplete: false, JobReference: &bigquery.JobReference{JobId: "job1", Location: "EU"}})
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/projects/p1/queries/job1"):
+			polled = r.URL.Query().Get("location") == "EU"
+			json.NewEncoder(w).Encode(bigquery.GetQueryResultsResponse{
+				JobComplete: true,
+				Schema:      &bigquery.TableSchema{Fields: []*bigquery.TableFieldSchema{{Name: "region", Type: "STRING"}, {Name: "n", Type: "INTEGER"}}},
+				Rows:        []*bigquery.TableRow{{F: []*bigquery.TableCell{{V: "EMEA"}, {V: nil}}}},
+			})
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	svc, err := bigquery.NewService(context.Background(), option.WithEndpoint(srv.URL), option.WithHTTPClient(srv.Client()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := NewQueryAPI(svc).Query(context.Background(), "p1", "SELECT 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !polled {
+		t.Error("the running job was not polled in its location")
+	}
+	want := &Table{Columns: []Column{{"region", "STRING"}, {"n", "INTEGER"}}, Rows: [][]*string{{str("EMEA"), nil}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Query() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,300 @@
+// Package buildreport summarizes a build for debugging and for CI jobs that gate on it:
+// the slides each deck got and the Slides requests sent for them, the API calls per
+// backend, model retries, how long each stage took, and every warning the run logged. It
+// wraps the presentation API and the log output, so the writers themselves don't change,
+// and writes the summary as JSON or Markdown.
+package buildreport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gogemini-practices/internal/debugdump"
+	"gogemini-practices/internal/llm"
+	"gogemini-practices/internal/presentation"
+
+	"google.golang.org/api/slides/v1"
+)
+
+// warningPrefix marks the log lines collected as warnings.
+const warningPrefix = "warning: "
+
+// Report is the summary of one build.
+type Report struct {
+	Status     string    `json:"status"`          // ok, or the failure class the run exited with
+	Error      string    `json:"error,omitempty"` // the failure's message
+	Started    time.Time `json:"started"`
+	DurationMS int64     `json:"duration_ms"`
+	// SlidesCreated and RequestsSent total the decks'
+	SlidesCreated int    `json:"slides_created"`
+	RequestsSent  int    `json:"requests_sent"`
+	Decks         []Deck `json:"decks,omitempty"`
+	// APICalls are the HTTP requests sent to each backend (gemini, cse, slides, sheets,
+	// drive, vision, or another host), retries included
+	APICalls map[string]debugdump.Count `json:"api_calls,omitempty"`
+	Models   map[string]llm.Stats       `json:"models,omitempty"`
+	Retries  int                        `json:"retries"` // model retries over all models
+	Stages   []Stage                    `json:"stages,omitempty"`
+	Warnings []string                   `json:"warnings"` // empty, never null, for jq and friends
+}
+
+// Deck is what one presentation got.
+type Deck struct {
+	PresentationID string `json:"presentation_id"`
+	SlidesCreated  int    `json:"slides_created"` // by batches that succeeded
+	Requests       int    `json:"requests"`       // Slides requests sent, in Batches batchUpdate calls
+	Batches        int    `json:"batches"`
+	Error          string `json:"error,omitempty"`
+}
+
+// Stage is how long one part of the build took.
+type Stage struct {
+	Name       string `json:"name"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// Recorder collects a report while the build runs. It is safe for concurrent use; a nil
+// *Recorder records nothing, so callers can wrap unconditionally.
+type Recorder struct {
+	now      func() time.Time
+	started  time.Time
+	mu       sync.Mutex
+	decks    []*Deck
+	stages   []Stage
+	warnings []string
+}
+
+// New starts a report now.
+func New() *Recorder {
+	return &Recorder{now: time.Now, started: time.Now()}
+}
+
+// Stage starts timing the named stage and returns the func that ends it.
+func (r *Recorder) Stage(name string) (done func()) {
+	if r == nil {
+		return func() {}
+	}
+	start := r.now()
+	return func() {
+		elapsed := r.now().Sub(start)
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.stages = append(r.stages, Stage{Name: name, DurationMS: elapsed.Milliseconds()})
+	}
+}
+
+// Log returns a writer for the log output that passes everything on to w and keeps the
+// lines logged as "warning: ..." as the report's warnings. A nil Recorder returns w.
+func (r *Recorder) Log(w io.Writer) io.Writer {
+	if r == nil {
+		return w
+	}
+	return logWriter{r, w}
+}
+
+type logWriter struct {
+	r *Recorder
+	w io.Writer
+}
+
+// Write keeps the warnings of p, which the log package hands over a line at a time, without
+// the line's date and time.
+func (l logWriter) Write(p []byte) (int, error) {
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		if i := strings.Index(line, warningPrefix); i >= 0 {
+			l.r.mu.Lock()
+			l.r.warnings = append(l.r.warnings, line[i+len(warningPrefix):])
+			l.r.mu.Unlock()
+		}
+	}
+	return l.w.Write(p)
+}
+
+// Slides wraps api so the requests sent through it, and the slides they create, count
+// toward their deck. A nil Recorder returns api unchanged.
+func (r *Recorder) Slides(api presentation.SlidesAPI) presentation.SlidesAPI {
+	if r == nil || api == nil {
+		return api
+	}
+	return slidesAPI{api, r}
+}
+
+type slidesAPI struct {
+	presentation.SlidesAPI
+	r *Recorder
+}
+
+func (a slidesAPI) BatchUpdate(ctx context.Context, presentationID string, requests []*slides.Request) error {
+	err := a.SlidesAPI.BatchUpdate(ctx, presentationID, requests)
+	created := 0
+	for _, req := range requests {
+		if req.CreateSlide != nil {
+			created++
+		}
+	}
+	a.r.mu.Lock()
+	defer a.r.mu.Unlock()
+	d := a.r.deck(presentationID)
+	d.Batches++
+	d.Requests += len(requests)
+	if err != nil {
+		if d.Error == "" {
+			d.Error = err.Error()
+		}
+		return err
+	}
+	d.SlidesCreated += created
+	return nil
+}
+
+// deck returns presentationID's entry, adding it on its first batch. r.mu must be held.
+func (r *Recorder) deck(presentationID string) *Deck {
+	for _, d := range r.decks {
+		if d.PresentationID == presentationID {
+			return d
+		}
+	}
+	d := &Deck{PresentationID: presentationID}
+	r.decks = append(r.decks, d)
+	return d
+}
+
+// Report returns what has been recorded so far as a successful build's report, with the
+// run's API call counts and model stats.
+func (r *Recorder) Report(calls map[string]debugdump.Count, models map[string]llm.Stats) Report {
+	if r == nil {
+		return Report{}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rep := Report{
+		Status:     "ok",
+		Started:    r.started.UTC(),
+		DurationMS: r.now().Sub(r.started).Milliseconds(),
+		APICalls:   calls,
+		Models:     models,
+		Stages:     append([]Stage(nil), r.stages...),
+		Warnings:   append([]string{}, r.warnings...),
+	}
+	for _, d := range r.decks {
+		rep.Decks = append(rep.Decks, *d)
+		rep.SlidesCreated += d.SlidesCreated
+		rep.RequestsSent += d.Requests
+	}
+	for _, s := range models {
+		rep.Retries += s.Retries
+	}
+	return rep
+}
+
+// SetDeckError records why presentationID's deck failed, adding the deck if no request
+// reached it.
+func (rep *Report) SetDeckError(presentationID, msg string) {
+	if msg == "" {
+		return
+	}
+	for i := range rep.Decks {
+		if rep.Decks[i].PresentationID == presentationID {
+			rep.Decks[i].Error = msg
+			return
+		}
+	}
+	rep.Decks = append(rep.Decks, Deck{PresentationID: presentationID, Error: msg})
+}
+
+// Write saves rep at path: as Markdown for a .md path, as JSON otherwise.
+func Write(path string, rep Report) error {
+	var data []byte
+	if ext := strings.ToLower(filepath.Ext(path)); ext == ".md" || ext == ".markdown" {
+		data = []byte(rep.Markdown())
+	} else {
+		b, err := json.MarshalIndent(rep, "", "  ")
+		if err != nil {
+			return err
+		}
+		data = append(b, '\n')
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create report dir: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("write report: %w", err)
+	}
+	return nil
+}
+
+// Markdown renders the report as a Markdown document: a status line, then a table each
+// for the decks, API calls, models, and stages, and the warnings as a list.
+func (rep Report) Markdown() string {
+	var b strings.Builder
+	b.WriteString("# Build report\n\n")
+	fmt.Fprintf(&b, "**Status:** %s · %s · slides created: %d · requests sent: %d · retries: %d · warnings: %d\n",
+		rep.Status, formatMS(rep.DurationMS), rep.SlidesCreated, rep.RequestsSent, rep.Retries, len(rep.Warnings))
+	if rep.Error != "" {
+		fmt.Fprintf(&b, "\n**Error:** %s\n", oneLine(rep.Error))
+	}
+	if len(rep.Decks) > 0 {
+		b.WriteString("\n## Decks\n\n| Presentation | Slides created | Requests | Batches | Error |\n|---|---:|---:|---:|---|\n")
+		for _, d := range rep.Decks {
+			fmt.Fprintf(&b, "| %s | %d | %d | %d | %s |\n", cell(d.PresentationID), d.SlidesCreated, d.Requests, d.Batches, cell(d.Error))
+		}
+	}
+	if len(rep.APICalls) > 0 {
+		b.WriteString("\n## API calls\n\n| Backend | Requests | Errors | Time |\n|---|---:|---:|---:|\n")
+		for _, name := range sortedKeys(rep.APICalls) {
+			c := rep.APICalls[name]
+			fmt.Fprintf(&b, "| %s | %d | %d | %s |\n", cell(name), c.Requests, c.Errors, formatMS(c.DurationMS))
+		}
+	}
+	if len(rep.Models) > 0 {
+		b.WriteString("\n## Models\n\n| Model | Calls | Retries | Errors | Time |\n|---|---:|---:|---:|---:|\n")
+		for _, name := range sortedKeys(rep.Models) {
+			s := rep.Models[name]
+			fmt.Fprintf(&b, "| %s | %d | %d | %d | %s |\n", cell(name), s.Calls, s.Retries, s.Errors, formatMS(s.LatencyMs))
+		}
+	}
+	if len(rep.Stages) > 0 {
+		b.WriteString("\n## Stages\n\n| Stage | Time |\n|---|---:|\n")
+		for _, s := range rep.Stages {
+			fmt.Fprintf(&b, "| %s | %s |\n", cell(s.Name), formatMS(s.DurationMS))
+		}
+	}
+	b.WriteString("\n## Warnings\n\n")
+	if len(rep.Warnings) == 0 {
+		b.WriteString("None.\n")
+	}
+	for _, w := range rep.Warnings {
+		fmt.Fprintf(&b, "- %s\n", oneLine(w))
+	}
+	return b.String()
+}
+
+func formatMS(ms int64) string {
+	return (time.Duration(ms) * time.Millisecond).String()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// cell escapes s for a table cell.
+func cell(s string) string {
+	return strings.ReplaceAll(oneLine(s), "|", `\|`)
+}
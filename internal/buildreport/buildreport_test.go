@@ -0,0 +1,141 @@
+package buildreport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gogemini-practices/internal/debugdump"
+	"gogemini-practices/internal/fakeapi"
+	"gogemini-practices/internal/llm"
+
+	"google.golang.org/api/slides/v1"
+)
+
+func TestRecorder(t *testing.T) {
+	ctx := context.Background()
+	clock := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	r := &Recorder{now: func() time.Time { return clock }, started: clock}
+
+	done := r.Stage("plan")
+	clock = clock.Add(1500 * time.Millisecond)
+	done()
+
+	var stderr bytes.Buffer
+	logger := log.New(r.Log(&stderr), "", log.LstdFlags)
+	logger.Printf("warning: palette generation failed, using default")
+	logger.Printf("wrote a deck")
+	if !strings.Contains(stderr.String(), "wrote a deck") {
+		t.Errorf("log output = %q, want every line passed on", stderr.String())
+	}
+
+	sl := r.Slides(&fakeapi.Slides{})
+	if err := sl.BatchUpdate(ctx, "deck", []*slides.Request{
+		{CreateSlide: &slides.CreateSlideRequest{ObjectId: "s1"}},
+		{CreateShape: &slides.CreateShapeRequest{ObjectId: "t1"}},
+		{CreateSlide: &slides.CreateSlideRequest{ObjectId: "s2"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	failing := r.Slides(&fakeapi.Slides{Err: errors.New("500")})
+	if err := failing.BatchUpdate(ctx, "deck", []*slides.Request{{CreateSlide: &slides.CreateSlideRequest{ObjectId: "s3"}}}); err == nil {
+		t.Fatal("want the call's error")
+	}
+	clock = clock.Add(500 * time.Millisecond)
+
+	calls := map[string]debugdump.Count{"slides": {Requests: 2, Errors: 1, DurationMS: 40}}
+	models := map[string]llm.Stats{"gemini-2.5-flash": {Calls: 3, Retries: 2}, "gemini-2.5-pro": {Calls: 1, Retries: 1}}
+	rep := r.Report(calls, models)
+	rep.SetDeckError("locked-deck", "deck is locked")
+	want := Report{
+		Status:        "ok",
+		Started:       time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC),
+		DurationMS:    2000,
+		SlidesCreated: 2,
+		RequestsSent:  4,
+		Decks: []Deck{
+			{PresentationID: "deck", SlidesCreated: 2, Requests: 4, Batches: 2, Error: "500"},
+			{PresentationID: "locked-deck", Error: "deck is locked"},
+		},
+		APICalls: calls,
+		Models:   models,
+		Retries:  3,
+		Stages:   []Stage{{Name: "plan", DurationMS: 1500}},
+		Warnings: []string{"palette generation failed, using default"},
+	}
+	if !reflect.DeepEqual(rep, want) {
+		t.Errorf("Report() =\n%+v\nwant\n%+v", rep, want)
+	}
+}
+
+func TestRecorder_Nil(t *testing.T) {
+	var r *Recorder
+	api := &fakeapi.Slides{}
+	if r.Slides(api) != api {
+		t.Error("a nil Recorder should return the API unchanged")
+	}
+	var buf bytes.Buffer
+	if r.Log(&buf) != &buf {
+		t.Error("a nil Recorder should return the writer unchanged")
+	}
+	r.Stage("plan")()
+}
+
+func TestWrite(t *testing.T) {
+	rep := Report{
+		Status:     "slides_api",
+		Error:      "slides API error: deck: WriteDeck: 500",
+		DurationMS: 1250,
+		Decks:      []Deck{{PresentationID: "deck", Requests: 12, Batches: 1, Error: "a | b"}},
+		APICalls:   map[string]debugdump.Count{"slides": {Requests: 3}, "gemini": {Requests: 2, Errors: 1}},
+		Warnings:   []string{},
+	}
+	dir := t.TempDir()
+
+	jsonPath := filepath.Join(dir, "out", "report.json")
+	if err := Write(jsonPath, rep); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if w, ok := got["warnings"].([]any); !ok || len(w) != 0 {
+		t.Errorf("warnings = %v, want an empty list", got["warnings"])
+	}
+	if got["status"] != "slides_api" {
+		t.Errorf("status = %v", got["status"])
+	}
+
+	mdPath := filepath.Join(dir, "report.md")
+	if err := Write(mdPath, rep); err != nil {
+		t.Fatal(err)
+	}
+	md, err := os.ReadFile(mdPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"**Status:** slides_api · 1.25s",
+		"**Error:** slides API error",
+		"| deck | 0 | 12 | 1 | a \\| b |",
+		"| gemini | 2 | 1 | 0s |\n| slides | 3 | 0 | 0s |",
+		"## Warnings\n\nNone.",
+	} {
+		if !strings.Contains(string(md), want) {
+			t.Errorf("Markdown report lacks %q:\n%s", want, md)
+		}
+	}
+}
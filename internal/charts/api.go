@@ -0,0 +1,61 @@
+package charts
+
+import (
+	"context"
+
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/sheets/v4"
+)
+
+// SheetsAPI is the part of the Sheets API the chart builders call. NewSheetsAPI adapts a
+// *sheets.Service; tests pass an in-memory fake (see internal/fakeapi).
+type SheetsAPI interface {
+	// GetSpreadsheet fetches the spreadsheet, limited to the fields projection.
+	GetSpreadsheet(ctx context.Context, spreadsheetID, fields string) (*sheets.Spreadsheet, error)
+	BatchUpdate(ctx context.Context, spreadsheetID string, req *sheets.BatchUpdateSpreadsheetRequest) (*sheets.BatchUpdateSpreadsheetResponse, error)
+	// ClearValues clears the A1 ranges.
+	ClearValues(ctx context.Context, spreadsheetID string, ranges []string) error
+	// UpdateValues writes each value range as raw (unparsed) input.
+	UpdateValues(ctx context.Context, spreadsheetID string, data []*sheets.ValueRange) error
+	// GetValues reads the A1 range row by row: numbers unformatted, dates as displayed.
+	GetValues(ctx context.Context, spreadsheetID, a1 string) ([][]interface{}, error)
+}
+
+// NewSheetsAPI wraps svc; a nil svc gives a nil SheetsAPI.
+func NewSheetsAPI(svc *sheets.Service) SheetsAPI {
+	if svc == nil {
+		return nil
+	}
+	return sheetsService{svc}
+}
+
+type sheetsService struct {
+	svc *sheets.Service
+}
+
+func (s sheetsService) GetSpreadsheet(ctx context.Context, spreadsheetID, fields string) (*sheets.Spreadsheet, error) {
+	return s.svc.Spreadsheets.Get(spreadsheetID).Fields(googleapi.Field(fields)).Context(ctx).Do()
+}
+
+func (s sheetsService) BatchUpdate(ctx context.Context, spreadsheetID string, req *sheets.BatchUpdateSpreadsheetRequest) (*sheets.BatchUpdateSpreadsheetResponse, error) {
+	return s.svc.Spreadsheets.BatchUpdate(spreadsheetID, req).Context(ctx).Do()
+}
+
+func (s sheetsService) ClearValues(ctx context.Context, spreadsheetID string, ranges []string) error {
+	_, err := s.svc.Spreadsheets.Values.BatchClear(spreadsheetID, &sheets.BatchClearValuesRequest{Ranges: ranges}).Context(ctx).Do()
+	return err
+}
+
+func (s sheetsService) UpdateValues(ctx context.Context, spreadsheetID string, data []*sheets.ValueRange) error {
+	_, err := s.svc.Spreadsheets.Values.BatchUpdate(spreadsheetID, &sheets.BatchUpdateValuesRequest{ValueInputOption: "RAW", Data: data}).Context(ctx).Do()
+	return err
+}
+
+func (s sheetsService) GetValues(ctx context.Context, spreadsheetID, a1 string) ([][]interface{}, error) {
+	vr, err := s.svc.Spreadsheets.Values.Get(spreadsheetID, a1).
+		ValueRenderOption("UNFORMATTED_VALUE").DateTimeRenderOption("FORMATTED_STRING").Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+	return vr.Values, nil
+}
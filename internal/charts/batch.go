@@ -0,0 +1,235 @@
+package charts
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// ChartJob is one topic's chart in a batched build.
+type ChartJob struct {
+	SheetTitle string // data tab title, e.g. from SheetTitle; holds the chart itself when the dataset has a SourceRange
+	Tag        ChartTag
+	Dataset    DatasetSpec
+}
+
+// buildPlan is the Sheets work for BuildCharts, derived from one spreadsheet snapshot.
+type buildPlan struct {
+	requests     []*sheets.Request    // structural batch: add tabs, cleanup, named ranges, metadata, charts
+	chartReplies []int                // index into the batch replies of each job's AddChart
+	clears       []string             // ranges to clear before writing (only for re-used tabs)
+	values       []*sheets.ValueRange // one table per job
+}
+
+// BuildCharts replaces the charts of previous runs (see CleanupSpreadsheetForCharts) with one
+// chart per job. Everything happens in three Sheets round trips regardless of topic count: one
+// spreadsheet fetch, one structural batch (new tabs with preassigned IDs, cleanup deletes, named
+// ranges, metadata, and every AddChart), and one values.batchUpdate that fills the tabs the
+// charts already point at. Re-used tabs add one values.batchClear. Chart sheets are not tagged;
+// cleanup finds them through the tagged data tabs they chart. A job whose dataset has a
+// SourceRange writes no data: its chart reads that range and sits on the job's tab, so
+// cleanup removes it with the tab. Returns: chart IDs in job order, error.
+func BuildCharts(ctx context.Context, sheetsSvc SheetsAPI, spreadsheetID string, jobs []ChartJob) ([]int64, error) {
+	return buildCharts(ctx, sheetsSvc, spreadsheetID, jobs, true, "")
+}
+
+// BuildDeckCharts is BuildCharts for one deck's charts: sheets tagged for another deck (see
+// MetadataDeckKey) are left alone, so several decks can share a spreadsheet. The jobs'
+// tags should carry the same deck.
+func BuildDeckCharts(ctx context.Context, sheetsSvc SheetsAPI, spreadsheetID, deck string, jobs []ChartJob) ([]int64, error) {
+	return buildCharts(ctx, sheetsSvc, spreadsheetID, jobs, true, deck)
+}
+
+// AddCharts is BuildCharts without the cleanup: earlier runs' tabs and charts are left in
+// place, e.g. when only one topic of a deck is rebuilt. Jobs should use a fresh run ID; a
+// job whose tab already exists is re-written, but that tab's older chart sheets remain.
+func AddCharts(ctx context.Context, sheetsSvc SheetsAPI, spreadsheetID string, jobs []ChartJob) ([]int64, error) {
+	return buildCharts(ctx, sheetsSvc, spreadsheetID, jobs, false, "")
+}
+
+func buildCharts(ctx context.Context, sheetsSvc SheetsAPI, spreadsheetID string, jobs []ChartJob, cleanup bool, deck string) ([]int64, error) {
+	if sheetsSvc == nil {
+		return nil, fmt.Errorf("sheetsSvc is nil")
+	}
+	if strings.TrimSpace(spreadsheetID) == "" {
+		return nil, fmt.Errorf("spreadsheetID is required")
+	}
+	ss, err := sheetsSvc.GetSpreadsheet(ctx, spreadsheetID, cleanupFields)
+	if err != nil {
+		return nil, fmt.Errorf("get spreadsheet: %w", err)
+	}
+	plan, err := planBuild(ss, jobs, cleanup, deck)
+	if err != nil {
+		return nil, err
+	}
+	if len(plan.requests) == 0 {
+		return nil, nil
+	}
+
+	resp, err := sheetsSvc.BatchUpdate(ctx, spreadsheetID, &sheets.BatchUpdateSpreadsheetRequest{Requests: plan.requests})
+	if err != nil {
+		return nil, fmt.Errorf("batch update (charts): %w", err)
+	}
+	ids := make([]int64, len(jobs))
+	for i, idx := range plan.chartReplies {
+		if resp == nil || idx >= len(resp.Replies) || resp.Replies[idx].AddChart == nil || resp.Replies[idx].AddChart.Chart == nil {
+			return nil, fmt.Errorf("missing add chart reply for %q", jobs[i].SheetTitle)
+		}
+		ids[i] = resp.Replies[idx].AddChart.Chart.ChartId
+	}
+
+	if len(plan.clears) > 0 {
+		if err := sheetsSvc.ClearValues(ctx, spreadsheetID, plan.clears); err != nil {
+			return nil, fmt.Errorf("clear values: %w", err)
+		}
+	}
+	if len(plan.values) > 0 {
+		if err := sheetsSvc.UpdateValues(ctx, spreadsheetID, plan.values); err != nil {
+			return nil, fmt.Errorf("write values: %w", err)
+		}
+	}
+	return ids, nil
+}
+
+// planBuild lays out BuildCharts' requests. Jobs whose tab already exists re-use it (and
+// its named range); other tabs are added with IDs above the highest existing one, ahead
+// of the cleanup deletes so the spreadsheet always keeps a grid sheet. Without cleanup no
+// sheet is deleted, and with a deck only sheets of that deck or untagged ones are.
+func planBuild(ss *sheets.Spreadsheet, jobs []ChartJob, cleanup bool, deck string) (buildPlan, error) {
+	var plan buildPlan
+	existing := map[string]int64{}
+	var nextID int64
+	for _, sh := range ss.Sheets {
+		if sh == nil || sh.Properties == nil {
+			continue
+		}
+		if sh.Properties.SheetId >= nextID {
+			nextID = sh.Properties.SheetId + 1
+		}
+		if !isChartSheet(sh) {
+			existing[sh.Properties.Title] = sh.Properties.SheetId
+		}
+	}
+	charted := map[int64][]int64{} // grid tab -> charts placed on it
+	for _, sh := range ss.Sheets {
+		if sh == nil || sh.Properties == nil || isChartSheet(sh) {
+			continue
+		}
+		for _, ch := range sh.Charts {
+			if ch != nil {
+				charted[sh.Properties.SheetId] = append(charted[sh.Properties.SheetId], ch.ChartId)
+			}
+		}
+	}
+	ranges := map[string]*sheets.NamedRange{}
+	for _, nr := range ss.NamedRanges {
+		if nr != nil {
+			ranges[nr.Name] = nr
+		}
+	}
+
+	sheetIDs := make([]int64, len(jobs))
+	sources := make([]int64, len(jobs)) // source tab of jobs with a SourceRange
+	srcRanges := make([]sourceRange, len(jobs))
+	keep := map[int64]bool{}
+	var adds []*sheets.Request
+	seen := map[string]bool{}
+	for i, job := range jobs {
+		title := strings.TrimSpace(job.SheetTitle)
+		if title == "" {
+			return plan, fmt.Errorf("job %d: sheet title is required", i+1)
+		}
+		if seen[title] {
+			return plan, fmt.Errorf("job %d: duplicate sheet title %q", i+1, title)
+		}
+		seen[title] = true
+		if job.Dataset.SourceRange != "" {
+			src, err := parseSourceRange(job.Dataset.SourceRange)
+			if err != nil {
+				return plan, fmt.Errorf("job %d: %w", i+1, err)
+			}
+			if sources[i], err = sourceSheetID(ss, src); err != nil {
+				return plan, fmt.Errorf("job %d: %w", i+1, err)
+			}
+			srcRanges[i] = src
+		} else if len(job.Dataset.Points) == 0 {
+			return plan, fmt.Errorf("job %d: no points to chart", i+1)
+		}
+		if id, ok := existing[title]; ok {
+			sheetIDs[i] = id
+			keep[id] = true
+			prior := dataSheet{ID: id, Range: ranges[job.Tag.RangeName()]}
+			if prior.Range != nil && (prior.Range.Range == nil || prior.Range.Range.SheetId != id) {
+				prior.Range = nil // named after this job but lives elsewhere; clear the whole tab
+			}
+			plan.clears = append(plan.clears, priorDataRange(title, prior))
+			continue
+		}
+		sheetIDs[i] = nextID
+		nextID++
+		adds = append(adds, &sheets.Request{AddSheet: &sheets.AddSheetRequest{Properties: &sheets.SheetProperties{SheetId: sheetIDs[i], Title: title}}})
+	}
+
+	plan.requests = append(plan.requests, adds...)
+	deleted := map[int64]bool{}
+	if cleanup {
+		var deletes []*sheets.Request
+		deletes, deleted = cleanupRequests(ss, keep, len(adds), deck)
+		plan.requests = append(plan.requests, deletes...)
+	}
+
+	for i, job := range jobs {
+		if job.Dataset.SourceRange != "" {
+			plan.requests = append(plan.requests, rangeJobRequests(job, sheetIDs[i], charted[sheetIDs[i]], deleted)...)
+			plan.chartReplies = append(plan.chartReplies, len(plan.requests))
+			plan.requests = append(plan.requests, &sheets.Request{AddChart: &sheets.AddChartRequest{
+				Chart: &sheets.EmbeddedChart{
+					Spec: rangeChartSpec(job.Dataset, srcRanges[i], sources[i]),
+					Position: &sheets.EmbeddedObjectPosition{OverlayPosition: &sheets.OverlayPosition{
+						AnchorCell: &sheets.GridCoordinate{SheetId: sheetIDs[i], ForceSendFields: []string{"SheetId", "RowIndex", "ColumnIndex"}},
+					}},
+				},
+			}})
+			continue
+		}
+		values := makeTable(job.Dataset)
+		dataRange := &sheets.GridRange{SheetId: sheetIDs[i], EndRowIndex: int64(len(values)), EndColumnIndex: int64(len(values[0]))}
+		prior := ranges[job.Tag.RangeName()]
+		if prior != nil && prior.Range != nil && deleted[prior.Range.SheetId] {
+			prior = nil // removed by cleanup above; add it afresh
+		}
+		plan.requests = append(plan.requests, namedRangeRequest(job.Tag.RangeName(), prior, dataRange))
+		if job.Tag.RunID != "" {
+			plan.requests = append(plan.requests, tagRequests(job.Tag, sheetIDs[i])...)
+		}
+		plan.chartReplies = append(plan.chartReplies, len(plan.requests))
+		plan.requests = append(plan.requests, &sheets.Request{AddChart: &sheets.AddChartRequest{
+			Chart: &sheets.EmbeddedChart{
+				Spec:     buildChartSpec(job.Dataset, sheetIDs[i]),
+				Position: &sheets.EmbeddedObjectPosition{NewSheet: true},
+			},
+		}})
+		plan.values = append(plan.values, &sheets.ValueRange{
+			Range:  a1Range(strings.TrimSpace(job.SheetTitle), "A1:"+columnLetter(len(values[0])-1)),
+			Values: values,
+		})
+	}
+	return plan, nil
+}
+
+// rangeJobRequests prepares a SourceRange job's tab for its chart: charts an earlier build
+// left on a re-used tab are removed (unless cleanup already deleted it) and the tab is tagged.
+func rangeJobRequests(job ChartJob, sheetID int64, prior []int64, deleted map[int64]bool) []*sheets.Request {
+	var reqs []*sheets.Request
+	if !deleted[sheetID] {
+		for _, id := range prior {
+			reqs = append(reqs, &sheets.Request{DeleteEmbeddedObject: &sheets.DeleteEmbeddedObjectRequest{ObjectId: id}})
+		}
+	}
+	if job.Tag.RunID != "" {
+		reqs = append(reqs, tagRequests(job.Tag, sheetID)...)
+	}
+	return reqs
+}
@@ -0,0 +1,208 @@
+package charts
+
+import (
+	"testing"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// snapshot builds a spreadsheet with a user tab (0), a tagged data tab from an earlier run (5)
+// charted on sheet 9, and a user chart sheet (12) charting the user tab.
+func snapshot() *sheets.Spreadsheet {
+	chartOn := func(id, source int64) *sheets.Sheet {
+		return &sheets.Sheet{
+			Properties: &sheets.SheetProperties{SheetId: id, SheetType: "OBJECT"},
+			Charts: []*sheets.EmbeddedChart{{Spec: &sheets.ChartSpec{PieChart: &sheets.PieChartSpec{
+				Domain: &sheets.ChartData{SourceRange: &sheets.ChartSourceRange{Sources: []*sheets.GridRange{{SheetId: source}}}},
+			}}}},
+		}
+	}
+	return &sheets.Spreadsheet{
+		Sheets: []*sheets.Sheet{
+			{Properties: &sheets.SheetProperties{SheetId: 0, Title: "Sheet1", SheetType: "GRID"}},
+			{
+				Properties:        &sheets.SheetProperties{SheetId: 5, Title: "old-1-sales", SheetType: "GRID"},
+				DeveloperMetadata: []*sheets.DeveloperMetadata{{MetadataKey: MetadataKey}},
+			},
+			chartOn(9, 5),
+			chartOn(12, 0),
+		},
+		NamedRanges: []*sheets.NamedRange{{NamedRangeId: "nr1", Name: "gsa_old_1", Range: &sheets.GridRange{SheetId: 5}}},
+	}
+}
+
+func job(title string, index int) ChartJob {
+	return ChartJob{
+		SheetTitle: title,
+		Tag:        ChartTag{RunID: "new", Index: index, Topic: "Sales"},
+		Dataset:    DatasetSpec{Type: "category", Points: []Point{{Label: "a", Value: 1}, {Label: "b", Value: 5}}},
+	}
+}
+
+func deletedSheets(reqs []*sheets.Request) map[int64]bool {
+	out := map[int64]bool{}
+	for _, r := range reqs {
+		if r.DeleteSheet != nil {
+			out[r.DeleteSheet.SheetId] = true
+		}
+	}
+	return out
+}
+
+func TestPlanBuild_NewRun(t *testing.T) {
+	plan, err := planBuild(snapshot(), []ChartJob{job("new-1-sales", 1), job("new-2-sales", 2)}, true, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// New tabs come first, with IDs above every existing sheet
+	for i, want := range []int64{13, 14} {
+		add := plan.requests[i].AddSheet
+		if add == nil || add.Properties.SheetId != want {
+			t.Fatalf("request %d = %+v, want AddSheet with id %d", i, plan.requests[i], want)
+		}
+	}
+	del := deletedSheets(plan.requests)
+	if !del[5] || !del[9] || del[0] || del[12] {
+		t.Errorf("deleted sheets = %v, want old run's tab and chart only", del)
+	}
+	if len(plan.chartReplies) != 2 {
+		t.Fatalf("got %d chart replies, want 2", len(plan.chartReplies))
+	}
+	for _, idx := range plan.chartReplies {
+		if plan.requests[idx].AddChart == nil {
+			t.Errorf("reply index %d is not an AddChart", idx)
+		}
+	}
+	if len(plan.clears) != 0 {
+		t.Errorf("clears = %v, want none for fresh tabs", plan.clears)
+	}
+	if got := plan.values[1].Range; got != "'new-2-sales'!A1:B" {
+		t.Errorf("values range = %q", got)
+	}
+}
+
+func TestPlanBuild_ReusesExistingTab(t *testing.T) {
+	j := job("old-1-sales", 1)
+	j.Tag.RunID = "old"
+	plan, err := planBuild(snapshot(), []ChartJob{j}, true, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	del := deletedSheets(plan.requests)
+	if del[5] || !del[9] {
+		t.Errorf("deleted sheets = %v, want tab 5 kept and its old chart 9 replaced", del)
+	}
+	if len(plan.clears) != 1 || plan.clears[0] != "gsa_old_1" {
+		t.Errorf("clears = %v, want just the named range", plan.clears)
+	}
+	for _, r := range plan.requests {
+		if r.AddSheet != nil || r.DeleteNamedRange != nil {
+			t.Errorf("unexpected request %+v when re-using a tab", r)
+		}
+	}
+}
+
+func TestPlanBuild_OtherDeck(t *testing.T) {
+	ss := snapshot()
+	ss.Sheets = append(ss.Sheets,
+		&sheets.Sheet{
+			Properties: &sheets.SheetProperties{SheetId: 20, Title: "b1-1-sales", SheetType: "GRID"},
+			DeveloperMetadata: []*sheets.DeveloperMetadata{
+				{MetadataKey: MetadataKey, MetadataValue: "b1"},
+				{MetadataKey: MetadataDeckKey, MetadataValue: "deck-b"},
+			},
+		},
+		&sheets.Sheet{
+			Properties: &sheets.SheetProperties{SheetId: 21, SheetType: "OBJECT"},
+			Charts: []*sheets.EmbeddedChart{{Spec: &sheets.ChartSpec{PieChart: &sheets.PieChartSpec{
+				Domain: &sheets.ChartData{SourceRange: &sheets.ChartSourceRange{Sources: []*sheets.GridRange{{SheetId: 20}}}},
+			}}}},
+		},
+	)
+	j := job("new-1-sales", 1)
+	j.Tag.Deck = "deck-a"
+	plan, err := planBuild(ss, []ChartJob{j}, true, "deck-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	del := deletedSheets(plan.requests)
+	if del[20] || del[21] {
+		t.Errorf("deleted sheets = %v, want deck-b's tab 20 and chart 21 kept", del)
+	}
+	if !del[5] || !del[9] {
+		t.Errorf("deleted sheets = %v, want the untagged earlier run's tab 5 and chart 9", del)
+	}
+	var deckTagged bool
+	for _, r := range plan.requests {
+		if md := r.CreateDeveloperMetadata; md != nil && md.DeveloperMetadata.MetadataKey == MetadataDeckKey {
+			deckTagged = md.DeveloperMetadata.MetadataValue == "deck-a"
+		}
+	}
+	if !deckTagged {
+		t.Error("new tab not tagged with deck-a")
+	}
+
+	// Without a deck, every deck's sheets are cleaned up
+	if plan, err = planBuild(ss, []ChartJob{j}, true, ""); err != nil {
+		t.Fatal(err)
+	}
+	if del := deletedSheets(plan.requests); !del[20] || !del[21] {
+		t.Errorf("deleted sheets = %v, want deck-b's sheets without a deck", del)
+	}
+}
+
+func TestPlanBuild_RejectsDuplicateTitles(t *testing.T) {
+	if _, err := planBuild(snapshot(), []ChartJob{job("x", 1), job("x", 2)}, true, ""); err == nil {
+		t.Error("expected error for duplicate sheet titles")
+	}
+}
+
+func TestPlanBuild_WithoutCleanup(t *testing.T) {
+	plan, err := planBuild(snapshot(), []ChartJob{job("new-3-sales", 3)}, false, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if del := deletedSheets(plan.requests); len(del) != 0 {
+		t.Errorf("deleted sheets = %v, want none", del)
+	}
+	for _, r := range plan.requests {
+		if r.DeleteNamedRange != nil {
+			t.Errorf("unexpected request %+v without cleanup", r)
+		}
+	}
+	if add := plan.requests[0].AddSheet; add == nil || add.Properties.SheetId != 13 {
+		t.Errorf("first request = %+v, want AddSheet with id 13", plan.requests[0])
+	}
+	if len(plan.chartReplies) != 1 {
+		t.Errorf("got %d chart replies, want 1", len(plan.chartReplies))
+	}
+}
+
+func TestPlanBuild_SourceRange(t *testing.T) {
+	j := job("new-1-sales", 1)
+	j.Dataset.SourceRange = "Sheet1!A1:B3"
+	plan, err := planBuild(snapshot(), []ChartJob{j}, true, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.values) != 0 || len(plan.clears) != 0 {
+		t.Errorf("values = %v, clears = %v; want nothing written", plan.values, plan.clears)
+	}
+	for _, r := range plan.requests {
+		if r.AddNamedRange != nil || r.UpdateNamedRange != nil {
+			t.Errorf("unexpected named range request %+v", r)
+		}
+	}
+	chart := plan.requests[plan.chartReplies[0]].AddChart.Chart
+	if src := chart.Spec.BasicChart.Domains[0].Domain.SourceRange.Sources[0]; src.SheetId != 0 || src.EndRowIndex != 3 {
+		t.Errorf("domain = %+v, want Sheet1 rows 1-3", src)
+	}
+	if pos := chart.Position.OverlayPosition; pos == nil || pos.AnchorCell.SheetId != 13 {
+		t.Errorf("position = %+v, want an overlay on the new tagged tab 13", chart.Position)
+	}
+
+	j.Dataset.SourceRange = "Missing!A1:B3"
+	if _, err := planBuild(snapshot(), []ChartJob{j}, true, ""); err == nil {
+		t.Error("expected an error for a range on a missing tab")
+	}
+}
@@ -0,0 +1,132 @@
+package charts
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// Data label modes for ChartOptions.DataLabels.
+const (
+	LabelsOff  = "off"
+	LabelsOn   = "on"
+	LabelsAuto = "auto" // label sparse charts only, see sparseLabelLimit
+)
+
+// sparseLabelLimit is the most labeled values (points x series) LabelsAuto will draw.
+const sparseLabelLimit = 8
+
+// ChartOptions tunes chart presentation. The zero value keeps the defaults: no data labels,
+// legend at the bottom, and automatic value-axis bounds. Sheets exposes no gridline control
+// for embedded basic charts, so gridlines always follow the chart's default theme.
+type ChartOptions struct {
+	DataLabels string   // LabelsOff | LabelsOn | LabelsAuto; "" means off
+	Legend     string   // BasicChartLegendPosition, e.g. BOTTOM_LEGEND or NO_LEGEND; see ParseLegend
+	AxisMin    *float64 // optional fixed value-axis minimum
+	AxisMax    *float64 // optional fixed value-axis maximum
+}
+
+// ParseLabels normalizes a data-label flag value.
+func ParseLabels(s string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "off", "none":
+		return LabelsOff, nil
+	case "on", "all":
+		return LabelsOn, nil
+	case "auto":
+		return LabelsAuto, nil
+	}
+	return "", fmt.Errorf("unknown data labels mode %q (want off|on|auto)", s)
+}
+
+// ParseLegend maps bottom|top|left|right|none to the Sheets legend position enum.
+func ParseLegend(s string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "bottom":
+		return "BOTTOM_LEGEND", nil
+	case "top":
+		return "TOP_LEGEND", nil
+	case "left":
+		return "LEFT_LEGEND", nil
+	case "right":
+		return "RIGHT_LEGEND", nil
+	case "none", "hidden", "off":
+		return "NO_LEGEND", nil
+	}
+	return "", fmt.Errorf("unknown legend position %q (want bottom|top|left|right|none)", s)
+}
+
+// ParseAxisBound parses an optional axis bound; an empty string means automatic (nil).
+func ParseAxisBound(s string) (*float64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid axis bound %q: %w", s, err)
+	}
+	return &v, nil
+}
+
+// Validate rejects inverted axis bounds.
+func (o ChartOptions) Validate() error {
+	if o.AxisMin != nil && o.AxisMax != nil && *o.AxisMin >= *o.AxisMax {
+		return fmt.Errorf("axis min %g must be below axis max %g", *o.AxisMin, *o.AxisMax)
+	}
+	return nil
+}
+
+// axisOptions is the dataset's chart options with its MinValue hint applied: the hint
+// fills in an automatic minimum, as long as it stays below a fixed maximum.
+func (ds DatasetSpec) axisOptions() ChartOptions {
+	o := ds.Options
+	if o.AxisMin == nil && ds.MinValue != nil && (o.AxisMax == nil || *ds.MinValue < *o.AxisMax) {
+		o.AxisMin = ds.MinValue
+	}
+	return o
+}
+
+// showLabels decides whether to label values for a chart with the given number of labeled values.
+func (o ChartOptions) showLabels(values int) bool {
+	switch o.DataLabels {
+	case LabelsOn:
+		return true
+	case LabelsAuto:
+		return values <= sparseLabelLimit
+	}
+	return false
+}
+
+// apply maps the options onto a basic chart spec; dataSeries counts the leading value
+// series (trend overlays are never labeled) and points the rows per series.
+func (o ChartOptions) apply(basic *sheets.BasicChartSpec, dataSeries, points int) {
+	if o.Legend != "" {
+		basic.LegendPosition = o.Legend
+	}
+	if o.showLabels(dataSeries * points) {
+		if basic.StackedType != "" {
+			// Per-segment labels crowd stacked columns; label the totals instead
+			basic.TotalDataLabel = &sheets.DataLabel{Type: "DATA"}
+		} else {
+			for i := 0; i < dataSeries && i < len(basic.Series); i++ {
+				basic.Series[i].DataLabel = &sheets.DataLabel{Type: "DATA"}
+			}
+		}
+	}
+	if o.AxisMin != nil || o.AxisMax != nil {
+		vw := &sheets.ChartAxisViewWindowOptions{ViewWindowMode: "EXPLICIT"}
+		// Zero bounds are meaningful (e.g. a 0 baseline) and must be sent explicitly
+		if o.AxisMin != nil {
+			vw.ViewWindowMin = *o.AxisMin
+			vw.ForceSendFields = append(vw.ForceSendFields, "ViewWindowMin")
+		}
+		if o.AxisMax != nil {
+			vw.ViewWindowMax = *o.AxisMax
+			vw.ForceSendFields = append(vw.ForceSendFields, "ViewWindowMax")
+		}
+		basic.Axis = append(basic.Axis, &sheets.BasicChartAxis{Position: "LEFT_AXIS", ViewWindowOptions: vw})
+	}
+}
@@ -0,0 +1,79 @@
+package charts
+
+import "testing"
+
+func TestParseLegend(t *testing.T) {
+	tests := map[string]string{"": "BOTTOM_LEGEND", "Top": "TOP_LEGEND", "none": "NO_LEGEND", "right": "RIGHT_LEGEND"}
+	for in, want := range tests {
+		if got, err := ParseLegend(in); err != nil || got != want {
+			t.Errorf("ParseLegend(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseLegend("center"); err == nil {
+		t.Error("expected error for unknown legend position")
+	}
+}
+
+func TestChartOptions_Validate(t *testing.T) {
+	lo, _ := ParseAxisBound("10")
+	hi, _ := ParseAxisBound("5")
+	if err := (ChartOptions{AxisMin: lo, AxisMax: hi}).Validate(); err == nil {
+		t.Error("expected error for min >= max")
+	}
+	if _, err := ParseAxisBound("ten"); err == nil {
+		t.Error("expected error for non-numeric bound")
+	}
+}
+
+func TestBuildChartSpec_Options(t *testing.T) {
+	zero := 0.0
+	ds := DatasetSpec{
+		Type:    "category",
+		Points:  []Point{{Label: "A", Value: 1}, {Label: "B", Value: 2}},
+		Options: ChartOptions{DataLabels: LabelsAuto, Legend: "NO_LEGEND", AxisMin: &zero},
+	}
+	basic := buildChartSpec(ds, 0).BasicChart
+	if basic.LegendPosition != "NO_LEGEND" {
+		t.Errorf("LegendPosition = %q, want NO_LEGEND", basic.LegendPosition)
+	}
+	if basic.Series[0].DataLabel == nil {
+		t.Error("expected data labels on a sparse chart with auto labels")
+	}
+	if len(basic.Axis) != 1 || basic.Axis[0].ViewWindowOptions.ViewWindowMode != "EXPLICIT" {
+		t.Fatalf("expected explicit value axis, got %+v", basic.Axis)
+	}
+	if f := basic.Axis[0].ViewWindowOptions.ForceSendFields; len(f) != 1 || f[0] != "ViewWindowMin" {
+		t.Errorf("ForceSendFields = %v, want [ViewWindowMin] so a zero baseline is sent", f)
+	}
+
+	// Dense charts stay unlabeled under auto; stacked charts label totals only
+	for i := 0; i < 10; i++ {
+		ds.Points = append(ds.Points, Point{Label: "x", Value: 1})
+	}
+	if buildChartSpec(ds, 0).BasicChart.Series[0].DataLabel != nil {
+		t.Error("auto labels should skip dense charts")
+	}
+	ds.Options.DataLabels = LabelsOn
+	ds.Stacked = Stacked
+	basic = buildChartSpec(ds, 0).BasicChart
+	if basic.TotalDataLabel == nil || basic.Series[0].DataLabel != nil {
+		t.Error("stacked charts should use total labels instead of per-series labels")
+	}
+}
+
+func TestDatasetSpec_MinValue(t *testing.T) {
+	zero, ten, five := 0.0, 10.0, 5.0
+	ds := DatasetSpec{Type: "timeseries", MinValue: &zero, Points: []Point{{Label: "2020", Value: 40}, {Label: "2021", Value: 42}}}
+	axis := buildChartSpec(ds, 0).BasicChart.Axis
+	if len(axis) != 1 || axis[0].ViewWindowOptions == nil || axis[0].ViewWindowOptions.ViewWindowMin != 0 || axis[0].ViewWindowOptions.ViewWindowMode != "EXPLICIT" {
+		t.Fatalf("axis = %+v, want an explicit window starting at zero", axis)
+	}
+	ds.Options.AxisMin = &ten
+	if got := ds.axisOptions().AxisMin; *got != 10 {
+		t.Errorf("AxisMin = %v, want the deck-wide 10 over MinValue", *got)
+	}
+	ds.Options.AxisMin, ds.Options.AxisMax, ds.MinValue = nil, &five, &ten
+	if got := ds.axisOptions().AxisMin; got != nil {
+		t.Errorf("AxisMin = %v, want none above the deck-wide maximum", *got)
+	}
+}
@@ -0,0 +1,68 @@
+package charts
+
+import (
+	"fmt"
+	"math"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// DonutHole is the PieHole fraction used for share charts.
+const DonutHole = 0.5
+
+// shareTolerance is how far a category dataset's total may stray from 100 and still
+// read as shares (model-generated percentages rarely sum exactly).
+const shareTolerance = 2.0
+
+// isShare reports whether a dataset represents parts of a whole: a single-series category
+// dataset of non-negative values that sum to roughly 100. A "%" unit alone isn't enough,
+// since rates such as growth or conversion are percentages that make no whole.
+func (ds DatasetSpec) isShare() bool {
+	if ds.Type != "category" || ds.multiSeries() || ds.Stacked != "" || len(ds.Points) < 2 {
+		return false
+	}
+	var sum float64
+	for _, p := range ds.Points {
+		if p.Value < 0 {
+			return false
+		}
+		sum += p.Value
+	}
+	return math.Abs(sum-100) <= shareTolerance
+}
+
+// shareLabels appends each point's share of the total to its label, e.g. "Mobile (42%)",
+// so the legend carries percentages alongside the slice labels Sheets draws by default.
+func shareLabels(points []Point) []string {
+	var sum float64
+	for _, p := range points {
+		sum += p.Value
+	}
+	out := make([]string, len(points))
+	for i, p := range points {
+		out[i] = fmt.Sprintf("%s (%.0f%%)", p.Label, p.Value/sum*100)
+	}
+	return out
+}
+
+// buildDonutSpec maps a share dataset onto a donut chart reading columns A:B of makeTable's output.
+func buildDonutSpec(ds DatasetSpec, sheetID int64) *sheets.ChartSpec {
+	rowCount := int64(len(ds.Points) + 1) // including header
+	domainRange := &sheets.GridRange{SheetId: sheetID, StartRowIndex: 1, EndRowIndex: rowCount, StartColumnIndex: 0, EndColumnIndex: 1}
+	seriesRange := &sheets.GridRange{SheetId: sheetID, StartRowIndex: 1, EndRowIndex: rowCount, StartColumnIndex: 1, EndColumnIndex: 2}
+
+	legend := "RIGHT_LEGEND"
+	if ds.Options.Legend != "" {
+		legend = ds.Options.Legend
+	}
+	return &sheets.ChartSpec{
+		Title:    ds.chartTitle(),
+		Subtitle: ds.sourceNote(),
+		PieChart: &sheets.PieChartSpec{
+			Domain:         &sheets.ChartData{SourceRange: &sheets.ChartSourceRange{Sources: []*sheets.GridRange{domainRange}}},
+			Series:         &sheets.ChartData{SourceRange: &sheets.ChartSourceRange{Sources: []*sheets.GridRange{seriesRange}}},
+			LegendPosition: legend,
+			PieHole:        DonutHole,
+		},
+	}
+}
@@ -0,0 +1,46 @@
+package charts
+
+import "testing"
+
+func TestIsShare(t *testing.T) {
+	pts := func(vals ...float64) []Point {
+		out := make([]Point, len(vals))
+		for i, v := range vals {
+			out[i] = Point{Label: "p", Value: v}
+		}
+		return out
+	}
+	tests := []struct {
+		name string
+		ds   DatasetSpec
+		want bool
+	}{
+		{"sums to 100", DatasetSpec{Type: "category", Points: pts(40, 35, 24)}, true},
+		{"percent shares", DatasetSpec{Type: "category", Unit: "%", Points: pts(55, 30, 16)}, true},
+		{"percent rates", DatasetSpec{Type: "category", Unit: "%", Points: pts(12, 4.5, 8)}, false},
+		{"rates over 100", DatasetSpec{Type: "category", Unit: "%", Points: pts(80, 65, 40)}, false},
+		{"not a whole", DatasetSpec{Type: "category", Points: pts(40, 35, 10)}, false},
+		{"timeseries", DatasetSpec{Type: "timeseries", Points: pts(50, 50)}, false},
+		{"negative", DatasetSpec{Type: "category", Unit: "%", Points: pts(120, -20)}, false},
+		{"single point", DatasetSpec{Type: "category", Points: pts(100)}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.ds.isShare(); got != tt.want {
+			t.Errorf("%s: isShare = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBuildChartSpec_Donut(t *testing.T) {
+	ds := DatasetSpec{Type: "category", Points: []Point{{Label: "Mobile", Value: 60}, {Label: "Desktop", Value: 40}}}
+	spec := buildChartSpec(ds, 3)
+	if spec.PieChart == nil || spec.BasicChart != nil {
+		t.Fatal("expected a pie chart spec for share data")
+	}
+	if spec.PieChart.PieHole != DonutHole {
+		t.Errorf("PieHole = %v, want %v", spec.PieChart.PieHole, DonutHole)
+	}
+	if got := makeTable(ds)[1][0]; got != "Mobile (60%)" {
+		t.Errorf("label = %v, want %q", got, "Mobile (60%)")
+	}
+}
@@ -0,0 +1,429 @@
+package charts
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+
+	"gogemini-practices/internal/palette"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// Default size for locally rendered charts; 4:3 matches the embedded Sheets chart frame.
+const (
+	RenderWidth  = 1200
+	RenderHeight = 900
+)
+
+// seriesColors is the rotation used without SeriesColors (Sheets' default theme order).
+var seriesColors = []string{"#4285F4", "#DB4437", "#F4B400", "#0F9D58", "#AB47BC", "#00ACC1", "#FF7043", "#9E9D24"}
+
+// RenderPNG draws the dataset as a PNG without any Google API, for decks built without a
+// spreadsheet or when Sheets fails. It mirrors the Sheets rendering choices: donuts for share
+// data, lines (plus trend overlay) for timeseries, and grouped or stacked columns otherwise.
+// Non-positive sizes fall back to RenderWidth x RenderHeight.
+func RenderPNG(ds DatasetSpec, width, height int) ([]byte, error) {
+	if len(ds.Points) == 0 {
+		return nil, fmt.Errorf("no points to chart")
+	}
+	if width <= 0 || height <= 0 {
+		width, height = RenderWidth, RenderHeight
+	}
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	title := fitText(ds.chartTitle(), width-40, 3)
+	drawText(img, (width-textWidth(title, 3))/2, 20, title, color.Black, 3)
+
+	plot := image.Rect(110, 90, width-40, height-110)
+	switch {
+	case ds.isShare():
+		renderDonut(img, ds, plot)
+	default:
+		renderAxes(img, ds, plot)
+		// The unit, with the scale the values are in, titles the value axis
+		if ds.Unit != "" {
+			drawText(img, 20, plot.Min.Y-30, fitText(ds.Unit, width-40, 2), color.Gray{0x55}, 2)
+		}
+	}
+	if note := ds.sourceNote(); note != "" {
+		drawText(img, 20, height-24, fitText(note, width-40, 2), color.Gray{0x77}, 2)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("encode chart png: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// seriesColor returns the color for series (or donut slice) i, from ds.SeriesColors when set.
+func (ds DatasetSpec) seriesColor(i int) color.RGBA {
+	hex := seriesColors[i%len(seriesColors)]
+	if len(ds.SeriesColors) > 0 {
+		hex = ds.SeriesColors[i%len(ds.SeriesColors)]
+	}
+	r, g, b, err := palette.RGB(hex)
+	if err != nil {
+		return color.RGBA{0x42, 0x85, 0xF4, 0xFF}
+	}
+	return color.RGBA{uint8(r * 255), uint8(g * 255), uint8(b * 255), 0xFF}
+}
+
+// seriesValues returns the dataset as one slice of values per series.
+func (ds DatasetSpec) seriesValues() [][]float64 {
+	n := 1
+	if ds.multiSeries() {
+		n = len(ds.Series)
+	}
+	out := make([][]float64, n)
+	for j := range out {
+		out[j] = make([]float64, len(ds.Points))
+	}
+	for i, p := range ds.Points {
+		if !ds.multiSeries() {
+			out[0][i] = p.Value
+			continue
+		}
+		for j := 0; j < n && j < len(p.Values); j++ {
+			out[j][i] = p.Values[j]
+		}
+	}
+	if ds.Stacked == PercentStacked {
+		for i := range ds.Points {
+			var total float64
+			for j := range out {
+				total += math.Abs(out[j][i])
+			}
+			for j := range out {
+				if total > 0 {
+					out[j][i] = out[j][i] / total * 100
+				}
+			}
+		}
+	}
+	return out
+}
+
+func renderAxes(img *image.RGBA, ds DatasetSpec, plot image.Rectangle) {
+	series := ds.seriesValues()
+	var trend []*float64
+	if kind := ds.trendKind(); kind != TrendNone {
+		trend = TrendValues(series[0], kind, ds.trendWindow())
+	}
+	line := ds.Type == "timeseries" && ds.Stacked == ""
+
+	// Value range always includes zero so columns have a baseline
+	lo, hi := 0.0, 0.0
+	for i := range ds.Points {
+		var pos, neg float64
+		for j := range series {
+			v := series[j][i]
+			if ds.Stacked != "" {
+				if v >= 0 {
+					pos += v
+				} else {
+					neg += v
+				}
+				continue
+			}
+			lo, hi = math.Min(lo, v), math.Max(hi, v)
+		}
+		lo, hi = math.Min(lo, neg), math.Max(hi, pos)
+	}
+	opts := ds.axisOptions()
+	if opts.AxisMin != nil {
+		lo = *opts.AxisMin
+	}
+	if opts.AxisMax != nil {
+		hi = *opts.AxisMax
+	}
+	// Columns grow from zero, or from the bottom of a log axis
+	var ticks []float64
+	baseline := 0.0
+	logAxis := ds.logAxis()
+	if logAxis {
+		lo, hi = logBounds(series)
+		for v := lo; v <= hi*1.001; v *= 10 {
+			ticks = append(ticks, v)
+		}
+		baseline = lo
+	} else {
+		step := niceStep(hi - lo)
+		lo = math.Floor(lo/step) * step
+		hi = math.Ceil(hi/step) * step
+		if hi <= lo {
+			hi = lo + step
+		}
+		for v := lo; v <= hi+step/2; v += step {
+			ticks = append(ticks, v)
+		}
+	}
+	y := func(v float64) int {
+		v = math.Max(lo, math.Min(hi, v))
+		if logAxis {
+			return plot.Max.Y - int((math.Log10(v)-math.Log10(lo))/(math.Log10(hi)-math.Log10(lo))*float64(plot.Dy()))
+		}
+		return plot.Max.Y - int((v-lo)/(hi-lo)*float64(plot.Dy()))
+	}
+
+	// Gridlines and value ticks
+	grid := color.RGBA{0xDD, 0xDD, 0xDD, 0xFF}
+	for _, v := range ticks {
+		py := y(v)
+		fillRect(img, image.Rect(plot.Min.X, py, plot.Max.X, py+1), grid)
+		label := formatTick(v)
+		drawText(img, plot.Min.X-12-textWidth(label, 2), py-13, label, color.Gray{0x55}, 2)
+	}
+	fillRect(img, image.Rect(plot.Min.X, y(baseline), plot.Max.X, y(baseline)+2), color.Gray{0x66})
+
+	n := len(ds.Points)
+	slot := float64(plot.Dx()) / float64(n)
+	center := func(i int) int { return plot.Min.X + int(slot*(float64(i)+0.5)) }
+
+	switch {
+	case line:
+		for j := range series {
+			c := ds.seriesColor(j)
+			for i := 1; i < n; i++ {
+				drawLine(img, center(i-1), y(series[j][i-1]), center(i), y(series[j][i]), 4, c, false)
+			}
+			for i := 0; i < n; i++ {
+				fillRect(img, image.Rect(center(i)-5, y(series[j][i])-5, center(i)+5, y(series[j][i])+5), c)
+			}
+		}
+		for i := 1; i < len(trend); i++ {
+			if trend[i-1] != nil && trend[i] != nil {
+				drawLine(img, center(i-1), y(*trend[i-1]), center(i), y(*trend[i]), 3, color.RGBA{0x55, 0x55, 0x55, 0xFF}, true)
+			}
+		}
+	case ds.Stacked != "":
+		barW := int(slot * 0.6)
+		for i := 0; i < n; i++ {
+			pos, neg := 0.0, 0.0
+			for j := range series {
+				v := series[j][i]
+				base := &pos
+				if v < 0 {
+					base = &neg
+				}
+				top, bottom := y(*base+v), y(*base)
+				if top > bottom {
+					top, bottom = bottom, top
+				}
+				fillRect(img, image.Rect(center(i)-barW/2, top, center(i)+barW/2, bottom), ds.seriesColor(j))
+				*base += v
+			}
+		}
+	default:
+		groupW := slot * 0.75
+		barW := int(groupW / float64(len(series)))
+		for i := 0; i < n; i++ {
+			left := center(i) - int(groupW/2)
+			for j := range series {
+				top, bottom := y(series[j][i]), y(baseline)
+				if top > bottom {
+					top, bottom = bottom, top
+				}
+				x0 := left + j*barW
+				fillRect(img, image.Rect(x0+1, top, x0+barW-1, bottom), ds.seriesColor(j))
+				if ds.Options.showLabels(len(series) * n) {
+					label := formatTick(series[j][i])
+					drawText(img, x0+(barW-textWidth(label, 2))/2, top-30, label, color.Gray{0x33}, 2)
+				}
+			}
+		}
+	}
+
+	// Category labels, shrunk to the slot width
+	for i, p := range ds.Points {
+		label := fitText(p.Label, int(slot)-6, 2)
+		drawText(img, center(i)-textWidth(label, 2)/2, plot.Max.Y+12, label, color.Gray{0x33}, 2)
+	}
+
+	// Legend for multi-series charts
+	if ds.multiSeries() && ds.Options.Legend != "NO_LEGEND" {
+		x := plot.Min.X
+		ly := plot.Max.Y + 60
+		for j, name := range ds.Series {
+			fillRect(img, image.Rect(x, ly+4, x+18, ly+22), ds.seriesColor(j))
+			drawText(img, x+26, ly, name, color.Gray{0x33}, 2)
+			x += 26 + textWidth(name, 2) + 30
+		}
+	}
+}
+
+func renderDonut(img *image.RGBA, ds DatasetSpec, plot image.Rectangle) {
+	var total float64
+	for _, p := range ds.Points {
+		total += p.Value
+	}
+	radius := math.Min(float64(plot.Dy()), float64(plot.Dx())*0.6) / 2
+	cx := float64(plot.Min.X) + radius + 20
+	cy := float64(plot.Min.Y+plot.Max.Y) / 2
+	inner := radius * DonutHole
+
+	// Cumulative slice end angles, clockwise from 12 o'clock
+	ends := make([]float64, len(ds.Points))
+	var acc float64
+	for i, p := range ds.Points {
+		acc += p.Value / total
+		ends[i] = acc * 2 * math.Pi
+	}
+	for py := int(cy - radius); py <= int(cy+radius); py++ {
+		for px := int(cx - radius); px <= int(cx+radius); px++ {
+			dx, dy := float64(px)-cx, float64(py)-cy
+			d := math.Hypot(dx, dy)
+			if d > radius || d < inner {
+				continue
+			}
+			a := math.Atan2(dx, -dy)
+			if a < 0 {
+				a += 2 * math.Pi
+			}
+			for i, end := range ends {
+				if a <= end {
+					img.Set(px, py, ds.seriesColor(i))
+					break
+				}
+			}
+		}
+	}
+
+	labels := shareLabels(ds.Points)
+	lx := int(cx+radius) + 60
+	ly := int(cy) - len(labels)*22
+	for i, label := range labels {
+		fillRect(img, image.Rect(lx, ly+4, lx+18, ly+22), ds.seriesColor(i))
+		drawText(img, lx+26, ly, fitText(label, img.Bounds().Dx()-lx-40, 2), color.Gray{0x33}, 2)
+		ly += 44
+	}
+}
+
+// logAxis reports whether the chart gets a log value axis: LogScale on an unstacked,
+// non-share chart whose values are all positive.
+func (ds DatasetSpec) logAxis() bool {
+	if !ds.LogScale || ds.Stacked != "" || ds.isShare() || len(ds.Points) == 0 {
+		return false
+	}
+	for _, vals := range ds.seriesValues() {
+		for _, v := range vals {
+			if v <= 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// NeedsImage reports whether the chart must be drawn by RenderPNG because Sheets can't
+// draw it: a log axis (see logAxis) on a chart that isn't read from a source range.
+func (ds DatasetSpec) NeedsImage() bool {
+	return ds.SourceRange == "" && ds.logAxis()
+}
+
+// logBounds spans the values with whole powers of ten, at least one decade apart.
+func logBounds(series [][]float64) (float64, float64) {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, vals := range series {
+		for _, v := range vals {
+			lo, hi = math.Min(lo, v), math.Max(hi, v)
+		}
+	}
+	lo = math.Pow(10, math.Floor(math.Log10(lo)))
+	hi = math.Pow(10, math.Ceil(math.Log10(hi)))
+	if hi <= lo {
+		hi = lo * 10
+	}
+	return lo, hi
+}
+
+// niceStep picks a 1/2/5 x 10^n tick step giving roughly five gridlines over span.
+func niceStep(span float64) float64 {
+	if span <= 0 {
+		return 1
+	}
+	raw := span / 5
+	mag := math.Pow(10, math.Floor(math.Log10(raw)))
+	for _, m := range []float64{1, 2, 5, 10} {
+		if raw <= m*mag {
+			return m * mag
+		}
+	}
+	return 10 * mag
+}
+
+func formatTick(v float64) string {
+	abs := math.Abs(v)
+	switch {
+	case abs >= 1e9:
+		return fmt.Sprintf("%.3gB", v/1e9)
+	case abs >= 1e6:
+		return fmt.Sprintf("%.3gM", v/1e6)
+	case abs >= 1e4:
+		return fmt.Sprintf("%.3gK", v/1e3)
+	}
+	return fmt.Sprintf("%.4g", v)
+}
+
+func fillRect(img *image.RGBA, r image.Rectangle, c color.Color) {
+	draw.Draw(img, r.Intersect(img.Bounds()), &image.Uniform{C: c}, image.Point{}, draw.Src)
+}
+
+// drawLine strokes a thick segment by stamping squares along it; dashed lines skip alternate runs.
+func drawLine(img *image.RGBA, x0, y0, x1, y1, width int, c color.Color, dashed bool) {
+	steps := int(math.Max(math.Abs(float64(x1-x0)), math.Abs(float64(y1-y0))))
+	if steps == 0 {
+		steps = 1
+	}
+	h := width / 2
+	for s := 0; s <= steps; s++ {
+		if dashed && (s/12)%2 == 1 {
+			continue
+		}
+		x := x0 + (x1-x0)*s/steps
+		y := y0 + (y1-y0)*s/steps
+		fillRect(img, image.Rect(x-h, y-h, x-h+width, y-h+width), c)
+	}
+}
+
+var face = basicfont.Face7x13
+
+func textWidth(s string, scale int) int {
+	return font.MeasureString(face, s).Ceil() * scale
+}
+
+// fitText truncates s with an ellipsis so it fits maxWidth pixels at the given scale.
+func fitText(s string, maxWidth, scale int) string {
+	if textWidth(s, scale) <= maxWidth {
+		return s
+	}
+	r := []rune(s)
+	for len(r) > 0 && textWidth(string(r)+"...", scale) > maxWidth {
+		r = r[:len(r)-1]
+	}
+	if len(r) == 0 {
+		return ""
+	}
+	return string(r) + "..."
+}
+
+// drawText renders s with its top-left corner at (x, y), scaled up from the 7x13 bitmap face.
+func drawText(dst *image.RGBA, x, y int, s string, c color.Color, scale int) {
+	w := textWidth(s, 1)
+	if w == 0 {
+		return
+	}
+	src := image.NewRGBA(image.Rect(0, 0, w, face.Height))
+	d := &font.Drawer{Dst: src, Src: &image.Uniform{C: c}, Face: face, Dot: fixed.P(0, face.Ascent)}
+	d.DrawString(s)
+	r := image.Rect(x, y, x+w*scale, y+face.Height*scale)
+	draw.NearestNeighbor.Scale(dst, r, src, src.Bounds(), draw.Over, nil)
+}
@@ -0,0 +1,85 @@
+package charts
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+)
+
+func TestRenderPNG(t *testing.T) {
+	tests := []struct {
+		name string
+		ds   DatasetSpec
+	}{
+		{"line with trend", DatasetSpec{Type: "timeseries", Trend: TrendLinear, Points: []Point{{Label: "2020", Value: 1}, {Label: "2021", Value: 3}, {Label: "2022", Value: 2}}}},
+		{"donut", DatasetSpec{Type: "category", Unit: "%", Points: []Point{{Label: "A", Value: 70}, {Label: "B", Value: 30}}}},
+		{"stacked", DatasetSpec{Type: "composition", Stacked: PercentStacked, Series: []string{"x", "y"}, Points: []Point{{Label: "Q1", Values: []float64{1, 3}}}}},
+		{"log line", DatasetSpec{Type: "timeseries", LogScale: true, Points: []Point{{Label: "2020", Value: 3}, {Label: "2021", Value: 300}, {Label: "2022", Value: 45000}}}},
+		{"negative columns", DatasetSpec{Type: "comparison", Points: []Point{{Label: "Loss", Value: -5}, {Label: "Gain", Value: 8}}}},
+	}
+	for _, tt := range tests {
+		data, err := RenderPNG(tt.ds, 600, 450)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("%s: decode: %v", tt.name, err)
+		}
+		if b := img.Bounds(); b.Dx() != 600 || b.Dy() != 450 {
+			t.Errorf("%s: size = %v, want 600x450", tt.name, b.Size())
+		}
+	}
+	if _, err := RenderPNG(DatasetSpec{}, 0, 0); err == nil {
+		t.Error("expected error for empty dataset")
+	}
+}
+
+func TestNeedsImage(t *testing.T) {
+	growth := []Point{{Label: "2020", Value: 5}, {Label: "2021", Value: 500}}
+	tests := []struct {
+		name string
+		ds   DatasetSpec
+		want bool
+	}{
+		{"log line", DatasetSpec{Type: "timeseries", LogScale: true, Points: growth}, true},
+		{"linear", DatasetSpec{Type: "timeseries", Points: growth}, false},
+		{"zero value", DatasetSpec{Type: "timeseries", LogScale: true, Points: []Point{{Label: "2020", Value: 0}, {Label: "2021", Value: 5}}}, false},
+		{"stacked", DatasetSpec{Type: "composition", LogScale: true, Stacked: Stacked, Series: []string{"x", "y"}, Points: []Point{{Label: "Q1", Values: []float64{1, 3}}}}, false},
+		{"source range", DatasetSpec{Type: "timeseries", LogScale: true, SourceRange: "Data!A1:B3", Points: growth}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.ds.NeedsImage(); got != tt.want {
+			t.Errorf("%s: NeedsImage() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLogBounds(t *testing.T) {
+	lo, hi := logBounds([][]float64{{3, 300, 45000}})
+	if lo != 1 || hi != 1e5 {
+		t.Errorf("logBounds = %v, %v; want 1, 100000", lo, hi)
+	}
+	if lo, hi := logBounds([][]float64{{10}}); lo != 10 || hi != 100 {
+		t.Errorf("logBounds of one power = %v, %v; want 10, 100", lo, hi)
+	}
+}
+
+func TestNiceStep(t *testing.T) {
+	tests := map[float64]float64{10: 2, 7.7e6: 2e6, 0.3: 0.1, 0: 1}
+	for span, want := range tests {
+		if got := niceStep(span); got != want {
+			t.Errorf("niceStep(%v) = %v, want %v", span, got, want)
+		}
+	}
+}
+
+func TestFitText(t *testing.T) {
+	if got := fitText("short", 1000, 2); got != "short" {
+		t.Errorf("fitText kept = %q", got)
+	}
+	got := fitText("a rather long category label", 100, 2)
+	if textWidth(got, 2) > 100 || got == "" {
+		t.Errorf("fitText = %q (%dpx), want <= 100px", got, textWidth(got, 2))
+	}
+}
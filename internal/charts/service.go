@@ -5,33 +5,167 @@ import (
 	"fmt"
 	"strings"
 
+	"gogemini-practices/internal/palette"
+
 	"google.golang.org/api/sheets/v4"
 	"google.golang.org/api/slides/v1"
 )
 
-// Point represents a single labeled numeric value.
+// Point represents a single labeled numeric value. For multi-series datasets, Values holds
+// one value per DatasetSpec.Series entry and Value is ignored.
 type Point struct {
-	Label string
-	Value float64
+	Label  string
+	Value  float64
+	Values []float64
 }
 
 // DatasetSpec describes a small dataset suitable for a single chart.
 type DatasetSpec struct {
-	Title  string
-	Unit   string
-	Type   string // timeseries | category | comparison
-	Points []Point
+	Title       string
+	Unit        string
+	Type        string // timeseries | category | comparison | composition
+	Points      []Point
+	Series      []string // optional series names; two or more make a multi-series chart
+	Stacked     string   // "" | STACKED | PERCENT_STACKED; see StackedType
+	Trend       string   // optional overlay for single-series timeseries: TrendLinear | TrendMovingAverage
+	TrendWindow int      // moving-average window; DefaultTrendWindow when <= 1
+	// SeriesColors are optional "#RRGGBB" colors for the series in order, repeating when
+	// there are more series; without them charts keep Sheets' default colors.
+	SeriesColors []string
+	Options      ChartOptions
+	// LogScale asks for a logarithmic value axis, for growth curves spanning orders of
+	// magnitude. The Sheets API has no log axis, so only RenderPNG draws one; see NeedsImage.
+	LogScale bool
+	// MinValue, when set, starts the value axis there (0 starts it at zero) unless
+	// Options.AxisMin fixes it for every chart.
+	MinValue *float64
+	// Source names where the figures come from, e.g. "BigQuery" or "model-estimated"; it
+	// is shown under the chart title as "Source: ...".
+	Source string
+	// SourceRange, when set, is an existing A1 range of the chart spreadsheet, e.g.
+	// "Sales!A1:C13", that the chart reads instead of a written copy of Points; see ReadRange.
+	SourceRange string
+}
+
+// Stacked types accepted by BasicChartSpec.StackedType.
+const (
+	Stacked        = "STACKED"
+	PercentStacked = "PERCENT_STACKED"
+)
+
+// StackedType resolves the stacking mode from the dataset type, an optional style hint
+// ("stacked", "percent", "none"), and the number of series. An explicit hint wins;
+// "composition" datasets default to 100%-stacked. Returns "" for unstacked charts, and
+// for fewer than two series, where a 100% stack would draw every column full.
+func StackedType(dsType, hint string, series int) string {
+	if series < 2 {
+		return ""
+	}
+	switch strings.ToLower(strings.TrimSpace(hint)) {
+	case "stacked":
+		return Stacked
+	case "percent", "percent_stacked", "100%":
+		return PercentStacked
+	case "none":
+		return ""
+	}
+	if strings.EqualFold(strings.TrimSpace(dsType), "composition") {
+		return PercentStacked
+	}
+	return ""
+}
+
+// multiSeries reports whether the dataset carries per-point values for two or more series.
+func (ds DatasetSpec) multiSeries() bool {
+	return len(ds.Series) > 1
+}
+
+// Developer metadata keys attached to every sheet the agent creates (data tabs and chart
+// sheets). MetadataKey carries the run ID and is what cleanup keys on; MetadataTopicKey
+// carries "<index>:<slug>" so a later run can find, refresh, or diff a single topic's data;
+// MetadataDeckKey carries the presentation ID, so decks sharing a spreadsheet only clean up
+// their own sheets.
+const (
+	MetadataKey      = "gogemini-slides-agent.run"
+	MetadataTopicKey = "gogemini-slides-agent.topic"
+	MetadataDeckKey  = "gogemini-slides-agent.deck"
+)
+
+// ChartTag identifies the run and topic a chart belongs to.
+type ChartTag struct {
+	RunID string
+	Index int    // 1-based topic position in the deck
+	Topic string // topic title; slugged for metadata
+	Deck  string // optional presentation ID the chart is embedded in; see MetadataDeckKey
+}
+
+// RangeName returns the named range holding the tag's chart data, e.g. "gsa_3f9a1c2e_2".
+// Named ranges only allow letters, digits, and underscores, so other runes become "_".
+func (t ChartTag) RangeName() string {
+	run := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, t.RunID)
+	return fmt.Sprintf("gsa_%s_%d", run, t.Index)
+}
+
+// topicValue is the MetadataTopicKey value for the tag.
+func (t ChartTag) topicValue() string {
+	return fmt.Sprintf("%d:%s", t.Index, Slug(t.Topic))
+}
+
+// Slug converts a topic title into a short tab-name-safe slug, e.g. "AI in Healthcare!" -> "ai-in-healthcare".
+func Slug(title string) string {
+	const maxLen = 30
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+			dash = false
+		case b.Len() > 0 && !dash:
+			b.WriteByte('-')
+			dash = true
+		}
+		if b.Len() >= maxLen {
+			break
+		}
+	}
+	slug := strings.Trim(b.String(), "-")
+	if len(slug) > maxLen {
+		slug = strings.TrimRight(slug[:maxLen], "-")
+	}
+	if slug == "" {
+		return "topic"
+	}
+	return slug
+}
+
+// SheetTitle names a run's data tab for a topic: "<runID>-<n>-<slug>". The 1-based
+// index keeps tabs unique when two topics share a slug.
+func SheetTitle(runID string, index int, topicTitle string) string {
+	return fmt.Sprintf("%s-%d-%s", runID, index, Slug(topicTitle))
 }
 
-// CreateSheetsChart writes the dataset into the given spreadsheet's sheet (creating it if needed),
-// clears prior data, wipes existing chart sheets, and creates a new chart. Returns: chartID, error.
-func CreateSheetsChart(ctx context.Context, sheetsSvc *sheets.Service, spreadsheetID string, sheetTitle string, ds DatasetSpec) (int64, error) {
+// CreateSheetsChart writes the dataset into the given spreadsheet's sheet (creating it if needed)
+// and creates a new chart on its own sheet. The data lands in the tag's named range, replacing
+// only what a previous write of the same range held. When tag.RunID is set, the data tab and chart
+// sheet are tagged with run and topic developer metadata so later runs can find and clean them up.
+// A dataset with a SourceRange is charted from that range as is: nothing is written and no data
+// tab is created. Returns: chartID, error.
+func CreateSheetsChart(ctx context.Context, sheetsSvc SheetsAPI, spreadsheetID string, sheetTitle string, tag ChartTag, ds DatasetSpec) (int64, error) {
 	if sheetsSvc == nil {
 		return 0, fmt.Errorf("sheetsSvc is nil")
 	}
 	if strings.TrimSpace(spreadsheetID) == "" {
 		return 0, fmt.Errorf("spreadsheetID is required")
 	}
+	if ds.SourceRange != "" {
+		return createRangeChart(ctx, sheetsSvc, spreadsheetID, tag, ds)
+	}
 	if strings.TrimSpace(sheetTitle) == "" {
 		sheetTitle = "Data"
 	}
@@ -40,85 +174,119 @@ func CreateSheetsChart(ctx context.Context, sheetsSvc *sheets.Service, spreadshe
 	}
 
 	// Ensure sheet exists, get its ID
-	sheetID, err := ensureGridSheet(ctx, sheetsSvc, spreadsheetID, sheetTitle)
+	rangeName := tag.RangeName()
+	target, err := ensureGridSheet(ctx, sheetsSvc, spreadsheetID, sheetTitle, rangeName)
 	if err != nil {
 		return 0, err
 	}
+	sheetID := target.ID
 
-	// Clear previous values on the target sheet
-	_, err = sheetsSvc.Spreadsheets.Values.Clear(spreadsheetID, sheetTitle+"!A:Z", &sheets.ClearValuesRequest{}).Context(ctx).Do()
-	if err != nil {
-		return 0, fmt.Errorf("clear values: %w", err)
-	}
-
-	// Wipe previous chart sheets
-	if err := deleteAllChartSheets(ctx, sheetsSvc, spreadsheetID); err != nil {
-		return 0, err
+	// Clear what a previous write left behind: just the named range when it exists; legacy
+	// sheets without one fall back to the whole used area
+	if clearRange := priorDataRange(sheetTitle, target); clearRange != "" {
+		if err := sheetsSvc.ClearValues(ctx, spreadsheetID, []string{clearRange}); err != nil {
+			return 0, fmt.Errorf("clear values: %w", err)
+		}
 	}
 
 	// Prepare typed values then convert at the boundary
-	headerValue := "Value"
-	if ds.Unit != "" {
-		headerValue = fmt.Sprintf("Value (%s)", ds.Unit)
-	}
-	labels := make([]string, 0, len(ds.Points))
-	nums := make([]float64, 0, len(ds.Points))
-	for _, p := range ds.Points {
-		labels = append(labels, p.Label)
-		nums = append(nums, p.Value)
-	}
-	values := makeCells(labels, headerValue, nums)
-	vr := &sheets.ValueRange{Values: values}
-	if _, err := sheetsSvc.Spreadsheets.Values.Update(spreadsheetID, sheetTitle+"!A1:B", vr).ValueInputOption("RAW").Context(ctx).Do(); err != nil {
+	values := makeTable(ds)
+	vr := &sheets.ValueRange{Range: a1Range(sheetTitle, "A1:"+columnLetter(len(values[0])-1)), Values: values}
+	if err := sheetsSvc.UpdateValues(ctx, spreadsheetID, []*sheets.ValueRange{vr}); err != nil {
 		return 0, fmt.Errorf("write values: %w", err)
 	}
 
-	// Define chart type
-	chartType := "COLUMN"
-	switch ds.Type {
-	case "timeseries":
-		chartType = "LINE"
-	case "category", "comparison":
-		chartType = "COLUMN"
-	}
-
-	// Build chart spec using ranges (A2:A, B2:B)
-	rowCount := int64(len(ds.Points) + 1) // including header
-	domainRange := &sheets.GridRange{SheetId: sheetID, StartRowIndex: 1, EndRowIndex: rowCount, StartColumnIndex: 0, EndColumnIndex: 1}
-	seriesRange := &sheets.GridRange{SheetId: sheetID, StartRowIndex: 1, EndRowIndex: rowCount, StartColumnIndex: 1, EndColumnIndex: 2}
-
 	addChartReq := &sheets.AddChartRequest{
 		Chart: &sheets.EmbeddedChart{
-			Spec: &sheets.ChartSpec{
-				Title: nonEmpty(ds.Title, "Chart"),
-				BasicChart: &sheets.BasicChartSpec{
-					ChartType:      chartType,
-					LegendPosition: "BOTTOM_LEGEND",
-					Domains: []*sheets.BasicChartDomain{
-						{Domain: &sheets.ChartData{SourceRange: &sheets.ChartSourceRange{Sources: []*sheets.GridRange{domainRange}}}},
-					},
-					Series: []*sheets.BasicChartSeries{
-						{Series: &sheets.ChartData{SourceRange: &sheets.ChartSourceRange{Sources: []*sheets.GridRange{seriesRange}}}, TargetAxis: "LEFT_AXIS"},
-					},
-				},
-			},
+			Spec:     buildChartSpec(ds, sheetID),
 			Position: &sheets.EmbeddedObjectPosition{NewSheet: true},
 		},
 	}
 
 	breq := &sheets.BatchUpdateSpreadsheetRequest{Requests: []*sheets.Request{{AddChart: addChartReq}}}
-	bresp, err := sheetsSvc.Spreadsheets.BatchUpdate(spreadsheetID, breq).Context(ctx).Do()
+	bresp, err := sheetsSvc.BatchUpdate(ctx, spreadsheetID, breq)
 	if err != nil {
 		return 0, fmt.Errorf("batch update (add chart): %w", err)
 	}
 	if bresp == nil || len(bresp.Replies) == 0 || bresp.Replies[0].AddChart == nil || bresp.Replies[0].AddChart.Chart == nil {
 		return 0, fmt.Errorf("missing add chart reply")
 	}
-	chartID := bresp.Replies[0].AddChart.Chart.ChartId
+	chart := bresp.Replies[0].AddChart.Chart
+	chartID := chart.ChartId
+
+	chartSheetID := int64(-1)
+	if chart.Position != nil && chart.Position.SheetId != 0 {
+		chartSheetID = chart.Position.SheetId
+	}
+	dataRange := &sheets.GridRange{SheetId: sheetID, StartRowIndex: 0, EndRowIndex: int64(len(values)), StartColumnIndex: 0, EndColumnIndex: int64(len(values[0]))}
+	reqs := []*sheets.Request{namedRangeRequest(rangeName, target.Range, dataRange)}
+	if tag.RunID != "" {
+		reqs = append(reqs, tagRequests(tag, sheetID, chartSheetID)...)
+	}
+	if _, err := sheetsSvc.BatchUpdate(ctx, spreadsheetID, &sheets.BatchUpdateSpreadsheetRequest{Requests: reqs}); err != nil {
+		return 0, fmt.Errorf("tag chart data: %w", err)
+	}
 
 	return chartID, nil
 }
 
+// priorDataRange returns the A1 range to clear before writing: "" for a freshly created sheet,
+// the existing named range, or the whole used area for a pre-existing sheet without one.
+func priorDataRange(sheetTitle string, target dataSheet) string {
+	switch {
+	case target.Created:
+		return ""
+	case target.Range != nil:
+		return target.Range.Name
+	}
+	return a1Range(sheetTitle, "A:Z")
+}
+
+// a1Range quotes the sheet title for A1 notation, e.g. ("3f9a-1-sales", "A1:B") -> "'3f9a-1-sales'!A1:B".
+func a1Range(sheetTitle, cells string) string {
+	return "'" + strings.ReplaceAll(sheetTitle, "'", "''") + "'!" + cells
+}
+
+// namedRangeRequest points the named range at the freshly written table, creating it or
+// resizing an existing one.
+func namedRangeRequest(name string, prior *sheets.NamedRange, gr *sheets.GridRange) *sheets.Request {
+	// Row/column 0 and SheetId 0 are meaningful and must be sent explicitly
+	gr.ForceSendFields = []string{"SheetId", "StartRowIndex", "StartColumnIndex"}
+	if prior != nil {
+		return &sheets.Request{UpdateNamedRange: &sheets.UpdateNamedRangeRequest{
+			NamedRange: &sheets.NamedRange{NamedRangeId: prior.NamedRangeId, Name: name, Range: gr},
+			Fields:     "range",
+		}}
+	}
+	return &sheets.Request{AddNamedRange: &sheets.AddNamedRangeRequest{NamedRange: &sheets.NamedRange{Name: name, Range: gr}}}
+}
+
+// tagRequests attaches run and topic metadata to the given sheets; negative IDs are skipped.
+func tagRequests(tag ChartTag, sheetIDs ...int64) []*sheets.Request {
+	var reqs []*sheets.Request
+	for _, id := range sheetIDs {
+		if id < 0 {
+			continue
+		}
+		kvs := [][2]string{{MetadataKey, tag.RunID}, {MetadataTopicKey, tag.topicValue()}}
+		if tag.Deck != "" {
+			kvs = append(kvs, [2]string{MetadataDeckKey, tag.Deck})
+		}
+		for _, kv := range kvs {
+			reqs = append(reqs, &sheets.Request{CreateDeveloperMetadata: &sheets.CreateDeveloperMetadataRequest{
+				DeveloperMetadata: &sheets.DeveloperMetadata{
+					MetadataKey:   kv[0],
+					MetadataValue: kv[1],
+					Visibility:    "DOCUMENT",
+					// SheetId 0 is the default first sheet and must be sent explicitly
+					Location: &sheets.DeveloperMetadataLocation{SheetId: id, ForceSendFields: []string{"SheetId"}},
+				},
+			}})
+		}
+	}
+	return reqs
+}
+
 // BuildEmbedRequests creates Slides requests to embed the given Sheets chart into a slide.
 // Position and size use EMU units to match official examples.
 func BuildEmbedRequests(spreadsheetID string, chartID int64, pageObjectID string, objectID string, xEMU, yEMU, widthEMU, heightEMU float64) []*slides.Request {
@@ -161,6 +329,25 @@ func BuildEmbedRequests(spreadsheetID string, chartID int64, pageObjectID string
 	}
 }
 
+// chartTitle is the title charts show: the dataset title with its unit, e.g. "Revenue
+// (billion USD)", unless the title already names the unit.
+func (ds DatasetSpec) chartTitle() string {
+	title := nonEmpty(ds.Title, "Chart")
+	unit := strings.TrimSpace(ds.Unit)
+	if unit == "" || strings.Contains(strings.ToLower(title), strings.ToLower(unit)) {
+		return title
+	}
+	return title + " (" + unit + ")"
+}
+
+// sourceNote is the provenance line under the chart title, or "" without a Source.
+func (ds DatasetSpec) sourceNote() string {
+	if s := strings.TrimSpace(ds.Source); s != "" {
+		return "Source: " + s
+	}
+	return ""
+}
+
 func nonEmpty(v, fallback string) string {
 	if v == "" {
 		return fallback
@@ -168,65 +355,294 @@ func nonEmpty(v, fallback string) string {
 	return v
 }
 
-// makeCells converts typed label/value slices into [][]interface{} expected by the Sheets API.
-func makeCells(labels []string, header string, nums []float64) [][]interface{} {
-	out := make([][]interface{}, 0, len(nums)+1)
-	out = append(out, []interface{}{"Label", header}) //nolint
-	for i := range nums {
-		out = append(out, []interface{}{labels[i], nums[i]}) //nolint
+// makeTable converts the dataset into the [][]interface{} grid expected by the Sheets API:
+// a header row followed by one row per point, with one value column per series.
+func makeTable(ds DatasetSpec) [][]interface{} {
+	header := []interface{}{"Label"} //nolint
+	if ds.multiSeries() {
+		for _, name := range ds.Series {
+			header = append(header, name)
+		}
+	} else if ds.Unit != "" {
+		header = append(header, fmt.Sprintf("Value (%s)", ds.Unit))
+	} else {
+		header = append(header, "Value")
+	}
+	var trend []*float64
+	if kind := ds.trendKind(); kind != TrendNone {
+		header = append(header, ds.trendHeader())
+		vals := make([]float64, len(ds.Points))
+		for i, p := range ds.Points {
+			vals[i] = p.Value
+		}
+		trend = TrendValues(vals, kind, ds.trendWindow())
+	}
+	var labels []string
+	if ds.isShare() {
+		labels = shareLabels(ds.Points)
+	}
+	out := make([][]interface{}, 0, len(ds.Points)+1)
+	out = append(out, header)
+	for i, p := range ds.Points {
+		row := []interface{}{p.Label} //nolint
+		if labels != nil {
+			row[0] = labels[i]
+		}
+		if ds.multiSeries() {
+			for j := range ds.Series {
+				var v float64
+				if j < len(p.Values) {
+					v = p.Values[j]
+				}
+				row = append(row, v)
+			}
+		} else {
+			row = append(row, p.Value)
+		}
+		if trend != nil {
+			// nil cells are skipped by the Sheets API, leaving gaps before the window fills
+			if v := trend[len(out)-1]; v != nil {
+				row = append(row, *v)
+			} else {
+				row = append(row, nil)
+			}
+		}
+		out = append(out, row)
 	}
 	return out
 }
 
-// CleanupSpreadsheetForCharts deletes prior generated sheets: all CHART-type tabs
-// and any grid sheets named with the prefix "Data_". Ensures at least one grid
-// sheet remains to satisfy Sheets constraints.
-func CleanupSpreadsheetForCharts(ctx context.Context, sheetsSvc *sheets.Service, spreadsheetID string) error {
+// seriesColorStyle is series i's color from SeriesColors, or nil for Sheets' default.
+func (ds DatasetSpec) seriesColorStyle(i int) *sheets.ColorStyle {
+	if len(ds.SeriesColors) == 0 {
+		return nil
+	}
+	r, g, b, err := palette.RGB(ds.SeriesColors[i%len(ds.SeriesColors)])
+	if err != nil {
+		return nil
+	}
+	return &sheets.ColorStyle{RgbColor: &sheets.Color{Red: r, Green: g, Blue: b}}
+}
+
+// buildChartSpec maps the dataset onto a BasicChart reading from the table written by makeTable.
+// Share datasets (see isShare) render as a donut instead.
+func buildChartSpec(ds DatasetSpec, sheetID int64) *sheets.ChartSpec {
+	if ds.isShare() {
+		return buildDonutSpec(ds, sheetID)
+	}
+	chartType := basicChartType(ds)
+
+	// Build chart spec using ranges (A2:A, B2:B, ...)
+	rowCount := int64(len(ds.Points) + 1) // including header
+	domainRange := &sheets.GridRange{SheetId: sheetID, StartRowIndex: 1, EndRowIndex: rowCount, StartColumnIndex: 0, EndColumnIndex: 1}
+
+	seriesCount := 1
+	if ds.multiSeries() {
+		seriesCount = len(ds.Series)
+	}
+	var series []*sheets.BasicChartSeries
+	for j := 0; j < seriesCount; j++ {
+		col := int64(j + 1)
+		seriesRange := &sheets.GridRange{SheetId: sheetID, StartRowIndex: 1, EndRowIndex: rowCount, StartColumnIndex: col, EndColumnIndex: col + 1}
+		s := &sheets.BasicChartSeries{Series: &sheets.ChartData{SourceRange: &sheets.ChartSourceRange{Sources: []*sheets.GridRange{seriesRange}}}, TargetAxis: "LEFT_AXIS"}
+		s.ColorStyle = ds.seriesColorStyle(j)
+		series = append(series, s)
+	}
+	if ds.trendKind() != TrendNone {
+		col := int64(seriesCount + 1)
+		trendRange := &sheets.GridRange{SheetId: sheetID, StartRowIndex: 1, EndRowIndex: rowCount, StartColumnIndex: col, EndColumnIndex: col + 1}
+		series = append(series, &sheets.BasicChartSeries{
+			Series:     &sheets.ChartData{SourceRange: &sheets.ChartSourceRange{Sources: []*sheets.GridRange{trendRange}}},
+			TargetAxis: "LEFT_AXIS",
+			Type:       "LINE",
+			LineStyle:  &sheets.LineStyle{Type: "MEDIUM_DASHED", Width: 2},
+		})
+	}
+
+	basic := &sheets.BasicChartSpec{
+		ChartType:      chartType,
+		LegendPosition: "BOTTOM_LEGEND",
+		Domains: []*sheets.BasicChartDomain{
+			{Domain: &sheets.ChartData{SourceRange: &sheets.ChartSourceRange{Sources: []*sheets.GridRange{domainRange}}}},
+		},
+		Series:      series,
+		StackedType: ds.Stacked,
+	}
+	// Series names live in the header row
+	if ds.multiSeries() || ds.trendKind() != TrendNone {
+		basic.HeaderCount = 1
+		basic.Domains[0].Domain.SourceRange.Sources[0].StartRowIndex = 0
+		for _, s := range series {
+			s.Series.SourceRange.Sources[0].StartRowIndex = 0
+		}
+	}
+	ds.axisOptions().apply(basic, seriesCount, len(ds.Points))
+	setAxisTitle(basic, ds.Unit)
+	return &sheets.ChartSpec{Title: ds.chartTitle(), Subtitle: ds.sourceNote(), BasicChart: basic}
+}
+
+// basicChartType picks the BasicChart type for the dataset: lines for timeseries, columns otherwise.
+func basicChartType(ds DatasetSpec) string {
+	// Lines don't stack meaningfully; stacked timeseries render as stacked columns
+	if ds.Type == "timeseries" && ds.Stacked == "" {
+		return "LINE"
+	}
+	return "COLUMN"
+}
+
+// columnLetter returns the A1 column letter for a zero-based index (0 -> A); tables stay well under 26 columns.
+func columnLetter(i int) string {
+	if i < 0 || i > 25 {
+		i = 25
+	}
+	return string(rune('A' + i))
+}
+
+// cleanupFields is the spreadsheet projection cleanup planning needs.
+const cleanupFields = "sheets(properties(sheetId,title,sheetType),developerMetadata(metadataKey,metadataValue)," +
+	"charts(chartId,spec(basicChart(domains(domain(sourceRange(sources(sheetId))))),pieChart(domain(sourceRange(sources(sheetId))))))),namedRanges(namedRangeId,name,range(sheetId))"
+
+// CleanupSpreadsheetForCharts deletes sheets created by previous agent runs: any sheet tagged
+// with MetadataKey developer metadata, plus legacy untagged "Data_N" tabs, the chart sheets
+// charting any of them, and named ranges on the deleted tabs. Unrelated user sheets and charts
+// are left alone; every deck's sheets go (BuildDeckCharts keeps other decks'). Ensures at
+// least one grid sheet remains to satisfy Sheets constraints.
+func CleanupSpreadsheetForCharts(ctx context.Context, sheetsSvc SheetsAPI, spreadsheetID string) error {
 	if strings.TrimSpace(spreadsheetID) == "" {
 		return fmt.Errorf("spreadsheetID is required")
 	}
-	ss, err := sheetsSvc.Spreadsheets.Get(spreadsheetID).
-		Fields("sheets(properties(sheetId,title,sheetType))").
-		Context(ctx).
-		Do()
+	ss, err := sheetsSvc.GetSpreadsheet(ctx, spreadsheetID, cleanupFields)
 	if err != nil {
 		return fmt.Errorf("get spreadsheet for cleanup: %w", err)
 	}
+	reqs, _ := cleanupRequests(ss, nil, 0, "")
+	if len(reqs) == 0 {
+		return nil
+	}
+	_, err = sheetsSvc.BatchUpdate(ctx, spreadsheetID, &sheets.BatchUpdateSpreadsheetRequest{Requests: reqs})
+	if err != nil {
+		return fmt.Errorf("cleanup spreadsheet: %w", err)
+	}
+	return nil
+}
+
+// cleanupRequests plans the deletes for CleanupSpreadsheetForCharts and returns them with the
+// set of deleted sheet IDs. Grid sheets in keep survive (they are about to be re-written) but
+// lose the chart sheets charting them. addedGrid counts grid sheets the same batch adds before
+// the deletes; when it is zero, one stale grid sheet is kept so the spreadsheet is never left
+// without one. With a deck, sheets tagged for another deck are left alone; sheets without a
+// deck tag, from runs before decks were tagged, are still deleted.
+func cleanupRequests(ss *sheets.Spreadsheet, keep map[int64]bool, addedGrid int, deck string) ([]*sheets.Request, map[int64]bool) {
+	stale := map[int64]bool{}
+	for _, sh := range ss.Sheets {
+		if sh == nil || sh.Properties == nil || isChartSheet(sh) || otherDeck(sh, deck) {
+			continue
+		}
+		if hasRunMetadata(sh) || strings.HasPrefix(sh.Properties.Title, "Data_") || keep[sh.Properties.SheetId] {
+			stale[sh.Properties.SheetId] = true
+		}
+	}
 	var gridDeleteIDs []int64
 	var chartDeleteIDs []int64
 	for _, sh := range ss.Sheets {
 		if sh == nil || sh.Properties == nil {
 			continue
 		}
-		if strings.EqualFold(sh.Properties.SheetType, "CHART") {
-			chartDeleteIDs = append(chartDeleteIDs, sh.Properties.SheetId)
+		if isChartSheet(sh) {
+			if hasRunMetadata(sh) && !otherDeck(sh, deck) || chartsReadFrom(sh, stale) {
+				chartDeleteIDs = append(chartDeleteIDs, sh.Properties.SheetId)
+			}
 			continue
 		}
-		if strings.HasPrefix(sh.Properties.Title, "Data_") {
+		if stale[sh.Properties.SheetId] && !keep[sh.Properties.SheetId] {
 			gridDeleteIDs = append(gridDeleteIDs, sh.Properties.SheetId)
 		}
 	}
 	// Ensure at least one grid sheet remains
-	if len(gridDeleteIDs) > 0 && len(gridDeleteIDs) == countGridSheets(ss) {
+	if addedGrid == 0 && len(keep) == 0 && len(gridDeleteIDs) > 0 && len(gridDeleteIDs) == countGridSheets(ss) {
 		gridDeleteIDs = gridDeleteIDs[1:]
 	}
 	var reqs []*sheets.Request
+	deleted := map[int64]bool{}
+	for _, id := range gridDeleteIDs {
+		deleted[id] = true
+	}
+	// Drop named ranges on deleted grid sheets first so none are left pointing at #REF
+	for _, nr := range ss.NamedRanges {
+		if nr != nil && nr.Range != nil && deleted[nr.Range.SheetId] {
+			reqs = append(reqs, &sheets.Request{DeleteNamedRange: &sheets.DeleteNamedRangeRequest{NamedRangeId: nr.NamedRangeId}})
+		}
+	}
 	for _, id := range chartDeleteIDs {
-		id := id
+		deleted[id] = true
 		reqs = append(reqs, &sheets.Request{DeleteSheet: &sheets.DeleteSheetRequest{SheetId: id}})
 	}
 	for _, id := range gridDeleteIDs {
-		id := id
 		reqs = append(reqs, &sheets.Request{DeleteSheet: &sheets.DeleteSheetRequest{SheetId: id}})
 	}
-	if len(reqs) == 0 {
-		return nil
+	return reqs, deleted
+}
+
+// isChartSheet reports whether the sheet holds an object such as a chart rather than a grid.
+// "CHART" is not a Sheets sheet type but is kept for spreadsheets tagged by older tooling.
+func isChartSheet(sh *sheets.Sheet) bool {
+	t := strings.ToUpper(sh.Properties.SheetType)
+	return t == "OBJECT" || t == "CHART"
+}
+
+func hasRunMetadata(sh *sheets.Sheet) bool {
+	for _, md := range sh.DeveloperMetadata {
+		if md != nil && md.MetadataKey == MetadataKey {
+			return true
+		}
 	}
-	_, err = sheetsSvc.Spreadsheets.BatchUpdate(spreadsheetID, &sheets.BatchUpdateSpreadsheetRequest{Requests: reqs}).Context(ctx).Do()
-	if err != nil {
-		return fmt.Errorf("cleanup spreadsheet: %w", err)
+	return false
+}
+
+// otherDeck reports whether the sheet is tagged for a deck other than deck; with no deck,
+// no sheet is.
+func otherDeck(sh *sheets.Sheet, deck string) bool {
+	if deck == "" {
+		return false
+	}
+	for _, md := range sh.DeveloperMetadata {
+		if md != nil && md.MetadataKey == MetadataDeckKey && md.MetadataValue != deck {
+			return true
+		}
 	}
-	return nil
+	return false
+}
+
+// chartsReadFrom reports whether any chart on the sheet takes its domain from one of the given sheets.
+func chartsReadFrom(sh *sheets.Sheet, sheetIDs map[int64]bool) bool {
+	reads := func(cd *sheets.ChartData) bool {
+		if cd == nil || cd.SourceRange == nil {
+			return false
+		}
+		for _, src := range cd.SourceRange.Sources {
+			if src != nil && sheetIDs[src.SheetId] {
+				return true
+			}
+		}
+		return false
+	}
+	for _, ch := range sh.Charts {
+		if ch == nil || ch.Spec == nil {
+			continue
+		}
+		if ch.Spec.PieChart != nil && reads(ch.Spec.PieChart.Domain) {
+			return true
+		}
+		if ch.Spec.BasicChart == nil {
+			continue
+		}
+		for _, d := range ch.Spec.BasicChart.Domains {
+			if d != nil && reads(d.Domain) {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 func countGridSheets(ss *sheets.Spreadsheet) int {
@@ -235,25 +651,35 @@ func countGridSheets(ss *sheets.Spreadsheet) int {
 		if sh == nil || sh.Properties == nil {
 			continue
 		}
-		if !strings.EqualFold(sh.Properties.SheetType, "CHART") {
+		if !isChartSheet(sh) {
 			n++
 		}
 	}
 	return n
 }
 
-func ensureGridSheet(ctx context.Context, sheetsSvc *sheets.Service, spreadsheetID, sheetTitle string) (int64, error) {
-	// Try to find existing sheet
-	ss, err := sheetsSvc.Spreadsheets.Get(spreadsheetID).
-		Fields("sheets(properties(sheetId,title,sheetType))").
-		Context(ctx).
-		Do()
+// dataSheet is the grid sheet a chart's data is written to.
+type dataSheet struct {
+	ID      int64
+	Created bool               // sheet was added by this call
+	Range   *sheets.NamedRange // existing named range for the data, if any
+}
+
+func ensureGridSheet(ctx context.Context, sheetsSvc SheetsAPI, spreadsheetID, sheetTitle, rangeName string) (dataSheet, error) {
+	// Try to find existing sheet and data range
+	ss, err := sheetsSvc.GetSpreadsheet(ctx, spreadsheetID, "sheets(properties(sheetId,title,sheetType)),namedRanges(namedRangeId,name)")
 	if err != nil {
-		return 0, fmt.Errorf("get spreadsheet: %w", err)
+		return dataSheet{}, fmt.Errorf("get spreadsheet: %w", err)
+	}
+	var prior *sheets.NamedRange
+	for _, nr := range ss.NamedRanges {
+		if nr != nil && nr.Name == rangeName {
+			prior = nr
+		}
 	}
 	for _, sh := range ss.Sheets {
 		if sh != nil && sh.Properties != nil && sh.Properties.Title == sheetTitle {
-			return sh.Properties.SheetId, nil
+			return dataSheet{ID: sh.Properties.SheetId, Range: prior}, nil
 		}
 	}
 
@@ -263,39 +689,13 @@ func ensureGridSheet(ctx context.Context, sheetsSvc *sheets.Service, spreadsheet
 			{AddSheet: &sheets.AddSheetRequest{Properties: &sheets.SheetProperties{Title: sheetTitle}}},
 		},
 	}
-	resp, err := sheetsSvc.Spreadsheets.BatchUpdate(spreadsheetID, bu).Context(ctx).Do()
+	resp, err := sheetsSvc.BatchUpdate(ctx, spreadsheetID, bu)
 	if err != nil {
-		return 0, fmt.Errorf("add sheet %q: %w", sheetTitle, err)
+		return dataSheet{}, fmt.Errorf("add sheet %q: %w", sheetTitle, err)
 	}
 	if resp == nil || len(resp.Replies) == 0 || resp.Replies[0].AddSheet == nil || resp.Replies[0].AddSheet.Properties == nil {
-		return 0, fmt.Errorf("missing add sheet reply")
-	}
-	return resp.Replies[0].AddSheet.Properties.SheetId, nil
-}
-
-func deleteAllChartSheets(ctx context.Context, sheetsSvc *sheets.Service, spreadsheetID string) error {
-	ss, err := sheetsSvc.Spreadsheets.Get(spreadsheetID).
-		Fields("sheets(properties(sheetId,sheetType))").
-		Context(ctx).
-		Do()
-	if err != nil {
-		return fmt.Errorf("get spreadsheet (for chart wipe): %w", err)
+		return dataSheet{}, fmt.Errorf("missing add sheet reply")
 	}
-	var reqs []*sheets.Request
-	for _, sh := range ss.Sheets {
-		if sh == nil || sh.Properties == nil {
-			continue
-		}
-		if strings.EqualFold(sh.Properties.SheetType, "CHART") {
-			reqs = append(reqs, &sheets.Request{DeleteSheet: &sheets.DeleteSheetRequest{SheetId: sh.Properties.SheetId}})
-		}
-	}
-	if len(reqs) == 0 {
-		return nil
-	}
-	_, err = sheetsSvc.Spreadsheets.BatchUpdate(spreadsheetID, &sheets.BatchUpdateSpreadsheetRequest{Requests: reqs}).Context(ctx).Do()
-	if err != nil {
-		return fmt.Errorf("delete chart sheets: %w", err)
-	}
-	return nil
+	// A same-named range on another (deleted or renamed) sheet is updated to point here
+	return dataSheet{ID: resp.Replies[0].AddSheet.Properties.SheetId, Created: true, Range: prior}, nil
 }
@@ -0,0 +1,220 @@
+package charts
+
+import (
+	"testing"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+func TestSlug(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"AI in Healthcare!", "ai-in-healthcare"},
+		{"  --Market   Growth 2024--  ", "market-growth-2024"},
+		{"Überblick & Ausblick", "berblick-ausblick"},
+		{"!!!", "topic"},
+		{"", "topic"},
+		{"A very long topic title that keeps going and going", "a-very-long-topic-title-that-k"},
+	}
+	for _, tt := range tests {
+		if got := Slug(tt.in); got != tt.want {
+			t.Errorf("Slug(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSheetTitle_DistinctForRepeatedTopics(t *testing.T) {
+	a := SheetTitle("run1", 1, "Revenue")
+	b := SheetTitle("run1", 2, "Revenue")
+	if a == b {
+		t.Fatalf("expected distinct titles, both %q", a)
+	}
+	if a != "run1-1-revenue" {
+		t.Errorf("SheetTitle = %q, want %q", a, "run1-1-revenue")
+	}
+}
+
+func TestChartsReadFrom(t *testing.T) {
+	sh := &sheets.Sheet{Charts: []*sheets.EmbeddedChart{{
+		Spec: &sheets.ChartSpec{BasicChart: &sheets.BasicChartSpec{
+			Domains: []*sheets.BasicChartDomain{{Domain: &sheets.ChartData{
+				SourceRange: &sheets.ChartSourceRange{Sources: []*sheets.GridRange{{SheetId: 7}}},
+			}}},
+		}},
+	}}}
+	if !chartsReadFrom(sh, map[int64]bool{7: true}) {
+		t.Error("expected chart reading sheet 7 to match")
+	}
+	if chartsReadFrom(sh, map[int64]bool{8: true}) {
+		t.Error("expected user chart on another sheet to be kept")
+	}
+}
+
+func TestStackedType(t *testing.T) {
+	tests := []struct {
+		dsType, hint string
+		series       int
+		want         string
+	}{
+		{"comparison", "", 2, ""},
+		{"comparison", "stacked", 2, Stacked},
+		{"timeseries", "percent", 3, PercentStacked},
+		{"composition", "", 2, PercentStacked},
+		{"composition", "stacked", 2, Stacked},
+		{"composition", "none", 2, ""},
+		{"composition", "", 1, ""},        // one series would fill every column to 100%
+		{"composition", "percent", 0, ""}, // single-value points
+		{"comparison", "stacked", 1, ""},
+	}
+	for _, tt := range tests {
+		if got := StackedType(tt.dsType, tt.hint, tt.series); got != tt.want {
+			t.Errorf("StackedType(%q, %q, %d) = %q, want %q", tt.dsType, tt.hint, tt.series, got, tt.want)
+		}
+	}
+}
+
+func TestBuildChartSpec_MultiSeriesStacked(t *testing.T) {
+	ds := DatasetSpec{
+		Type:    "timeseries",
+		Series:  []string{"EU", "US", "APAC"},
+		Stacked: PercentStacked,
+		Points: []Point{
+			{Label: "2023", Values: []float64{1, 2, 3}},
+			{Label: "2024", Values: []float64{2, 3, 4}},
+		},
+	}
+	table := makeTable(ds)
+	if len(table) != 3 || len(table[0]) != 4 || table[0][3] != "APAC" {
+		t.Fatalf("unexpected table %v", table)
+	}
+
+	basic := buildChartSpec(ds, 42).BasicChart
+	if basic.ChartType != "COLUMN" {
+		t.Errorf("ChartType = %q, want COLUMN for stacked data", basic.ChartType)
+	}
+	if basic.StackedType != PercentStacked {
+		t.Errorf("StackedType = %q, want %q", basic.StackedType, PercentStacked)
+	}
+	if len(basic.Series) != 3 {
+		t.Fatalf("got %d series, want 3", len(basic.Series))
+	}
+	if basic.HeaderCount != 1 {
+		t.Errorf("HeaderCount = %d, want 1 so series take names from the header row", basic.HeaderCount)
+	}
+	if col := basic.Series[2].Series.SourceRange.Sources[0].StartColumnIndex; col != 3 {
+		t.Errorf("third series column = %d, want 3", col)
+	}
+}
+
+func TestBuildChartSpec_SingleSeries(t *testing.T) {
+	ds := DatasetSpec{Type: "timeseries", Unit: "%", Points: []Point{{Label: "Q1", Value: 1}}}
+	if h := makeTable(ds)[0][1]; h != "Value (%)" {
+		t.Errorf("header = %v, want %q", h, "Value (%)")
+	}
+	basic := buildChartSpec(ds, 0).BasicChart
+	if basic.ChartType != "LINE" || basic.StackedType != "" || len(basic.Series) != 1 {
+		t.Errorf("unexpected spec: type=%q stacked=%q series=%d", basic.ChartType, basic.StackedType, len(basic.Series))
+	}
+}
+
+func TestBuildChartSpec_SeriesColors(t *testing.T) {
+	ds := DatasetSpec{
+		Type:         "timeseries",
+		Series:       []string{"a", "b", "c"},
+		SeriesColors: []string{"#FF0000", "#0000FF"},
+		Points:       []Point{{Label: "2020", Values: []float64{1, 2, 3}}},
+	}
+	series := buildChartSpec(ds, 0).BasicChart.Series
+	for i, want := range []float64{1, 0, 1} {
+		if c := series[i].ColorStyle; c == nil || c.RgbColor.Red != want {
+			t.Errorf("series %d color = %+v, want red %v", i, c, want)
+		}
+	}
+	ds.SeriesColors = nil
+	if c := buildChartSpec(ds, 0).BasicChart.Series[0].ColorStyle; c != nil {
+		t.Errorf("color without SeriesColors = %+v, want Sheets' default", c)
+	}
+}
+
+func TestChartTitle(t *testing.T) {
+	tests := []struct {
+		ds   DatasetSpec
+		want string
+	}{
+		{DatasetSpec{Title: "Revenue", Unit: "billion USD"}, "Revenue (billion USD)"},
+		{DatasetSpec{Title: "Revenue in USD", Unit: "usd"}, "Revenue in USD"},
+		{DatasetSpec{Title: "Visits"}, "Visits"},
+		{DatasetSpec{Unit: "%"}, "Chart (%)"},
+	}
+	for _, tt := range tests {
+		if got := tt.ds.chartTitle(); got != tt.want {
+			t.Errorf("chartTitle(%q, %q) = %q, want %q", tt.ds.Title, tt.ds.Unit, got, tt.want)
+		}
+	}
+}
+
+func TestBuildChartSpec_SourceNote(t *testing.T) {
+	ds := DatasetSpec{Title: "Visits", Type: "category", Source: "Google Analytics", Points: []Point{{Label: "A", Value: 1}, {Label: "B", Value: 5}}}
+	if got := buildChartSpec(ds, 0).Subtitle; got != "Source: Google Analytics" {
+		t.Errorf("Subtitle = %q, want the source note", got)
+	}
+	ds.Unit, ds.Points = "%", []Point{{Label: "A", Value: 40}, {Label: "B", Value: 60}}
+	if got := buildChartSpec(ds, 0); got.PieChart == nil || got.Subtitle != "Source: Google Analytics" {
+		t.Errorf("donut = %+v, want the source note", got)
+	}
+	ds.Source = " "
+	if got := buildChartSpec(ds, 0).Subtitle; got != "" {
+		t.Errorf("Subtitle without a source = %q, want none", got)
+	}
+}
+
+func TestChartTag_RangeName(t *testing.T) {
+	tests := []struct {
+		tag  ChartTag
+		want string
+	}{
+		{ChartTag{RunID: "3f9a1c2e", Index: 2}, "gsa_3f9a1c2e_2"},
+		{ChartTag{RunID: "q3-review", Index: 1}, "gsa_q3_review_1"},
+	}
+	for _, tt := range tests {
+		if got := tt.tag.RangeName(); got != tt.want {
+			t.Errorf("RangeName(%+v) = %q, want %q", tt.tag, got, tt.want)
+		}
+	}
+	if got := (ChartTag{Index: 3, Topic: "Cloud Costs"}).topicValue(); got != "3:cloud-costs" {
+		t.Errorf("topicValue = %q, want %q", got, "3:cloud-costs")
+	}
+}
+
+func TestPriorDataRange(t *testing.T) {
+	nr := &sheets.NamedRange{Name: "gsa_run_1"}
+	tests := []struct {
+		name   string
+		target dataSheet
+		want   string
+	}{
+		{"new sheet", dataSheet{Created: true, Range: nr}, ""},
+		{"refresh", dataSheet{Range: nr}, "gsa_run_1"},
+		{"legacy sheet", dataSheet{}, "'Data_1'!A:Z"},
+	}
+	for _, tt := range tests {
+		if got := priorDataRange("Data_1", tt.target); got != tt.want {
+			t.Errorf("%s: priorDataRange = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTagRequests(t *testing.T) {
+	reqs := tagRequests(ChartTag{RunID: "r1", Index: 1, Topic: "Sales"}, 0, -1, 9)
+	if len(reqs) != 4 {
+		t.Fatalf("got %d requests, want run+topic metadata on 2 sheets", len(reqs))
+	}
+	loc := reqs[0].CreateDeveloperMetadata.DeveloperMetadata.Location
+	if loc.SheetId != 0 || len(loc.ForceSendFields) == 0 {
+		t.Error("sheet 0 must be sent explicitly")
+	}
+	if v := reqs[1].CreateDeveloperMetadata.DeveloperMetadata.MetadataValue; v != "1:sales" {
+		t.Errorf("topic metadata = %q, want %q", v, "1:sales")
+	}
+}
@@ -0,0 +1,297 @@
+package charts
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// MaxRangeRows is the most data rows, after the header, a source range may hold.
+const MaxRangeRows = 20
+
+// sourceRange is a parsed A1 range of an existing tab. Indexes are zero-based and
+// half-open like a GridRange; endRow is 0 for an open-ended range such as "Sales!A:C".
+type sourceRange struct {
+	sheet            string
+	startRow, endRow int64
+	startCol, endCol int64
+}
+
+var cellPattern = regexp.MustCompile(`^([A-Za-z]{1,3})([0-9]*)$`)
+
+// parseSourceRange parses "Sales!A1:C13" or "'Q3 sales'!A:C". The range must name its tab
+// and span a label column and at least one value column.
+func parseSourceRange(s string) (sourceRange, error) {
+	s = strings.TrimSpace(s)
+	bang := strings.LastIndex(s, "!")
+	if bang <= 0 {
+		return sourceRange{}, fmt.Errorf("range %q: want <tab>!<from>:<to>, e.g. Sales!A1:C13", s)
+	}
+	sheet, cells := s[:bang], s[bang+1:]
+	if len(sheet) >= 2 && strings.HasPrefix(sheet, "'") && strings.HasSuffix(sheet, "'") {
+		sheet = strings.ReplaceAll(sheet[1:len(sheet)-1], "''", "'")
+	}
+	from, to, ok := strings.Cut(cells, ":")
+	if !ok {
+		return sourceRange{}, fmt.Errorf("range %q: want <from>:<to> cells, e.g. A1:C13", s)
+	}
+	r := sourceRange{sheet: sheet}
+	var err error
+	var startRow int64
+	if r.startCol, startRow, err = parseCell(from); err != nil {
+		return sourceRange{}, fmt.Errorf("range %q: %w", s, err)
+	}
+	if r.endCol, r.endRow, err = parseCell(to); err != nil {
+		return sourceRange{}, fmt.Errorf("range %q: %w", s, err)
+	}
+	if startRow > 0 {
+		r.startRow = startRow - 1
+	}
+	r.endCol++
+	if r.endCol-r.startCol < 2 {
+		return sourceRange{}, fmt.Errorf("range %q: want a label column and at least one value column", s)
+	}
+	if r.endRow != 0 && r.endRow-r.startRow < 2 {
+		return sourceRange{}, fmt.Errorf("range %q: want a header row and at least one data row", s)
+	}
+	return r, nil
+}
+
+// parseCell splits an A1 cell such as "C13" into a zero-based column and its 1-based row;
+// the row is 0 when the cell has none ("C").
+func parseCell(cell string) (col, row int64, err error) {
+	m := cellPattern.FindStringSubmatch(strings.TrimSpace(cell))
+	if m == nil {
+		return 0, 0, fmt.Errorf("bad cell %q", cell)
+	}
+	for _, r := range strings.ToUpper(m[1]) {
+		col = col*26 + int64(r-'A'+1)
+	}
+	if m[2] != "" {
+		if row, err = strconv.ParseInt(m[2], 10, 64); err != nil || row < 1 {
+			return 0, 0, fmt.Errorf("bad cell %q", cell)
+		}
+	}
+	return col - 1, row, nil
+}
+
+// column returns the GridRange of the range's i-th column on sheetID, header row included.
+func (r sourceRange) column(sheetID int64, i int) *sheets.GridRange {
+	col := r.startCol + int64(i)
+	return &sheets.GridRange{
+		SheetId: sheetID, StartRowIndex: r.startRow, EndRowIndex: r.endRow, StartColumnIndex: col, EndColumnIndex: col + 1,
+		// SheetId, row, and column 0 are meaningful and must be sent explicitly
+		ForceSendFields: []string{"SheetId", "StartRowIndex", "StartColumnIndex"},
+	}
+}
+
+// ReadRange reads an existing A1 range, e.g. "Sales!A1:C13", as a dataset whose
+// SourceRange is set, so the chart reads the range itself. The first row holds headers,
+// the first column labels, and every other column numbers, one series each; rows with an
+// empty value are skipped. The title is the first value header, and labels that are all
+// years or dates make a timeseries.
+func ReadRange(ctx context.Context, sheetsSvc SheetsAPI, spreadsheetID, a1 string) (DatasetSpec, error) {
+	src, err := parseSourceRange(a1)
+	if err != nil {
+		return DatasetSpec{}, err
+	}
+	rows, err := sheetsSvc.GetValues(ctx, spreadsheetID, a1)
+	if err != nil {
+		return DatasetSpec{}, fmt.Errorf("read range %q: %w", a1, err)
+	}
+	if len(rows) < 2 {
+		return DatasetSpec{}, fmt.Errorf("range %q: want a header row and at least one data row", a1)
+	}
+	if len(rows)-1 > MaxRangeRows {
+		return DatasetSpec{}, fmt.Errorf("range %q has %d data rows; charts take at most %d", a1, len(rows)-1, MaxRangeRows)
+	}
+	width := int(src.endCol - src.startCol)
+	header := make([]string, width)
+	for i := range header {
+		header[i] = cellText(rows[0], i)
+	}
+	for i, h := range header[1:] {
+		if h == "" {
+			return DatasetSpec{}, fmt.Errorf("range %q: value column %d has no header", a1, i+1)
+		}
+	}
+
+	ds := DatasetSpec{Title: header[1], Type: "timeseries", SourceRange: strings.TrimSpace(a1)}
+	if width > 2 {
+		ds.Series = header[1:]
+	}
+rows:
+	for _, row := range rows[1:] {
+		label := cellText(row, 0)
+		if label == "" {
+			continue
+		}
+		p := Point{Label: label}
+		for i := 1; i < width; i++ {
+			v, ok, err := cellNumber(row, i)
+			if err != nil {
+				return DatasetSpec{}, fmt.Errorf("range %q: column %q: %w", a1, header[i], err)
+			}
+			if !ok {
+				continue rows
+			}
+			p.Values = append(p.Values, v)
+		}
+		if !ds.multiSeries() {
+			p.Value, p.Values = p.Values[0], nil
+		}
+		ds.Points = append(ds.Points, p)
+		if !isDateLabel(label) {
+			ds.Type = "category"
+		}
+	}
+	if len(ds.Points) == 0 {
+		return DatasetSpec{}, fmt.Errorf("range %q: no rows with a label and values", a1)
+	}
+	return ds, nil
+}
+
+// Describe is the dataset as compact text for a prompt, e.g.
+// `Revenue (USD, timeseries): 2025=10; 2026=12.5`.
+func (ds DatasetSpec) Describe() string {
+	var b strings.Builder
+	b.WriteString(nonEmpty(ds.Title, "Chart"))
+	b.WriteString(" (")
+	if ds.Unit != "" {
+		b.WriteString(ds.Unit + ", ")
+	}
+	b.WriteString(ds.Type)
+	if ds.multiSeries() {
+		b.WriteString("; series " + strings.Join(ds.Series, " / "))
+	}
+	b.WriteString("): ")
+	for i, p := range ds.Points {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		vals := []float64{p.Value}
+		if ds.multiSeries() {
+			vals = p.Values
+		}
+		text := make([]string, len(vals))
+		for j, v := range vals {
+			text[j] = strconv.FormatFloat(v, 'g', -1, 64)
+		}
+		b.WriteString(p.Label + "=" + strings.Join(text, "/"))
+	}
+	return b.String()
+}
+
+// cellText is row[i] as trimmed text; missing cells are "".
+func cellText(row []interface{}, i int) string {
+	if i >= len(row) || row[i] == nil {
+		return ""
+	}
+	if f, ok := row[i].(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return strings.TrimSpace(fmt.Sprint(row[i]))
+}
+
+// cellNumber is row[i] as a number; ok is false for an empty cell.
+func cellNumber(row []interface{}, i int) (v float64, ok bool, err error) {
+	if i < len(row) {
+		if f, isNum := row[i].(float64); isNum {
+			return f, true, nil
+		}
+	}
+	s := cellText(row, i)
+	if s == "" {
+		return 0, false, nil
+	}
+	v, err = strconv.ParseFloat(strings.ReplaceAll(s, ",", ""), 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("%q is not a number", s)
+	}
+	return v, true, nil
+}
+
+// isDateLabel reports whether a label reads as a year, month, or date.
+func isDateLabel(s string) bool {
+	for _, layout := range []string{"2006", "2006-01", "2006-01-02", "1/2/2006", "Jan 2006", "January 2006"} {
+		if _, err := time.Parse(layout, s); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// rangeChartSpec charts the source range on sheetID as is: its first column is the domain
+// and every further column a series named by the header row.
+func rangeChartSpec(ds DatasetSpec, src sourceRange, sheetID int64) *sheets.ChartSpec {
+	domain := &sheets.ChartData{SourceRange: &sheets.ChartSourceRange{Sources: []*sheets.GridRange{src.column(sheetID, 0)}}}
+	seriesCount := int(src.endCol-src.startCol) - 1
+	var series []*sheets.BasicChartSeries
+	for j := 1; j <= seriesCount; j++ {
+		s := &sheets.BasicChartSeries{
+			Series:     &sheets.ChartData{SourceRange: &sheets.ChartSourceRange{Sources: []*sheets.GridRange{src.column(sheetID, j)}}},
+			TargetAxis: "LEFT_AXIS",
+		}
+		s.ColorStyle = ds.seriesColorStyle(j - 1)
+		series = append(series, s)
+	}
+	basic := &sheets.BasicChartSpec{
+		ChartType:      basicChartType(ds),
+		LegendPosition: "BOTTOM_LEGEND",
+		Domains:        []*sheets.BasicChartDomain{{Domain: domain}},
+		Series:         series,
+		HeaderCount:    1,
+		StackedType:    ds.Stacked,
+	}
+	ds.Options.apply(basic, seriesCount, len(ds.Points))
+	return &sheets.ChartSpec{Title: ds.chartTitle(), Subtitle: ds.sourceNote(), BasicChart: basic}
+}
+
+// sourceSheetID finds the grid tab a source range reads from.
+func sourceSheetID(ss *sheets.Spreadsheet, src sourceRange) (int64, error) {
+	for _, sh := range ss.Sheets {
+		if sh != nil && sh.Properties != nil && !isChartSheet(sh) && sh.Properties.Title == src.sheet {
+			return sh.Properties.SheetId, nil
+		}
+	}
+	return 0, fmt.Errorf("no tab %q for the chart's range", src.sheet)
+}
+
+// createRangeChart is CreateSheetsChart for a dataset with a SourceRange: the chart goes on a
+// sheet of its own, tagged when tag.RunID is set, and nothing is written.
+func createRangeChart(ctx context.Context, sheetsSvc SheetsAPI, spreadsheetID string, tag ChartTag, ds DatasetSpec) (int64, error) {
+	src, err := parseSourceRange(ds.SourceRange)
+	if err != nil {
+		return 0, err
+	}
+	ss, err := sheetsSvc.GetSpreadsheet(ctx, spreadsheetID, "sheets(properties(sheetId,title,sheetType))")
+	if err != nil {
+		return 0, fmt.Errorf("get spreadsheet: %w", err)
+	}
+	sheetID, err := sourceSheetID(ss, src)
+	if err != nil {
+		return 0, err
+	}
+	bresp, err := sheetsSvc.BatchUpdate(ctx, spreadsheetID, &sheets.BatchUpdateSpreadsheetRequest{Requests: []*sheets.Request{{AddChart: &sheets.AddChartRequest{
+		Chart: &sheets.EmbeddedChart{Spec: rangeChartSpec(ds, src, sheetID), Position: &sheets.EmbeddedObjectPosition{NewSheet: true}},
+	}}}})
+	if err != nil {
+		return 0, fmt.Errorf("batch update (add chart): %w", err)
+	}
+	if bresp == nil || len(bresp.Replies) == 0 || bresp.Replies[0].AddChart == nil || bresp.Replies[0].AddChart.Chart == nil {
+		return 0, fmt.Errorf("missing add chart reply")
+	}
+	chart := bresp.Replies[0].AddChart.Chart
+	// The source tab is the user's; only the chart sheet is tagged, so cleanup removes just it
+	if tag.RunID != "" && chart.Position != nil && chart.Position.SheetId != 0 {
+		if _, err := sheetsSvc.BatchUpdate(ctx, spreadsheetID, &sheets.BatchUpdateSpreadsheetRequest{Requests: tagRequests(tag, chart.Position.SheetId)}); err != nil {
+			return 0, fmt.Errorf("tag chart sheet: %w", err)
+		}
+	}
+	return chart.ChartId, nil
+}
@@ -0,0 +1,124 @@
+package charts
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"gogemini-practices/internal/fakeapi"
+)
+
+func TestParseSourceRange(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    sourceRange
+		wantErr bool
+	}{
+		{in: "Sales!A1:B13", want: sourceRange{sheet: "Sales", endRow: 13, endCol: 2}},
+		{in: "'Q3 ''24'!C5:E9", want: sourceRange{sheet: "Q3 '24", startRow: 4, endRow: 9, startCol: 2, endCol: 5}},
+		{in: "Sales!A:C", want: sourceRange{sheet: "Sales", endCol: 3}},
+		{in: "Sales!AA2:AB", want: sourceRange{sheet: "Sales", startRow: 1, startCol: 26, endCol: 28}},
+		{in: "A1:B13", wantErr: true},
+		{in: "Sales!A1", wantErr: true},
+		{in: "Sales!A1:A13", wantErr: true}, // no value column
+		{in: "Sales!A1:B1", wantErr: true},  // no data row
+		{in: "Sales!A0:B3", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseSourceRange(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseSourceRange(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("parseSourceRange(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestReadRange(t *testing.T) {
+	tests := []struct {
+		name    string
+		a1      string
+		rows    [][]interface{}
+		want    DatasetSpec
+		wantErr bool
+	}{
+		{
+			name: "single series by year",
+			a1:   "Sales!A1:B4",
+			rows: [][]interface{}{{"Year", "Revenue"}, {2024.0, 10.0}, {2025.0, ""}, {"2026", "1,250.5"}},
+			want: DatasetSpec{Title: "Revenue", Type: "timeseries", SourceRange: "Sales!A1:B4", Points: []Point{
+				{Label: "2024", Value: 10},
+				{Label: "2026", Value: 1250.5},
+			}},
+		},
+		{
+			name: "multi series by region",
+			a1:   "Sales!A1:C3",
+			rows: [][]interface{}{{"Region", "2025", "2026"}, {"EMEA", 1.0, 2.0}, {"US", 3.0, 4.0}},
+			want: DatasetSpec{Title: "2025", Type: "category", Series: []string{"2025", "2026"}, SourceRange: "Sales!A1:C3", Points: []Point{
+				{Label: "EMEA", Values: []float64{1, 2}},
+				{Label: "US", Values: []float64{3, 4}},
+			}},
+		},
+		{name: "text value", a1: "Sales!A1:B2", rows: [][]interface{}{{"Year", "Revenue"}, {"2024", "lots"}}, wantErr: true},
+		{name: "value column without header", a1: "Sales!A1:B2", rows: [][]interface{}{{"Year"}, {"2024", 1.0}}, wantErr: true},
+		{name: "header only", a1: "Sales!A1:B9", rows: [][]interface{}{{"Year", "Revenue"}}, wantErr: true},
+		{name: "unknown range", a1: "Other!A1:B2", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &fakeapi.Sheets{Ranges: map[string][][]interface{}{}}
+			if tt.rows != nil {
+				api.Ranges[tt.a1] = tt.rows
+			}
+			got, err := ReadRange(context.Background(), api, "sheet-1", tt.a1)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadRange() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadRange() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRangeChartSpec(t *testing.T) {
+	src, err := parseSourceRange("Sales!B2:D8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	basic := rangeChartSpec(DatasetSpec{Type: "timeseries"}, src, 7).BasicChart
+	if basic.ChartType != "LINE" || basic.HeaderCount != 1 || len(basic.Series) != 2 {
+		t.Fatalf("unexpected spec: type=%q header=%d series=%d", basic.ChartType, basic.HeaderCount, len(basic.Series))
+	}
+	domain := basic.Domains[0].Domain.SourceRange.Sources[0]
+	if domain.SheetId != 7 || domain.StartRowIndex != 1 || domain.EndRowIndex != 8 || domain.StartColumnIndex != 1 {
+		t.Errorf("domain = %+v, want column B, rows 2-8 of sheet 7", domain)
+	}
+	if col := basic.Series[1].Series.SourceRange.Sources[0].StartColumnIndex; col != 3 {
+		t.Errorf("second series column = %d, want 3 (D)", col)
+	}
+}
+
+func TestDatasetSpec_Describe(t *testing.T) {
+	tests := []struct {
+		ds   DatasetSpec
+		want string
+	}{
+		{
+			ds:   DatasetSpec{Title: "Revenue", Unit: "USD", Type: "timeseries", Points: []Point{{Label: "2025", Value: 10}, {Label: "2026", Value: 12.5}}},
+			want: "Revenue (USD, timeseries): 2025=10; 2026=12.5",
+		},
+		{
+			ds:   DatasetSpec{Title: "Orders", Type: "comparison", Series: []string{"2025", "2026"}, Points: []Point{{Label: "EMEA", Values: []float64{10, 12}}}},
+			want: "Orders (comparison; series 2025 / 2026): EMEA=10/12",
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.ds.Describe(); got != tt.want {
+			t.Errorf("Describe() = %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package charts
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Trend kinds for the optional overlay series on timeseries charts.
+const (
+	TrendNone          = ""
+	TrendLinear        = "linear"
+	TrendMovingAverage = "moving-average"
+)
+
+// DefaultTrendWindow is the trailing window used for moving averages when none is set.
+const DefaultTrendWindow = 3
+
+// ParseTrend normalizes a trend flag or model hint. Accepts "", "none", "linear",
+// "moving-average" (or "ma", "moving_average").
+func ParseTrend(s string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "none":
+		return TrendNone, nil
+	case "linear", "trendline":
+		return TrendLinear, nil
+	case "moving-average", "moving_average", "ma":
+		return TrendMovingAverage, nil
+	}
+	return "", fmt.Errorf("unknown trend %q (want none|linear|moving-average)", s)
+}
+
+// trendKind returns the trend to draw for the dataset: only single-series timeseries
+// with at least three points get one.
+func (ds DatasetSpec) trendKind() string {
+	if ds.Type != "timeseries" || ds.multiSeries() || ds.Stacked != "" || len(ds.Points) < 3 {
+		return TrendNone
+	}
+	return ds.Trend
+}
+
+// trendHeader labels the trend column, which Sheets also uses as the legend entry.
+func (ds DatasetSpec) trendHeader() string {
+	if ds.trendKind() == TrendMovingAverage {
+		return fmt.Sprintf("Moving avg (%d)", ds.trendWindow())
+	}
+	return "Trend"
+}
+
+func (ds DatasetSpec) trendWindow() int {
+	if ds.TrendWindow > 1 {
+		return ds.TrendWindow
+	}
+	return DefaultTrendWindow
+}
+
+// TrendValues computes the overlay series for values. Moving averages are trailing and
+// leave the first window-1 entries unset (nil) so the line starts once the window is full;
+// linear trends are an ordinary least-squares fit over the point index.
+func TrendValues(values []float64, kind string, window int) []*float64 {
+	out := make([]*float64, len(values))
+	switch kind {
+	case TrendMovingAverage:
+		if window < 2 {
+			window = DefaultTrendWindow
+		}
+		var sum float64
+		for i, v := range values {
+			sum += v
+			if i >= window {
+				sum -= values[i-window]
+			}
+			if i >= window-1 {
+				avg := sum / float64(window)
+				out[i] = &avg
+			}
+		}
+	case TrendLinear:
+		n := float64(len(values))
+		if n < 2 {
+			return out
+		}
+		var sx, sy, sxx, sxy float64
+		for i, v := range values {
+			x := float64(i)
+			sx += x
+			sy += v
+			sxx += x * x
+			sxy += x * v
+		}
+		den := n*sxx - sx*sx
+		if den == 0 {
+			return out
+		}
+		slope := (n*sxy - sx*sy) / den
+		intercept := (sy - slope*sx) / n
+		for i := range values {
+			y := intercept + slope*float64(i)
+			out[i] = &y
+		}
+	}
+	return out
+}
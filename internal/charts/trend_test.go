@@ -0,0 +1,67 @@
+package charts
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseTrend(t *testing.T) {
+	tests := []struct {
+		in, want string
+		wantErr  bool
+	}{
+		{"", TrendNone, false},
+		{"none", TrendNone, false},
+		{"Linear", TrendLinear, false},
+		{"ma", TrendMovingAverage, false},
+		{"moving_average", TrendMovingAverage, false},
+		{"exponential", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseTrend(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseTrend(%q) = %q, %v; want %q, err=%v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestTrendValues_MovingAverage(t *testing.T) {
+	got := TrendValues([]float64{1, 2, 3, 4, 5}, TrendMovingAverage, 3)
+	if got[0] != nil || got[1] != nil {
+		t.Fatalf("expected leading gaps before the window fills, got %v %v", got[0], got[1])
+	}
+	for i, want := range map[int]float64{2: 2, 3: 3, 4: 4} {
+		if got[i] == nil || *got[i] != want {
+			t.Errorf("MA[%d] = %v, want %v", i, got[i], want)
+		}
+	}
+}
+
+func TestTrendValues_Linear(t *testing.T) {
+	got := TrendValues([]float64{1, 3, 5, 7}, TrendLinear, 0)
+	for i, want := range []float64{1, 3, 5, 7} {
+		if got[i] == nil || math.Abs(*got[i]-want) > 1e-9 {
+			t.Errorf("linear[%d] = %v, want %v", i, got[i], want)
+		}
+	}
+}
+
+func TestBuildChartSpec_TrendSeries(t *testing.T) {
+	ds := DatasetSpec{Type: "timeseries", Trend: TrendMovingAverage, Points: []Point{
+		{Label: "Jan", Value: 1}, {Label: "Feb", Value: 2}, {Label: "Mar", Value: 3},
+	}}
+	table := makeTable(ds)
+	if table[0][2] != "Moving avg (3)" || table[1][2] != nil || table[3][2] != 2.0 {
+		t.Fatalf("unexpected trend column: %v", table)
+	}
+	basic := buildChartSpec(ds, 0).BasicChart
+	if len(basic.Series) != 2 || basic.Series[1].LineStyle == nil {
+		t.Fatalf("expected dashed trend series, got %+v", basic.Series)
+	}
+
+	// Category data never gets a trend even when requested
+	ds.Type = "category"
+	if len(makeTable(ds)[0]) != 2 {
+		t.Error("trend column added to non-timeseries dataset")
+	}
+}
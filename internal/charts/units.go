@@ -0,0 +1,141 @@
+package charts
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// scaleWords maps the magnitude words a figure or unit may carry to their factor.
+var scaleWords = map[string]float64{
+	"thousand": 1e3, "thousands": 1e3,
+	"million": 1e6, "millions": 1e6, "mn": 1e6, "mio": 1e6,
+	"billion": 1e9, "billions": 1e9, "bn": 1e9,
+	"trillion": 1e12, "trillions": 1e12, "tn": 1e12,
+}
+
+// scaleSuffixes are the one-letter magnitudes written right after a number, as in "$1.2B".
+var scaleSuffixes = map[rune]float64{'k': 1e3, 'K': 1e3, 'm': 1e6, 'M': 1e6, 'b': 1e9, 'B': 1e9, 't': 1e12, 'T': 1e12}
+
+// currencySymbols names the unit a leading currency sign stands for.
+var currencySymbols = map[rune]string{'$': "USD", '€': "EUR", '£': "GBP", '¥': "JPY"}
+
+// ParseQuantity reads a figure as people write it: "$1.2B", "1,200 million", "€300k",
+// "45%", or "USD 2.5 bn". It returns the number as written, the magnitude it carries (1
+// without one), and the unit it names: a currency code for a sign or code, "%", or the
+// words after it ("1.2 million users" -> "users"). A one-letter magnitude must touch the
+// number, so "5 m" is 5 with unit "m".
+func ParseQuantity(s string) (value, scale float64, unit string, ok bool) {
+	s = strings.TrimSpace(s)
+	scale = 1
+	// A leading currency code, as in "USD 2.5 bn"
+	if code, rest, found := strings.Cut(s, " "); found && len(code) == 3 && strings.ToUpper(code) == code && isLetters(code) {
+		unit, s = code, strings.TrimSpace(rest)
+	}
+	if r, size := utf8.DecodeRuneInString(s); currencySymbols[r] != "" {
+		unit, s = currencySymbols[r], strings.TrimSpace(s[size:])
+	}
+	end := 0
+	for end < len(s) && (s[end] >= '0' && s[end] <= '9' || s[end] == '.' || s[end] == ',' || end == 0 && (s[end] == '-' || s[end] == '+')) {
+		end++
+	}
+	v, err := strconv.ParseFloat(strings.ReplaceAll(s[:end], ",", ""), 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, 0, "", false
+	}
+	rest := s[end:]
+	if r, size := utf8.DecodeRuneInString(rest); scaleSuffixes[r] != 0 {
+		if next, _ := utf8.DecodeRuneInString(rest[size:]); !unicode.IsLetter(next) {
+			scale, rest = scaleSuffixes[r], rest[size:]
+		}
+	}
+	rest = strings.TrimSpace(rest)
+	if strings.HasPrefix(rest, "%") {
+		return v, scale, "%", true
+	}
+	words := strings.Fields(rest)
+	if len(words) > 0 && scale == 1 {
+		if f := scaleWords[strings.ToLower(words[0])]; f != 0 {
+			scale, words = f, words[1:]
+		}
+	}
+	if len(words) > 0 && strings.EqualFold(words[0], "of") {
+		words = words[1:]
+	}
+	if unit == "" {
+		unit = strings.Join(words, " ")
+	}
+	return v, scale, unit, true
+}
+
+func isLetters(s string) bool {
+	for _, r := range s {
+		if !unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// SplitScale splits a magnitude word off a dataset unit: "billion USD", "USD bn", and
+// "millions of users" give 1e9 "USD", 1e9 "USD", and 1e6 "users". A unit without one
+// gives 1 and the unit as is.
+func SplitScale(unit string) (float64, string) {
+	words := strings.Fields(unit)
+	switch {
+	case len(words) == 0:
+	case scaleWords[strings.ToLower(words[0])] != 0:
+		f := scaleWords[strings.ToLower(words[0])]
+		words = words[1:]
+		if len(words) > 0 && strings.EqualFold(words[0], "of") {
+			words = words[1:]
+		}
+		return f, strings.Join(words, " ")
+	case scaleWords[strings.ToLower(words[len(words)-1])] != 0:
+		return scaleWords[strings.ToLower(words[len(words)-1])], strings.Join(words[:len(words)-1], " ")
+	}
+	return 1, strings.TrimSpace(unit)
+}
+
+// Scale picks one magnitude for values in base units, so a chart's axis reads 1.2 and 0.8
+// rather than 1200000000 and 800000000: million, billion, or trillion by the largest
+// absolute value, or 1 and "" below a million.
+func Scale(values []float64) (float64, string) {
+	largest := 0.0
+	for _, v := range values {
+		largest = math.Max(largest, math.Abs(v))
+	}
+	switch {
+	case largest >= 1e12:
+		return 1e12, "trillion"
+	case largest >= 1e9:
+		return 1e9, "billion"
+	case largest >= 1e6:
+		return 1e6, "million"
+	}
+	return 1, ""
+}
+
+// ScaledUnit names the unit of scaled values, e.g. "billion USD", "million", or "users".
+func ScaledUnit(word, unit string) string {
+	return strings.TrimSpace(word + " " + unit)
+}
+
+// setAxisTitle titles the value axis with the dataset unit, which carries the scale the
+// values are in (see Scale), keeping any bounds ChartOptions set on it.
+func setAxisTitle(basic *sheets.BasicChartSpec, unit string) {
+	if unit == "" {
+		return
+	}
+	for _, a := range basic.Axis {
+		if a.Position == "LEFT_AXIS" {
+			a.Title = unit
+			return
+		}
+	}
+	basic.Axis = append(basic.Axis, &sheets.BasicChartAxis{Position: "LEFT_AXIS", Title: unit})
+}
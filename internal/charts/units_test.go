@@ -0,0 +1,94 @@
+package charts
+
+import (
+	"testing"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+func TestParseQuantity(t *testing.T) {
+	tests := []struct {
+		in        string
+		wantValue float64
+		wantScale float64
+		wantUnit  string
+		wantOK    bool
+	}{
+		{"$1.2B", 1.2, 1e9, "USD", true},
+		{"1,200 million", 1200, 1e6, "", true},
+		{"€300k", 300, 1e3, "EUR", true},
+		{"45%", 45, 1, "%", true},
+		{"USD 2.5 bn", 2.5, 1e9, "USD", true},
+		{"1.2 millions of users", 1.2, 1e6, "users", true},
+		{"5 m", 5, 1, "m", true},
+		{"-3.5M", -3.5, 1e6, "", true},
+		{"12 Mbps", 12, 1, "Mbps", true},
+		{"about 5", 0, 0, "", false},
+		{"", 0, 0, "", false},
+	}
+	for _, tt := range tests {
+		v, scale, unit, ok := ParseQuantity(tt.in)
+		if ok != tt.wantOK || v != tt.wantValue || scale != tt.wantScale || unit != tt.wantUnit {
+			t.Errorf("ParseQuantity(%q) = %v, %v, %q, %v; want %v, %v, %q, %v", tt.in, v, scale, unit, ok, tt.wantValue, tt.wantScale, tt.wantUnit, tt.wantOK)
+		}
+	}
+}
+
+func TestSplitScale(t *testing.T) {
+	tests := []struct {
+		in         string
+		wantFactor float64
+		wantUnit   string
+	}{
+		{"billion USD", 1e9, "USD"},
+		{"USD bn", 1e9, "USD"},
+		{"Millions of users", 1e6, "users"},
+		{"thousand", 1e3, ""},
+		{"USD", 1, "USD"},
+		{" ms ", 1, "ms"},
+		{"", 1, ""},
+	}
+	for _, tt := range tests {
+		if f, u := SplitScale(tt.in); f != tt.wantFactor || u != tt.wantUnit {
+			t.Errorf("SplitScale(%q) = %v, %q; want %v, %q", tt.in, f, u, tt.wantFactor, tt.wantUnit)
+		}
+	}
+}
+
+func TestScale(t *testing.T) {
+	tests := []struct {
+		values     []float64
+		wantFactor float64
+		wantWord   string
+	}{
+		{[]float64{1.2e9, 8e8}, 1e9, "billion"},
+		{[]float64{-2.5e6, 1}, 1e6, "million"},
+		{[]float64{3e12}, 1e12, "trillion"},
+		{[]float64{999999, 12}, 1, ""},
+		{nil, 1, ""},
+	}
+	for _, tt := range tests {
+		if f, w := Scale(tt.values); f != tt.wantFactor || w != tt.wantWord {
+			t.Errorf("Scale(%v) = %v, %q; want %v, %q", tt.values, f, w, tt.wantFactor, tt.wantWord)
+		}
+	}
+	if got := ScaledUnit("billion", "USD"); got != "billion USD" {
+		t.Errorf("ScaledUnit() = %q, want %q", got, "billion USD")
+	}
+}
+
+func TestBuildChartSpec_AxisTitle(t *testing.T) {
+	min := 0.0
+	ds := DatasetSpec{Title: "Revenue", Unit: "billion USD", Type: "category", Points: []Point{{Label: "A", Value: 1.2}, {Label: "B", Value: 0.8}}}
+	ds.Options.AxisMin = &min
+	axes := buildChartSpec(ds, 7).BasicChart.Axis
+	if len(axes) != 1 || axes[0].Position != "LEFT_AXIS" || axes[0].Title != "billion USD" || axes[0].ViewWindowOptions == nil {
+		t.Errorf("axes = %+v, want one titled LEFT_AXIS keeping its bounds", axes)
+	}
+
+	basic := &sheets.BasicChartSpec{}
+	setAxisTitle(basic, "")
+	if len(basic.Axis) != 0 {
+		t.Errorf("setAxisTitle(\"\") added %d axes", len(basic.Axis))
+	}
+}
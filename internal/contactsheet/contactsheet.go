@@ -0,0 +1,117 @@
+// Package contactsheet composes slide thumbnails into one grid image, a whole deck at a
+// glance that fits in a pull request, chat message, or notification.
+package contactsheet
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif" // register decoders for thumbnails
+	_ "image/jpeg"
+	"image/png"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// Tile is one cell of the sheet.
+type Tile struct {
+	Label string // printed under the image, e.g. the slide number
+	Data  []byte // PNG, JPEG, or GIF; empty or undecodable draws a gray "unavailable" cell
+}
+
+// Options controls the layout. The zero value gives 400px tiles, at most 4 per row.
+type Options struct {
+	Columns   int    // tiles per row; 0 picks up to 4
+	TileWidth int    // pixels; 0 is 400. Tiles keep the first decodable image's aspect ratio
+	Title     string // optional heading, e.g. the deck's name
+}
+
+const (
+	defaultColumns   = 4
+	defaultTileWidth = 400
+	gap              = 16 // around and between tiles
+	labelHeight      = 20 // below each tile
+)
+
+var (
+	background  = color.RGBA{0xF1, 0xF3, 0xF4, 0xFF}
+	missingFill = color.RGBA{0xDA, 0xDC, 0xE0, 0xFF}
+	ink         = color.RGBA{0x20, 0x21, 0x24, 0xFF}
+	face        = basicfont.Face7x13
+)
+
+// Compose draws tiles left to right, top to bottom, and returns the sheet as PNG.
+func Compose(tiles []Tile, opts Options) ([]byte, error) {
+	if len(tiles) == 0 {
+		return nil, errors.New("contact sheet needs at least one tile")
+	}
+	cols := opts.Columns
+	if cols <= 0 {
+		cols = min(len(tiles), defaultColumns)
+	}
+	cols = min(cols, len(tiles))
+	rows := (len(tiles) + cols - 1) / cols
+	tw := opts.TileWidth
+	if tw <= 0 {
+		tw = defaultTileWidth
+	}
+
+	imgs := make([]image.Image, len(tiles))
+	th := tw * 9 / 16 // Slides' default 16:9 page until an image says otherwise
+	sized := false
+	for i, t := range tiles {
+		img, _, err := image.Decode(bytes.NewReader(t.Data))
+		if err != nil {
+			continue
+		}
+		imgs[i] = img
+		if b := img.Bounds(); !sized && b.Dx() > 0 {
+			th, sized = max(1, tw*b.Dy()/b.Dx()), true
+		}
+	}
+
+	top := gap
+	if opts.Title != "" {
+		top += labelHeight + gap/2
+	}
+	w := gap + cols*(tw+gap)
+	h := top + rows*(th+labelHeight+gap)
+	sheet := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(sheet, sheet.Bounds(), &image.Uniform{C: background}, image.Point{}, draw.Src)
+	if opts.Title != "" {
+		drawText(sheet, opts.Title, gap, gap, w-2*gap)
+	}
+	for i, t := range tiles {
+		x := gap + (i%cols)*(tw+gap)
+		y := top + (i/cols)*(th+labelHeight+gap)
+		cell := image.Rect(x, y, x+tw, y+th)
+		if imgs[i] != nil {
+			draw.CatmullRom.Scale(sheet, cell, imgs[i], imgs[i].Bounds(), draw.Src, nil)
+		} else {
+			draw.Draw(sheet, cell, &image.Uniform{C: missingFill}, image.Point{}, draw.Src)
+			drawText(sheet, "unavailable", x+tw/2-font.MeasureString(face, "unavailable").Ceil()/2, y+th/2-face.Height/2, tw)
+		}
+		drawText(sheet, t.Label, x, y+th+(labelHeight-face.Height)/2, tw)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, sheet); err != nil {
+		return nil, fmt.Errorf("encode contact sheet: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// drawText writes s with its top-left corner at x, y, cut to fit maxWidth pixels.
+func drawText(dst draw.Image, s string, x, y, maxWidth int) {
+	r := []rune(s)
+	for len(r) > 0 && font.MeasureString(face, string(r)).Ceil() > maxWidth {
+		r = r[:len(r)-1]
+	}
+	d := &font.Drawer{Dst: dst, Src: &image.Uniform{C: ink}, Face: face, Dot: fixed.P(x, y+face.Ascent)}
+	d.DrawString(string(r))
+}
@@ -0,0 +1,77 @@
+package contactsheet
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestCompose(t *testing.T) {
+	slide := func(c color.Color) []byte {
+		img := image.NewRGBA(image.Rect(0, 0, 800, 450))
+		for y := 0; y < 450; y++ {
+			for x := 0; x < 800; x++ {
+				img.Set(x, y, c)
+			}
+		}
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+	red := slide(color.RGBA{0xFF, 0, 0, 0xFF})
+	tiles := []Tile{{Label: "1", Data: red}, {Label: "2", Data: red}, {Label: "3"}, {Label: "4", Data: []byte("not an image")}, {Label: "5", Data: red}}
+
+	tests := []struct {
+		name          string
+		opts          Options
+		wantW, wantH  int
+		wantRedAt     image.Point // inside the first tile
+		wantMissingAt image.Point // inside the third tile
+	}{
+		{
+			name:          "defaults",
+			opts:          Options{},
+			wantW:         gap + 4*(400+gap),
+			wantH:         gap + 2*(225+labelHeight+gap),
+			wantRedAt:     image.Pt(gap+200, gap+100),
+			wantMissingAt: image.Pt(gap+2*(400+gap)+10, gap+10),
+		},
+		{
+			name:          "columns, width, and title",
+			opts:          Options{Columns: 2, TileWidth: 160, Title: "Q3 review"},
+			wantW:         gap + 2*(160+gap),
+			wantH:         gap + labelHeight + gap/2 + 3*(90+labelHeight+gap),
+			wantRedAt:     image.Pt(gap+80, gap+labelHeight+gap/2+45),
+			wantMissingAt: image.Pt(gap+10, gap+labelHeight+gap/2+(90+labelHeight+gap)+10),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := Compose(tiles, tt.opts)
+			if err != nil {
+				t.Fatalf("Compose() error = %v", err)
+			}
+			img, err := png.Decode(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("decode sheet: %v", err)
+			}
+			if b := img.Bounds(); b.Dx() != tt.wantW || b.Dy() != tt.wantH {
+				t.Errorf("sheet is %dx%d, want %dx%d", b.Dx(), b.Dy(), tt.wantW, tt.wantH)
+			}
+			if r, g, _, _ := img.At(tt.wantRedAt.X, tt.wantRedAt.Y).RGBA(); r>>8 != 0xFF || g>>8 != 0 {
+				t.Errorf("first tile pixel = %v, want the slide's red", img.At(tt.wantRedAt.X, tt.wantRedAt.Y))
+			}
+			if got := color.RGBAModel.Convert(img.At(tt.wantMissingAt.X, tt.wantMissingAt.Y)); got != missingFill {
+				t.Errorf("missing tile pixel = %v, want %v", got, missingFill)
+			}
+		})
+	}
+
+	if _, err := Compose(nil, Options{}); err == nil {
+		t.Error("Compose(nil) succeeded, want an error")
+	}
+}
@@ -0,0 +1,263 @@
+// Package debugdump writes redacted copies of a run's API traffic (Gemini prompts and
+// responses, Custom Search results, Slides, Sheets, Drive, and Vision request bodies) to a
+// directory, one numbered JSON file per exchange, so a failing run can be attached to a bug
+// report. Request headers, which carry API keys and OAuth tokens, are never written; key
+// query parameters and the secrets given to New are replaced with REDACTED everywhere.
+// Every exchange is also counted by stage (see Counts), with or without a directory.
+package debugdump
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Redacted replaces every secret in a dump.
+const Redacted = "REDACTED"
+
+// redactedParams are query parameters that carry credentials.
+var redactedParams = []string{"key", "access_token"}
+
+// Exchange is the file format: one request and its response.
+type Exchange struct {
+	Stage        string `json:"stage"`
+	Method       string `json:"method"`
+	URL          string `json:"url"`
+	RequestBody  string `json:"request_body,omitempty"`
+	Status       int    `json:"status,omitempty"`
+	ResponseBody string `json:"response_body,omitempty"`
+	Error        string `json:"error,omitempty"`
+	DurationMS   int64  `json:"duration_ms"`
+}
+
+// Count is the traffic to one stage: requests sent, those that failed (no response, or a
+// status of 400 or more, retried ones included), and their total time.
+type Count struct {
+	Requests   int   `json:"requests"`
+	Errors     int   `json:"errors,omitempty"`
+	DurationMS int64 `json:"duration_ms"`
+}
+
+// Dumper writes exchanges into one directory. A nil *Dumper dumps nothing, so callers can
+// wrap clients unconditionally.
+type Dumper struct {
+	dir     string
+	secrets []string
+	mu      sync.Mutex
+	seq     int
+	counts  map[string]Count
+}
+
+// New creates dir if needed. secrets (API keys, say) are redacted wherever they appear;
+// empty ones are ignored. An empty dir writes nothing and only counts the exchanges.
+func New(dir string, secrets ...string) (*Dumper, error) {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("create dump dir: %w", err)
+		}
+	}
+	d := &Dumper{dir: dir, counts: map[string]Count{}}
+	for _, s := range secrets {
+		if s != "" {
+			d.secrets = append(d.secrets, s)
+		}
+	}
+	return d, nil
+}
+
+// Client returns a copy of base (http.DefaultClient when nil) whose requests are dumped.
+// Wrap clients after authentication so token exchanges stay out of the dump.
+func (d *Dumper) Client(base *http.Client) *http.Client {
+	if base == nil {
+		base = http.DefaultClient
+	}
+	if d == nil {
+		return base
+	}
+	c := *base
+	c.Transport = d.Transport(base.Transport)
+	return &c
+}
+
+// Transport wraps base (http.DefaultTransport when nil) so its requests are dumped.
+func (d *Dumper) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if d == nil {
+		return base
+	}
+	return &transport{d: d, base: base}
+}
+
+type transport struct {
+	d    *Dumper
+	base http.RoundTripper
+}
+
+// RoundTrip sends req and dumps the exchange. Failing to write the dump never fails the request.
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ex := Exchange{Stage: Stage(req.URL), Method: req.Method, URL: t.d.redact(redactURL(req.URL))}
+	if t.d.dir == "" {
+		start := time.Now()
+		resp, err := t.base.RoundTrip(req)
+		t.d.count(ex.Stage, resp, err, time.Since(start))
+		return resp, err
+	}
+	if req.Body != nil && req.Body != http.NoBody {
+		b, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("read request body: %w", err)
+		}
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(b))
+		ex.RequestBody = t.d.body(b, req.Header.Get("Content-Type"))
+	}
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	elapsed := time.Since(start)
+	ex.DurationMS = elapsed.Milliseconds()
+	t.d.count(ex.Stage, resp, err, elapsed)
+	if err != nil {
+		ex.Error = t.d.redact(err.Error())
+		t.d.write(ex)
+		return nil, err
+	}
+	b, readErr := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(b))
+	ex.Status = resp.StatusCode
+	ex.ResponseBody = t.d.body(b, resp.Header.Get("Content-Type"))
+	if readErr != nil {
+		ex.Error = t.d.redact(readErr.Error())
+	}
+	t.d.write(ex)
+	return resp, readErr
+}
+
+// count adds an exchange to its stage's Count.
+func (d *Dumper) count(stage string, resp *http.Response, err error, elapsed time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	c := d.counts[stage]
+	c.Requests++
+	if err != nil || resp.StatusCode >= 400 {
+		c.Errors++
+	}
+	c.DurationMS += elapsed.Milliseconds()
+	d.counts[stage] = c
+}
+
+// Counts returns the requests sent so far through d's clients, by Stage; nil for a nil d.
+func (d *Dumper) Counts() map[string]Count {
+	if d == nil {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	out := make(map[string]Count, len(d.counts))
+	for stage, c := range d.counts {
+		out[stage] = c
+	}
+	return out
+}
+
+// Stage names the API a request goes to: gemini, cse, slides, sheets, drive, or vision,
+// and the host for anything else.
+func Stage(u *url.URL) string {
+	host, path := u.Hostname(), u.Path
+	switch {
+	case host == "generativelanguage.googleapis.com" || strings.HasSuffix(host, "aiplatform.googleapis.com"):
+		return "gemini"
+	case host == "customsearch.googleapis.com" || strings.HasPrefix(path, "/customsearch/"):
+		return "cse"
+	case host == "slides.googleapis.com":
+		return "slides"
+	case host == "sheets.googleapis.com":
+		return "sheets"
+	case host == "vision.googleapis.com":
+		return "vision"
+	case strings.HasPrefix(path, "/drive/") || strings.HasPrefix(path, "/upload/drive/"):
+		return "drive"
+	}
+	return host
+}
+
+// body is the dumpable form of a request or response body: text is redacted and kept,
+// anything else (image bytes, multipart uploads) is reduced to its size and type.
+func (d *Dumper) body(b []byte, contentType string) string {
+	if len(b) == 0 {
+		return ""
+	}
+	mt, _, _ := mime.ParseMediaType(contentType)
+	if !isText(mt) {
+		return fmt.Sprintf("<%d bytes of %s omitted>", len(b), firstNonEmpty(mt, "unknown content"))
+	}
+	return d.redact(string(b))
+}
+
+func isText(mediaType string) bool {
+	return mediaType == "" || strings.HasPrefix(mediaType, "text/") || strings.HasSuffix(mediaType, "json") || mediaType == "application/x-www-form-urlencoded"
+}
+
+func (d *Dumper) redact(s string) string {
+	for _, secret := range d.secrets {
+		s = strings.ReplaceAll(s, secret, Redacted)
+	}
+	return s
+}
+
+// write stores ex as <seq>-<stage>.json; the sequence number keeps the files in request order.
+func (d *Dumper) write(ex Exchange) {
+	b, err := json.MarshalIndent(ex, "", "  ")
+	if err != nil {
+		return
+	}
+	d.mu.Lock()
+	d.seq++
+	name := fmt.Sprintf("%04d-%s.json", d.seq, fileSafe(ex.Stage))
+	d.mu.Unlock()
+	_ = os.WriteFile(filepath.Join(d.dir, name), b, 0o644)
+}
+
+func redactURL(u *url.URL) string {
+	c := *u
+	q := c.Query()
+	for _, p := range redactedParams {
+		if q.Has(p) {
+			q.Set(p, Redacted)
+		}
+	}
+	c.RawQuery = q.Encode()
+	c.User = nil
+	return c.String()
+}
+
+func fileSafe(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '.' {
+			return r
+		}
+		return '_'
+	}, strings.ToLower(s))
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,151 @@
+package debugdump
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDumper_Client(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.URL.Path == "/image" {
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte("\x89PNG fake"))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.Write([]byte(`{"echo":` + string(body) + `}`))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	d, err := New(dir, "s3cret-key", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := d.Client(srv.Client())
+
+	resp, err := client.Post(srv.URL+"/v1/things?key=s3cret-key&q=cats", "application/json", strings.NewReader(`{"prompt":"uses s3cret-key"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(got) != `{"echo":{"prompt":"uses s3cret-key"}}` {
+		t.Errorf("caller got body %q; the dump must not change it", got)
+	}
+	resp, err = client.Get(srv.URL + "/image")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 2 {
+		t.Fatalf("got %d dump files, want 2: %v", len(files), files)
+	}
+	var exchanges []Exchange
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(b), "s3cret-key") {
+			t.Errorf("%s leaks the secret:\n%s", f, b)
+		}
+		var ex Exchange
+		if err := json.Unmarshal(b, &ex); err != nil {
+			t.Fatal(err)
+		}
+		exchanges = append(exchanges, ex)
+	}
+
+	post := exchanges[0]
+	if post.Method != http.MethodPost || post.Status != http.StatusOK || !strings.Contains(post.URL, "key=REDACTED") || !strings.Contains(post.URL, "q=cats") {
+		t.Errorf("first exchange = %+v", post)
+	}
+	if post.RequestBody != `{"prompt":"uses REDACTED"}` {
+		t.Errorf("request body = %q", post.RequestBody)
+	}
+	if img := exchanges[1]; img.ResponseBody != "<9 bytes of image/png omitted>" {
+		t.Errorf("image response body = %q", img.ResponseBody)
+	}
+}
+
+func TestDumper_Nil(t *testing.T) {
+	var d *Dumper
+	base := &http.Client{}
+	if d.Client(base) != base {
+		t.Error("a nil Dumper should return the client unchanged")
+	}
+}
+
+func TestDumper_Counts(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	cwd, _ := os.Getwd()
+	d, err := New("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := d.Client(srv.Client())
+	for _, path := range []string{"/a", "/b", "/missing"} {
+		resp, err := client.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if path != "/missing" && string(got) != "ok" {
+			t.Errorf("%s: caller got body %q", path, got)
+		}
+	}
+	u, _ := url.Parse(srv.URL)
+	got := d.Counts()[Stage(u)]
+	if got.Requests != 3 || got.Errors != 1 {
+		t.Errorf("counts = %+v, want 3 requests and 1 error", got)
+	}
+	if files, _ := filepath.Glob(filepath.Join(cwd, "*.json")); len(files) != 0 {
+		t.Errorf("a dumper without a directory wrote %v", files)
+	}
+	var nilDumper *Dumper
+	if nilDumper.Counts() != nil {
+		t.Error("a nil Dumper should count nothing")
+	}
+}
+
+func TestStage(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://generativelanguage.googleapis.com/v1beta/models/gemini-2.5-flash:generateContent", "gemini"},
+		{"https://customsearch.googleapis.com/customsearch/v1?q=x", "cse"},
+		{"https://slides.googleapis.com/v1/presentations/abc:batchUpdate", "slides"},
+		{"https://sheets.googleapis.com/v4/spreadsheets/abc", "sheets"},
+		{"https://www.googleapis.com/upload/drive/v3/files?uploadType=multipart", "drive"},
+		{"https://www.googleapis.com/drive/v3/files/abc/permissions", "drive"},
+		{"https://vision.googleapis.com/v1/images:annotate", "vision"},
+		{"https://example.com/cat.jpg", "example.com"},
+	}
+	for _, tt := range tests {
+		u, _ := url.Parse(tt.url)
+		if got := Stage(u); got != tt.want {
+			t.Errorf("Stage(%s) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+// Package decklock keeps two runs from rebuilding the same deck at once: each run holds an
+// advisory lock file named after the presentation ID while it wipes and rewrites the deck,
+// and a second run waits for it or gives up. Locks are only seen by runs sharing the lock
+// directory, such as one machine's scheduler, webhook server, and manual runs, or several
+// machines sharing a network directory.
+package decklock
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrLocked means another run holds the deck's lock.
+var ErrLocked = errors.New("deck is locked by another run")
+
+// TTL is how long a lock outlives its last heartbeat: a run that crashed without releasing
+// its lock stops blocking others after this long. Held locks are refreshed every TTL/4.
+const TTL = 2 * time.Minute
+
+// pollInterval is how often a waiting run retries a held lock.
+const pollInterval = 2 * time.Second
+
+// Options controls Acquire.
+type Options struct {
+	Dir   string        // lock directory; empty uses DefaultDir
+	Wait  time.Duration // how long to wait for a held lock; 0 fails at once
+	RunID string        // recorded in the lock, for the error another run reports
+	ttl   time.Duration // TTL unless set, in tests
+}
+
+// Info is what a lock file records about its holder.
+type Info struct {
+	Owner    string    `json:"owner"` // host:pid
+	RunID    string    `json:"run_id,omitempty"`
+	Acquired time.Time `json:"acquired"`
+}
+
+// Lock is a held lock; Release it when the deck is written.
+type Lock struct {
+	path string
+	data []byte
+	stop chan struct{}
+	once sync.Once
+}
+
+// DefaultDir is a per-user directory next to the generated image cache.
+func DefaultDir() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "gogemini-slides", "locks"), nil
+}
+
+// unsafeChars are replaced in the lock file name; presentation IDs don't have any.
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// Acquire takes the lock on presentationID. A held lock that hasn't been refreshed within
+// TTL is taken over; otherwise Acquire retries until opts.Wait has passed and then returns
+// an error wrapping ErrLocked that names the holder.
+func Acquire(ctx context.Context, presentationID string, opts Options) (*Lock, error) {
+	dir := opts.Dir
+	if dir == "" {
+		d, err := DefaultDir()
+		if err != nil {
+			return nil, fmt.Errorf("lock dir: %w", err)
+		}
+		dir = d
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("lock dir: %w", err)
+	}
+	ttl := opts.ttl
+	if ttl <= 0 {
+		ttl = TTL
+	}
+	host, _ := os.Hostname()
+	data, err := json.Marshal(Info{Owner: fmt.Sprintf("%s:%d", host, os.Getpid()), RunID: opts.RunID, Acquired: time.Now().UTC()})
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, unsafeChars.ReplaceAllString(presentationID, "_")+".lock")
+	deadline := time.Now().Add(opts.Wait)
+	for {
+		err := create(path, data)
+		if err == nil {
+			l := &Lock{path: path, data: data, stop: make(chan struct{})}
+			go l.heartbeat(max(ttl/4, time.Millisecond))
+			return l, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("lock %s: %w", presentationID, err)
+		}
+		removed, err := removeStale(path, ttl)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", presentationID, err)
+		}
+		if removed {
+			continue
+		}
+		if !time.Now().Before(deadline) {
+			return nil, fmt.Errorf("%s: %w", presentationID, holder(path))
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(min(pollInterval, time.Until(deadline))):
+		}
+	}
+}
+
+// create writes a new lock file, failing with os.ErrExist if there is one.
+func create(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+	return f.Close()
+}
+
+// takeovers numbers this process's stale-lock renames, so each gets its own file name.
+var takeovers atomic.Int64
+
+// beforeTakeover runs between finding a stale lock and renaming it aside, and beforeLinkBack
+// between renaming a fresh lock aside and putting it back; tests race other runs there.
+var beforeTakeover, beforeLinkBack = func() {}, func() {}
+
+// removeStale deletes the lock at path if its holder stopped refreshing it, reporting
+// whether it did. Between reading the stale lock and removing it, another waiter may have
+// taken it over and created a fresh one, so the lock is first renamed aside, atomically, and
+// only deleted if it is still the stale one. A fresh lock caught by the rename is put back;
+// if yet another run has created a lock at path meanwhile, the fresh one is left aside and
+// an error wrapping ErrLocked is returned, since neither holder's file may be removed.
+func removeStale(path string, ttl time.Duration) (bool, error) {
+	stale, err := os.ReadFile(path)
+	if err != nil {
+		return errors.Is(err, os.ErrNotExist), nil // released meanwhile: try again
+	}
+	if fi, err := os.Stat(path); err != nil || time.Since(fi.ModTime()) < ttl {
+		return err != nil && errors.Is(err, os.ErrNotExist), nil
+	}
+	beforeTakeover()
+	aside := fmt.Sprintf("%s.%d-%d.stale", path, os.Getpid(), takeovers.Add(1))
+	if err := os.Rename(path, aside); err != nil {
+		return errors.Is(err, os.ErrNotExist), nil
+	}
+	got, err := os.ReadFile(aside)
+	fi, serr := os.Stat(aside)
+	if err == nil && serr == nil && bytes.Equal(got, stale) && time.Since(fi.ModTime()) >= ttl {
+		os.Remove(aside)
+		return true, nil
+	}
+	// Someone else's fresh lock: Link, unlike Rename, won't replace a lock created since
+	beforeLinkBack()
+	if err := os.Link(aside, path); err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return false, fmt.Errorf("%w: contended while taking over a stale lock; the displaced lock is left at %s", ErrLocked, aside)
+		}
+		return false, fmt.Errorf("restore lock: %w", err)
+	}
+	os.Remove(aside) // path links the same file now
+	return false, nil
+}
+
+// holder describes the lock at path for the error a blocked run reports.
+func holder(path string) error {
+	var info Info
+	if b, err := os.ReadFile(path); err != nil || json.Unmarshal(b, &info) != nil || info.Owner == "" {
+		return ErrLocked
+	}
+	if info.RunID != "" {
+		return fmt.Errorf("%w (%s, run %s, since %s)", ErrLocked, info.Owner, info.RunID, info.Acquired.Format(time.RFC3339))
+	}
+	return fmt.Errorf("%w (%s, since %s)", ErrLocked, info.Owner, info.Acquired.Format(time.RFC3339))
+}
+
+// heartbeat refreshes the lock file's time until Release, so it isn't taken for stale.
+func (l *Lock) heartbeat(every time.Duration) {
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-t.C:
+			now := time.Now()
+			_ = os.Chtimes(l.path, now, now)
+		}
+	}
+}
+
+// Release stops the heartbeat and removes the lock file, unless another run has since
+// taken it over. Releasing twice is a no-op.
+func (l *Lock) Release() error {
+	var err error
+	l.once.Do(func() {
+		close(l.stop)
+		b, rerr := os.ReadFile(l.path)
+		if rerr != nil || string(b) != string(l.data) {
+			return
+		}
+		if rerr := os.Remove(l.path); rerr != nil && !errors.Is(rerr, os.ErrNotExist) {
+			err = fmt.Errorf("release lock: %w", rerr)
+		}
+	})
+	return err
+}
@@ -0,0 +1,197 @@
+package decklock
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAcquire(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	first, err := Acquire(ctx, "deck-1", Options{Dir: dir, RunID: "run-a"})
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	tests := []struct {
+		name    string
+		id      string
+		opts    Options
+		wantErr string // substring; empty wants the lock
+	}{
+		{name: "held", id: "deck-1", opts: Options{Dir: dir}, wantErr: "run run-a"},
+		{name: "held, waited out", id: "deck-1", opts: Options{Dir: dir, Wait: 50 * time.Millisecond}, wantErr: "locked"},
+		{name: "other deck", id: "deck-2", opts: Options{Dir: dir}},
+		{name: "stale", id: "deck-1", opts: Options{Dir: dir, ttl: time.Nanosecond}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := Acquire(ctx, tt.id, tt.opts)
+			if tt.wantErr != "" {
+				if !errors.Is(err, ErrLocked) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Acquire() error = %v, want ErrLocked mentioning %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Acquire() error = %v", err)
+			}
+			if err := l.Release(); err != nil {
+				t.Errorf("Release() error = %v", err)
+			}
+		})
+	}
+
+	// The stale case took the lock over and released it; the first holder's release
+	// must not remove a lock it no longer owns
+	second, err := Acquire(ctx, "deck-1", Options{Dir: dir})
+	if err != nil {
+		t.Fatalf("Acquire() after takeover error = %v", err)
+	}
+	if err := first.Release(); err != nil {
+		t.Errorf("Release() of a taken-over lock error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "deck-1.lock")); err != nil {
+		t.Errorf("taken-over lock was removed: %v", err)
+	}
+	if err := second.Release(); err != nil {
+		t.Errorf("Release() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "deck-1.lock")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("released lock still exists: %v", err)
+	}
+
+	// A waiting run gets the lock once it's released
+	held, err := Acquire(ctx, "deck-3", Options{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.AfterFunc(20*time.Millisecond, func() { held.Release() })
+	l, err := Acquire(ctx, "deck-3", Options{Dir: dir, Wait: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("Acquire() with wait error = %v", err)
+	}
+	l.Release()
+}
+
+// TestAcquire_StaleTakeover checks that of several runs finding the same stale lock, only
+// one takes it over: the others must not delete the fresh lock the winner created.
+func TestAcquire_StaleTakeover(t *testing.T) {
+	ctx := context.Background()
+	for round := 0; round < 20; round++ {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "deck.lock")
+		if err := os.WriteFile(path, []byte(`{"owner":"crashed:1"}`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		old := time.Now().Add(-time.Hour)
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatal(err)
+		}
+		const runs = 8
+		locks := make(chan *Lock, runs)
+		errs := make(chan error, runs)
+		start := make(chan struct{})
+		for range runs {
+			go func() {
+				<-start
+				l, err := Acquire(ctx, "deck", Options{Dir: dir, ttl: time.Minute})
+				if err != nil {
+					errs <- err
+					return
+				}
+				locks <- l
+			}()
+		}
+		close(start)
+		var held []*Lock
+		for range runs {
+			select {
+			case l := <-locks:
+				held = append(held, l)
+			case err := <-errs:
+				if !errors.Is(err, ErrLocked) {
+					t.Fatalf("Acquire() error = %v, want ErrLocked", err)
+				}
+			}
+		}
+		if len(held) != 1 {
+			t.Fatalf("round %d: %d runs hold the lock, want 1", round, len(held))
+		}
+		held[0].Release()
+		if files, _ := filepath.Glob(filepath.Join(dir, "*.stale")); len(files) != 0 {
+			t.Errorf("stale lock left aside: %v", files)
+		}
+	}
+}
+
+func TestRemoveStale_KeepsFreshLock(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "deck.lock")
+	if err := os.WriteFile(path, []byte(`{"owner":"crashed:1"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+	fresh := `{"owner":"winner:2"}`
+	beforeTakeover = func() {
+		// Another waiter removes the stale lock and takes its own
+		os.Remove(path)
+		os.WriteFile(path, []byte(fresh), 0o644)
+	}
+	defer func() { beforeTakeover = func() {} }()
+
+	if removed, err := removeStale(path, time.Minute); removed || err != nil {
+		t.Errorf("removeStale() = %v, %v; want false for a lock taken over meanwhile", removed, err)
+	}
+	if b, err := os.ReadFile(path); err != nil || string(b) != fresh {
+		t.Errorf("lock = %q, %v; want the fresh lock kept", b, err)
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "*.stale")); len(files) != 0 {
+		t.Errorf("lock left aside: %v", files)
+	}
+}
+
+func TestRemoveStale_LinkBackContended(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "deck.lock")
+	if err := os.WriteFile(path, []byte(`{"owner":"crashed:1"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+	fresh, third := `{"owner":"winner:2"}`, `{"owner":"third:3"}`
+	beforeTakeover = func() {
+		os.Remove(path)
+		os.WriteFile(path, []byte(fresh), 0o644)
+	}
+	beforeLinkBack = func() {
+		// A third run finds no lock while the fresh one is aside and takes its own
+		os.WriteFile(path, []byte(third), 0o644)
+	}
+	defer func() { beforeTakeover, beforeLinkBack = func() {}, func() {} }()
+
+	removed, err := removeStale(path, time.Minute)
+	if removed || !errors.Is(err, ErrLocked) {
+		t.Fatalf("removeStale() = %v, %v; want a contention error wrapping ErrLocked", removed, err)
+	}
+	if b, err := os.ReadFile(path); err != nil || string(b) != third {
+		t.Errorf("lock = %q, %v; want the third run's lock untouched", b, err)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*.stale"))
+	if len(files) != 1 {
+		t.Fatalf("aside files = %v, want the displaced fresh lock kept", files)
+	}
+	if b, _ := os.ReadFile(files[0]); string(b) != fresh {
+		t.Errorf("aside lock = %q, want the fresh lock %q", b, fresh)
+	}
+}
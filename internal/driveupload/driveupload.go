@@ -0,0 +1,65 @@
+package driveupload
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
+)
+
+// UploadPublicImage stores image bytes in Drive, grants "anyone with the link" read access,
+// and returns a URL that Slides CreateImage can fetch. Returns: url, error.
+func UploadPublicImage(ctx context.Context, driveSvc *drive.Service, name, mimeType string, data []byte) (string, error) {
+	if driveSvc == nil {
+		return "", fmt.Errorf("driveSvc is nil")
+	}
+	if len(data) == 0 {
+		return "", fmt.Errorf("no image data to upload")
+	}
+	if strings.TrimSpace(name) == "" {
+		name = "image"
+	}
+	if mimeType == "" {
+		mimeType = "image/png"
+	}
+
+	f, err := driveSvc.Files.Create(&drive.File{Name: name, MimeType: mimeType}).
+		Media(bytes.NewReader(data)).
+		Fields("id").
+		Context(ctx).
+		Do()
+	if err != nil {
+		return "", fmt.Errorf("drive upload %q: %w", name, err)
+	}
+	if _, err := driveSvc.Permissions.Create(f.Id, &drive.Permission{Type: "anyone", Role: "reader"}).Context(ctx).Do(); err != nil {
+		return "", fmt.Errorf("drive share %q: %w", name, err)
+	}
+	return fmt.Sprintf("https://drive.google.com/uc?export=download&id=%s", f.Id), nil
+}
+
+// CreateDoc imports an HTML document into Drive as a Google Doc named name, private to
+// the caller, and returns its file ID and link. Returns: id, url, error.
+func CreateDoc(ctx context.Context, driveSvc *drive.Service, name, htmlDoc string) (string, string, error) {
+	if driveSvc == nil {
+		return "", "", fmt.Errorf("driveSvc is nil")
+	}
+	if strings.TrimSpace(name) == "" {
+		name = "document"
+	}
+	f, err := driveSvc.Files.Create(&drive.File{Name: name, MimeType: "application/vnd.google-apps.document"}).
+		Media(strings.NewReader(htmlDoc), googleapi.ContentType("text/html")).
+		Fields("id", "webViewLink").
+		Context(ctx).
+		Do()
+	if err != nil {
+		return "", "", fmt.Errorf("drive create doc %q: %w", name, err)
+	}
+	url := f.WebViewLink
+	if url == "" {
+		url = fmt.Sprintf("https://docs.google.com/document/d/%s/edit", f.Id)
+	}
+	return f.Id, url, nil
+}
@@ -0,0 +1,190 @@
+// Package fakeapi holds in-memory stand-ins for the Slides and Sheets APIs, so the deck
+// and chart writers can be tested by asserting the requests they send.
+package fakeapi
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"google.golang.org/api/sheets/v4"
+	"google.golang.org/api/slides/v1"
+)
+
+// Slides implements presentation.SlidesAPI. Each batch is recorded, and slide creates and
+// deletes and linked chart embeds are applied to Presentation so later Gets see them; other
+// requests only land in Batches. A created slide's speaker notes shape is "<slide ID>_notes".
+type Slides struct {
+	Presentation *slides.Presentation
+	Batches      [][]*slides.Request
+	Err          error // returned by every call when set
+	// UnloadedCharts embeds linked charts without a rendered image, as Slides does for a
+	// chart whose spreadsheet the deck's owner can't open.
+	UnloadedCharts bool
+	// ThumbnailBase prefixes the thumbnail URLs GetThumbnail returns: <base>/<page ID>.png.
+	ThumbnailBase string
+	// ThumbnailErr, when set, fails GetThumbnail for the page IDs it maps.
+	ThumbnailErr map[string]error
+}
+
+func (f *Slides) Get(ctx context.Context, presentationID string) (*slides.Presentation, error) {
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	if f.Presentation == nil {
+		f.Presentation = &slides.Presentation{PresentationId: presentationID}
+	}
+	return f.Presentation, nil
+}
+
+func (f *Slides) BatchUpdate(ctx context.Context, presentationID string, requests []*slides.Request) error {
+	if f.Err != nil {
+		return f.Err
+	}
+	f.Batches = append(f.Batches, requests)
+	pres, _ := f.Get(ctx, presentationID)
+	for _, r := range requests {
+		switch {
+		case r.CreateSlide != nil:
+			id := r.CreateSlide.ObjectId
+			sld := &slides.Page{ObjectId: id, SlideProperties: &slides.SlideProperties{NotesPage: &slides.Page{
+				ObjectId:        id + "_notes_page",
+				NotesProperties: &slides.NotesProperties{SpeakerNotesObjectId: id + "_notes"},
+			}}}
+			at := len(pres.Slides)
+			if slices.Contains(r.CreateSlide.ForceSendFields, "InsertionIndex") || r.CreateSlide.InsertionIndex > 0 {
+				at = min(int(r.CreateSlide.InsertionIndex), at)
+			}
+			pres.Slides = slices.Insert(pres.Slides, at, sld)
+		case r.CreateSheetsChart != nil:
+			c := r.CreateSheetsChart
+			chart := &slides.SheetsChart{SpreadsheetId: c.SpreadsheetId, ChartId: c.ChartId}
+			if !f.UnloadedCharts {
+				chart.ContentUrl = fmt.Sprintf("https://charts.example/%s/%d", c.SpreadsheetId, c.ChartId)
+			}
+			for _, p := range pres.Slides {
+				if p.ObjectId == c.ElementProperties.PageObjectId {
+					p.PageElements = append(p.PageElements, &slides.PageElement{ObjectId: c.ObjectId, SheetsChart: chart})
+				}
+			}
+		case r.DeleteObject != nil:
+			pres.Slides = slices.DeleteFunc(pres.Slides, func(p *slides.Page) bool { return p.ObjectId == r.DeleteObject.ObjectId })
+		}
+	}
+	return nil
+}
+
+// GetThumbnail implements presentation.ThumbnailAPI with an 800x450 thumbnail under
+// ThumbnailBase for any page of the deck.
+func (f *Slides) GetThumbnail(ctx context.Context, presentationID, pageID, size string) (*slides.Thumbnail, error) {
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	if err := f.ThumbnailErr[pageID]; err != nil {
+		return nil, err
+	}
+	pres, _ := f.Get(ctx, presentationID)
+	if !slices.ContainsFunc(pres.Slides, func(p *slides.Page) bool { return p.ObjectId == pageID }) {
+		return nil, fmt.Errorf("page %s not found", pageID)
+	}
+	return &slides.Thumbnail{ContentUrl: f.ThumbnailBase + "/" + pageID + ".png", Width: 800, Height: 450}, nil
+}
+
+// Requests flattens every recorded batch.
+func (f *Slides) Requests() []*slides.Request {
+	var all []*slides.Request
+	for _, b := range f.Batches {
+		all = append(all, b...)
+	}
+	return all
+}
+
+// Sheets implements charts.SheetsAPI. AddSheet and DeleteSheet are applied to Spreadsheet;
+// AddChart replies with chart IDs counting up from 1. Everything sent is recorded.
+type Sheets struct {
+	Spreadsheet *sheets.Spreadsheet
+	Batches     []*sheets.BatchUpdateSpreadsheetRequest
+	Cleared     []string
+	Values      []*sheets.ValueRange
+	Ranges      map[string][][]interface{} // GetValues results by A1 range
+	Err         error                      // returned by every call when set
+
+	nextChartID int64
+}
+
+func (f *Sheets) GetSpreadsheet(ctx context.Context, spreadsheetID, fields string) (*sheets.Spreadsheet, error) {
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	if f.Spreadsheet == nil {
+		f.Spreadsheet = &sheets.Spreadsheet{
+			SpreadsheetId: spreadsheetID,
+			Sheets:        []*sheets.Sheet{{Properties: &sheets.SheetProperties{SheetId: 0, Title: "Sheet1", SheetType: "GRID"}}},
+		}
+	}
+	return f.Spreadsheet, nil
+}
+
+func (f *Sheets) BatchUpdate(ctx context.Context, spreadsheetID string, req *sheets.BatchUpdateSpreadsheetRequest) (*sheets.BatchUpdateSpreadsheetResponse, error) {
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	f.Batches = append(f.Batches, req)
+	ss, _ := f.GetSpreadsheet(ctx, spreadsheetID, "")
+	resp := &sheets.BatchUpdateSpreadsheetResponse{SpreadsheetId: spreadsheetID}
+	for _, r := range req.Requests {
+		reply := &sheets.Response{}
+		switch {
+		case r.AddSheet != nil:
+			props := *r.AddSheet.Properties
+			if props.SheetId == 0 {
+				for _, sh := range ss.Sheets {
+					props.SheetId = max(props.SheetId, sh.Properties.SheetId+1)
+				}
+			}
+			ss.Sheets = append(ss.Sheets, &sheets.Sheet{Properties: &props})
+			reply.AddSheet = &sheets.AddSheetResponse{Properties: &props}
+		case r.DeleteSheet != nil:
+			ss.Sheets = slices.DeleteFunc(ss.Sheets, func(sh *sheets.Sheet) bool { return sh.Properties.SheetId == r.DeleteSheet.SheetId })
+		case r.AddChart != nil:
+			f.nextChartID++
+			chart := *r.AddChart.Chart
+			chart.ChartId = f.nextChartID
+			reply.AddChart = &sheets.AddChartResponse{Chart: &chart}
+		}
+		resp.Replies = append(resp.Replies, reply)
+	}
+	return resp, nil
+}
+
+func (f *Sheets) ClearValues(ctx context.Context, spreadsheetID string, ranges []string) error {
+	if f.Err != nil {
+		return f.Err
+	}
+	f.Cleared = append(f.Cleared, ranges...)
+	return nil
+}
+
+func (f *Sheets) UpdateValues(ctx context.Context, spreadsheetID string, data []*sheets.ValueRange) error {
+	if f.Err != nil {
+		return f.Err
+	}
+	for _, vr := range data {
+		if vr == nil || vr.Range == "" {
+			return fmt.Errorf("value range without a range")
+		}
+	}
+	f.Values = append(f.Values, data...)
+	return nil
+}
+
+func (f *Sheets) GetValues(ctx context.Context, spreadsheetID, a1 string) ([][]interface{}, error) {
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	rows, ok := f.Ranges[a1]
+	if !ok {
+		return nil, fmt.Errorf("unable to parse range: %s", a1)
+	}
+	return rows, nil
+}
@@ -0,0 +1,186 @@
+// Package fallbackimg chooses the image a topic gets when its image search finds nothing
+// usable. Instead of one stock URL on every such slide, a pool of images is spread over
+// the topics: each starts at a slot picked from its title and takes the first one no
+// other topic has taken yet.
+package fallbackimg
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// DefaultKey is the key of a pool file's entries for tones it lists no entries for.
+const DefaultKey = "default"
+
+// imageExts are the local files a folder pool takes, the formats Slides can insert.
+var imageExts = map[string]bool{".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".webp": true}
+
+// Entries lists the fallback images configured at path for tone. Path is one of:
+//   - a folder of images, whose subfolder named after the tone, when it holds any images,
+//     is used instead;
+//   - a JSON file mapping tones to lists of image URLs or local image paths, relative to
+//     the file, with DefaultKey's list used for other tones.
+//
+// The tone matches a subfolder or key case-insensitively, either as a whole or by any of
+// its words, so "formal, concise" matches "formal". Local images are returned as paths
+// (see IsLocal) for the caller to host.
+func Entries(path, tone string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("fallback images: %w", err)
+	}
+	if info.IsDir() {
+		return folderEntries(path, tone)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("fallback images: %w", err)
+	}
+	var byTone map[string][]string
+	if err := json.Unmarshal(b, &byTone); err != nil {
+		return nil, fmt.Errorf("fallback images: parse %s: %w", path, err)
+	}
+	keys := make([]string, 0, len(byTone))
+	for k := range byTone {
+		keys = append(keys, k)
+	}
+	key, ok := matchTone(keys, tone)
+	if !ok {
+		key = DefaultKey
+	}
+	var entries []string
+	for _, e := range byTone[key] {
+		if e = strings.TrimSpace(e); e == "" {
+			continue
+		}
+		if IsLocal(e) && !filepath.IsAbs(e) {
+			e = filepath.Join(filepath.Dir(path), e)
+		}
+		entries = append(entries, e)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("fallback images: %s lists no images for tone %q or %q", path, tone, DefaultKey)
+	}
+	return entries, nil
+}
+
+// IsLocal reports whether an entry is a local file rather than an image URL.
+func IsLocal(entry string) bool {
+	lower := strings.ToLower(entry)
+	return !strings.HasPrefix(lower, "https://") && !strings.HasPrefix(lower, "http://")
+}
+
+// folderEntries lists the images in dir, or in its subfolder for tone when that has any.
+func folderEntries(dir, tone string) ([]string, error) {
+	ents, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("fallback images: %w", err)
+	}
+	var subdirs []string
+	for _, e := range ents {
+		if e.IsDir() {
+			subdirs = append(subdirs, e.Name())
+		}
+	}
+	if sub, ok := matchTone(subdirs, tone); ok {
+		if images, err := folderImages(filepath.Join(dir, sub)); err == nil && len(images) > 0 {
+			return images, nil
+		}
+	}
+	images, err := folderImages(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(images) == 0 {
+		return nil, fmt.Errorf("fallback images: no .png, .jpg, .gif, or .webp files in %s", dir)
+	}
+	return images, nil
+}
+
+// folderImages returns the paths of the image files directly in dir, sorted.
+func folderImages(dir string) ([]string, error) {
+	ents, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("fallback images: %w", err)
+	}
+	var images []string
+	for _, e := range ents {
+		if !e.IsDir() && imageExts[strings.ToLower(filepath.Ext(e.Name()))] {
+			images = append(images, filepath.Join(dir, e.Name()))
+		}
+	}
+	sort.Strings(images)
+	return images, nil
+}
+
+// matchTone returns the key naming tone, compared case-insensitively: the whole tone
+// first, then each of its words in order.
+func matchTone(keys []string, tone string) (string, bool) {
+	byName := map[string]string{}
+	for _, k := range keys {
+		byName[strings.ToLower(strings.TrimSpace(k))] = k
+	}
+	tone = strings.ToLower(strings.TrimSpace(tone))
+	if tone == "" {
+		return "", false
+	}
+	if k, ok := byName[tone]; ok {
+		return k, true
+	}
+	for _, w := range strings.FieldsFunc(tone, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		if k, ok := byName[w]; ok {
+			return k, true
+		}
+	}
+	return "", false
+}
+
+// Pool hands out fallback image URLs, one per topic. It is safe for concurrent use.
+type Pool struct {
+	urls []string
+
+	mu    sync.Mutex
+	taken []bool
+	left  int
+}
+
+// NewPool returns a pool of urls; an empty pool picks "".
+func NewPool(urls []string) *Pool {
+	return &Pool{urls: urls, taken: make([]bool, len(urls)), left: len(urls)}
+}
+
+// Pick returns the URL for topic: from the slot its title hashes to, the first one not
+// taken yet. Once every URL is taken they are all free again, so a deck repeats an image
+// only when it has more fallbacks than the pool has images. A given title starts at the
+// same slot on every run.
+func (p *Pool) Pick(topic string) string {
+	if p == nil || len(p.urls) == 0 {
+		return ""
+	}
+	h := fnv.New32a()
+	h.Write([]byte(strings.ToLower(strings.TrimSpace(topic))))
+	start := int(h.Sum32() % uint32(len(p.urls)))
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.left == 0 {
+		clear(p.taken)
+		p.left = len(p.urls)
+	}
+	for i := range p.urls {
+		slot := (start + i) % len(p.urls)
+		if !p.taken[slot] {
+			p.taken[slot] = true
+			p.left--
+			return p.urls[slot]
+		}
+	}
+	return "" // unreachable: left > 0
+}
@@ -0,0 +1,96 @@
+package fallbackimg
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestEntries(t *testing.T) {
+	dir := t.TempDir()
+	for _, f := range []string{"a.png", "b.JPG", "notes.txt", "formal/f.png", "empty/readme.md"} {
+		p := filepath.Join(dir, f)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	file := filepath.Join(dir, "pool.json")
+	if err := os.WriteFile(file, []byte(`{
+		"default": ["https://img/1.jpg", "https://img/2.jpg"],
+		"Playful": ["https://img/fun.jpg", "a.png", ""]
+	}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		path    string
+		tone    string
+		want    []string
+		wantErr bool
+	}{
+		{name: "folder", path: dir, want: []string{filepath.Join(dir, "a.png"), filepath.Join(dir, "b.JPG")}},
+		{name: "folder tone subfolder", path: dir, tone: "Formal, concise", want: []string{filepath.Join(dir, "formal", "f.png")}},
+		{name: "folder tone subfolder without images", path: dir, tone: "empty", want: []string{filepath.Join(dir, "a.png"), filepath.Join(dir, "b.JPG")}},
+		{name: "file default", path: file, tone: "serious", want: []string{"https://img/1.jpg", "https://img/2.jpg"}},
+		{name: "file tone", path: file, tone: "playful", want: []string{"https://img/fun.jpg", filepath.Join(dir, "a.png")}},
+		{name: "folder without images", path: filepath.Join(dir, "empty"), wantErr: true},
+		{name: "missing", path: filepath.Join(dir, "nope"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Entries(tt.path, tt.tone)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Entries() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Entries() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPool_Pick(t *testing.T) {
+	urls := []string{"u1", "u2", "u3"}
+	topics := []string{"Intro", "Costs", "Risks", "Outlook", "Summary", "Q&A"}
+
+	p := NewPool(urls)
+	counts := map[string]int{}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, topic := range topics[:3] {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			u := p.Pick(topic)
+			mu.Lock()
+			counts[u]++
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	for _, u := range urls {
+		if counts[u] != 1 {
+			t.Errorf("first three picks = %v, want each URL once", counts)
+		}
+	}
+	for _, topic := range topics[3:] {
+		counts[p.Pick(topic)]++
+	}
+	for _, u := range urls {
+		if counts[u] != 2 {
+			t.Errorf("six picks = %v, want each URL twice", counts)
+		}
+	}
+
+	if a, b := NewPool(urls).Pick("Costs"), NewPool(urls).Pick("Costs"); a != b {
+		t.Errorf("Pick(Costs) = %q then %q, want the same slot on every run", a, b)
+	}
+	if got := NewPool(nil).Pick("Intro"); got != "" {
+		t.Errorf("empty pool Pick() = %q, want \"\"", got)
+	}
+}
@@ -0,0 +1,190 @@
+package formatting
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ToPlainText renders parsed segments as readable plain text for speaker notes and email
+// bodies: bullets become "• " (sub-items "◦ ", indented), numbered items "1. ", quotes
+// "> ", and links "text (url)". Unlike CleanText it keeps list structure visible.
+func (tp *TextProcessor) ToPlainText(segments []TextSegment) string {
+	var lines []string
+	numbers := map[int]int{} // next number per numbered-list level
+	for _, line := range splitLines(segments) {
+		ps := line.paragraph()
+		// Numbering continues across deeper items and restarts after anything shallower
+		for level := range numbers {
+			if ps.List == ListNone || level > ps.Level || (level == ps.Level && ps.List != ListNumbered) {
+				delete(numbers, level)
+			}
+		}
+		prefix := ""
+		switch {
+		case ps.List == ListBullet:
+			marker := "• "
+			if ps.Level >= 1 {
+				marker = "◦ "
+			}
+			prefix = strings.Repeat("  ", ps.Level) + marker
+		case ps.List == ListNumbered:
+			numbers[ps.Level]++
+			prefix = strings.Repeat("  ", ps.Level) + strconv.Itoa(numbers[ps.Level]) + ". "
+		case ps.Quote:
+			prefix = "> "
+		}
+
+		var b strings.Builder
+		b.WriteString(prefix)
+		for _, seg := range line {
+			b.WriteString(seg.Text)
+			if seg.Link != "" && seg.Link != seg.Text {
+				fmt.Fprintf(&b, " (%s)", seg.Link)
+			}
+		}
+		lines = append(lines, b.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// WordCount counts the words a presenter would say in plain text: whitespace-separated
+// fields with at least one letter or digit, so bullets, arrows, and dashes don't count.
+func WordCount(text string) int {
+	n := 0
+	for _, f := range strings.Fields(text) {
+		if strings.IndexFunc(f, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) >= 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// ToHTML renders parsed segments as an HTML fragment for previews and email: plain lines
+// become <p>, bullet and numbered items nested <ul>/<ol> lists, and quote lines one
+// <blockquote>. Character styles map to <strong>, <em>, <code>, <sup>, <sub>, <a>, and
+// an inline color/font-size style. All text is escaped.
+func (tp *TextProcessor) ToHTML(segments []TextSegment) string {
+	var b strings.Builder
+	var lists []string // open list tags, outermost first; each has an open <li>
+	inQuote := false
+	closeLists := func(depth int) {
+		for len(lists) > depth {
+			fmt.Fprintf(&b, "</li></%s>", lists[len(lists)-1])
+			lists = lists[:len(lists)-1]
+		}
+	}
+	for _, line := range splitLines(segments) {
+		ps := line.paragraph()
+		if ps.List == ListNone {
+			closeLists(0)
+			if ps.Quote && !inQuote {
+				b.WriteString("<blockquote>")
+			} else if !ps.Quote && inQuote {
+				b.WriteString("</blockquote>")
+			}
+			inQuote = ps.Quote
+			if len(line) > 0 {
+				fmt.Fprintf(&b, "<p>%s</p>", inlineHTML(line))
+			}
+			continue
+		}
+
+		if inQuote {
+			b.WriteString("</blockquote>")
+			inQuote = false
+		}
+		tag := "ul"
+		if ps.List == ListNumbered {
+			tag = "ol"
+		}
+		depth := ps.Level + 1
+		closeLists(depth)
+		if len(lists) == depth {
+			if lists[depth-1] != tag {
+				closeLists(depth - 1)
+			} else {
+				b.WriteString("</li>")
+			}
+		}
+		for len(lists) < depth {
+			fmt.Fprintf(&b, "<%s>", tag)
+			lists = append(lists, tag)
+		}
+		fmt.Fprintf(&b, "<li>%s", inlineHTML(line))
+	}
+	closeLists(0)
+	if inQuote {
+		b.WriteString("</blockquote>")
+	}
+	return b.String()
+}
+
+// inlineHTML renders one line's segments with their character styles.
+func inlineHTML(line textLine) string {
+	var b strings.Builder
+	for _, seg := range line {
+		text := html.EscapeString(seg.Text)
+		if seg.Baseline == "SUPERSCRIPT" {
+			text = "<sup>" + text + "</sup>"
+		} else if seg.Baseline == "SUBSCRIPT" {
+			text = "<sub>" + text + "</sub>"
+		}
+		if seg.Code {
+			text = "<code>" + text + "</code>"
+		}
+		if seg.Italic && !seg.Quote { // quotes are italic through <blockquote> styling
+			text = "<em>" + text + "</em>"
+		}
+		if seg.Bold {
+			text = "<strong>" + text + "</strong>"
+		}
+		var css []string
+		if seg.Color != "" {
+			css = append(css, "color:"+html.EscapeString(seg.Color))
+		}
+		if seg.FontSize > 0 {
+			css = append(css, "font-size:"+strconv.FormatFloat(seg.FontSize, 'g', -1, 64)+"pt")
+		}
+		if len(css) > 0 {
+			text = `<span style="` + strings.Join(css, ";") + `">` + text + "</span>"
+		}
+		if seg.Link != "" {
+			text = `<a href="` + html.EscapeString(seg.Link) + `">` + text + "</a>"
+		}
+		b.WriteString(text)
+	}
+	return b.String()
+}
+
+// textLine is the segments of one line, without its newline.
+type textLine []TextSegment
+
+// paragraph is the line's paragraph style, taken from its first segment.
+func (l textLine) paragraph() Style {
+	if len(l) == 0 {
+		return Style{}
+	}
+	return Style{List: l[0].List, Level: l[0].Level, Quote: l[0].Quote}
+}
+
+// splitLines groups segments into lines, splitting on newlines inside segment text too.
+func splitLines(segments []TextSegment) []textLine {
+	lines := []textLine{nil}
+	for _, seg := range segments {
+		parts := strings.Split(seg.Text, "\n")
+		for i, part := range parts {
+			if i > 0 {
+				lines = append(lines, nil)
+			}
+			if part != "" {
+				piece := seg
+				piece.Text = part
+				lines[len(lines)-1] = append(lines[len(lines)-1], piece)
+			}
+		}
+	}
+	return lines
+}
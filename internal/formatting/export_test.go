@@ -0,0 +1,84 @@
+package formatting
+
+import "testing"
+
+func TestTextProcessor_ToPlainText(t *testing.T) {
+	processor := NewTextProcessor()
+
+	tests := []struct {
+		name string
+		in   []TextSegment
+		want string
+	}{
+		{
+			name: "markup",
+			in:   processor.ParseMarkup("**AI** in care:\n• Faster `triage`\n  ◦ CO~2~ data\n> Do no harm"),
+			want: "AI in care:\n• Faster triage\n  ◦ CO2 data\n> Do no harm",
+		},
+		{
+			name: "numbered and links",
+			in: []TextSegment{
+				{Text: "First", Style: Style{List: ListNumbered}},
+				{Text: "\n"},
+				{Text: "detail", Style: Style{List: ListBullet, Level: 1}},
+				{Text: "\n"},
+				{Text: "Docs", Style: Style{List: ListNumbered, Link: "https://example.com"}},
+				{Text: "\nEnd\n"},
+				{Text: "Again", Style: Style{List: ListNumbered}},
+			},
+			want: "1. First\n  ◦ detail\n2. Docs (https://example.com)\nEnd\n1. Again",
+		},
+	}
+	for _, tt := range tests {
+		if got := processor.ToPlainText(tt.in); got != tt.want {
+			t.Errorf("%s: ToPlainText() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWordCount(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"", 0},
+		{"AI in care", 3},
+		{"• Faster triage\n  ◦ CO2 data", 4},
+		{"Revenue → 40% — up", 3},
+		{"  -- | |  ", 0},
+	}
+	for _, tt := range tests {
+		if got := WordCount(tt.in); got != tt.want {
+			t.Errorf("WordCount(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTextProcessor_ToHTML(t *testing.T) {
+	processor := NewTextProcessor()
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"paragraphs", "A **<b>** & m^2^\n\nB", "<p>A <strong>&lt;b&gt;</strong> &amp; m<sup>2</sup></p><p>B</p>"},
+		{
+			"nested list",
+			"• one\n  ◦ sub `x`\n• two",
+			"<ul><li>one<ul><li>sub <code>x</code></li></ul></li><li>two</li></ul>",
+		},
+		{"quote", "> Be **bold**\n> twice\nAfter", "<blockquote><p>Be <strong>bold</strong></p><p>twice</p></blockquote><p>After</p>"},
+	}
+	for _, tt := range tests {
+		if got := processor.ToHTML(processor.ParseMarkup(tt.in)); got != tt.want {
+			t.Errorf("%s: ToHTML() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	styled := []TextSegment{{Text: "go", Style: Style{Link: "https://go.dev?a=1&b=2", Color: "#FF0000", FontSize: 14, List: ListNumbered}}}
+	want := `<ol><li><a href="https://go.dev?a=1&amp;b=2"><span style="color:#FF0000;font-size:14pt">go</span></a></li></ol>`
+	if got := processor.ToHTML(styled); got != want {
+		t.Errorf("ToHTML() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,137 @@
+package formatting
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Diagnostic kinds reported by Validate.
+const (
+	DiagUnbalancedBold  = "unbalanced-bold"
+	DiagMalformedBullet = "malformed-bullet"
+	DiagLineTooLong     = "line-too-long"
+)
+
+// MaxLineLength is the longest line, in characters of markup, that Validate accepts;
+// longer lines wrap across most of a summary box.
+const MaxLineLength = 140
+
+// Diagnostic is one markup problem found by Validate.
+type Diagnostic struct {
+	Line    int    // 1-based line number
+	Kind    string // DiagUnbalancedBold | DiagMalformedBullet | DiagLineTooLong
+	Message string
+	Fixable bool // Fix repairs it without changing the wording
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("line %d: %s: %s", d.Line, d.Kind, d.Message)
+}
+
+// malformedBullet matches bullet-like lines that ParseMarkup would render as plain text:
+// Markdown list markers, bullets glued to their text, and mis-indented bullets.
+var malformedBullet = regexp.MustCompile(`^(\s*)([-*+•◦])(\s*)(.*)$`)
+
+// Validate lints model-written markup so callers can repair (see Fix) or re-prompt
+// instead of rendering stray asterisks or unstyled bullets. Lines inside fenced code
+// blocks are skipped and backtick spans are ignored. Returns: diagnostics in line order.
+func (tp *TextProcessor) Validate(text string) []Diagnostic {
+	var diags []Diagnostic
+	inBlock := false
+	for i, line := range strings.Split(text, "\n") {
+		n := i + 1
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inBlock = !inBlock
+			continue
+		}
+		if inBlock {
+			continue
+		}
+		if c := strings.Count(tp.codePattern.ReplaceAllString(line, ""), "**"); c%2 != 0 {
+			diags = append(diags, Diagnostic{Line: n, Kind: DiagUnbalancedBold, Message: "odd number of ** markers", Fixable: true})
+		}
+		if _, ok := tp.fixBullet(line); ok {
+			diags = append(diags, Diagnostic{Line: n, Kind: DiagMalformedBullet, Message: fmt.Sprintf("%q is not a \"• \" or \"  ◦ \" bullet", truncate(line, 20)), Fixable: true})
+		}
+		if l := utf8.RuneCountInString(line); l > MaxLineLength {
+			diags = append(diags, Diagnostic{Line: n, Kind: DiagLineTooLong, Message: fmt.Sprintf("%d characters, max %d", l, MaxLineLength)})
+		}
+	}
+	return diags
+}
+
+// Fix repairs the fixable diagnostics: bullets are rewritten to the "• " / "  ◦ " markers
+// (indented Markdown items and "◦" become sub-bullets) and the last unmatched ** on a line is
+// dropped. Over-long lines are left for the caller.
+func (tp *TextProcessor) Fix(text string) string {
+	lines := strings.Split(text, "\n")
+	inBlock := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inBlock = !inBlock
+			continue
+		}
+		if inBlock {
+			continue
+		}
+		if fixed, ok := tp.fixBullet(line); ok {
+			line = fixed
+		}
+		if strings.Count(tp.codePattern.ReplaceAllString(line, ""), "**")%2 != 0 {
+			line = tp.dropLastBold(line)
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// fixBullet returns the well-formed version of a malformed bullet line, or false when
+// the line is not a bullet or already well-formed.
+func (tp *TextProcessor) fixBullet(line string) (string, bool) {
+	if tp.bulletPattern.MatchString(line) || tp.subBulletPattern.MatchString(line) {
+		return "", false
+	}
+	m := malformedBullet.FindStringSubmatch(line)
+	if m == nil {
+		return "", false
+	}
+	indent, marker, gap, content := m[1], m[2], m[3], m[4]
+	if content == "" || strings.HasPrefix(line[len(indent):], "**") {
+		return "", false // a lone dash, or bold text rather than a list marker
+	}
+	if (marker == "-" || marker == "*" || marker == "+") && gap == "" {
+		return "", false // "-5%" or "+3 pts" are values, not list items
+	}
+	if marker == "◦" || (indent != "" && marker != "•") {
+		return "  ◦ " + content, true
+	}
+	return "• " + content, true
+}
+
+// dropLastBold removes the last ** outside backtick spans.
+func (tp *TextProcessor) dropLastBold(line string) string {
+	spans := tp.codePattern.FindAllStringIndex(line, -1)
+	for i := strings.LastIndex(line, "**"); i >= 0; i = strings.LastIndex(line[:i], "**") {
+		inCode := false
+		for _, s := range spans {
+			if i >= s[0] && i < s[1] {
+				inCode = true
+				break
+			}
+		}
+		if !inCode {
+			return line[:i] + line[i+2:]
+		}
+	}
+	return line
+}
+
+// truncate shortens s to at most n runes for messages.
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n]) + "…"
+}
@@ -0,0 +1,47 @@
+package formatting
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTextProcessor_Validate(t *testing.T) {
+	processor := NewTextProcessor()
+
+	tests := []struct {
+		name  string
+		in    string
+		kinds []string
+		fixed string
+	}{
+		{"clean", "**Key** point\n• one\n  ◦ two", nil, "**Key** point\n• one\n  ◦ two"},
+		{"unbalanced bold", "A **key point", []string{DiagUnbalancedBold}, "A key point"},
+		{"bold in code ignored", "Use `**kwargs` **now**", nil, "Use `**kwargs` **now**"},
+		{"markdown bullets", "- one\n  * two\n+ three", []string{DiagMalformedBullet, DiagMalformedBullet, DiagMalformedBullet}, "• one\n  ◦ two\n• three"},
+		{"glued and misindented", "•one\n◦ two\n • three", []string{DiagMalformedBullet, DiagMalformedBullet, DiagMalformedBullet}, "• one\n  ◦ two\n• three"},
+		{"signed values are not bullets", "-5% churn\n+3 pts", nil, "-5% churn\n+3 pts"},
+		{"fenced code skipped", "```\n- x **\n```", nil, "```\n- x **\n```"},
+		{"too long", strings.Repeat("a", MaxLineLength+1), []string{DiagLineTooLong}, strings.Repeat("a", MaxLineLength+1)},
+	}
+	for _, tt := range tests {
+		var kinds []string
+		for _, d := range processor.Validate(tt.in) {
+			kinds = append(kinds, d.Kind)
+		}
+		if !reflect.DeepEqual(kinds, tt.kinds) {
+			t.Errorf("%s: Validate() kinds = %v, want %v", tt.name, kinds, tt.kinds)
+		}
+		if got := processor.Fix(tt.in); got != tt.fixed {
+			t.Errorf("%s: Fix() = %q, want %q", tt.name, got, tt.fixed)
+		}
+	}
+
+	diags := processor.Validate("ok\n- two")
+	if len(diags) != 1 || diags[0].Line != 2 || !diags[0].Fixable {
+		t.Fatalf("Validate() = %+v, want one fixable diagnostic on line 2", diags)
+	}
+	if s := diags[0].String(); !strings.HasPrefix(s, "line 2: malformed-bullet:") {
+		t.Errorf("String() = %q", s)
+	}
+}
@@ -10,48 +10,95 @@ import (
 
 // TextSegment represents a piece of text with formatting information
 type TextSegment struct {
-	Text     string
-	IsBold   bool
-	IsBullet bool
-	Level    int // 0=main bullet, 1=sub-bullet
+	Text string
+	Style
 }
 
+// CodeFont is the monospace font applied to inline code and fenced code blocks.
+const CodeFont = "Roboto Mono"
+
+// QuoteIndent is the left indent, in points, of blockquote paragraphs.
+const QuoteIndent = 24.0
+
+// quoteGray colors blockquotes when no accent color is set.
+var quoteGray = &slides.OptionalColor{OpaqueColor: &slides.OpaqueColor{RgbColor: &slides.RgbColor{Red: 0.4, Green: 0.4, Blue: 0.4}}}
+
 // TextProcessor handles conversion from custom markup to Google Slides formatting
 type TextProcessor struct {
 	boldPattern      *regexp.Regexp
+	codePattern      *regexp.Regexp
+	supPattern       *regexp.Regexp
+	subPattern       *regexp.Regexp
 	bulletPattern    *regexp.Regexp
 	subBulletPattern *regexp.Regexp
+	quotePattern     *regexp.Regexp
+	boldColor        *slides.OptionalColor
+	paragraphs       ParagraphStyles
 }
 
-// NewTextProcessor creates a new text processor with compiled regex patterns
+// The markup patterns are compiled once and shared; a Regexp is safe for concurrent use.
+var (
+	boldPattern      = regexp.MustCompile(`\*\*(.*?)\*\*`)
+	codePattern      = regexp.MustCompile("`([^`]+)`")
+	supPattern       = regexp.MustCompile(`\^([^\^\s]+)\^`)
+	subPattern       = regexp.MustCompile(`~([^~\s]+)~`)
+	bulletPattern    = regexp.MustCompile(`^• (.*)$`)
+	subBulletPattern = regexp.MustCompile(`^  ◦ (.*)$`)
+	quotePattern     = regexp.MustCompile(`^> ?(.*)$`)
+)
+
+// NewTextProcessor creates a new text processor using the shared compiled patterns
 func NewTextProcessor() *TextProcessor {
 	return &TextProcessor{
-		boldPattern:      regexp.MustCompile(`\*\*(.*?)\*\*`),
-		bulletPattern:    regexp.MustCompile(`^• (.*)$`),
-		subBulletPattern: regexp.MustCompile(`^  ◦ (.*)$`),
+		boldPattern:      boldPattern,
+		codePattern:      codePattern,
+		supPattern:       supPattern,
+		subPattern:       subPattern,
+		bulletPattern:    bulletPattern,
+		subBulletPattern: subBulletPattern,
+		quotePattern:     quotePattern,
+		paragraphs:       DefaultParagraphStyles(),
 	}
 }
 
-// ParseMarkup converts custom markup text into structured segments
+// SetParagraphStyles replaces the paragraph spacing and alignment (DefaultParagraphStyles
+// until set) used by ToSlidesRequests and TitleRequests.
+func (tp *TextProcessor) SetParagraphStyles(ps ParagraphStyles) {
+	tp.paragraphs = ps
+}
+
+// SetBoldColor makes bold (key information) and blockquote text render in the given accent
+// color. Channel values range from 0.0 to 1.0.
+func (tp *TextProcessor) SetBoldColor(r, g, b float64) {
+	tp.boldColor = &slides.OptionalColor{OpaqueColor: &slides.OpaqueColor{RgbColor: &slides.RgbColor{Red: r, Green: g, Blue: b}}}
+}
+
+// ParseMarkup converts custom markup text into structured segments. Invalid UTF-8 is
+// replaced first: split between segments, stray bytes could pair up into a different
+// character in the inserted text and shift every range after it.
 func (tp *TextProcessor) ParseMarkup(text string) []TextSegment {
 	var segments []TextSegment
-	lines := strings.Split(text, "\n")
+	lines := strings.Split(strings.ToValidUTF8(text, "\uFFFD"), "\n")
 
-	for _, line := range lines {
+	for i, line := range lines {
 		// Check if line is a bullet point
 		if tp.bulletPattern.MatchString(line) {
 			content := tp.bulletPattern.ReplaceAllString(line, "$1")
-			segments = append(segments, tp.parseBoldInText(content, true, 0)...)
+			segments = append(segments, tp.parseInline(content, Style{List: ListBullet})...)
 		} else if tp.subBulletPattern.MatchString(line) {
 			content := tp.subBulletPattern.ReplaceAllString(line, "$1")
-			segments = append(segments, tp.parseBoldInText(content, true, 1)...)
+			segments = append(segments, tp.parseInline(content, Style{List: ListBullet, Level: 1})...)
+		} else if tp.quotePattern.MatchString(line) {
+			content := tp.quotePattern.ReplaceAllString(line, "$1")
+			segments = append(segments, tp.parseInline(content, Style{Quote: true, Italic: true})...)
 		} else {
-			// Regular text, check for bold markup
-			segments = append(segments, tp.parseBoldInText(line, false, 0)...)
+			// Regular text, check for bold and code markup
+			segments = append(segments, tp.parseInline(line, Style{})...)
 		}
 
-		// Add newline segment except for last line
-		if line != lines[len(lines)-1] {
+		// Add newline segment except after the last line; compared by index, since a line
+		// may repeat the last one's text
+		if i < len(lines)-1 {
 			segments = append(segments, TextSegment{Text: "\n"})
 		}
 	}
@@ -59,12 +106,72 @@ func (tp *TextProcessor) ParseMarkup(text string) []TextSegment {
 	return segments
 }
 
+// parseInline splits out `code` spans first, so markup inside code stays literal, then
+// extracts bold markup from the text between them. Every segment starts from the line's style.
+func (tp *TextProcessor) parseInline(text string, line Style) []TextSegment {
+	var segments []TextSegment
+	lastEnd := 0
+	for _, match := range tp.codePattern.FindAllStringSubmatchIndex(text, -1) {
+		if match[0] > lastEnd {
+			segments = append(segments, tp.parseBoldInText(text[lastEnd:match[0]], line)...)
+		}
+		code := line
+		code.Code = true
+		segments = append(segments, TextSegment{Text: text[match[2]:match[3]], Style: code})
+		lastEnd = match[1]
+	}
+	if lastEnd < len(text) {
+		segments = append(segments, tp.parseBoldInText(text[lastEnd:], line)...)
+	}
+	return segments
+}
+
+// parseScripts splits ^superscript^ and ~subscript~ spans (no spaces inside, so "~50%" or
+// "x ^ y" stay literal) out of a segment, keeping its other formatting
+func (tp *TextProcessor) parseScripts(seg TextSegment) []TextSegment {
+	out := []TextSegment{seg}
+	for _, sp := range []struct {
+		re       *regexp.Regexp
+		baseline string
+	}{{tp.supPattern, "SUPERSCRIPT"}, {tp.subPattern, "SUBSCRIPT"}} {
+		var next []TextSegment
+		for _, s := range out {
+			if s.Baseline != "" {
+				next = append(next, s)
+				continue
+			}
+			lastEnd := 0
+			for _, m := range sp.re.FindAllStringSubmatchIndex(s.Text, -1) {
+				if m[0] > lastEnd {
+					plain := s
+					plain.Text = s.Text[lastEnd:m[0]]
+					next = append(next, plain)
+				}
+				script := s
+				script.Text = s.Text[m[2]:m[3]]
+				script.Baseline = sp.baseline
+				next = append(next, script)
+				lastEnd = m[1]
+			}
+			if lastEnd < len(s.Text) {
+				rest := s
+				rest.Text = s.Text[lastEnd:]
+				next = append(next, rest)
+			}
+		}
+		out = next
+	}
+	return out
+}
+
 // parseBoldInText extracts bold markup from text and creates segments
-func (tp *TextProcessor) parseBoldInText(text string, isBullet bool, level int) []TextSegment {
+func (tp *TextProcessor) parseBoldInText(text string, line Style) []TextSegment {
 	var segments []TextSegment
 	lastEnd := 0
 
 	matches := tp.boldPattern.FindAllStringSubmatchIndex(text, -1)
+	bold := line
+	bold.Bold = true
 
 	for _, match := range matches {
 		start, end := match[0], match[1]
@@ -72,83 +179,84 @@ func (tp *TextProcessor) parseBoldInText(text string, isBullet bool, level int)
 
 		// Add text before bold
 		if start > lastEnd {
-			segments = append(segments, TextSegment{
-				Text:     text[lastEnd:start],
-				IsBullet: isBullet,
-				Level:    level,
-			})
+			segments = append(segments, TextSegment{Text: text[lastEnd:start], Style: line})
 		}
 
 		// Add bold text
-		segments = append(segments, TextSegment{
-			Text:     text[boldStart:boldEnd],
-			IsBold:   true,
-			IsBullet: isBullet,
-			Level:    level,
-		})
+		segments = append(segments, TextSegment{Text: text[boldStart:boldEnd], Style: bold})
 
 		lastEnd = end
 	}
 
 	// Add remaining text
 	if lastEnd < len(text) {
-		segments = append(segments, TextSegment{
-			Text:     text[lastEnd:],
-			IsBullet: isBullet,
-			Level:    level,
-		})
+		segments = append(segments, TextSegment{Text: text[lastEnd:], Style: line})
 	}
 
-	return segments
+	// Superscript/subscript may appear inside or outside bold text
+	var scripted []TextSegment
+	for _, seg := range segments {
+		scripted = append(scripted, tp.parseScripts(seg)...)
+	}
+	return scripted
 }
 
 // ToSlidesRequests converts text segments to Google Slides API requests
 func (tp *TextProcessor) ToSlidesRequests(segments []TextSegment, objectID string) []*slides.Request {
+	return tp.toRequests(segments, objectID, tp.paragraphs.Body.over(tp.paragraphs.Global))
+}
+
+// TitleRequests is ToSlidesRequests for a title box, styled with the Title paragraph style.
+func (tp *TextProcessor) TitleRequests(segments []TextSegment, objectID string) []*slides.Request {
+	return tp.toRequests(segments, objectID, tp.paragraphs.Title.over(tp.paragraphs.Global))
+}
+
+// toRequests inserts the segments' text and styles it; paragraphs get base, with the
+// Bullet and Quote styles layered over it on their ranges. Styling is driven by textAttrs
+// and paragraphAttrs, so it needs no changes when a markup type is added.
+func (tp *TextProcessor) toRequests(segments []TextSegment, objectID string, base ParagraphStyle) []*slides.Request {
 	var requests []*slides.Request
 
-	// First, build the plain text and collect formatting info
-	plainText := ""
-	var boldRanges []struct{ start, end int }
-	var bulletRanges []struct{ start, end, level int }
+	// First, build the plain text and collect one list of spans per style facet. Adjacent
+	// segments with the same key extend one span, including across a single newline (but not
+	// a blank line), so each style run costs one request rather than one per segment.
+	var plainText strings.Builder
+	textSpans := make([][]span, len(textAttrs))
+	paragraphSpans := make([][]span, len(paragraphAttrs))
 
 	currentPos := 0 // UTF-16 code units
-	bulletStart := -1
-	currentBulletLevel := -1
+	newlines := 0   // newline segments since the last styled segment
 
 	for _, segment := range segments {
+		if segment.Text == "" {
+			continue // would style an empty range, which Slides rejects
+		}
 		segmentStart := currentPos
-		segmentLen := utf16Len(segment.Text)
+		segmentLen := UTF16Len(segment.Text)
 		segmentEnd := segmentStart + segmentLen
 
-		plainText += segment.Text
-
-		// Track bold ranges
-		if segment.IsBold {
-			boldRanges = append(boldRanges, struct{ start, end int }{segmentStart, segmentEnd})
+		plainText.WriteString(segment.Text)
+		currentPos = segmentEnd
+		if segment.Text == "\n" {
+			newlines++
+			continue
 		}
+		gap := 0
+		if newlines == 1 {
+			gap = 1
+		}
+		newlines = 0
 
-		// Track bullet ranges
-		if segment.IsBullet {
-			if bulletStart == -1 {
-				bulletStart = segmentStart
-				currentBulletLevel = segment.Level
+		for i, attr := range textAttrs {
+			if key := attr.key(tp, segment.Style); key != "" {
+				textSpans[i] = addSpan(textSpans[i], segmentStart, segmentEnd, key, gap)
+			}
+		}
+		for i, attr := range paragraphAttrs {
+			if key := attr.key(segment.Style); key != "" {
+				paragraphSpans[i] = addSpan(paragraphSpans[i], segmentStart, segmentEnd, key, gap)
 			}
-		} else if bulletStart != -1 {
-			// End of bullet section
-			bulletRanges = append(bulletRanges, struct{ start, end, level int }{
-				bulletStart, segmentStart, currentBulletLevel,
-			})
-			bulletStart = -1
 		}
-
-		currentPos = segmentEnd
-	}
-
-	// Handle final bullet range
-	if bulletStart != -1 {
-		bulletRanges = append(bulletRanges, struct{ start, end, level int }{
-			bulletStart, currentPos, currentBulletLevel,
-		})
 	}
 
 	// Insert the plain text
@@ -156,73 +264,259 @@ func (tp *TextProcessor) ToSlidesRequests(segments []TextSegment, objectID strin
 		InsertText: &slides.InsertTextRequest{
 			ObjectId:       objectID,
 			InsertionIndex: 0,
-			Text:           plainText,
+			Text:           plainText.String(),
 		},
 	})
+	if req := base.request(objectID, &slides.Range{Type: "ALL"}); req != nil {
+		requests = append(requests, req)
+	}
 
-	// Apply bold formatting
-	for _, boldRange := range boldRanges {
-		startIdx := int64(boldRange.start)
-		endIdx := int64(boldRange.end)
-		requests = append(requests, &slides.Request{
-			UpdateTextStyle: &slides.UpdateTextStyleRequest{
-				ObjectId: objectID,
-				Style: &slides.TextStyle{
-					Bold: true,
-				},
-				Fields: "bold",
-				TextRange: &slides.Range{
-					Type:       "FIXED_RANGE",
-					StartIndex: &startIdx,
-					EndIndex:   &endIdx,
-				},
-			},
-		})
-	}
-
-	// Apply bullet formatting
-	for _, bulletRange := range bulletRanges {
-		bulletPreset := "BULLET_DISC_CIRCLE_SQUARE"
-		if bulletRange.level == 1 {
-			bulletPreset = "BULLET_ARROW_DIAMOND_DISC"
-		}
-
-		startIdx := int64(bulletRange.start)
-		endIdx := int64(bulletRange.end)
-		requests = append(requests, &slides.Request{
-			CreateParagraphBullets: &slides.CreateParagraphBulletsRequest{
-				ObjectId: objectID,
-				TextRange: &slides.Range{
-					Type:       "FIXED_RANGE",
-					StartIndex: &startIdx,
-					EndIndex:   &endIdx,
-				},
-				BulletPreset: bulletPreset,
+	// Character styles: facets covering the same range share one request
+	byRange := map[[2]int]*slides.UpdateTextStyleRequest{}
+	for i, attr := range textAttrs {
+		for _, sp := range textSpans[i] {
+			req, ok := byRange[[2]int{sp.start, sp.end}]
+			if !ok {
+				req = &slides.UpdateTextStyleRequest{ObjectId: objectID, Style: &slides.TextStyle{}, TextRange: fixedRange(sp)}
+				byRange[[2]int{sp.start, sp.end}] = req
+				requests = append(requests, &slides.Request{UpdateTextStyle: req})
+			} else {
+				req.Fields += ","
+			}
+			attr.set(tp, req.Style, sp.key)
+			req.Fields += attr.field
+		}
+	}
+
+	// Paragraph styles: lists, quotes
+	for i, attr := range paragraphAttrs {
+		for _, sp := range paragraphSpans[i] {
+			requests = append(requests, attr.requests(tp, objectID, fixedRange(sp), sp.key, base)...)
+		}
+	}
+
+	return requests
+}
+
+// span is a styled [start, end) range of text in UTF-16 code units; key distinguishes
+// styles of one kind, e.g. the baseline offset or bullet preset.
+type span struct {
+	start, end int
+	key        string
+}
+
+// addSpan appends [start, end) to spans, or extends the last span when it has the same key
+// and ends at most gap units before start.
+func addSpan(spans []span, start, end int, key string, gap int) []span {
+	if n := len(spans); n > 0 && spans[n-1].key == key && start-spans[n-1].end <= gap {
+		spans[n-1].end = end
+		return spans
+	}
+	return append(spans, span{start, end, key})
+}
+
+// fixedRange is the Slides text range of a span.
+func fixedRange(sp span) *slides.Range {
+	start, end := int64(sp.start), int64(sp.end)
+	return &slides.Range{Type: "FIXED_RANGE", StartIndex: &start, EndIndex: &end}
+}
+
+// SplitCodeBlocks separates ``` fenced code blocks from the surrounding markup. It returns
+// the text with fences removed and the contents of each block in order; an unterminated
+// fence runs to the end of the text.
+func (tp *TextProcessor) SplitCodeBlocks(text string) (string, []string) {
+	var prose, block []string
+	var blocks []string
+	inBlock := false
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			if inBlock {
+				blocks = append(blocks, strings.Join(block, "\n"))
+				block = nil
+			}
+			inBlock = !inBlock
+			continue
+		}
+		if inBlock {
+			block = append(block, line)
+		} else {
+			prose = append(prose, line)
+		}
+	}
+	if inBlock && len(block) > 0 {
+		blocks = append(blocks, strings.Join(block, "\n"))
+	}
+	return strings.TrimRight(strings.Join(prose, "\n"), "\n"), blocks
+}
+
+// CodeBlockRequests inserts code into a text box as light monospace text, for a shape
+// whose dark background the caller sets.
+func (tp *TextProcessor) CodeBlockRequests(code, objectID string) []*slides.Request {
+	if code == "" {
+		return nil
+	}
+	return []*slides.Request{
+		{InsertText: &slides.InsertTextRequest{ObjectId: objectID, InsertionIndex: 0, Text: code}},
+		{UpdateTextStyle: &slides.UpdateTextStyleRequest{
+			ObjectId: objectID,
+			Style: &slides.TextStyle{
+				FontFamily:      CodeFont,
+				FontSize:        &slides.Dimension{Magnitude: 12, Unit: "PT"},
+				ForegroundColor: &slides.OptionalColor{OpaqueColor: &slides.OpaqueColor{RgbColor: &slides.RgbColor{Red: 0.93, Green: 0.93, Blue: 0.93}}},
 			},
-		})
+			Fields:    "fontFamily,fontSize,foregroundColor",
+			TextRange: &slides.Range{Type: "ALL"},
+		}},
 	}
+}
 
+// Table is a parsed pipe table; the first row is the header.
+type Table [][]string
+
+// Pipe tables larger than this are truncated so they fit under the summary.
+const (
+	MaxTableRows    = 6 // including the header
+	MaxTableColumns = 4
+)
+
+// tableSeparator matches a Markdown header separator cell such as "---" or ":--:".
+var tableSeparator = regexp.MustCompile(`^:?-+:?$`)
+
+// SplitTables separates Markdown pipe tables (two or more consecutive lines starting with
+// "|") from the surrounding markup. Separator rows are dropped, ragged rows are padded, and
+// each table is truncated to MaxTableRows x MaxTableColumns. A lone pipe line stays text.
+func (tp *TextProcessor) SplitTables(text string) (string, []Table) {
+	var prose, run []string
+	var tables []Table
+	flush := func() {
+		if len(run) >= 2 {
+			if t := parseTable(run); len(t) > 0 {
+				tables = append(tables, t)
+			}
+		} else {
+			prose = append(prose, run...)
+		}
+		run = nil
+	}
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "|") {
+			run = append(run, line)
+			continue
+		}
+		flush()
+		prose = append(prose, line)
+	}
+	flush()
+	return strings.TrimRight(strings.Join(prose, "\n"), "\n"), tables
+}
+
+// parseTable splits pipe lines into cells, skipping separator rows.
+func parseTable(lines []string) Table {
+	var t Table
+	width := 0
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		line = strings.TrimSuffix(strings.TrimPrefix(line, "|"), "|")
+		cells := strings.Split(line, "|")
+		separator := true
+		for i := range cells {
+			cells[i] = strings.TrimSpace(cells[i])
+			if !tableSeparator.MatchString(cells[i]) {
+				separator = false
+			}
+		}
+		if separator {
+			continue
+		}
+		if len(t) == MaxTableRows {
+			break
+		}
+		if len(cells) > MaxTableColumns {
+			cells = cells[:MaxTableColumns]
+		}
+		if len(cells) > width {
+			width = len(cells)
+		}
+		t = append(t, cells)
+	}
+	for i, row := range t {
+		for len(row) < width {
+			row = append(row, "")
+		}
+		t[i] = row
+	}
+	return t
+}
+
+// TableRequests creates a Slides table at props and fills it with the table's cells as
+// plain 12pt text (markup removed) under a bold, shaded header row.
+func (tp *TextProcessor) TableRequests(t Table, objectID string, props *slides.PageElementProperties) []*slides.Request {
+	if len(t) == 0 || len(t[0]) == 0 {
+		return nil
+	}
+	rows, cols := int64(len(t)), int64(len(t[0]))
+	requests := []*slides.Request{
+		{CreateTable: &slides.CreateTableRequest{ObjectId: objectID, ElementProperties: props, Rows: rows, Columns: cols}},
+		{UpdateTableCellProperties: &slides.UpdateTableCellPropertiesRequest{
+			ObjectId:   objectID,
+			TableRange: &slides.TableRange{Location: &slides.TableCellLocation{}, RowSpan: 1, ColumnSpan: cols},
+			TableCellProperties: &slides.TableCellProperties{
+				TableCellBackgroundFill: &slides.TableCellBackgroundFill{SolidFill: &slides.SolidFill{Color: &slides.OpaqueColor{RgbColor: &slides.RgbColor{Red: 0.9, Green: 0.9, Blue: 0.9}}}},
+			},
+			Fields: "tableCellBackgroundFill.solidFill.color",
+		}},
+	}
+	for r, row := range t {
+		for c, cell := range row {
+			text := tp.CleanText(cell)
+			if text == "" {
+				continue // styling an empty cell's text range is rejected
+			}
+			loc := &slides.TableCellLocation{RowIndex: int64(r), ColumnIndex: int64(c)}
+			style := &slides.TextStyle{FontSize: &slides.Dimension{Magnitude: 12, Unit: "PT"}}
+			fields := "fontSize"
+			if r == 0 {
+				style.Bold = true
+				fields = "bold,fontSize"
+			}
+			requests = append(requests,
+				&slides.Request{InsertText: &slides.InsertTextRequest{ObjectId: objectID, CellLocation: loc, InsertionIndex: 0, Text: text}},
+				&slides.Request{UpdateTextStyle: &slides.UpdateTextStyleRequest{ObjectId: objectID, CellLocation: loc, Style: style, Fields: fields, TextRange: &slides.Range{Type: "ALL"}}},
+			)
+		}
+	}
 	return requests
 }
 
 // CleanText removes all markup and returns plain text
 func (tp *TextProcessor) CleanText(text string) string {
-	// Remove bold markup
+	// Remove bold, code, and script markup
 	cleaned := tp.boldPattern.ReplaceAllString(text, "$1")
+	cleaned = tp.codePattern.ReplaceAllString(cleaned, "$1")
+	cleaned = tp.supPattern.ReplaceAllString(cleaned, "$1")
+	cleaned = tp.subPattern.ReplaceAllString(cleaned, "$1")
 
-	// Remove bullet markers
+	// Remove bullet and quote markers
 	lines := strings.Split(cleaned, "\n")
 	for i, line := range lines {
 		if tp.bulletPattern.MatchString(line) {
 			lines[i] = tp.bulletPattern.ReplaceAllString(line, "$1")
 		} else if tp.subBulletPattern.MatchString(line) {
 			lines[i] = tp.subBulletPattern.ReplaceAllString(line, "$1")
+		} else if tp.quotePattern.MatchString(line) {
+			lines[i] = tp.quotePattern.ReplaceAllString(line, "$1")
 		}
 	}
 
 	return strings.Join(lines, "\n")
 }
 
-func utf16Len(s string) int {
-	return len(utf16.Encode([]rune(s)))
+// UTF16Len is the length of s in the UTF-16 code units Slides text indexes count. It
+// counts in place rather than encoding a copy.
+func UTF16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16.RuneLen(r)
+	}
+	return n
 }
@@ -1,8 +1,12 @@
 package formatting
 
 import (
+	"fmt"
 	"reflect"
+	"strings"
 	"testing"
+
+	"google.golang.org/api/slides/v1"
 )
 
 func TestTextProcessor_ParseMarkup(t *testing.T) {
@@ -18,7 +22,7 @@ func TestTextProcessor_ParseMarkup(t *testing.T) {
 			input: "This is **bold** text",
 			expected: []TextSegment{
 				{Text: "This is "},
-				{Text: "bold", IsBold: true},
+				{Text: "bold", Style: Style{Bold: true}},
 				{Text: " text"},
 			},
 		},
@@ -26,9 +30,9 @@ func TestTextProcessor_ParseMarkup(t *testing.T) {
 			name:  "multiple bold sections",
 			input: "**First** and **second** bold",
 			expected: []TextSegment{
-				{Text: "First", IsBold: true},
+				{Text: "First", Style: Style{Bold: true}},
 				{Text: " and "},
-				{Text: "second", IsBold: true},
+				{Text: "second", Style: Style{Bold: true}},
 				{Text: " bold"},
 			},
 		},
@@ -36,38 +40,38 @@ func TestTextProcessor_ParseMarkup(t *testing.T) {
 			name:  "bullet point",
 			input: "• This is a bullet point",
 			expected: []TextSegment{
-				{Text: "This is a bullet point", IsBullet: true, Level: 0},
+				{Text: "This is a bullet point", Style: Style{List: ListBullet}},
 			},
 		},
 		{
 			name:  "sub-bullet point",
 			input: "  ◦ This is a sub-bullet",
 			expected: []TextSegment{
-				{Text: "This is a sub-bullet", IsBullet: true, Level: 1},
+				{Text: "This is a sub-bullet", Style: Style{List: ListBullet, Level: 1}},
 			},
 		},
 		{
 			name:  "bullet with bold",
 			input: "• **Key point** with details",
 			expected: []TextSegment{
-				{Text: "Key point", IsBold: true, IsBullet: true, Level: 0},
-				{Text: " with details", IsBullet: true, Level: 0},
+				{Text: "Key point", Style: Style{Bold: true, List: ListBullet}},
+				{Text: " with details", Style: Style{List: ListBullet}},
 			},
 		},
 		{
 			name:  "complex mixed content",
 			input: "**Machine Learning** overview:\n• **Supervised** learning\n  ◦ Classification tasks\n• **Unsupervised** learning",
 			expected: []TextSegment{
-				{Text: "Machine Learning", IsBold: true},
+				{Text: "Machine Learning", Style: Style{Bold: true}},
 				{Text: " overview:"},
 				{Text: "\n"},
-				{Text: "Supervised", IsBold: true, IsBullet: true, Level: 0},
-				{Text: " learning", IsBullet: true, Level: 0},
+				{Text: "Supervised", Style: Style{Bold: true, List: ListBullet}},
+				{Text: " learning", Style: Style{List: ListBullet}},
 				{Text: "\n"},
-				{Text: "Classification tasks", IsBullet: true, Level: 1},
+				{Text: "Classification tasks", Style: Style{List: ListBullet, Level: 1}},
 				{Text: "\n"},
-				{Text: "Unsupervised", IsBold: true, IsBullet: true, Level: 0},
-				{Text: " learning", IsBullet: true, Level: 0},
+				{Text: "Unsupervised", Style: Style{Bold: true, List: ListBullet}},
+				{Text: " learning", Style: Style{List: ListBullet}},
 			},
 		},
 	}
@@ -119,6 +123,7 @@ func TestTextProcessor_CleanText(t *testing.T) {
 
 func TestTextProcessor_ToSlidesRequests(t *testing.T) {
 	processor := NewTextProcessor()
+	processor.SetParagraphStyles(ParagraphStyles{}) // see TestTextProcessor_ParagraphStyles
 	objectID := "test_object_id"
 
 	tests := []struct {
@@ -134,7 +139,7 @@ func TestTextProcessor_ToSlidesRequests(t *testing.T) {
 			name: "simple bold text",
 			segments: []TextSegment{
 				{Text: "This is "},
-				{Text: "bold", IsBold: true},
+				{Text: "bold", Style: Style{Bold: true}},
 				{Text: " text"},
 			},
 			expected: struct {
@@ -150,8 +155,8 @@ func TestTextProcessor_ToSlidesRequests(t *testing.T) {
 		{
 			name: "bullet with bold",
 			segments: []TextSegment{
-				{Text: "Key point", IsBold: true, IsBullet: true, Level: 0},
-				{Text: " details", IsBullet: true, Level: 0},
+				{Text: "Key point", Style: Style{Bold: true, List: ListBullet}},
+				{Text: " details", Style: Style{List: ListBullet}},
 			},
 			expected: struct {
 				plainText    string
@@ -263,13 +268,13 @@ func BenchmarkParseMarkup(b *testing.B) {
 func BenchmarkToSlidesRequests(b *testing.B) {
 	processor := NewTextProcessor()
 	segments := []TextSegment{
-		{Text: "Machine Learning", IsBold: true},
+		{Text: "Machine Learning", Style: Style{Bold: true}},
 		{Text: " revolutionizes healthcare:\n"},
-		{Text: "Diagnostic accuracy", IsBold: true, IsBullet: true, Level: 0},
-		{Text: " - 95% improvement\n", IsBullet: true, Level: 0},
-		{Text: "Drug discovery", IsBold: true, IsBullet: true, Level: 0},
-		{Text: " - Reduces time by ", IsBullet: true, Level: 0},
-		{Text: "40%", IsBold: true, IsBullet: true, Level: 0},
+		{Text: "Diagnostic accuracy", Style: Style{Bold: true, List: ListBullet}},
+		{Text: " - 95% improvement\n", Style: Style{List: ListBullet}},
+		{Text: "Drug discovery", Style: Style{Bold: true, List: ListBullet}},
+		{Text: " - Reduces time by ", Style: Style{List: ListBullet}},
+		{Text: "40%", Style: Style{Bold: true, List: ListBullet}},
 	}
 
 	b.ResetTimer()
@@ -277,3 +282,443 @@ func BenchmarkToSlidesRequests(b *testing.B) {
 		processor.ToSlidesRequests(segments, "test_id")
 	}
 }
+
+// BenchmarkToSlidesRequests_Long converts a summary of many short styled segments, where
+// building the inserted text dominates.
+func BenchmarkToSlidesRequests_Long(b *testing.B) {
+	processor := NewTextProcessor()
+	var segments []TextSegment
+	for i := 0; i < 200; i++ {
+		segments = append(segments,
+			TextSegment{Text: "Key point", Style: Style{Bold: true, List: ListBullet}},
+			TextSegment{Text: " with some detail 🚀\n", Style: Style{List: ListBullet}},
+		)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		processor.ToSlidesRequests(segments, "test_id")
+	}
+}
+
+// BenchmarkNewTextProcessor measures the per-deck setup cost.
+func BenchmarkNewTextProcessor(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		NewTextProcessor()
+	}
+}
+
+func TestTextProcessor_SetBoldColor(t *testing.T) {
+	processor := NewTextProcessor()
+	processor.SetBoldColor(0.8, 0.1, 0.1)
+	processor.SetParagraphStyles(ParagraphStyles{})
+
+	requests := processor.ToSlidesRequests(processor.ParseMarkup("A **key** fact"), "test_id")
+	if len(requests) != 2 || requests[1].UpdateTextStyle == nil {
+		t.Fatalf("expected InsertText + one UpdateTextStyle, got %d requests", len(requests))
+	}
+	style := requests[1].UpdateTextStyle
+	if style.Fields != "bold,foregroundColor" {
+		t.Errorf("Fields = %q, want %q", style.Fields, "bold,foregroundColor")
+	}
+	if style.Style.ForegroundColor == nil || style.Style.ForegroundColor.OpaqueColor.RgbColor.Red != 0.8 {
+		t.Errorf("ForegroundColor = %+v, want red 0.8", style.Style.ForegroundColor)
+	}
+}
+
+func TestTextProcessor_InlineCode(t *testing.T) {
+	processor := NewTextProcessor()
+
+	got := processor.ParseMarkup("• Run `go test ./...` before **merging**")
+	want := []TextSegment{
+		{Text: "Run ", Style: Style{List: ListBullet}},
+		{Text: "go test ./...", Style: Style{Code: true, List: ListBullet}},
+		{Text: " before ", Style: Style{List: ListBullet}},
+		{Text: "merging", Style: Style{Bold: true, List: ListBullet}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseMarkup() = %+v, want %+v", got, want)
+	}
+
+	// Markup inside code stays literal
+	got = processor.ParseMarkup("Use `**kwargs` here")
+	if len(got) != 3 || !got[1].Code || got[1].Bold || got[1].Text != "**kwargs" {
+		t.Errorf("code span = %+v, want literal **kwargs", got)
+	}
+
+	requests := processor.ToSlidesRequests(processor.ParseMarkup("a `b` c"), "obj")
+	var fontReq bool
+	for _, r := range requests {
+		if r.UpdateTextStyle != nil && r.UpdateTextStyle.Style.FontFamily == CodeFont {
+			fontReq = true
+			if *r.UpdateTextStyle.TextRange.StartIndex != 2 || *r.UpdateTextStyle.TextRange.EndIndex != 3 {
+				t.Errorf("code range = [%d,%d), want [2,3)", *r.UpdateTextStyle.TextRange.StartIndex, *r.UpdateTextStyle.TextRange.EndIndex)
+			}
+		}
+	}
+	if !fontReq {
+		t.Error("expected a monospace UpdateTextStyle request")
+	}
+	if cleaned := processor.CleanText("Run `make`"); cleaned != "Run make" {
+		t.Errorf("CleanText() = %q, want %q", cleaned, "Run make")
+	}
+}
+
+func TestTextProcessor_SplitCodeBlocks(t *testing.T) {
+	processor := NewTextProcessor()
+
+	tests := []struct {
+		name       string
+		input      string
+		wantProse  string
+		wantBlocks []string
+	}{
+		{"no fences", "plain **text**", "plain **text**", nil},
+		{"one block", "Intro:\n```go\nfmt.Println(1)\n```", "Intro:", []string{"fmt.Println(1)"}},
+		{"unterminated", "x\n```\na\nb", "x", []string{"a\nb"}},
+		{"two blocks", "```\na\n```\nmid\n```\nb\n```", "mid", []string{"a", "b"}},
+	}
+	for _, tt := range tests {
+		prose, blocks := processor.SplitCodeBlocks(tt.input)
+		if prose != tt.wantProse || !reflect.DeepEqual(blocks, tt.wantBlocks) {
+			t.Errorf("%s: SplitCodeBlocks() = %q, %q; want %q, %q", tt.name, prose, blocks, tt.wantProse, tt.wantBlocks)
+		}
+	}
+	if reqs := processor.CodeBlockRequests("", "obj"); reqs != nil {
+		t.Errorf("CodeBlockRequests(\"\") = %v, want nil", reqs)
+	}
+}
+
+func TestTextProcessor_Scripts(t *testing.T) {
+	processor := NewTextProcessor()
+
+	tests := []struct {
+		name string
+		in   string
+		want []TextSegment
+	}{
+		{"superscript", "E = mc^2^", []TextSegment{{Text: "E = mc"}, {Text: "2", Style: Style{Baseline: "SUPERSCRIPT"}}}},
+		{"subscript", "CO~2~ levels", []TextSegment{{Text: "CO"}, {Text: "2", Style: Style{Baseline: "SUBSCRIPT"}}, {Text: " levels"}}},
+		{"inside bold", "**H~2~O**", []TextSegment{{Text: "H", Style: Style{Bold: true}}, {Text: "2", Style: Style{Bold: true, Baseline: "SUBSCRIPT"}}, {Text: "O", Style: Style{Bold: true}}}},
+		{"spaced tildes stay literal", "~50% in ~3 years", []TextSegment{{Text: "~50% in ~3 years"}}},
+		{"code stays literal", "`a^b^`", []TextSegment{{Text: "a^b^", Style: Style{Code: true}}}},
+	}
+	for _, tt := range tests {
+		if got := processor.ParseMarkup(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: ParseMarkup(%q) = %+v, want %+v", tt.name, tt.in, got, tt.want)
+		}
+	}
+
+	var offsets []string
+	for _, r := range processor.ToSlidesRequests(processor.ParseMarkup("x^2^ + y~i~"), "obj") {
+		if r.UpdateTextStyle != nil && r.UpdateTextStyle.Fields == "baselineOffset" {
+			offsets = append(offsets, r.UpdateTextStyle.Style.BaselineOffset)
+		}
+	}
+	if !reflect.DeepEqual(offsets, []string{"SUPERSCRIPT", "SUBSCRIPT"}) {
+		t.Errorf("baseline offsets = %v, want [SUPERSCRIPT SUBSCRIPT]", offsets)
+	}
+	if cleaned := processor.CleanText("CO~2~ and m^2^"); cleaned != "CO2 and m2" {
+		t.Errorf("CleanText() = %q, want %q", cleaned, "CO2 and m2")
+	}
+}
+
+func TestTextProcessor_SplitTables(t *testing.T) {
+	processor := NewTextProcessor()
+
+	tests := []struct {
+		name       string
+		in         string
+		wantProse  string
+		wantTables []Table
+	}{
+		{
+			name:       "header separator dropped",
+			in:         "Plans compared:\n| Plan | Price |\n|---|:--:|\n| Free | $0 |\n| Pro | $9 |\nPick one.",
+			wantProse:  "Plans compared:\nPick one.",
+			wantTables: []Table{{{"Plan", "Price"}, {"Free", "$0"}, {"Pro", "$9"}}},
+		},
+		{
+			name:       "ragged rows padded",
+			in:         "| a | b | c |\n| 1 |",
+			wantProse:  "",
+			wantTables: []Table{{{"a", "b", "c"}, {"1", "", ""}}},
+		},
+		{
+			name:      "lone pipe line stays text",
+			in:        "| not a table\nmore",
+			wantProse: "| not a table\nmore",
+		},
+		{
+			name:       "truncated",
+			in:         "|a|b|c|d|e|\n|1|2|3|4|5|\n|1|\n|2|\n|3|\n|4|\n|5|\n|6|",
+			wantProse:  "",
+			wantTables: []Table{{{"a", "b", "c", "d"}, {"1", "2", "3", "4"}, {"1", "", "", ""}, {"2", "", "", ""}, {"3", "", "", ""}, {"4", "", "", ""}}},
+		},
+	}
+	for _, tt := range tests {
+		prose, tables := processor.SplitTables(tt.in)
+		if prose != tt.wantProse || !reflect.DeepEqual(tables, tt.wantTables) {
+			t.Errorf("%s: SplitTables() = %q, %v, want %q, %v", tt.name, prose, tables, tt.wantProse, tt.wantTables)
+		}
+	}
+
+	reqs := processor.TableRequests(Table{{"**Plan**", "Price"}, {"Free", ""}}, "tbl", &slides.PageElementProperties{PageObjectId: "p"})
+	if reqs[0].CreateTable == nil || reqs[0].CreateTable.Rows != 2 || reqs[0].CreateTable.Columns != 2 {
+		t.Fatalf("first request = %+v, want a 2x2 CreateTable", reqs[0])
+	}
+	var inserted []string
+	for _, r := range reqs {
+		if r.InsertText != nil {
+			inserted = append(inserted, r.InsertText.Text)
+		}
+	}
+	if !reflect.DeepEqual(inserted, []string{"Plan", "Price", "Free"}) {
+		t.Errorf("inserted cell text = %q, want markup removed and empty cells skipped", inserted)
+	}
+}
+
+func TestTextProcessor_Blockquote(t *testing.T) {
+	processor := NewTextProcessor()
+
+	got := processor.ParseMarkup("> **Latency** is delay\n>between hops\nAfter")
+	want := []TextSegment{
+		{Text: "Latency", Style: Style{Bold: true, Quote: true, Italic: true}},
+		{Text: " is delay", Style: Style{Quote: true, Italic: true}},
+		{Text: "\n"},
+		{Text: "between hops", Style: Style{Quote: true, Italic: true}},
+		{Text: "\n"},
+		{Text: "After"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseMarkup() = %+v, want %+v", got, want)
+	}
+
+	var italic, indent []string
+	for _, r := range processor.ToSlidesRequests(got, "obj") {
+		if r.UpdateTextStyle != nil && r.UpdateTextStyle.Style.Italic {
+			italic = append(italic, fmt.Sprintf("[%d,%d)", *r.UpdateTextStyle.TextRange.StartIndex, *r.UpdateTextStyle.TextRange.EndIndex))
+			if r.UpdateTextStyle.Style.ForegroundColor != quoteGray {
+				t.Error("quote without accent color should be gray")
+			}
+		}
+		if r.UpdateParagraphStyle != nil && r.UpdateParagraphStyle.Style.IndentStart != nil && r.UpdateParagraphStyle.Style.IndentStart.Magnitude == QuoteIndent {
+			indent = append(indent, r.UpdateParagraphStyle.Fields)
+		}
+	}
+	// Both quoted lines form one range, ending at the second line's newline
+	if !reflect.DeepEqual(italic, []string{"[0,29)"}) || len(indent) != 1 {
+		t.Errorf("italic ranges = %v, indents = %v, want one range [0,29) and one indent", italic, indent)
+	}
+	if cleaned := processor.CleanText("> quoted"); cleaned != "quoted" {
+		t.Errorf("CleanText() = %q, want %q", cleaned, "quoted")
+	}
+}
+
+func TestTextProcessor_ParagraphStyles(t *testing.T) {
+	processor := NewTextProcessor()
+	processor.SetParagraphStyles(ParagraphStyles{
+		Global: ParagraphStyle{LineSpacing: Float(100)},
+		Title:  ParagraphStyle{Alignment: "CENTER"},
+		Body:   ParagraphStyle{SpaceBelow: Float(0)},
+		Bullet: ParagraphStyle{SpaceBelow: Float(4)},
+	})
+
+	paragraphStyles := func(reqs []*slides.Request) []*slides.UpdateParagraphStyleRequest {
+		var out []*slides.UpdateParagraphStyleRequest
+		for _, r := range reqs {
+			if r.UpdateParagraphStyle != nil {
+				out = append(out, r.UpdateParagraphStyle)
+			}
+		}
+		return out
+	}
+
+	title := paragraphStyles(processor.TitleRequests(processor.ParseMarkup("Title"), "t"))
+	if len(title) != 1 || title[0].Fields != "alignment,lineSpacing" || title[0].TextRange.Type != "ALL" {
+		t.Fatalf("title paragraph styles = %+v, want one centered ALL-range style with global line spacing", title)
+	}
+
+	body := paragraphStyles(processor.ToSlidesRequests(processor.ParseMarkup("Intro\n• point"), "b"))
+	if len(body) != 2 {
+		t.Fatalf("got %d body paragraph styles, want box + bullet", len(body))
+	}
+	if body[0].Fields != "lineSpacing,spaceBelow" || body[0].Style.SpaceBelow.Magnitude != 0 || len(body[0].Style.SpaceBelow.ForceSendFields) == 0 {
+		t.Errorf("body style = %q %+v, want explicit zero space below", body[0].Fields, body[0].Style.SpaceBelow)
+	}
+	if *body[1].TextRange.StartIndex != 6 || body[1].Style.SpaceBelow.Magnitude != 4 || body[1].Style.LineSpacing != 100 {
+		t.Errorf("bullet style = %+v from %d, want space below 4 over inherited line spacing from 6", body[1].Style, *body[1].TextRange.StartIndex)
+	}
+
+	processor.SetParagraphStyles(ParagraphStyles{})
+	if got := paragraphStyles(processor.ToSlidesRequests(processor.ParseMarkup("x"), "b")); len(got) != 0 {
+		t.Errorf("empty styles emitted %d paragraph requests, want 0", len(got))
+	}
+}
+
+func TestParseAlignment(t *testing.T) {
+	tests := []struct {
+		in, want string
+		wantErr  bool
+	}{
+		{"", "START", false},
+		{"Center", "CENTER", false},
+		{"right", "END", false},
+		{"justify", "JUSTIFIED", false},
+		{"middle", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseAlignment(tt.in)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseAlignment(%q) = %q, %v, want %q (error %v)", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestTextProcessor_MergesStyleRanges(t *testing.T) {
+	processor := NewTextProcessor()
+	processor.SetParagraphStyles(ParagraphStyles{})
+
+	// Bold split by a subscript, and three same-level bullets around a sub-bullet
+	text := "**H~2~O** matters\n• one\n• two\n  ◦ detail\n• three\n• four"
+	var bold, bullets []string
+	for _, r := range processor.ToSlidesRequests(processor.ParseMarkup(text), "obj") {
+		if r.UpdateTextStyle != nil && r.UpdateTextStyle.Style.Bold {
+			bold = append(bold, fmt.Sprintf("[%d,%d)", *r.UpdateTextStyle.TextRange.StartIndex, *r.UpdateTextStyle.TextRange.EndIndex))
+		}
+		if r.CreateParagraphBullets != nil {
+			bullets = append(bullets, fmt.Sprintf("%s[%d,%d)", r.CreateParagraphBullets.BulletPreset, *r.CreateParagraphBullets.TextRange.StartIndex, *r.CreateParagraphBullets.TextRange.EndIndex))
+		}
+	}
+	if !reflect.DeepEqual(bold, []string{"[0,3)"}) {
+		t.Errorf("bold ranges = %v, want one merged [0,3)", bold)
+	}
+	want := []string{
+		"BULLET_DISC_CIRCLE_SQUARE[12,19)",
+		"BULLET_ARROW_DIAMOND_DISC[20,26)",
+		"BULLET_DISC_CIRCLE_SQUARE[27,37)",
+	}
+	if !reflect.DeepEqual(bullets, want) {
+		t.Errorf("bullet ranges = %v, want %v", bullets, want)
+	}
+}
+
+func TestTextProcessor_StyleFacets(t *testing.T) {
+	processor := NewTextProcessor()
+	processor.SetParagraphStyles(ParagraphStyles{})
+
+	segments := []TextSegment{
+		{Text: "Docs", Style: Style{Italic: true, Link: "https://example.com", Color: "#FF0000", FontSize: 14, List: ListNumbered}},
+		{Text: "\n"},
+		{Text: "next", Style: Style{List: ListNumbered}},
+	}
+	requests := processor.ToSlidesRequests(segments, "obj")
+	if len(requests) != 3 {
+		t.Fatalf("got %d requests, want InsertText, one grouped UpdateTextStyle, one numbered list", len(requests))
+	}
+	style := requests[1].UpdateTextStyle
+	if style.Fields != "italic,link,foregroundColor,fontSize" {
+		t.Errorf("Fields = %q, want every facet in one request", style.Fields)
+	}
+	if style.Style.Link.Url != "https://example.com" || style.Style.FontSize.Magnitude != 14 || style.Style.ForegroundColor.OpaqueColor.RgbColor.Red != 1 {
+		t.Errorf("Style = %+v", style.Style)
+	}
+	bullets := requests[2].CreateParagraphBullets
+	if bullets.BulletPreset != "NUMBERED_DIGIT_ALPHA_ROMAN" || *bullets.TextRange.EndIndex != 9 {
+		t.Errorf("list = %s ending %d, want one numbered list over both lines", bullets.BulletPreset, *bullets.TextRange.EndIndex)
+	}
+
+	// A blank line ends a list
+	var lists int
+	for _, r := range processor.ToSlidesRequests(processor.ParseMarkup("• a\n\n• b"), "obj") {
+		if r.CreateParagraphBullets != nil {
+			lists++
+		}
+	}
+	if lists != 2 {
+		t.Errorf("got %d bullet ranges, want 2 separated by the blank line", lists)
+	}
+}
+
+func TestUTF16Len(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"", 0},
+		{"plain", 5},
+		{"café", 4},
+		{"◦ sub", 5},
+		{"ship 🚀", 7},
+		{"\xff", 1},
+	}
+	for _, tt := range tests {
+		if got := UTF16Len(tt.in); got != tt.want {
+			t.Errorf("UTF16Len(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+// FuzzParseMarkup feeds arbitrary model text through the markup pipeline. Parsing must keep
+// every line break, and the Slides requests must style only ranges inside the inserted text.
+func FuzzParseMarkup(f *testing.F) {
+	for _, seed := range []string{
+		"This is **bold** text",
+		"• a\n  ◦ b\n• a",
+		"a\nb\na",
+		"•\n• \n  ◦ \n•• x\n- y\n* **z**",
+		"> quote with `**code**`\n> ",
+		"x^2^ and H~2~O, ~50% and ^",
+		"```\n• not a bullet\n```\n```go\nunterminated",
+		"| a | b |\n|---|---|\n| 1 | 2 |",
+		"ship 🚀 **🚀** `🚀`",
+		"**unclosed bold\n\n\n",
+		"\xff\xfe **\xff**",
+	} {
+		f.Add(seed)
+	}
+	tp := NewTextProcessor()
+	f.Fuzz(func(t *testing.T, text string) {
+		segments := tp.ParseMarkup(text)
+		var joined strings.Builder
+		for _, s := range segments {
+			joined.WriteString(s.Text)
+		}
+		if got, want := strings.Count(joined.String(), "\n"), strings.Count(text, "\n"); got != want {
+			t.Fatalf("ParseMarkup(%q) kept %d line breaks, want %d", text, got, want)
+		}
+		requests := tp.ToSlidesRequests(segments, "obj")
+		if len(requests) == 0 || requests[0].InsertText == nil {
+			t.Fatalf("ToSlidesRequests(%q) doesn't start with InsertText", text)
+		}
+		n := int64(UTF16Len(requests[0].InsertText.Text))
+		for _, r := range requests[1:] {
+			var rng *slides.Range
+			switch {
+			case r.UpdateTextStyle != nil:
+				rng = r.UpdateTextStyle.TextRange
+			case r.UpdateParagraphStyle != nil:
+				rng = r.UpdateParagraphStyle.TextRange
+			case r.CreateParagraphBullets != nil:
+				rng = r.CreateParagraphBullets.TextRange
+			}
+			if rng == nil || rng.Type != "FIXED_RANGE" {
+				continue
+			}
+			if *rng.StartIndex < 0 || *rng.StartIndex >= *rng.EndIndex || *rng.EndIndex > n {
+				t.Fatalf("ParseMarkup(%q): range [%d, %d) outside the %d inserted units", text, *rng.StartIndex, *rng.EndIndex, n)
+			}
+		}
+		tp.ToPlainText(segments)
+		tp.ToHTML(segments)
+		tp.CleanText(text)
+		tp.Validate(text)
+		if fixed := tp.Fix(text); strings.Count(fixed, "\n") != strings.Count(text, "\n") {
+			t.Fatalf("Fix(%q) changed the line count", text)
+		}
+		tp.SplitCodeBlocks(text)
+		tp.SplitTables(text)
+	})
+}
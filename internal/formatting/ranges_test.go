@@ -0,0 +1,198 @@
+package formatting
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"google.golang.org/api/slides/v1"
+)
+
+// segmentList is an arbitrary run of segments for property tests: text mixing one- and
+// two-unit UTF-16 characters, newlines, and empty strings, under random styles.
+type segmentList []TextSegment
+
+var (
+	propertyRunes = []string{"a", "Z", " ", "é", "◦", "•", "🚀", "𝑥", "\n", "*"}
+	propertyLinks = []string{"", "https://a.example", "https://b.example"}
+	propertyLists = []string{ListNone, ListNone, ListBullet, ListNumbered}
+)
+
+func (segmentList) Generate(r *rand.Rand, size int) reflect.Value {
+	n := r.Intn(size + 1)
+	segs := make(segmentList, n)
+	for i := range segs {
+		if r.Intn(4) == 0 {
+			segs[i] = TextSegment{Text: "\n"}
+			continue
+		}
+		var b strings.Builder
+		for j := r.Intn(6); j > 0; j-- {
+			b.WriteString(propertyRunes[r.Intn(len(propertyRunes))])
+		}
+		s := Style{
+			Bold:   r.Intn(2) == 0,
+			Italic: r.Intn(3) == 0,
+			Code:   r.Intn(4) == 0,
+			Link:   propertyLinks[r.Intn(len(propertyLinks))],
+			List:   propertyLists[r.Intn(len(propertyLists))],
+			Level:  r.Intn(2),
+			Quote:  r.Intn(4) == 0,
+		}
+		switch r.Intn(4) {
+		case 0:
+			s.Baseline = "SUPERSCRIPT"
+		case 1:
+			s.Baseline = "SUBSCRIPT"
+		}
+		if r.Intn(4) == 0 {
+			s.Color = "#FF0000"
+		}
+		if r.Intn(4) == 0 {
+			s.FontSize = float64(10 + r.Intn(3))
+		}
+		segs[i] = TextSegment{Text: b.String(), Style: s}
+	}
+	return reflect.ValueOf(segs)
+}
+
+// styledRange is one facet of one request: a range and the style key it sets there.
+type styledRange struct {
+	start, end int
+	key        string
+}
+
+// textFacetKeys reads back, per TextStyle field, the key a request set, so it can be
+// compared with textAttrs' key for a segment. Colors are compared by presence only.
+var textFacetKeys = map[string]func(*slides.TextStyle) string{
+	"bold":   func(ts *slides.TextStyle) string { return flagKey(ts.Bold) },
+	"italic": func(ts *slides.TextStyle) string { return flagKey(ts.Italic) },
+	"fontFamily": func(ts *slides.TextStyle) string {
+		return flagKey(ts.FontFamily == CodeFont)
+	},
+	"baselineOffset":  func(ts *slides.TextStyle) string { return ts.BaselineOffset },
+	"link":            func(ts *slides.TextStyle) string { return ts.Link.Url },
+	"foregroundColor": func(ts *slides.TextStyle) string { return flagKey(ts.ForegroundColor != nil) },
+	"fontSize": func(ts *slides.TextStyle) string {
+		return strconv.FormatFloat(ts.FontSize.Magnitude, 'g', -1, 64)
+	},
+}
+
+func flagKey(on bool) string {
+	if on {
+		return "1"
+	}
+	return ""
+}
+
+// TestToSlidesRequests_RangeProperties checks, for arbitrary segment lists, that the
+// inserted text is the segments' text and that every facet's ranges are non-empty, inside
+// the text, on segment boundaries, non-overlapping, and cover exactly the segments that
+// have the facet, with its value. Newline segments between two styled ones may be covered
+// or not, since adjacent spans merge across a single line break.
+func TestToSlidesRequests_RangeProperties(t *testing.T) {
+	tp := NewTextProcessor()
+	tp.SetBoldColor(0.1, 0.2, 0.3)
+	check := func(segs segmentList) bool {
+		requests := tp.ToSlidesRequests(segs, "obj")
+		var text strings.Builder
+		bounds := map[int]bool{0: true}
+		pos := 0
+		for _, s := range segs {
+			text.WriteString(s.Text)
+			pos += UTF16Len(s.Text)
+			bounds[pos] = true
+		}
+		if requests[0].InsertText == nil || requests[0].InsertText.Text != text.String() {
+			t.Logf("inserted %q, want %q", requests[0].InsertText.Text, text.String())
+			return false
+		}
+		if got := UTF16Len(text.String()); got != pos {
+			t.Logf("inserted %d units, segments sum to %d", got, pos)
+			return false
+		}
+
+		facets := map[string][]styledRange{}
+		add := func(facet string, rng *slides.Range, key string) {
+			facets[facet] = append(facets[facet], styledRange{int(*rng.StartIndex), int(*rng.EndIndex), key})
+		}
+		for _, r := range requests[1:] {
+			switch {
+			case r.UpdateTextStyle != nil:
+				for _, field := range strings.Split(r.UpdateTextStyle.Fields, ",") {
+					add(field, r.UpdateTextStyle.TextRange, textFacetKeys[field](r.UpdateTextStyle.Style))
+				}
+			case r.CreateParagraphBullets != nil:
+				add("bullets", r.CreateParagraphBullets.TextRange, r.CreateParagraphBullets.BulletPreset)
+			case r.UpdateParagraphStyle != nil && r.UpdateParagraphStyle.Fields == "indentStart,indentFirstLine":
+				add("quote", r.UpdateParagraphStyle.TextRange, "1")
+			}
+		}
+		for facet, ranges := range facets {
+			sort.Slice(ranges, func(i, j int) bool { return ranges[i].start < ranges[j].start })
+			for i, r := range ranges {
+				if r.start < 0 || r.start >= r.end || r.end > pos {
+					t.Logf("%s range [%d,%d) outside [0,%d) or empty", facet, r.start, r.end, pos)
+					return false
+				}
+				if !bounds[r.start] || !bounds[r.end] {
+					t.Logf("%s range [%d,%d) splits a segment", facet, r.start, r.end)
+					return false
+				}
+				if i > 0 && r.start < ranges[i-1].end {
+					t.Logf("%s ranges [%d,%d) and [%d,%d) overlap", facet, ranges[i-1].start, ranges[i-1].end, r.start, r.end)
+					return false
+				}
+			}
+		}
+
+		keyOf := func(facet string, s Style) string {
+			switch facet {
+			case "bullets":
+				return listPreset(s)
+			case "quote":
+				return flagKey(s.Quote)
+			case "foregroundColor":
+				return flagKey(tp.colorKey(s) != "")
+			}
+			for _, attr := range textAttrs {
+				if attr.field == facet {
+					return attr.key(tp, s)
+				}
+			}
+			panic("unknown facet " + facet)
+		}
+		pos = 0
+		for _, s := range segs {
+			start := pos
+			pos += UTF16Len(s.Text)
+			if s.Text == "\n" || s.Text == "" {
+				continue
+			}
+			for _, facet := range []string{"bold", "italic", "fontFamily", "baselineOffset", "link", "foregroundColor", "fontSize", "bullets", "quote"} {
+				got := ""
+				for _, r := range facets[facet] {
+					if r.start <= start && pos <= r.end {
+						got = r.key
+					} else if r.start < pos && start < r.end {
+						t.Logf("%s range [%d,%d) partly covers segment %q at [%d,%d)", facet, r.start, r.end, s.Text, start, pos)
+						return false
+					}
+				}
+				if want := keyOf(facet, s.Style); got != want {
+					t.Logf("segment %q at [%d,%d) has %s %q, want %q", s.Text, start, pos, facet, got, want)
+					return false
+				}
+			}
+		}
+		return true
+	}
+	cfg := &quick.Config{MaxCount: 2000, Rand: rand.New(rand.NewSource(1))}
+	if err := quick.Check(check, cfg); err != nil {
+		t.Error(err)
+	}
+}
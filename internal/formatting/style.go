@@ -0,0 +1,165 @@
+package formatting
+
+import (
+	"strconv"
+
+	"gogemini-practices/internal/palette"
+
+	"google.golang.org/api/slides/v1"
+)
+
+// List types for Style.List.
+const (
+	ListNone     = ""
+	ListBullet   = "BULLET"
+	ListNumbered = "NUMBERED"
+)
+
+// Style is the formatting of a TextSegment. The character fields style the segment's own
+// text; List, Level, and Quote style the paragraph it belongs to.
+type Style struct {
+	Bold     bool
+	Italic   bool
+	Code     bool    // monospace CodeFont
+	Baseline string  // "" | SUPERSCRIPT | SUBSCRIPT
+	Link     string  // URL the text links to
+	Color    string  // "#RRGGBB"; "" keeps the box color, or the accent for bold and quotes
+	FontSize float64 // points; 0 keeps the box size
+	List     string  // ListNone | ListBullet | ListNumbered
+	Level    int     // list nesting: 0=main item, 1=sub-item
+	Quote    bool    // blockquote paragraph
+}
+
+// textAttr maps one character-level facet of Style onto a Slides TextStyle field. key
+// returns "" when the facet is unset; equal keys on adjacent text share one range, and
+// facets over the same range share one UpdateTextStyle request.
+type textAttr struct {
+	field string
+	key   func(tp *TextProcessor, s Style) string
+	set   func(tp *TextProcessor, ts *slides.TextStyle, key string)
+}
+
+// textAttrs lists the character facets in request field order. A new character markup
+// needs a Style field and an entry here; the range tracking in toRequests is generic.
+var textAttrs = []textAttr{
+	{"bold", flag(func(s Style) bool { return s.Bold }), func(_ *TextProcessor, ts *slides.TextStyle, _ string) { ts.Bold = true }},
+	{"italic", flag(func(s Style) bool { return s.Italic }), func(_ *TextProcessor, ts *slides.TextStyle, _ string) { ts.Italic = true }},
+	{"fontFamily", flag(func(s Style) bool { return s.Code }), func(_ *TextProcessor, ts *slides.TextStyle, _ string) { ts.FontFamily = CodeFont }},
+	{"baselineOffset", func(_ *TextProcessor, s Style) string { return s.Baseline }, func(_ *TextProcessor, ts *slides.TextStyle, key string) { ts.BaselineOffset = key }},
+	{"link", func(_ *TextProcessor, s Style) string { return s.Link }, func(_ *TextProcessor, ts *slides.TextStyle, key string) { ts.Link = &slides.Link{Url: key} }},
+	{"foregroundColor", (*TextProcessor).colorKey, func(tp *TextProcessor, ts *slides.TextStyle, key string) { ts.ForegroundColor = tp.color(key) }},
+	{"fontSize", func(_ *TextProcessor, s Style) string {
+		if s.FontSize <= 0 {
+			return ""
+		}
+		return strconv.FormatFloat(s.FontSize, 'g', -1, 64)
+	}, func(_ *TextProcessor, ts *slides.TextStyle, key string) {
+		size, _ := strconv.ParseFloat(key, 64)
+		ts.FontSize = &slides.Dimension{Magnitude: size, Unit: "PT"}
+	}},
+}
+
+// flag adapts a boolean facet to a textAttr key.
+func flag(on func(Style) bool) func(*TextProcessor, Style) string {
+	return func(_ *TextProcessor, s Style) string {
+		if on(s) {
+			return "1"
+		}
+		return ""
+	}
+}
+
+// Color keys besides explicit hex colors.
+const (
+	colorAccent = "accent"
+	colorGray   = "gray"
+)
+
+// colorKey resolves a segment's text color: its own Color, else the accent for bold and
+// quoted text, else gray for quotes without an accent.
+func (tp *TextProcessor) colorKey(s Style) string {
+	switch {
+	case s.Color != "":
+		return s.Color
+	case (s.Bold || s.Quote) && tp.boldColor != nil:
+		return colorAccent
+	case s.Quote:
+		return colorGray
+	}
+	return ""
+}
+
+// color turns a colorKey into a Slides color; invalid hex colors fall back to the accent
+// or, without one, gray.
+func (tp *TextProcessor) color(key string) *slides.OptionalColor {
+	switch key {
+	case colorAccent:
+		return tp.boldColor
+	case colorGray:
+		return quoteGray
+	}
+	r, g, b, err := palette.RGB(key)
+	if err != nil {
+		if tp.boldColor != nil {
+			return tp.boldColor
+		}
+		return quoteGray
+	}
+	return &slides.OptionalColor{OpaqueColor: &slides.OpaqueColor{RgbColor: &slides.RgbColor{Red: r, Green: g, Blue: b}}}
+}
+
+// paragraphAttr maps one paragraph-level facet of Style onto requests for a range of
+// whole paragraphs; base is the box's paragraph style.
+type paragraphAttr struct {
+	key      func(s Style) string
+	requests func(tp *TextProcessor, objectID string, textRange *slides.Range, key string, base ParagraphStyle) []*slides.Request
+}
+
+var paragraphAttrs = []paragraphAttr{
+	{listPreset, func(tp *TextProcessor, objectID string, textRange *slides.Range, key string, base ParagraphStyle) []*slides.Request {
+		requests := []*slides.Request{{CreateParagraphBullets: &slides.CreateParagraphBulletsRequest{
+			ObjectId:     objectID,
+			TextRange:    textRange,
+			BulletPreset: key,
+		}}}
+		if req := tp.paragraphs.Bullet.over(base).request(objectID, textRange); req != nil {
+			requests = append(requests, req)
+		}
+		return requests
+	}},
+	{func(s Style) string {
+		if s.Quote {
+			return "1"
+		}
+		return ""
+	}, func(tp *TextProcessor, objectID string, textRange *slides.Range, _ string, base ParagraphStyle) []*slides.Request {
+		requests := []*slides.Request{{UpdateParagraphStyle: &slides.UpdateParagraphStyleRequest{
+			ObjectId: objectID,
+			Style: &slides.ParagraphStyle{
+				IndentStart:     &slides.Dimension{Magnitude: QuoteIndent, Unit: "PT"},
+				IndentFirstLine: &slides.Dimension{Magnitude: QuoteIndent, Unit: "PT"},
+			},
+			Fields:    "indentStart,indentFirstLine",
+			TextRange: textRange,
+		}}}
+		if req := tp.paragraphs.Quote.over(base).request(objectID, textRange); req != nil {
+			requests = append(requests, req)
+		}
+		return requests
+	}},
+}
+
+// listPreset picks the bullet glyphs for a list paragraph; sub-items of bullet lists use
+// a distinct preset so levels stay visually apart.
+func listPreset(s Style) string {
+	switch s.List {
+	case ListBullet:
+		if s.Level >= 1 {
+			return "BULLET_ARROW_DIAMOND_DISC"
+		}
+		return "BULLET_DISC_CIRCLE_SQUARE"
+	case ListNumbered:
+		return "NUMBERED_DIGIT_ALPHA_ROMAN"
+	}
+	return ""
+}
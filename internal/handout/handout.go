@@ -0,0 +1,192 @@
+// Package handout renders a deck's plan as a reading handout, one page per topic with
+// the full summary, the topic's chart, and its sources: richer than the slides, for people
+// who read rather than watch. It renders as Markdown or as HTML for importing into a Google
+// Doc, where each topic starts a new page.
+package handout
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"gogemini-practices/internal/formatting"
+)
+
+// Handout is the document for one plan.
+type Handout struct {
+	Title    string
+	Subtitle string // optional, e.g. the audience
+	Pages    []Page
+}
+
+// Page is one topic.
+type Page struct {
+	Title   string
+	Summary string   // in the summary markup; see formatting.TextProcessor.ParseMarkup
+	Steps   []string // optional ordered steps of a process topic
+	Stat    string   // optional headline figure, e.g. "42% — of users churn in month one"
+	Code    string   // optional example code
+	Lang    string   // the code's language, if known
+	Chart   *Image   // optional
+	Sources []Source
+}
+
+// Image is a picture on a page: a relative path in Markdown, a fetchable URL in a Doc.
+type Image struct {
+	Src string
+	Alt string
+}
+
+// Source is where something on a page came from. URL is optional.
+type Source struct {
+	Label string
+	URL   string
+}
+
+var urlPattern = regexp.MustCompile(`https?://[^\s<>()\[\]"']+[^\s<>()\[\]"'.,;:!?]`)
+
+// LinkSources lists the distinct URLs written into text, e.g. a summary, as sources.
+func LinkSources(text string) []Source {
+	var out []Source
+	seen := map[string]bool{}
+	for _, u := range urlPattern.FindAllString(text, -1) {
+		if !seen[u] {
+			seen[u] = true
+			out = append(out, Source{Label: u, URL: u})
+		}
+	}
+	return out
+}
+
+// Markdown renders the handout with a horizontal rule between topics. Bullets of the
+// summary markup become Markdown list items; the rest of the markup is Markdown already.
+func (h Handout) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", oneLine(firstNonEmpty(h.Title, "Handout")))
+	if s := oneLine(h.Subtitle); s != "" {
+		fmt.Fprintf(&b, "\n_%s_\n", s)
+	}
+	for i, p := range h.Pages {
+		if i > 0 {
+			b.WriteString("\n---\n")
+		}
+		fmt.Fprintf(&b, "\n## %d. %s\n", i+1, oneLine(p.Title))
+		if p.Stat != "" {
+			fmt.Fprintf(&b, "\n**%s**\n", oneLine(p.Stat))
+		}
+		if s := markdownSummary(p.Summary); s != "" {
+			fmt.Fprintf(&b, "\n%s\n", s)
+		}
+		if len(p.Steps) > 0 {
+			b.WriteString("\n")
+			for j, s := range p.Steps {
+				fmt.Fprintf(&b, "%d. %s\n", j+1, oneLine(s))
+			}
+		}
+		if p.Chart != nil && p.Chart.Src != "" {
+			fmt.Fprintf(&b, "\n![%s](%s)\n", oneLine(p.Chart.Alt), p.Chart.Src)
+		}
+		if code := strings.Trim(p.Code, "\n"); code != "" {
+			fmt.Fprintf(&b, "\n```%s\n%s\n```\n", p.Lang, code)
+		}
+		if len(p.Sources) > 0 {
+			b.WriteString("\n**Sources**\n\n")
+			for _, s := range p.Sources {
+				if s.URL != "" && s.URL != s.Label {
+					fmt.Fprintf(&b, "- [%s](%s)\n", oneLine(s.Label), s.URL)
+				} else {
+					fmt.Fprintf(&b, "- %s\n", oneLine(firstNonEmpty(s.Label, s.URL)))
+				}
+			}
+		}
+	}
+	return b.String()
+}
+
+// HTML renders the handout as a standalone HTML document, each topic after the first
+// starting a new page. Summaries go through formatting.TextProcessor.ToHTML, so all text
+// is escaped.
+func (h Handout) HTML() string {
+	var b strings.Builder
+	tp := formatting.NewTextProcessor()
+	title := html.EscapeString(oneLine(firstNonEmpty(h.Title, "Handout")))
+	fmt.Fprintf(&b, "<!DOCTYPE html>\n<html><head><meta charset=\"utf-8\"><title>%s</title></head><body>\n<h1>%s</h1>\n", title, title)
+	if s := oneLine(h.Subtitle); s != "" {
+		fmt.Fprintf(&b, "<p><em>%s</em></p>\n", html.EscapeString(s))
+	}
+	for i, p := range h.Pages {
+		heading := "<h2>"
+		if i > 0 {
+			heading = `<h2 style="page-break-before: always">`
+		}
+		fmt.Fprintf(&b, "%s%d. %s</h2>\n", heading, i+1, html.EscapeString(oneLine(p.Title)))
+		if p.Stat != "" {
+			fmt.Fprintf(&b, "<p><strong>%s</strong></p>\n", html.EscapeString(oneLine(p.Stat)))
+		}
+		if s := strings.TrimSpace(p.Summary); s != "" {
+			b.WriteString(tp.ToHTML(tp.ParseMarkup(tp.Fix(s))))
+			b.WriteString("\n")
+		}
+		if len(p.Steps) > 0 {
+			b.WriteString("<ol>")
+			for _, s := range p.Steps {
+				fmt.Fprintf(&b, "<li>%s</li>", html.EscapeString(oneLine(s)))
+			}
+			b.WriteString("</ol>\n")
+		}
+		if p.Chart != nil && p.Chart.Src != "" {
+			fmt.Fprintf(&b, "<p><img src=\"%s\" alt=\"%s\" width=\"600\"></p>\n", html.EscapeString(p.Chart.Src), html.EscapeString(oneLine(p.Chart.Alt)))
+		}
+		if code := strings.Trim(p.Code, "\n"); code != "" {
+			fmt.Fprintf(&b, "<pre><code>%s</code></pre>\n", html.EscapeString(code))
+		}
+		if len(p.Sources) > 0 {
+			b.WriteString("<p><strong>Sources</strong></p>\n<ul>")
+			for _, s := range p.Sources {
+				label := html.EscapeString(oneLine(firstNonEmpty(s.Label, s.URL)))
+				if s.URL != "" {
+					fmt.Fprintf(&b, "<li><a href=\"%s\">%s</a></li>", html.EscapeString(s.URL), label)
+				} else {
+					fmt.Fprintf(&b, "<li>%s</li>", label)
+				}
+			}
+			b.WriteString("</ul>\n")
+		}
+	}
+	b.WriteString("</body></html>\n")
+	return b.String()
+}
+
+// markdownSummary turns the summary markup's "• " and "  ◦ " bullets into Markdown list
+// items, after the same fixes the slides get.
+func markdownSummary(summary string) string {
+	tp := formatting.NewTextProcessor()
+	lines := strings.Split(tp.Fix(strings.TrimSpace(summary)), "\n")
+	inBlock := false
+	for i, line := range lines {
+		switch trimmed := strings.TrimLeft(line, " "); {
+		case strings.HasPrefix(trimmed, "```"):
+			inBlock = !inBlock
+		case inBlock:
+		case strings.HasPrefix(trimmed, "◦ "):
+			lines[i] = "  - " + strings.TrimPrefix(trimmed, "◦ ")
+		case strings.HasPrefix(trimmed, "• "):
+			lines[i] = "- " + strings.TrimPrefix(trimmed, "• ")
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,64 @@
+package handout
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestHandout_Markdown(t *testing.T) {
+	h := Handout{
+		Title:    "Q3 pricing",
+		Subtitle: "For the board",
+		Pages: []Page{
+			{
+				Title:   "Why now",
+				Stat:    "8% — cost growth",
+				Summary: "Costs **rose**:\n• Energy\n  ◦ Gas\n```\n• not a bullet\n```",
+				Chart:   &Image{Src: "q3-charts/1-costs.png", Alt: "Costs"},
+				Sources: []Source{{Label: "BigQuery"}, {Label: "Report", URL: "https://example.com/r"}},
+			},
+			{Title: "The plan", Steps: []string{"Announce", "Raise"}, Code: "x := 1\n", Lang: "go"},
+		},
+	}
+	want := "# Q3 pricing\n\n_For the board_\n\n" +
+		"## 1. Why now\n\n**8% — cost growth**\n\n" +
+		"Costs **rose**:\n- Energy\n  - Gas\n```\n• not a bullet\n```\n\n" +
+		"![Costs](q3-charts/1-costs.png)\n\n" +
+		"**Sources**\n\n- BigQuery\n- [Report](https://example.com/r)\n" +
+		"\n---\n\n## 2. The plan\n\n1. Announce\n2. Raise\n\n```go\nx := 1\n```\n"
+	if got := h.Markdown(); got != want {
+		t.Errorf("Markdown() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestHandout_HTML(t *testing.T) {
+	h := Handout{Title: "A & B", Pages: []Page{
+		{Title: "One", Summary: "**Bold** <tag>", Chart: &Image{Src: "https://drive.example/uc?id=1&x=2", Alt: "c"}},
+		{Title: "Two", Sources: []Source{{Label: "Web", URL: "https://example.com"}}},
+	}}
+	got := h.HTML()
+	for _, want := range []string{
+		"<h1>A &amp; B</h1>",
+		"<h2>1. One</h2>",
+		`<h2 style="page-break-before: always">2. Two</h2>`,
+		"<strong>Bold</strong> &lt;tag&gt;",
+		`<img src="https://drive.example/uc?id=1&amp;x=2" alt="c" width="600">`,
+		`<li><a href="https://example.com">Web</a></li>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("HTML() missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestLinkSources(t *testing.T) {
+	got := LinkSources("See https://a.example/x, and (https://b.example/y). Again https://a.example/x.")
+	var urls []string
+	for _, s := range got {
+		urls = append(urls, s.URL)
+	}
+	if want := []string{"https://a.example/x", "https://b.example/y"}; !slices.Equal(urls, want) {
+		t.Errorf("LinkSources() = %v, want %v", urls, want)
+	}
+}
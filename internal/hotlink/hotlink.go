@@ -0,0 +1,112 @@
+// Package hotlink detects image hosts that refuse requests from other sites. Such hosts
+// often answer the CLI's HEAD check but 403 Google Slides when it fetches the image with
+// a Referer of its own, so their images have to be copied somewhere Slides can read.
+package hotlink
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ForeignReferer is the Referer a cross-site fetch is tried with, like the one Slides sends.
+const ForeignReferer = "https://docs.google.com/"
+
+// Result is what Check found out about an image URL.
+type Result struct {
+	// Protected reports that the host refuses the image to other sites, so it must be
+	// downloaded and re-hosted rather than linked.
+	Protected bool
+	// Referer is the Referer the image downloads with: "" for none, or the page it was found
+	// on when the host serves only requests coming from its own pages.
+	Referer string
+}
+
+// Detector checks image URLs and remembers the verdict for each host, so a run fetches
+// from a host only for its first image. It is safe for concurrent use.
+type Detector struct {
+	Client *http.Client // nil uses a client with a 5s timeout
+
+	mu    sync.Mutex
+	hosts map[string]hostVerdict
+}
+
+type hostVerdict struct {
+	protected   bool
+	needsOrigin bool // only requests with the image's page as Referer are served
+}
+
+// Check compares a fetch of imageURL without a Referer to one with ForeignReferer. An image
+// served without a Referer but refused to the foreign one is protected; an image refused
+// without a Referer is protected if it is served with pageURL, the page it was found on.
+// An error means the image could not be fetched at all.
+func (d *Detector) Check(ctx context.Context, imageURL, pageURL string) (Result, error) {
+	u, err := url.Parse(imageURL)
+	if err != nil || u.Host == "" {
+		return Result{}, fmt.Errorf("hotlink check: invalid URL %q", imageURL)
+	}
+	host := strings.ToLower(u.Host)
+	d.mu.Lock()
+	v, ok := d.hosts[host]
+	d.mu.Unlock()
+	if !ok {
+		if v, err = d.probe(ctx, imageURL, pageURL); err != nil {
+			return Result{}, err
+		}
+		d.mu.Lock()
+		if d.hosts == nil {
+			d.hosts = map[string]hostVerdict{}
+		}
+		d.hosts[host] = v
+		d.mu.Unlock()
+	}
+	res := Result{Protected: v.protected}
+	if v.needsOrigin {
+		res.Referer = pageURL
+	}
+	return res, nil
+}
+
+// probe fetches imageURL with each Referer in turn and reads the host's policy off the answers.
+func (d *Detector) probe(ctx context.Context, imageURL, pageURL string) (hostVerdict, error) {
+	if d.served(ctx, imageURL, "") {
+		return hostVerdict{protected: !d.served(ctx, imageURL, ForeignReferer)}, nil
+	}
+	if pageURL != "" && d.served(ctx, imageURL, pageURL) {
+		return hostVerdict{protected: true, needsOrigin: true}, nil
+	}
+	return hostVerdict{}, fmt.Errorf("hotlink check: %s is not served with or without a Referer", imageURL)
+}
+
+// served reports whether a GET of the image's first bytes with referer returns an image.
+// A host that answers with an HTML page or a redirect to a placeholder counts as refusing.
+func (d *Detector) served(ctx context.Context, imageURL, referer string) bool {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil)
+	if err != nil {
+		return false
+	}
+	req.Header.Set("Range", "bytes=0-1023")
+	if referer != "" {
+		req.Header.Set("Referer", referer)
+	}
+	client := d.Client
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1024))
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		return false
+	}
+	ct := strings.ToLower(resp.Header.Get("Content-Type"))
+	return ct == "" || strings.HasPrefix(ct, "image/")
+}
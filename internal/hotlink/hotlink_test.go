@@ -0,0 +1,113 @@
+package hotlink
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	const page = "https://example.com/gallery"
+	tests := []struct {
+		name    string
+		serve   func(referer string) bool // whether the host serves the image to referer
+		page    string
+		want    Result
+		wantErr bool
+	}{
+		{
+			name:  "open host",
+			serve: func(string) bool { return true },
+			want:  Result{},
+		},
+		{
+			name:  "foreign referer refused",
+			serve: func(ref string) bool { return ref == "" },
+			want:  Result{Protected: true},
+		},
+		{
+			name:  "own pages only",
+			serve: func(ref string) bool { return ref == page },
+			page:  page,
+			want:  Result{Protected: true, Referer: page},
+		},
+		{
+			name:    "own pages only, page unknown",
+			serve:   func(ref string) bool { return ref == page },
+			wantErr: true,
+		},
+		{
+			name:    "never served",
+			serve:   func(string) bool { return false },
+			page:    page,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if !tt.serve(r.Header.Get("Referer")) {
+					http.Error(w, "hotlinking not allowed", http.StatusForbidden)
+					return
+				}
+				w.Header().Set("Content-Type", "image/jpeg")
+				w.Write([]byte("\xff\xd8\xff"))
+			}))
+			defer srv.Close()
+
+			var d Detector
+			got, err := d.Check(context.Background(), srv.URL+"/a.jpg", tt.page)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Check() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheck_HTMLPlaceholder(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Referer") != "" {
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<html>no hotlinking</html>"))
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("\x89PNG"))
+	}))
+	defer srv.Close()
+	var d Detector
+	if got, err := d.Check(context.Background(), srv.URL+"/a.png", ""); err != nil || !got.Protected {
+		t.Errorf("Check() = %+v, %v; want protected", got, err)
+	}
+}
+
+func TestCheck_CachesHost(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if !strings.HasPrefix(r.Header.Get("Range"), "bytes=0-") {
+			t.Errorf("Range = %q, want the first bytes only", r.Header.Get("Range"))
+		}
+		if r.Header.Get("Referer") != "" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "image/jpeg")
+	}))
+	defer srv.Close()
+	var d Detector
+	for _, path := range []string{"/a.jpg", "/b.jpg", "/c.jpg"} {
+		if got, err := d.Check(context.Background(), srv.URL+path, ""); err != nil || !got.Protected {
+			t.Fatalf("Check(%s) = %+v, %v; want protected", path, got, err)
+		}
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("made %d requests, want 2 for the first image only", n)
+	}
+}
@@ -0,0 +1,107 @@
+package icons
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultIcon is used when no keyword in the text matches the catalog.
+const DefaultIcon = "lightbulb"
+
+// entry maps a Material Symbols glyph name to the keywords that select it.
+type entry struct {
+	Name     string
+	Keywords []string
+}
+
+// catalog is a small, curated subset of the Material Symbols set (Apache 2.0).
+// Keywords are matched as word prefixes, so "educat" matches "education" and "educator";
+// keywords of four letters or fewer ("ai", "car", "plan") must match a whole word or its
+// plural, so "car" matches "cars" but not "career".
+var catalog = []entry{
+	{"health_and_safety", []string{"health", "medic", "hospital", "clinic", "doctor", "patient", "care", "disease", "dental", "hygiene"}},
+	{"psychology", []string{"mind", "mental", "psycholog", "brain", "cognit", "emotion"}},
+	{"school", []string{"school", "educat", "learn", "student", "teach", "class", "children", "kids"}},
+	{"science", []string{"science", "research", "lab", "experiment", "chemi", "biolog", "physic"}},
+	{"memory", []string{"ai", "machine", "algorithm", "model", "neural", "comput", "chip", "hardware"}},
+	{"code", []string{"code", "software", "program", "develop", "api", "engineer"}},
+	{"cloud", []string{"cloud", "server", "hosting", "saas"}},
+	{"security", []string{"secur", "privacy", "protect", "risk", "threat", "cyber", "safety"}},
+	{"payments", []string{"money", "financ", "payment", "bank", "revenue", "cost", "price", "budget", "invest"}},
+	{"trending_up", []string{"growth", "trend", "increase", "rise", "market", "sales", "performance"}},
+	{"bar_chart", []string{"data", "statistic", "metric", "analytics", "chart", "number"}},
+	{"groups", []string{"team", "people", "population", "communit", "social", "audience", "user"}},
+	{"public", []string{"global", "world", "countr", "international", "geograph"}},
+	{"eco", []string{"environment", "climate", "green", "sustainab", "nature", "energy", "carbon"}},
+	{"sports_motorsports", []string{"f1", "formula", "racing", "motorsport", "driver", "pilot", "grand", "prix"}},
+	{"sports_soccer", []string{"sport", "football", "soccer", "game", "athlet"}},
+	{"sports_esports", []string{"videogame", "gaming", "esport", "console", "steam"}},
+	{"restaurant", []string{"food", "nutrition", "diet", "meal", "restaurant", "cook"}},
+	{"directions_car", []string{"car", "vehicle", "transport", "traffic", "automotive"}},
+	{"flight", []string{"travel", "flight", "airline", "tourism", "trip"}},
+	{"apartment", []string{"city", "urban", "building", "housing", "estate", "architect"}},
+	{"history_edu", []string{"history", "histor", "ancient", "century", "era", "heritage"}},
+	{"gavel", []string{"law", "legal", "regulat", "policy", "compliance", "govern"}},
+	{"campaign", []string{"marketing", "campaign", "brand", "advertis", "promot"}},
+	{"schedule", []string{"time", "schedule", "deadline", "timeline", "plan"}},
+	{"chat", []string{"communicat", "chat", "message", "conversation", "feedback"}},
+	{"build", []string{"tool", "build", "repair", "maintenance", "manufactur"}},
+	{"rocket_launch", []string{"launch", "startup", "innovat", "space", "future"}},
+}
+
+// Pick returns the Material Symbols glyph name whose keywords best match the text.
+func Pick(text string) string {
+	words := tokenize(text)
+	if len(words) == 0 {
+		return DefaultIcon
+	}
+	best := DefaultIcon
+	bestScore := 0
+	for _, e := range catalog {
+		score := 0
+		for _, kw := range e.Keywords {
+			for _, w := range words {
+				if matches(w, kw) {
+					score++
+					break
+				}
+			}
+		}
+		if score > bestScore {
+			bestScore = score
+			best = e.Name
+		}
+	}
+	return best
+}
+
+// matches reports whether the word w selects keyword kw.
+func matches(w, kw string) bool {
+	if len(kw) <= 4 {
+		return w == kw || w == kw+"s"
+	}
+	return strings.HasPrefix(w, kw)
+}
+
+// URL returns the hosted SVG for a Material Symbols glyph. SVGs must be
+// rasterized before insertion since Slides CreateImage rejects them.
+func URL(name string) string {
+	if strings.TrimSpace(name) == "" {
+		name = DefaultIcon
+	}
+	return fmt.Sprintf("https://fonts.gstatic.com/s/i/short-term/release/materialsymbolsoutlined/%s/default/48px.svg", name)
+}
+
+func tokenize(s string) []string {
+	s = strings.ToLower(s)
+	parts := strings.FieldsFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	})
+	out := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if len(p) >= 2 {
+			out = append(out, p)
+		}
+	}
+	return out
+}
@@ -0,0 +1,41 @@
+package icons
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPick(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "AI in Healthcare: diagnostic accuracy for patients", want: "health_and_safety"},
+		{text: "Ferrari vs Williams F1 pilots", want: "sports_motorsports"},
+		{text: "Climate change and carbon emissions", want: "eco"},
+		{text: "Airline hubs", want: "flight"},          // "ai" must not prefix-match "airline"
+		{text: "Career paths", want: DefaultIcon},       // "car" and "care" must not prefix-match "career"
+		{text: "Label printers", want: DefaultIcon},     // nor "lab" "label"
+		{text: "Planet formation", want: DefaultIcon},   // nor "plan" "planet"
+		{text: "Electric cars", want: "directions_car"}, // plurals still match
+		{text: "Patient care", want: "health_and_safety"},
+		{text: "", want: DefaultIcon},
+		{text: "zzz qqq", want: DefaultIcon},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := Pick(tt.text); got != tt.want {
+				t.Errorf("Pick(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestURL(t *testing.T) {
+	if u := URL(""); !strings.Contains(u, "/"+DefaultIcon+"/") {
+		t.Errorf("URL(\"\") = %q, want default icon", u)
+	}
+	if u := URL("eco"); !strings.HasSuffix(u, ".svg") {
+		t.Errorf("URL(eco) = %q, want svg", u)
+	}
+}
@@ -6,36 +6,74 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"sort"
 	"strings"
 	"time"
 )
 
 type Options struct {
-	ImgSize          string // icon|small|medium|large|xlarge|xxlarge|huge
-	ImgType          string // clipart|face|lineart|news|photo
-	ImgColorType     string // mono|gray|color
-	ImgDominantColor string // red|orange|yellow|green|teal|blue|purple|pink|white|gray|black|brown
-	Rights           string // e.g., cc_publicdomain|cc_attribute|...
-	Safe             string // off|medium|active
-	Num              int    // max results to fetch, 1-10
+	ImgSize          string       // icon|small|medium|large|xlarge|xxlarge|huge
+	ImgType          string       // clipart|face|lineart|news|photo
+	ImgColorType     string       // mono|gray|color
+	ImgDominantColor string       // red|orange|yellow|green|teal|blue|purple|pink|white|gray|black|brown
+	Rights           string       // e.g., cc_publicdomain|cc_attribute|...
+	Safe             string       // off|medium|active
+	Num              int          // max results to fetch, 1-10
+	MinWidth         int          // discard results narrower than this many pixels (0 = no limit)
+	MinHeight        int          // discard results shorter than this many pixels (0 = no limit)
+	Region           string       // two-letter country code: rank its results higher (gl) and keep only its sites (cr)
+	Language         string       // language code such as fr or pt-BR the query is written in (hl)
+	Client           *http.Client // nil uses a client with a 10s timeout
 }
 
 type SearchResponse struct {
-	Items []struct {
-		Title   string `json:"title"`
-		Link    string `json:"link"`
-		Snippet string `json:"snippet"`
-		Mime    string `json:"mime"`
-	} `json:"items"`
+	Items []Item `json:"items"`
+}
+
+// Item is a single CSE image result.
+type Item struct {
+	Title   string    `json:"title"`
+	Link    string    `json:"link"`
+	Snippet string    `json:"snippet"`
+	Mime    string    `json:"mime"`
+	Image   ImageInfo `json:"image"`
+}
+
+// ImageInfo is the image metadata CSE returns alongside each result.
+type ImageInfo struct {
+	ContextLink     string `json:"contextLink"`
+	Width           int    `json:"width"`
+	Height          int    `json:"height"`
+	ByteSize        int    `json:"byteSize"`
+	ThumbnailLink   string `json:"thumbnailLink"`
+	ThumbnailWidth  int    `json:"thumbnailWidth"`
+	ThumbnailHeight int    `json:"thumbnailHeight"`
+}
+
+// Candidate is a search result with its relevance score.
+type Candidate struct {
+	Item
+	Score      int     // query words in the title, snippet, and link, plus HTTPS and image MIME bonuses
+	Similarity float64 // cosine similarity to the topic, set by RankBySimilarity
 }
 
 // SearchBestImage queries Google Custom Search for images and returns the best matching image URL.
 func SearchBestImage(ctx context.Context, apiKey, cx, query string, opts Options) (string, error) {
+	cands, err := SearchImages(ctx, apiKey, cx, query, opts)
+	if err != nil {
+		return "", err
+	}
+	return cands[0].Link, nil
+}
+
+// SearchImages queries Google Custom Search for images and returns all results ranked
+// best first, so callers can fall through to the next candidate when one is unusable.
+func SearchImages(ctx context.Context, apiKey, cx, query string, opts Options) ([]Candidate, error) {
 	if strings.TrimSpace(apiKey) == "" || strings.TrimSpace(cx) == "" {
-		return "", fmt.Errorf("missing CSE key or cx")
+		return nil, fmt.Errorf("missing CSE key or cx")
 	}
 	if strings.TrimSpace(query) == "" {
-		return "", fmt.Errorf("empty query")
+		return nil, fmt.Errorf("empty query")
 	}
 	if opts.Num <= 0 || opts.Num > 10 {
 		opts.Num = 5
@@ -66,32 +104,39 @@ func SearchBestImage(ctx context.Context, apiKey, cx, query string, opts Options
 	if opts.Rights != "" {
 		q.Set("rights", opts.Rights)
 	}
-	u.RawQuery = q.Encode()
-
-	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
-	httpClient := &http.Client{Timeout: 10 * time.Second}
-	resp, err := httpClient.Do(req)
-	if err != nil {
-		return "", err
+	if opts.Region != "" {
+		q.Set("gl", strings.ToLower(opts.Region))
+		q.Set("cr", "country"+strings.ToUpper(opts.Region))
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("cse http %d", resp.StatusCode)
+	if opts.Language != "" {
+		q.Set("hl", opts.Language)
 	}
 
-	var sr SearchResponse
-	if err := json.NewDecoder(resp.Body).Decode(&sr); err != nil {
-		return "", err
+	httpClient := opts.Client
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	sr, err := search(ctx, httpClient, u, q)
+	if err == nil && len(sr.Items) == 0 && q.Has("cr") {
+		// Few sites are attributed to some countries; keep the region as a bias only
+		q.Del("cr")
+		sr, err = search(ctx, httpClient, u, q)
+	}
+	if err != nil {
+		return nil, err
 	}
 	if len(sr.Items) == 0 {
-		return "", fmt.Errorf("no results")
+		return nil, fmt.Errorf("no results")
+	}
+	items := filterByResolution(sr.Items, opts.MinWidth, opts.MinHeight)
+	if len(items) == 0 {
+		return nil, fmt.Errorf("no results at least %dx%d px", opts.MinWidth, opts.MinHeight)
 	}
 
 	// Score by topic word matches in title/snippet
 	terms := tokenize(query)
-	bestIdx := 0
-	bestScore := -1
-	for i, it := range sr.Items {
+	cands := make([]Candidate, 0, len(items))
+	for _, it := range items {
 		score := scoreItem(it.Title, it.Snippet, it.Link, terms)
 		// prefer https and typical image mimes
 		if strings.HasPrefix(strings.ToLower(it.Link), "https://") {
@@ -100,12 +145,84 @@ func SearchBestImage(ctx context.Context, apiKey, cx, query string, opts Options
 		if strings.HasPrefix(it.Mime, "image/") {
 			score += 1
 		}
-		if score > bestScore {
-			bestScore = score
-			bestIdx = i
+		cands = append(cands, Candidate{Item: it, Score: score})
+	}
+	// Stable keeps CSE's own ranking as the tie-breaker
+	sort.SliceStable(cands, func(i, j int) bool { return cands[i].Score > cands[j].Score })
+	return cands, nil
+}
+
+// search runs one CSE request with the query parameters q.
+func search(ctx context.Context, httpClient *http.Client, u *url.URL, q url.Values) (SearchResponse, error) {
+	withQuery := *u
+	withQuery.RawQuery = q.Encode()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, withQuery.String(), nil)
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return SearchResponse{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return SearchResponse{}, fmt.Errorf("cse http %d", resp.StatusCode)
+	}
+	var sr SearchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&sr); err != nil {
+		return SearchResponse{}, err
+	}
+	return sr, nil
+}
+
+// CheckLocale reports whether region is a two-letter country code (e.g. fr) and language
+// a language code with an optional region or script subtag (e.g. fr, pt-BR, zh-Hant);
+// either may be empty.
+func CheckLocale(region, language string) error {
+	if region != "" && (len(region) != 2 || !isLetters(region)) {
+		return fmt.Errorf("region %q is not a two-letter country code", region)
+	}
+	if language != "" {
+		lang, sub, hasSub := strings.Cut(language, "-")
+		if len(lang) < 2 || len(lang) > 3 || !isLetters(lang) || hasSub && (len(sub) < 2 || len(sub) > 4 || !isAlnum(sub)) {
+			return fmt.Errorf("language %q is not a language code such as fr or pt-BR", language)
+		}
+	}
+	return nil
+}
+
+func isLetters(s string) bool {
+	for _, r := range s {
+		if !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlnum(s string) bool {
+	for _, r := range s {
+		if !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9') {
+			return false
 		}
 	}
-	return sr.Items[bestIdx].Link, nil
+	return true
+}
+
+// filterByResolution drops items whose reported dimensions are below the minimums.
+// Items without dimension metadata are kept, since CSE omits it for some results.
+func filterByResolution(items []Item, minW, minH int) []Item {
+	if minW <= 0 && minH <= 0 {
+		return items
+	}
+	out := make([]Item, 0, len(items))
+	for _, it := range items {
+		if it.Image.Width > 0 && it.Image.Width < minW {
+			continue
+		}
+		if it.Image.Height > 0 && it.Image.Height < minH {
+			continue
+		}
+		out = append(out, it)
+	}
+	return out
 }
 
 func tokenize(s string) []string {
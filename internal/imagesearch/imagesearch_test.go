@@ -0,0 +1,158 @@
+package imagesearch
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFilterByResolution(t *testing.T) {
+	items := []Item{
+		{Link: "https://a/thumb.jpg", Image: ImageInfo{Width: 200, Height: 150}},
+		{Link: "https://a/large.jpg", Image: ImageInfo{Width: 1600, Height: 900}},
+		{Link: "https://a/short.jpg", Image: ImageInfo{Width: 1600, Height: 200}},
+		{Link: "https://a/unknown.jpg"},
+	}
+
+	got := filterByResolution(items, 640, 360)
+	var links []string
+	for _, it := range got {
+		links = append(links, it.Link)
+	}
+	want := []string{"https://a/large.jpg", "https://a/unknown.jpg"}
+	if !reflect.DeepEqual(links, want) {
+		t.Errorf("filterByResolution() = %v, want %v", links, want)
+	}
+
+	if n := len(filterByResolution(items, 0, 0)); n != len(items) {
+		t.Errorf("no limits kept %d items, want %d", n, len(items))
+	}
+}
+
+func TestScoreItem(t *testing.T) {
+	terms := tokenize("AI in Healthcare")
+	if got := scoreItem("Healthcare AI robots", "", "https://x/img.jpg", terms); got != 2 {
+		t.Errorf("scoreItem() = %d, want 2", got)
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestSearchImages_Locale(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      Options
+		noResults bool // the first request finds nothing
+		want      []url.Values
+	}{
+		{
+			name: "no locale",
+			want: []url.Values{{}},
+		},
+		{
+			name: "region and language",
+			opts: Options{Region: "FR", Language: "fr"},
+			want: []url.Values{{"gl": {"fr"}, "cr": {"countryFR"}, "hl": {"fr"}}},
+		},
+		{
+			name:      "restriction retried as bias only",
+			opts:      Options{Region: "is"},
+			noResults: true,
+			want:      []url.Values{{"gl": {"is"}, "cr": {"countryIS"}}, {"gl": {"is"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []url.Values
+			tt.opts.Client = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+				q := url.Values{}
+				for _, k := range []string{"gl", "cr", "hl"} {
+					if r.URL.Query().Has(k) {
+						q.Set(k, r.URL.Query().Get(k))
+					}
+				}
+				got = append(got, q)
+				body := `{"items":[{"link":"https://a/1.jpg"}]}`
+				if tt.noResults && len(got) == 1 {
+					body = `{}`
+				}
+				return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body)), Header: http.Header{}}, nil
+			})}
+			if _, err := SearchImages(context.Background(), "key", "cx", "eiffel tower", tt.opts); err != nil {
+				t.Fatalf("SearchImages() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("locale parameters = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckLocale(t *testing.T) {
+	tests := []struct {
+		region, language string
+		wantErr          bool
+	}{
+		{"", "", false},
+		{"fr", "fr", false},
+		{"BR", "pt-BR", false},
+		{"tw", "zh-Hant", false},
+		{"fra", "", true},
+		{"f1", "", true},
+		{"", "french", true},
+		{"", "pt_BR", true},
+		{"", "pt-", true},
+	}
+	for _, tt := range tests {
+		if err := CheckLocale(tt.region, tt.language); (err != nil) != tt.wantErr {
+			t.Errorf("CheckLocale(%q, %q) error = %v, wantErr %v", tt.region, tt.language, err, tt.wantErr)
+		}
+	}
+}
+
+func TestRankBySimilarity(t *testing.T) {
+	cands := []Candidate{
+		{Item: Item{Title: "Cloud storage pricing", Link: "https://a/1.jpg"}, Score: 3},
+		{Item: Item{Title: "Team trust", Snippet: "colleagues collaborating", Link: "https://a/2.jpg"}, Score: 1},
+		{Item: Item{Link: "https://a/3.jpg"}, Score: 1},
+	}
+	vectors := map[string][]float32{
+		"Psychological safety":                 {1, 0},
+		"Cloud storage pricing":                {0, 1},
+		"Team trust\ncolleagues collaborating": {0.9, 0.1},
+		"https://a/3.jpg":                      {0.5, 0.5},
+	}
+	embed := func(_ context.Context, texts []string) ([][]float32, error) {
+		var out [][]float32
+		for _, text := range texts {
+			out = append(out, vectors[text])
+		}
+		return out, nil
+	}
+	got, err := RankBySimilarity(context.Background(), cands, "Psychological safety", embed)
+	if err != nil {
+		t.Fatalf("RankBySimilarity: %v", err)
+	}
+	var links []string
+	for _, c := range got {
+		links = append(links, c.Link)
+	}
+	if want := []string{"https://a/2.jpg", "https://a/3.jpg", "https://a/1.jpg"}; !reflect.DeepEqual(links, want) {
+		t.Errorf("ranked %v, want %v", links, want)
+	}
+	if got[0].Similarity < 0.99 || got[2].Similarity != 0 {
+		t.Errorf("similarities = %g, %g, want about 1 and 0", got[0].Similarity, got[2].Similarity)
+	}
+
+	failing := func(context.Context, []string) ([][]float32, error) { return nil, errors.New("quota") }
+	if got, err := RankBySimilarity(context.Background(), cands, "x", failing); err == nil || got[0].Link != "https://a/1.jpg" {
+		t.Errorf("failed embed = %v, %v; want an error and the original order", got[0].Link, err)
+	}
+}
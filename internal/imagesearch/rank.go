@@ -0,0 +1,69 @@
+package imagesearch
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Embedder returns one embedding vector per text, in order.
+type Embedder func(ctx context.Context, texts []string) ([][]float32, error)
+
+// RankBySimilarity re-ranks cands, best first, by the cosine similarity between the
+// embedding of about (a topic's title and summary) and that of each candidate's title and
+// snippet, which it stores in Similarity. Unlike the word-match Score, this ranks an image
+// of a related concept above one that merely repeats a query word, which matters most for
+// abstract topics. On an error cands is returned unchanged.
+func RankBySimilarity(ctx context.Context, cands []Candidate, about string, embed Embedder) ([]Candidate, error) {
+	if len(cands) == 0 {
+		return cands, nil
+	}
+	texts := []string{about}
+	for _, c := range cands {
+		texts = append(texts, candidateText(c.Item))
+	}
+	vecs, err := embed(ctx, texts)
+	if err != nil {
+		return cands, fmt.Errorf("embed image candidates: %w", err)
+	}
+	if len(vecs) != len(texts) {
+		return cands, fmt.Errorf("embed image candidates: got %d embeddings for %d texts", len(vecs), len(texts))
+	}
+	ranked := make([]Candidate, len(cands))
+	for i, c := range cands {
+		c.Similarity = cosine(vecs[0], vecs[i+1])
+		ranked[i] = c
+	}
+	// Stable keeps the word-match ranking as the tie-breaker
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].Similarity > ranked[j].Similarity })
+	return ranked, nil
+}
+
+// candidateText is what a result is embedded by: its title and snippet, or its link's
+// path when CSE gives neither.
+func candidateText(it Item) string {
+	if text := strings.TrimSpace(it.Title + "\n" + it.Snippet); text != "" {
+		return text
+	}
+	return it.Link
+}
+
+// cosine is the cosine similarity of a and b, or 0 when either is empty, zero, or their
+// lengths differ.
+func cosine(a, b []float32) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(na*nb)
+}
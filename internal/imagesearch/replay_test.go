@@ -0,0 +1,37 @@
+package imagesearch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gogemini-practices/internal/vcr"
+)
+
+// TestSearchImages_Replay runs a real Custom Search query from a recorded cassette. Record it
+// with VCR_MODE=record, CSE_API_KEY, and CSE_CX set.
+func TestSearchImages_Replay(t *testing.T) {
+	rec := vcr.ForTest(t, filepath.Join("testdata", "cassettes", "search_images.json"), nil)
+	key, cx := "replayed", rec.Var("cx")
+	if rec.Mode() == vcr.Record {
+		key, cx = os.Getenv("CSE_API_KEY"), os.Getenv("CSE_CX")
+		if key == "" || cx == "" {
+			t.Fatal("recording needs CSE_API_KEY and CSE_CX")
+		}
+		rec.SetVar("cx", cx)
+	}
+
+	cands, err := SearchImages(context.Background(), key, cx, "solar panels on a roof", Options{ImgSize: "large", Safe: "active", MinWidth: 640, MinHeight: 360, Client: rec.Client()})
+	if err != nil {
+		t.Fatalf("SearchImages() error = %v", err)
+	}
+	for i, c := range cands {
+		if c.Image.Width < 640 || c.Image.Height < 360 {
+			t.Errorf("candidate %d is %dx%d, below the minimum", i, c.Image.Width, c.Image.Height)
+		}
+		if i > 0 && c.Score > cands[i-1].Score {
+			t.Errorf("candidates not ranked: %d after %d", c.Score, cands[i-1].Score)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"google.golang.org/genai"
+)
+
+// ErrNoFixture is returned by a fixture client for a call no fixture matches.
+var ErrNoFixture = errors.New("no fixture matches the call")
+
+// Fixture is one canned reply of a fixture directory, read from a <name>.json file. A call
+// gets the reply of the first fixture, by file name, whose Model and Contains both match;
+// empty ones match anything.
+type Fixture struct {
+	Model    string `json:"model,omitempty"`    // exact model name
+	Contains string `json:"contains,omitempty"` // text the prompt must include
+	// The reply: Text verbatim, or Reply as JSON text, plus an optional Image file next
+	// to the fixture (PNG, JPEG, or GIF).
+	Text  string          `json:"text,omitempty"`
+	Reply json.RawMessage `json:"reply,omitempty"`
+	Image string          `json:"image,omitempty"`
+
+	name  string
+	image []byte
+}
+
+// fixtureModels serves Models calls from fixtures, without network or API key.
+type fixtureModels struct {
+	fixtures []Fixture
+}
+
+// LoadFixtures reads every *.json fixture of dir, in file name order.
+func LoadFixtures(dir string) ([]Fixture, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no *.json fixtures in %s", dir)
+	}
+	sort.Strings(paths)
+	var fixtures []Fixture
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		var f Fixture
+		if err := json.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("fixture %s: %w", p, err)
+		}
+		f.name = filepath.Base(p)
+		if f.Image != "" {
+			if f.image, err = os.ReadFile(filepath.Join(dir, f.Image)); err != nil {
+				return nil, fmt.Errorf("fixture %s: %w", p, err)
+			}
+		}
+		if f.Text == "" && len(f.Reply) == 0 && f.image == nil {
+			return nil, fmt.Errorf("fixture %s has no text, reply, or image", p)
+		}
+		fixtures = append(fixtures, f)
+	}
+	return fixtures, nil
+}
+
+// OpenFixtures makes the process's client for apiKey one that answers from the fixtures
+// in dir, so every pass that calls Open with that key, image generation included, runs
+// offline. Embeddings need no fixture: each text gets a vector hashed from its words.
+func OpenFixtures(apiKey, dir string, opts Options) (*Client, error) {
+	fixtures, err := LoadFixtures(dir)
+	if err != nil {
+		return nil, err
+	}
+	c := New(&fixtureModels{fixtures: fixtures}, opts)
+	openMu.Lock()
+	defer openMu.Unlock()
+	clients[apiKey] = c
+	return c, nil
+}
+
+func (m *fixtureModels) GenerateContent(ctx context.Context, model string, contents []*genai.Content, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	prompt := contentsText(contents)
+	for _, f := range m.fixtures {
+		if f.Model != "" && f.Model != model || !strings.Contains(prompt, f.Contains) {
+			continue
+		}
+		text := f.Text
+		if text == "" && len(f.Reply) > 0 {
+			text = string(f.Reply)
+		}
+		var parts []*genai.Part
+		if text != "" {
+			parts = append(parts, genai.NewPartFromText(text))
+		}
+		if f.image != nil {
+			parts = append(parts, genai.NewPartFromBytes(f.image, http.DetectContentType(f.image)))
+		}
+		return &genai.GenerateContentResponse{
+			Candidates: []*genai.Candidate{{Content: genai.NewContentFromParts(parts, genai.RoleModel), FinishReason: genai.FinishReasonStop}},
+			UsageMetadata: &genai.GenerateContentResponseUsageMetadata{
+				PromptTokenCount:     int32(len(prompt) / 4),
+				CandidatesTokenCount: int32(len(text) / 4),
+				TotalTokenCount:      int32((len(prompt) + len(text)) / 4),
+			},
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: model %s, prompt %q", ErrNoFixture, model, truncate(prompt, 80))
+}
+
+// embedDims is the size of fixture embeddings.
+const embedDims = 64
+
+func (m *fixtureModels) EmbedContent(ctx context.Context, model string, contents []*genai.Content, config *genai.EmbedContentConfig) (*genai.EmbedContentResponse, error) {
+	res := &genai.EmbedContentResponse{}
+	for _, c := range contents {
+		res.Embeddings = append(res.Embeddings, &genai.ContentEmbedding{Values: hashEmbedding(contentsText([]*genai.Content{c}))})
+	}
+	return res, nil
+}
+
+// hashEmbedding spreads text's lowercased words over embedDims buckets and normalizes the
+// result, so texts sharing words come out similar.
+func hashEmbedding(text string) []float32 {
+	v := make([]float32, embedDims)
+	for _, w := range strings.Fields(strings.ToLower(text)) {
+		h := fnv.New32a()
+		h.Write([]byte(w))
+		v[h.Sum32()%embedDims]++
+	}
+	var norm float64
+	for _, x := range v {
+		norm += float64(x * x)
+	}
+	if norm > 0 {
+		for i := range v {
+			v[i] /= float32(math.Sqrt(norm))
+		}
+	}
+	return v
+}
+
+func contentsText(contents []*genai.Content) string {
+	var b strings.Builder
+	for _, c := range contents {
+		if c == nil {
+			continue
+		}
+		for _, p := range c.Parts {
+			if p != nil && p.Text != "" {
+				b.WriteString(p.Text)
+				b.WriteString("\n")
+			}
+		}
+	}
+	return b.String()
+}
+
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n]) + "…"
+	}
+	return s
+}
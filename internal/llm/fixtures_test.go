@@ -0,0 +1,81 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/genai"
+)
+
+func TestOpenFixtures(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"10-classifier.json": `{"contains": "TRUE or FALSE", "text": "FALSE"}`,
+		"20-outline.json":    `{"contains": "planner", "reply": [{"topic": "A", "summary": "a"}]}`,
+		"30-image.json":      `{"model": "image-model", "image": "img.gif"}`,
+		"notes.txt":          "not a fixture",
+		"img.gif":            "GIF89a",
+	}
+	for name, body := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c, err := OpenFixtures("fixture-key", dir, Options{})
+	if err != nil {
+		t.Fatalf("OpenFixtures() error = %v", err)
+	}
+	ctx := context.Background()
+	if shared, err := Open(ctx, "fixture-key", Options{}); err != nil || shared != c {
+		t.Errorf("Open() with the fixture key = %p, %v; want the fixture client %p", shared, err, c)
+	}
+
+	tests := []struct {
+		model, prompt string
+		wantText      string
+		wantImage     bool
+		wantErr       error
+	}{
+		{model: "m", prompt: "Return only TRUE or FALSE.", wantText: "FALSE"},
+		{model: "m", prompt: "You are an expert presentation planner.", wantText: `[{"topic": "A", "summary": "a"}]`},
+		{model: "image-model", prompt: "A calm office", wantImage: true},
+		{model: "m", prompt: "Something else", wantErr: ErrNoFixture},
+	}
+	for _, tt := range tests {
+		res, err := c.GenerateContent(ctx, tt.model, genai.Text(tt.prompt), nil)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%q: err = %v, want %v", tt.prompt, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if got := res.Text(); got != tt.wantText {
+			t.Errorf("%q: text = %q, want %q", tt.prompt, got, tt.wantText)
+		}
+		var image bool
+		for _, p := range res.Candidates[0].Content.Parts {
+			image = image || p.InlineData != nil && p.InlineData.MIMEType == "image/gif"
+		}
+		if image != tt.wantImage {
+			t.Errorf("%q: image = %v, want %v", tt.prompt, image, tt.wantImage)
+		}
+	}
+
+	emb, err := c.EmbedContent(ctx, "embed", []*genai.Content{genai.NewContentFromText("remote work", genai.RoleUser), genai.NewContentFromText("remote work", genai.RoleUser)}, nil)
+	if err != nil || len(emb.Embeddings) != 2 || len(emb.Embeddings[0].Values) != embedDims {
+		t.Fatalf("EmbedContent() = %+v, %v; want two %d-value embeddings", emb, err, embedDims)
+	}
+	for i, v := range emb.Embeddings[0].Values {
+		if v != emb.Embeddings[1].Values[i] {
+			t.Fatal("same text: want the same embedding")
+		}
+	}
+
+	if _, err := OpenFixtures("other-key", t.TempDir(), Options{}); err == nil {
+		t.Error("empty directory: want an error")
+	}
+}
@@ -0,0 +1,304 @@
+// Package llm wraps Gemini model calls in one client shared by every pass of a run: the
+// outline, classifier, and optional calls in main, image generation in picturegen, and
+// embeddings. Transient failures (rate limits, 5xx, network errors) are retried with
+// backoff; a model that keeps failing trips a circuit breaker so later calls fail fast
+// instead of each waiting out its retries; and calls, retries, errors, and latency are
+// counted per model.
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// ErrCircuitOpen is returned, wrapping the model's last error, while a model's breaker is
+// open.
+var ErrCircuitOpen = errors.New("model temporarily unavailable after repeated failures")
+
+// Models is the part of genai.Models the client calls; *genai.Models implements it.
+type Models interface {
+	GenerateContent(ctx context.Context, model string, contents []*genai.Content, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error)
+	EmbedContent(ctx context.Context, model string, contents []*genai.Content, config *genai.EmbedContentConfig) (*genai.EmbedContentResponse, error)
+}
+
+// Options tunes retries and the breaker. The zero value gives the defaults.
+type Options struct {
+	// Retries is how many times a transient failure is retried (0 = DefaultRetries; < 0
+	// never retries).
+	Retries int
+	// BaseDelay is the first backoff, doubled on each retry up to MaxDelay, with jitter.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// FailureThreshold consecutive failed calls to a model open its breaker for Cooldown;
+	// then one trial call decides whether it closes again.
+	FailureThreshold int
+	Cooldown         time.Duration
+	HTTPClient       *http.Client // for Open; nil uses the default
+
+	sleep func(context.Context, time.Duration) error // tests
+	now   func() time.Time
+}
+
+// Defaults for Options.
+const (
+	DefaultRetries          = 2
+	DefaultBaseDelay        = 500 * time.Millisecond
+	DefaultMaxDelay         = 8 * time.Second
+	DefaultFailureThreshold = 5
+	DefaultCooldown         = 30 * time.Second
+)
+
+func (o Options) withDefaults() Options {
+	if o.Retries == 0 {
+		o.Retries = DefaultRetries
+	}
+	o.Retries = max(o.Retries, 0)
+	if o.BaseDelay <= 0 {
+		o.BaseDelay = DefaultBaseDelay
+	}
+	if o.MaxDelay <= 0 {
+		o.MaxDelay = DefaultMaxDelay
+	}
+	if o.FailureThreshold <= 0 {
+		o.FailureThreshold = DefaultFailureThreshold
+	}
+	if o.Cooldown <= 0 {
+		o.Cooldown = DefaultCooldown
+	}
+	if o.sleep == nil {
+		o.sleep = sleep
+	}
+	if o.now == nil {
+		o.now = time.Now
+	}
+	return o
+}
+
+// Stats are one model's counters.
+type Stats struct {
+	Calls         int   `json:"calls"`                    // calls made, not counting retries
+	Retries       int   `json:"retries,omitempty"`        // extra attempts after transient failures
+	Errors        int   `json:"errors,omitempty"`         // calls that failed in the end
+	ShortCircuits int   `json:"short_circuits,omitempty"` // calls refused by an open breaker
+	LatencyMs     int64 `json:"latency_ms"`               // total over all calls, backoff included
+	MaxLatencyMs  int64 `json:"max_latency_ms"`
+}
+
+// Client makes model calls with retries and per-model circuit breaking. It is safe for
+// concurrent use.
+type Client struct {
+	models Models
+	opts   Options
+
+	mu       sync.Mutex
+	breakers map[string]*breaker
+	stats    map[string]*Stats
+}
+
+type breaker struct {
+	failures  int       // consecutive
+	openUntil time.Time // zero while closed
+	trial     bool      // a half-open trial call is in flight
+	lastErr   error
+}
+
+// New wraps models.
+func New(models Models, opts Options) *Client {
+	return &Client{models: models, opts: opts.withDefaults(), breakers: map[string]*breaker{}, stats: map[string]*Stats{}}
+}
+
+var (
+	openMu  sync.Mutex
+	clients = map[string]*Client{}
+)
+
+// Open returns the process's client for a Gemini API key, creating it with opts on first
+// use; later calls with the same key share its breakers and stats whatever opts they pass.
+func Open(ctx context.Context, apiKey string, opts Options) (*Client, error) {
+	if apiKey == "" {
+		return nil, errors.New("apiKey is required")
+	}
+	openMu.Lock()
+	defer openMu.Unlock()
+	if c, ok := clients[apiKey]; ok {
+		return c, nil
+	}
+	gc, err := genai.NewClient(ctx, &genai.ClientConfig{APIKey: apiKey, Backend: genai.BackendGeminiAPI, HTTPClient: opts.HTTPClient})
+	if err != nil {
+		return nil, err
+	}
+	c := New(gc.Models, opts)
+	clients[apiKey] = c
+	return c, nil
+}
+
+// GenerateContent calls the model, retrying transient failures.
+func (c *Client) GenerateContent(ctx context.Context, model string, contents []*genai.Content, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	var res *genai.GenerateContentResponse
+	err := c.do(ctx, model, func() error {
+		var err error
+		res, err = c.models.GenerateContent(ctx, model, contents, config)
+		return err
+	})
+	return res, err
+}
+
+// EmbedContent calls the embedding model, retrying transient failures.
+func (c *Client) EmbedContent(ctx context.Context, model string, contents []*genai.Content, config *genai.EmbedContentConfig) (*genai.EmbedContentResponse, error) {
+	var res *genai.EmbedContentResponse
+	err := c.do(ctx, model, func() error {
+		var err error
+		res, err = c.models.EmbedContent(ctx, model, contents, config)
+		return err
+	})
+	return res, err
+}
+
+// Stats returns a copy of every model's counters.
+func (c *Client) Stats() map[string]Stats {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.stats) == 0 {
+		return nil
+	}
+	out := make(map[string]Stats, len(c.stats))
+	for m, s := range c.stats {
+		out[m] = *s
+	}
+	return out
+}
+
+func (c *Client) do(ctx context.Context, model string, call func() error) error {
+	start := c.opts.now()
+	if err := c.admit(model); err != nil {
+		return err
+	}
+	var err error
+	retries := 0
+	for attempt := 0; ; attempt++ {
+		if err = call(); err == nil || !Transient(err) || attempt >= c.opts.Retries || ctx.Err() != nil {
+			break
+		}
+		if serr := c.opts.sleep(ctx, c.backoff(attempt)); serr != nil {
+			break
+		}
+		retries++
+	}
+	c.done(model, err, retries, c.opts.now().Sub(start))
+	return err
+}
+
+// admit counts the call and refuses it while the model's breaker is open. Once the
+// cooldown is over, one call at a time is let through as the trial.
+func (c *Client) admit(model string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.statsFor(model)
+	s.Calls++
+	b := c.breakers[model]
+	if b == nil || b.openUntil.IsZero() {
+		return nil
+	}
+	if c.opts.now().Before(b.openUntil) || b.trial {
+		s.ShortCircuits++
+		s.Errors++
+		return fmt.Errorf("%s: %w: %w", model, ErrCircuitOpen, b.lastErr)
+	}
+	b.trial = true
+	return nil
+}
+
+// done records a call's outcome. Only transient failures count toward the breaker: a bad
+// request says nothing about the model's health.
+func (c *Client) done(model string, err error, retries int, took time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.statsFor(model)
+	s.Retries += retries
+	ms := took.Milliseconds()
+	s.LatencyMs += ms
+	s.MaxLatencyMs = max(s.MaxLatencyMs, ms)
+	if err != nil {
+		s.Errors++
+	}
+	b := c.breakers[model]
+	if b == nil {
+		b = &breaker{}
+		c.breakers[model] = b
+	}
+	wasTrial := b.trial
+	b.trial = false
+	switch {
+	case err == nil || !Transient(err):
+		b.failures, b.openUntil, b.lastErr = 0, time.Time{}, nil
+	default:
+		b.failures++
+		b.lastErr = err
+		if wasTrial || b.failures >= c.opts.FailureThreshold {
+			b.openUntil = c.opts.now().Add(c.opts.Cooldown)
+		}
+	}
+}
+
+func (c *Client) statsFor(model string) *Stats {
+	s := c.stats[model]
+	if s == nil {
+		s = &Stats{}
+		c.stats[model] = s
+	}
+	return s
+}
+
+// backoff is the wait before retry attempt+1: BaseDelay doubled per attempt, capped at
+// MaxDelay, with up to 50% jitter so concurrent callers spread out.
+func (c *Client) backoff(attempt int) time.Duration {
+	d := c.opts.BaseDelay << attempt
+	if d <= 0 || d > c.opts.MaxDelay {
+		d = c.opts.MaxDelay
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+// Transient reports whether err is worth retrying: rate limits, server errors, and
+// network failures. Cancellation and the caller's deadline are not.
+func Transient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrCircuitOpen) {
+		return false
+	}
+	var apiErr genai.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.Code {
+		case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		switch apiErr.Status {
+		case "RESOURCE_EXHAUSTED", "UNAVAILABLE", "INTERNAL", "DEADLINE_EXCEEDED":
+			return true
+		}
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
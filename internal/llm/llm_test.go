@@ -0,0 +1,163 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// fakeModels replies with errs in turn, then successes.
+type fakeModels struct {
+	errs  []error
+	calls int
+}
+
+func (f *fakeModels) next() error {
+	f.calls++
+	if len(f.errs) == 0 {
+		return nil
+	}
+	err := f.errs[0]
+	f.errs = f.errs[1:]
+	return err
+}
+
+func (f *fakeModels) GenerateContent(ctx context.Context, model string, contents []*genai.Content, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	if err := f.next(); err != nil {
+		return nil, err
+	}
+	return &genai.GenerateContentResponse{}, nil
+}
+
+func (f *fakeModels) EmbedContent(ctx context.Context, model string, contents []*genai.Content, config *genai.EmbedContentConfig) (*genai.EmbedContentResponse, error) {
+	if err := f.next(); err != nil {
+		return nil, err
+	}
+	return &genai.EmbedContentResponse{}, nil
+}
+
+// testClient never sleeps and runs on a clock the test moves.
+func testClient(f *fakeModels, opts Options) (*Client, *time.Time) {
+	now := time.Unix(1_700_000_000, 0)
+	opts.sleep = func(context.Context, time.Duration) error { return nil }
+	opts.now = func() time.Time { return now }
+	return New(f, opts), &now
+}
+
+var (
+	rateLimited = genai.APIError{Code: 429, Status: "RESOURCE_EXHAUSTED"}
+	badRequest  = genai.APIError{Code: 400, Status: "INVALID_ARGUMENT"}
+)
+
+func TestClient_Retries(t *testing.T) {
+	tests := []struct {
+		name      string
+		errs      []error
+		opts      Options
+		wantErr   bool
+		wantCalls int
+		want      Stats
+	}{
+		{name: "success", wantCalls: 1, want: Stats{Calls: 1}},
+		{name: "transient then success", errs: []error{rateLimited, genai.APIError{Code: 503}}, wantCalls: 3, want: Stats{Calls: 1, Retries: 2}},
+		{name: "retries run out", errs: []error{rateLimited, rateLimited, rateLimited, rateLimited}, wantErr: true, wantCalls: 3, want: Stats{Calls: 1, Retries: 2, Errors: 1}},
+		{name: "bad request isn't retried", errs: []error{badRequest}, wantErr: true, wantCalls: 1, want: Stats{Calls: 1, Errors: 1}},
+		{name: "retries off", errs: []error{rateLimited}, opts: Options{Retries: -1}, wantErr: true, wantCalls: 1, want: Stats{Calls: 1, Errors: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &fakeModels{errs: tt.errs}
+			c, _ := testClient(f, tt.opts)
+			_, err := c.GenerateContent(context.Background(), "m", nil, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if f.calls != tt.wantCalls {
+				t.Errorf("model called %d times, want %d", f.calls, tt.wantCalls)
+			}
+			if got := c.Stats()["m"]; got != tt.want {
+				t.Errorf("stats = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_CircuitBreaker(t *testing.T) {
+	ctx := context.Background()
+	var errs []error
+	for range 4 {
+		errs = append(errs, rateLimited)
+	}
+	f := &fakeModels{errs: errs}
+	c, now := testClient(f, Options{Retries: -1, FailureThreshold: 3, Cooldown: time.Minute})
+
+	for i := range 3 {
+		if _, err := c.GenerateContent(ctx, "m", nil, nil); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("call %d: err = %v, want the model's error", i, err)
+		}
+	}
+	_, err := c.GenerateContent(ctx, "m", nil, nil)
+	if !errors.Is(err, ErrCircuitOpen) || f.calls != 3 {
+		t.Fatalf("after 3 failures: err = %v, model calls %d; want a short circuit", err, f.calls)
+	}
+	var apiErr genai.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != 429 {
+		t.Errorf("short circuit %v doesn't wrap the last error", err)
+	}
+	if _, err := c.GenerateContent(ctx, "other", nil, nil); err == nil {
+		t.Error("other model: want its own (failing) call, not a success") // the fake's 4th error
+	} else if errors.Is(err, ErrCircuitOpen) {
+		t.Error("other model: breakers are per model")
+	}
+
+	// After the cooldown a failed trial reopens the breaker at once; a good one closes it
+	*now = now.Add(time.Minute)
+	f.errs = []error{rateLimited}
+	if _, err := c.GenerateContent(ctx, "m", nil, nil); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("trial: err = %v, want the model's error", err)
+	}
+	if _, err := c.GenerateContent(ctx, "m", nil, nil); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("after a failed trial: err = %v, want a short circuit", err)
+	}
+	*now = now.Add(time.Minute)
+	if _, err := c.GenerateContent(ctx, "m", nil, nil); err != nil {
+		t.Fatalf("good trial: %v", err)
+	}
+	if _, err := c.EmbedContent(ctx, "m", nil, nil); err != nil {
+		t.Fatalf("after a good trial: %v", err)
+	}
+	if got := c.Stats()["m"]; got.Calls != 8 || got.ShortCircuits != 2 || got.Errors != 6 {
+		t.Errorf("stats = %+v, want 8 calls, 2 short circuits, 6 errors", got)
+	}
+}
+
+func TestTransient(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{rateLimited, true},
+		{fmt.Errorf("outline: %w", genai.APIError{Code: 500}), true},
+		{genai.APIError{Code: 499, Status: "UNAVAILABLE"}, true},
+		{badRequest, false},
+		{&timeoutErr{}, true},
+		{context.Canceled, false},
+		{errors.New("invalid JSON"), false},
+	}
+	for _, tt := range tests {
+		if got := Transient(tt.err); got != tt.want {
+			t.Errorf("Transient(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+type timeoutErr struct{}
+
+func (*timeoutErr) Error() string   { return "i/o timeout" }
+func (*timeoutErr) Timeout() bool   { return true }
+func (*timeoutErr) Temporary() bool { return true }
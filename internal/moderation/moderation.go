@@ -0,0 +1,98 @@
+package moderation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/api/vision/v1"
+)
+
+// Level selects how aggressively images are rejected.
+type Level string
+
+const (
+	Off      Level = "off"
+	Standard Level = "standard" // reject LIKELY and above; check errors keep the image
+	Strict   Level = "strict"   // reject POSSIBLE and above; check errors reject (classroom decks)
+)
+
+// ParseLevel validates a moderation level; empty input returns Off.
+func ParseLevel(s string) (Level, error) {
+	switch l := Level(strings.ToLower(strings.TrimSpace(s))); l {
+	case "":
+		return Off, nil
+	case Off, Standard, Strict:
+		return l, nil
+	default:
+		return "", fmt.Errorf("unknown moderation level %q (off|standard|strict)", s)
+	}
+}
+
+// likelihoodRank orders Vision likelihood values from least to most likely.
+var likelihoodRank = map[string]int{
+	"UNKNOWN":       0,
+	"VERY_UNLIKELY": 1,
+	"UNLIKELY":      2,
+	"POSSIBLE":      3,
+	"LIKELY":        4,
+	"VERY_LIKELY":   5,
+}
+
+// Verdict is the outcome of a moderation check.
+type Verdict struct {
+	Allowed bool
+	Reason  string // set when rejected, e.g. "racy=LIKELY"
+}
+
+// Evaluate applies the level's threshold to a SafeSearch annotation. Adult, violence,
+// and racy content are checked; medical and spoof are informational only.
+func Evaluate(ann *vision.SafeSearchAnnotation, level Level) Verdict {
+	if level == Off || ann == nil {
+		return Verdict{Allowed: true}
+	}
+	threshold := likelihoodRank["LIKELY"]
+	if level == Strict {
+		threshold = likelihoodRank["POSSIBLE"]
+	}
+	var reasons []string
+	for _, c := range []struct{ name, v string }{{"adult", ann.Adult}, {"violence", ann.Violence}, {"racy", ann.Racy}} {
+		if likelihoodRank[c.v] >= threshold {
+			reasons = append(reasons, c.name+"="+c.v)
+		}
+	}
+	if len(reasons) > 0 {
+		return Verdict{Allowed: false, Reason: strings.Join(reasons, ", ")}
+	}
+	return Verdict{Allowed: true}
+}
+
+// CheckURL runs Vision SafeSearch on a publicly reachable image URL. When the check itself
+// fails, Standard keeps the image (returning the error for logging) and Strict rejects it.
+func CheckURL(ctx context.Context, svc *vision.Service, imageURL string, level Level) (Verdict, error) {
+	if level == Off {
+		return Verdict{Allowed: true}, nil
+	}
+	failed := func(err error) (Verdict, error) {
+		return Verdict{Allowed: level != Strict, Reason: "moderation check failed"}, err
+	}
+	if svc == nil {
+		return failed(fmt.Errorf("vision service is nil"))
+	}
+	req := &vision.BatchAnnotateImagesRequest{Requests: []*vision.AnnotateImageRequest{{
+		Image:    &vision.Image{Source: &vision.ImageSource{ImageUri: imageURL}},
+		Features: []*vision.Feature{{Type: "SAFE_SEARCH_DETECTION"}},
+	}}}
+	resp, err := svc.Images.Annotate(req).Context(ctx).Do()
+	if err != nil {
+		return failed(fmt.Errorf("vision annotate: %w", err))
+	}
+	if resp == nil || len(resp.Responses) == 0 || resp.Responses[0] == nil {
+		return failed(fmt.Errorf("missing vision response"))
+	}
+	r := resp.Responses[0]
+	if r.Error != nil && r.Error.Message != "" {
+		return failed(fmt.Errorf("vision: %s", r.Error.Message))
+	}
+	return Evaluate(r.SafeSearchAnnotation, level), nil
+}
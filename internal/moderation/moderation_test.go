@@ -0,0 +1,47 @@
+package moderation
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/api/vision/v1"
+)
+
+func TestEvaluate(t *testing.T) {
+	possibleRacy := &vision.SafeSearchAnnotation{Adult: "VERY_UNLIKELY", Violence: "UNLIKELY", Racy: "POSSIBLE", Medical: "VERY_LIKELY"}
+	likelyViolence := &vision.SafeSearchAnnotation{Adult: "UNLIKELY", Violence: "LIKELY", Racy: "UNLIKELY"}
+
+	tests := []struct {
+		name    string
+		ann     *vision.SafeSearchAnnotation
+		level   Level
+		allowed bool
+	}{
+		{name: "standard allows possible", ann: possibleRacy, level: Standard, allowed: true},
+		{name: "strict rejects possible", ann: possibleRacy, level: Strict, allowed: false},
+		{name: "standard rejects likely", ann: likelyViolence, level: Standard, allowed: false},
+		{name: "off allows anything", ann: likelyViolence, level: Off, allowed: true},
+		{name: "missing annotation", ann: nil, level: Strict, allowed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := Evaluate(tt.ann, tt.level)
+			if v.Allowed != tt.allowed {
+				t.Errorf("Evaluate() = %+v, want allowed=%v", v, tt.allowed)
+			}
+			if !v.Allowed && v.Reason == "" {
+				t.Error("rejected verdict should carry a reason")
+			}
+		})
+	}
+}
+
+func TestCheckURL_FailurePolicy(t *testing.T) {
+	ctx := context.Background()
+	if v, err := CheckURL(ctx, nil, "https://example.com/a.png", Standard); err == nil || !v.Allowed {
+		t.Errorf("standard: got %+v, %v; want allowed with error", v, err)
+	}
+	if v, err := CheckURL(ctx, nil, "https://example.com/a.png", Strict); err == nil || v.Allowed {
+		t.Errorf("strict: got %+v, %v; want rejected with error", v, err)
+	}
+}
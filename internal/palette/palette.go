@@ -0,0 +1,135 @@
+package palette
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// MinContrast is the WCAG AA ratio required for normal-size text.
+const MinContrast = 4.5
+
+// Palette is a small set of hex colors ("#RRGGBB") applied across a deck.
+type Palette struct {
+	Primary    string `json:"primary"`    // titles and first chart series
+	Secondary  string `json:"secondary"`  // additional chart series
+	Accent     string `json:"accent"`     // bold/accent text and dividers
+	Text       string `json:"text"`       // body text
+	Background string `json:"background"` // slide background the others are checked against
+}
+
+// Default returns a neutral palette used when generation fails.
+func Default() Palette {
+	return Palette{Primary: "#1A73E8", Secondary: "#34A853", Accent: "#C5221F", Text: "#202124", Background: "#FFFFFF"}
+}
+
+// RGB parses "#RRGGBB" (or "RRGGBB", or "#RGB") into 0..1 channel values.
+func RGB(hex string) (r, g, b float64, err error) {
+	h := strings.TrimPrefix(strings.TrimSpace(hex), "#")
+	if len(h) == 3 {
+		h = string([]byte{h[0], h[0], h[1], h[1], h[2], h[2]})
+	}
+	if len(h) != 6 {
+		return 0, 0, 0, fmt.Errorf("invalid hex color %q", hex)
+	}
+	v, err := strconv.ParseUint(h, 16, 32)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid hex color %q", hex)
+	}
+	return float64(v>>16&0xFF) / 255, float64(v>>8&0xFF) / 255, float64(v&0xFF) / 255, nil
+}
+
+// Hex formats 0..1 channel values as "#RRGGBB".
+func Hex(r, g, b float64) string {
+	c := func(v float64) int { return int(math.Round(math.Max(0, math.Min(1, v)) * 255)) }
+	return fmt.Sprintf("#%02X%02X%02X", c(r), c(g), c(b))
+}
+
+// ContrastRatio returns the WCAG contrast ratio between two hex colors (1..21).
+func ContrastRatio(a, b string) (float64, error) {
+	la, err := luminance(a)
+	if err != nil {
+		return 0, err
+	}
+	lb, err := luminance(b)
+	if err != nil {
+		return 0, err
+	}
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05), nil
+}
+
+// Normalize fills missing or invalid colors from Default and darkens (or lightens,
+// on dark backgrounds) Primary, Accent, and Text until they meet MinContrast
+// against Background. Secondary is only used for chart series and is left as-is.
+func (p *Palette) Normalize() {
+	def := Default()
+	fix := func(v *string, fallback string) {
+		if _, _, _, err := RGB(*v); err != nil {
+			*v = fallback
+			return
+		}
+		r, g, b, _ := RGB(*v)
+		*v = Hex(r, g, b)
+	}
+	fix(&p.Background, def.Background)
+	fix(&p.Primary, def.Primary)
+	fix(&p.Secondary, def.Secondary)
+	fix(&p.Accent, def.Accent)
+	fix(&p.Text, def.Text)
+
+	bgLum, _ := luminance(p.Background)
+	darken := bgLum > 0.5
+	for _, v := range []*string{&p.Primary, &p.Accent, &p.Text} {
+		*v = ensureContrast(*v, p.Background, darken)
+	}
+}
+
+// SeriesColors returns the chart series colors: Primary, Secondary, and Accent, then the
+// same three blended halfway toward Background, so up to six series stay on palette.
+func (p Palette) SeriesColors() []string {
+	base := []string{p.Primary, p.Secondary, p.Accent}
+	out := append([]string(nil), base...)
+	br, bg, bb, err := RGB(p.Background)
+	if err != nil {
+		br, bg, bb = 1, 1, 1
+	}
+	for _, c := range base {
+		if r, g, b, err := RGB(c); err == nil {
+			out = append(out, Hex((r+br)/2, (g+bg)/2, (b+bb)/2))
+		}
+	}
+	return out
+}
+
+func ensureContrast(fg, bg string, darken bool) string {
+	r, g, b, _ := RGB(fg)
+	for i := 0; i < 20; i++ {
+		if cr, _ := ContrastRatio(Hex(r, g, b), bg); cr >= MinContrast {
+			break
+		}
+		if darken {
+			r, g, b = r*0.85, g*0.85, b*0.85
+		} else {
+			r, g, b = r+(1-r)*0.15, g+(1-g)*0.15, b+(1-b)*0.15
+		}
+	}
+	return Hex(r, g, b)
+}
+
+func luminance(hex string) (float64, error) {
+	r, g, b, err := RGB(hex)
+	if err != nil {
+		return 0, err
+	}
+	lin := func(c float64) float64 {
+		if c <= 0.03928 {
+			return c / 12.92
+		}
+		return math.Pow((c+0.055)/1.055, 2.4)
+	}
+	return 0.2126*lin(r) + 0.7152*lin(g) + 0.0722*lin(b), nil
+}
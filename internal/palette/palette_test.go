@@ -0,0 +1,59 @@
+package palette
+
+import (
+	"math"
+	"testing"
+)
+
+func TestContrastRatio(t *testing.T) {
+	cr, err := ContrastRatio("#000000", "#FFFFFF")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(cr-21) > 0.01 {
+		t.Errorf("ContrastRatio(black, white) = %v, want 21", cr)
+	}
+	if _, err := ContrastRatio("nope", "#FFFFFF"); err == nil {
+		t.Error("expected error for invalid hex")
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	p := Palette{Primary: "#FFEB3B", Secondary: "", Accent: "fc0", Text: "#777777", Background: "#FFFFFF"}
+	p.Normalize()
+
+	if p.Secondary != Default().Secondary {
+		t.Errorf("Secondary = %q, want default %q", p.Secondary, Default().Secondary)
+	}
+	for name, c := range map[string]string{"primary": p.Primary, "accent": p.Accent, "text": p.Text} {
+		cr, err := ContrastRatio(c, p.Background)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if cr < MinContrast {
+			t.Errorf("%s %s contrast %.2f < %.1f", name, c, cr, MinContrast)
+		}
+	}
+}
+
+func TestNormalize_DarkBackgroundLightens(t *testing.T) {
+	p := Palette{Primary: "#222222", Accent: "#333333", Text: "#111111", Secondary: "#444444", Background: "#000000"}
+	p.Normalize()
+	if cr, _ := ContrastRatio(p.Text, p.Background); cr < MinContrast {
+		t.Errorf("text %s contrast %.2f on dark background", p.Text, cr)
+	}
+}
+
+func TestSeriesColors(t *testing.T) {
+	p := Palette{Primary: "#000000", Secondary: "#FF0000", Accent: "#0000FF", Background: "#FFFFFF"}
+	want := []string{"#000000", "#FF0000", "#0000FF", "#808080", "#FF8080", "#8080FF"}
+	got := p.SeriesColors()
+	if len(got) != len(want) {
+		t.Fatalf("SeriesColors() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("SeriesColors()[%d] = %s, want %s", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package phash
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif" // register decoders for searched images
+	_ "image/jpeg"
+	_ "image/png"
+	"math"
+	"math/bits"
+	"sort"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// NearDuplicate is the default Hamming distance at or below which two hashes are
+// treated as the same picture (resized, recompressed, or lightly cropped copies).
+const NearDuplicate = 10
+
+const (
+	sampleSize = 32 // images are reduced to 32x32 grayscale before the DCT
+	hashSize   = 8  // the top-left 8x8 low-frequency coefficients form the hash
+)
+
+// Compute returns the 64-bit DCT perceptual hash of encoded image bytes.
+func Compute(data []byte) (uint64, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return 0, fmt.Errorf("decode image: %w", err)
+	}
+	return FromImage(img), nil
+}
+
+// FromImage returns the 64-bit DCT perceptual hash of an image.
+func FromImage(img image.Image) uint64 {
+	gray := image.NewGray(image.Rect(0, 0, sampleSize, sampleSize))
+	draw.ApproxBiLinear.Scale(gray, gray.Bounds(), img, img.Bounds(), draw.Src, nil)
+
+	var px [sampleSize][sampleSize]float64
+	for y := 0; y < sampleSize; y++ {
+		for x := 0; x < sampleSize; x++ {
+			px[y][x] = float64(gray.GrayAt(x, y).Y)
+		}
+	}
+
+	coeffs := make([]float64, 0, hashSize*hashSize)
+	for v := 0; v < hashSize; v++ {
+		for u := 0; u < hashSize; u++ {
+			coeffs = append(coeffs, dct(&px, u, v))
+		}
+	}
+	// Median of the AC coefficients; the DC term (index 0) only encodes overall brightness.
+	ac := append([]float64(nil), coeffs[1:]...)
+	sort.Float64s(ac)
+	median := (ac[len(ac)/2-1] + ac[len(ac)/2]) / 2
+
+	var h uint64
+	for i, c := range coeffs {
+		if c > median {
+			h |= 1 << uint(i)
+		}
+	}
+	return h
+}
+
+// Distance returns the Hamming distance between two hashes (0 = identical, 64 = opposite).
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// dct computes the (u, v) coefficient of the 2D DCT-II over the sample grid.
+func dct(px *[sampleSize][sampleSize]float64, u, v int) float64 {
+	sum := 0.0
+	for y := 0; y < sampleSize; y++ {
+		cy := math.Cos(float64(2*y+1) * float64(v) * math.Pi / (2 * sampleSize))
+		for x := 0; x < sampleSize; x++ {
+			sum += px[y][x] * math.Cos(float64(2*x+1)*float64(u)*math.Pi/(2*sampleSize)) * cy
+		}
+	}
+	return sum
+}
@@ -0,0 +1,50 @@
+package phash
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"golang.org/x/image/draw"
+)
+
+// pattern draws a deterministic gradient with a bright square whose position varies by seed.
+func pattern(w, h, seed int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			v := uint8((x*255/w + y*seed*40/h) % 256)
+			img.Set(x, y, color.RGBA{R: v, G: v / 2, B: 255 - v, A: 255})
+		}
+	}
+	sq := image.Rect(w*seed/8, h/4, w*seed/8+w/4, h/2)
+	draw.Draw(img, sq, image.White, image.Point{}, draw.Src)
+	return img
+}
+
+func TestFromImage_ResizedCopyIsNearDuplicate(t *testing.T) {
+	orig := pattern(400, 300, 1)
+	small := image.NewRGBA(image.Rect(0, 0, 160, 120))
+	draw.CatmullRom.Scale(small, small.Bounds(), orig, orig.Bounds(), draw.Src, nil)
+
+	if d := Distance(FromImage(orig), FromImage(small)); d > NearDuplicate {
+		t.Errorf("resized copy distance = %d, want <= %d", d, NearDuplicate)
+	}
+}
+
+func TestFromImage_DifferentImagesAreFar(t *testing.T) {
+	a := FromImage(pattern(400, 300, 1))
+	b := FromImage(pattern(400, 300, 5))
+	if d := Distance(a, b); d <= NearDuplicate {
+		t.Errorf("different images distance = %d, want > %d", d, NearDuplicate)
+	}
+}
+
+func TestDistance(t *testing.T) {
+	if d := Distance(0, ^uint64(0)); d != 64 {
+		t.Errorf("Distance(0, all ones) = %d, want 64", d)
+	}
+	if d := Distance(0b1011, 0b0001); d != 2 {
+		t.Errorf("Distance = %d, want 2", d)
+	}
+}
@@ -0,0 +1,103 @@
+package picturegen
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif" // register decoders for model output
+	_ "image/jpeg"
+	"image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// AspectRatio is a width:height ratio for generated images.
+type AspectRatio string
+
+const (
+	AspectWide     AspectRatio = "16:9"
+	AspectStandard AspectRatio = "4:3"
+	AspectSquare   AspectRatio = "1:1"
+)
+
+var aspectRatios = map[AspectRatio][2]int{
+	AspectWide:     {16, 9},
+	AspectStandard: {4, 3},
+	AspectSquare:   {1, 1},
+}
+
+// ImageConfig controls the framing of generated images. The zero value returns
+// the model output unchanged.
+type ImageConfig struct {
+	AspectRatio AspectRatio // optional: 16:9 | 4:3 | 1:1
+	Width       int         // optional target width in pixels; height follows the aspect ratio
+}
+
+func (c ImageConfig) validate() error {
+	if c.AspectRatio != "" {
+		if _, ok := aspectRatios[c.AspectRatio]; !ok {
+			return fmt.Errorf("unsupported aspect ratio %q (16:9|4:3|1:1)", c.AspectRatio)
+		}
+	}
+	if c.Width < 0 || c.Width > 4096 {
+		return fmt.Errorf("width %d out of range (0-4096)", c.Width)
+	}
+	return nil
+}
+
+// promptWithFraming asks the model for the requested framing up front so the
+// later crop removes as little of the subject as possible.
+func (c ImageConfig) promptWithFraming(prompt string) string {
+	if c.AspectRatio == "" {
+		return prompt
+	}
+	return fmt.Sprintf("%s\nCompose the image for a %s aspect ratio.", prompt, c.AspectRatio)
+}
+
+// fitImage decodes data, center-crops it to the configured aspect ratio, and scales it
+// to the configured width. Unchanged images keep their original encoding.
+func fitImage(data []byte, cfg ImageConfig) (*GeneratedImage, error) {
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode generated image: %w", err)
+	}
+	b := src.Bounds()
+	if cfg.AspectRatio == "" && cfg.Width == 0 {
+		return &GeneratedImage{Data: data, Width: b.Dx(), Height: b.Dy()}, nil
+	}
+
+	crop := b
+	if r, ok := aspectRatios[cfg.AspectRatio]; ok {
+		crop = centerCrop(b, r[0], r[1])
+	}
+	w, h := crop.Dx(), crop.Dy()
+	if cfg.Width > 0 {
+		h = int(float64(h) * float64(cfg.Width) / float64(w))
+		w = cfg.Width
+	}
+	if w == b.Dx() && h == b.Dy() && crop == b {
+		return &GeneratedImage{Data: data, Width: w, Height: h}, nil
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, crop, draw.Src, nil)
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, dst); err != nil {
+		return nil, fmt.Errorf("encode png: %w", err)
+	}
+	return &GeneratedImage{Data: buf.Bytes(), Width: w, Height: h}, nil
+}
+
+// centerCrop returns the largest rectangle centered in b with ratio rw:rh.
+func centerCrop(b image.Rectangle, rw, rh int) image.Rectangle {
+	w, h := b.Dx(), b.Dy()
+	if w*rh > h*rw { // too wide
+		nw := h * rw / rh
+		x0 := b.Min.X + (w-nw)/2
+		return image.Rect(x0, b.Min.Y, x0+nw, b.Max.Y)
+	}
+	nh := w * rh / rw
+	y0 := b.Min.Y + (h-nh)/2
+	return image.Rect(b.Min.X, y0, b.Max.X, y0+nh)
+}
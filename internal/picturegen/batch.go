@@ -0,0 +1,126 @@
+package picturegen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	genai "google.golang.org/genai"
+
+	"gogemini-practices/internal/llm"
+)
+
+// ScoreModel is the vision model used to rate candidates against their topic.
+const ScoreModel = "gemini-2.0-flash"
+
+// MaxCandidates caps how many images GenerateCandidates requests per prompt.
+const MaxCandidates = 4
+
+// Candidate is a generated image with its topic-relevance score (0-10).
+type Candidate struct {
+	*GeneratedImage
+	Score float64
+}
+
+// BatchResult holds the highest-scoring candidate and the remaining alternates, best first.
+type BatchResult struct {
+	Best       Candidate
+	Alternates []Candidate
+}
+
+// GenerateCandidates generates n images for the prompt concurrently, scores each against
+// the topic with a vision model, and returns the best plus alternates. Failed generations
+// are dropped; an error is returned only if none succeed. Scoring failures count as 0.
+func GenerateCandidates(ctx context.Context, prompt, topic, apiKey string, n int, cfg ImageConfig) (*BatchResult, error) {
+	if prompt == "" {
+		return nil, errors.New("prompt is required")
+	}
+	if apiKey == "" {
+		return nil, errors.New("apiKey is required")
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	if n <= 0 {
+		n = 1
+	}
+	if n > MaxCandidates {
+		n = MaxCandidates
+	}
+	if strings.TrimSpace(topic) == "" {
+		topic = prompt
+	}
+
+	client, err := llm.Open(ctx, apiKey, llm.Options{})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]Candidate, n)
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			res, err := generateWithRetry(ctx, client, prompt, cfg)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			img := res.image()
+			score, _ := scoreImage(ctx, client, img, topic)
+			results[i] = Candidate{GeneratedImage: img, Score: score}
+		}(i)
+	}
+	wg.Wait()
+
+	var ok []Candidate
+	for i, c := range results {
+		if errs[i] == nil && c.GeneratedImage != nil {
+			ok = append(ok, c)
+		}
+	}
+	if len(ok) == 0 {
+		return nil, fmt.Errorf("all %d candidates failed: %w", n, errors.Join(errs...))
+	}
+	sort.SliceStable(ok, func(a, b int) bool { return ok[a].Score > ok[b].Score })
+	return &BatchResult{Best: ok[0], Alternates: ok[1:]}, nil
+}
+
+// scoreImage asks the vision model how well the image illustrates the topic.
+func scoreImage(ctx context.Context, client *llm.Client, img *GeneratedImage, topic string) (float64, error) {
+	prompt := fmt.Sprintf("Rate from 0 to 10 how well this image illustrates the presentation topic %q on a slide. Consider relevance, clarity, and absence of text artifacts. Reply with the number only.", topic)
+	contents := []*genai.Content{genai.NewContentFromParts([]*genai.Part{
+		genai.NewPartFromBytes(img.Data, http.DetectContentType(img.Data)),
+		genai.NewPartFromText(prompt),
+	}, genai.RoleUser)}
+	res, err := client.GenerateContent(ctx, ScoreModel, contents, nil)
+	if err != nil {
+		return 0, err
+	}
+	return parseScore(res.Text())
+}
+
+// parseScore extracts a 0-10 score from model output like "7", "7.5/10", or "Score: 8".
+func parseScore(s string) (float64, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return !(r >= '0' && r <= '9' || r == '.')
+	})
+	for _, f := range fields {
+		v, err := strconv.ParseFloat(strings.Trim(f, "."), 64)
+		if err != nil {
+			continue
+		}
+		if v < 0 || v > 10 {
+			return 0, fmt.Errorf("score out of range: %q", s)
+		}
+		return v, nil
+	}
+	return 0, fmt.Errorf("no score in %q", s)
+}
@@ -0,0 +1,100 @@
+package picturegen
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+)
+
+// Cache stores generated images on disk keyed by model, prompt, and ImageConfig,
+// so re-runs and plan rebuilds reuse images instead of paying to regenerate them.
+type Cache struct {
+	Dir string
+}
+
+// DefaultCacheDir returns the per-user cache location for generated images.
+func DefaultCacheDir() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "gogemini-slides", "images"), nil
+}
+
+// NewCache creates the cache directory if needed.
+func NewCache(dir string) (*Cache, error) {
+	if dir == "" {
+		return nil, errors.New("cache dir is required")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create cache dir: %w", err)
+	}
+	return &Cache{Dir: dir}, nil
+}
+
+// Key derives the cache key for a generation request.
+func (c *Cache) Key(model, prompt string, cfg ImageConfig) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00%d", model, prompt, cfg.AspectRatio, cfg.Width)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Get returns the cached image for the request, if present.
+func (c *Cache) Get(model, prompt string, cfg ImageConfig) (*GeneratedImage, bool) {
+	data, err := os.ReadFile(c.path(c.Key(model, prompt, cfg)))
+	if err != nil {
+		return nil, false
+	}
+	dims, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, false // corrupt entry; treat as a miss so it gets regenerated
+	}
+	return &GeneratedImage{Data: data, Width: dims.Width, Height: dims.Height}, true
+}
+
+// Put stores the image for the request. Writes are atomic so concurrent runs never read partial files.
+func (c *Cache) Put(model, prompt string, cfg ImageConfig, img *GeneratedImage) error {
+	if img == nil || len(img.Data) == 0 {
+		return errors.New("nothing to cache")
+	}
+	tmp, err := os.CreateTemp(c.Dir, "tmp-*")
+	if err != nil {
+		return fmt.Errorf("cache temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(img.Data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("cache write: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("cache close: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path(c.Key(model, prompt, cfg))); err != nil {
+		return fmt.Errorf("cache rename: %w", err)
+	}
+	return nil
+}
+
+// GenerateImage returns a cached image when available; otherwise it generates one
+// with the package-level GenerateImage and caches the result.
+func (c *Cache) GenerateImage(ctx context.Context, prompt string, apiKey string, cfg ImageConfig) (*GeneratedImage, error) {
+	if img, ok := c.Get(ImageModel, prompt, cfg); ok {
+		return img, nil
+	}
+	img, err := GenerateImage(ctx, prompt, apiKey, cfg)
+	if err != nil {
+		return nil, err
+	}
+	_ = c.Put(ImageModel, prompt, cfg, img) // caching is best-effort
+	return img, nil
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.Dir, key+".img")
+}
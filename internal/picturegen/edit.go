@@ -0,0 +1,123 @@
+package picturegen
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	genai "google.golang.org/genai"
+
+	"gogemini-practices/internal/llm"
+)
+
+// EditSession refines an image over several turns: each instruction ("make it blue-toned",
+// "add a laptop") is sent with the conversation so far, so the model edits its own last
+// image rather than starting over. An EditSession is not safe for concurrent use.
+type EditSession struct {
+	cfg     ImageConfig
+	history []*genai.Content
+	current *GeneratedImage
+	send    func(ctx context.Context, contents []*genai.Content) (*genai.GenerateContentResponse, error)
+}
+
+// NewEditSession starts a session on image data, such as a generated slide image.
+func NewEditSession(ctx context.Context, data []byte, apiKey string, cfg ImageConfig) (*EditSession, error) {
+	if apiKey == "" {
+		return nil, errors.New("apiKey is required")
+	}
+	client, err := llm.Open(ctx, apiKey, llm.Options{})
+	if err != nil {
+		return nil, err
+	}
+	return newEditSession(data, cfg, func(ctx context.Context, contents []*genai.Content) (*genai.GenerateContentResponse, error) {
+		return client.GenerateContent(ctx, ImageModel, contents, nil)
+	})
+}
+
+func newEditSession(data []byte, cfg ImageConfig, send func(context.Context, []*genai.Content) (*genai.GenerateContentResponse, error)) (*EditSession, error) {
+	if len(data) == 0 {
+		return nil, errors.New("image to edit is required")
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	img, err := fitImage(data, ImageConfig{}) // checks that the image decodes
+	if err != nil {
+		return nil, err
+	}
+	return &EditSession{cfg: cfg, current: img, send: send}, nil
+}
+
+// Current returns the latest image: the starting image until an edit succeeds.
+func (s *EditSession) Current() *GeneratedImage {
+	return s.current
+}
+
+// Turns returns how many edits have succeeded.
+func (s *EditSession) Turns() int {
+	return len(s.history) / 2
+}
+
+// Edit applies instruction to the current image and returns the result, framed by the
+// session's ImageConfig. A failed edit leaves the session unchanged, so the next
+// instruction edits the same image; like GenerateImage, a safety block is retried once
+// with a softened instruction and an empty answer once as is.
+func (s *EditSession) Edit(ctx context.Context, instruction string) (*GeneratedImage, error) {
+	instruction = strings.TrimSpace(instruction)
+	if instruction == "" {
+		return nil, errors.New("edit instruction is required")
+	}
+	img, turn, err := s.edit(ctx, instruction)
+	switch {
+	case errors.Is(err, ErrSafety):
+		img, turn, err = s.edit(ctx, softenPrompt(instruction))
+	case errors.Is(err, ErrModel):
+		img, turn, err = s.edit(ctx, instruction)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.history = append(s.history, turn...)
+	s.current = img
+	return img, nil
+}
+
+// edit sends one turn and returns the edited image with the user and model contents to
+// add to the history.
+func (s *EditSession) edit(ctx context.Context, instruction string) (*GeneratedImage, []*genai.Content, error) {
+	parts := []*genai.Part{genai.NewPartFromText(s.cfg.promptWithFraming(editPrompt(instruction)))}
+	if len(s.history) == 0 {
+		// Later turns refer to the model's own last image, already in the history
+		parts = append([]*genai.Part{genai.NewPartFromBytes(s.current.Data, http.DetectContentType(s.current.Data))}, parts...)
+	}
+	user := genai.NewContentFromParts(parts, genai.RoleUser)
+	res, err := s.send(ctx, append(append([]*genai.Content{}, s.history...), user))
+	if err != nil {
+		return nil, nil, classifyAPIError(err)
+	}
+	img, err := imageFromResponse(res, instruction, s.cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	model := res.Candidates[0].Content
+	if model.Role == "" {
+		model.Role = genai.RoleModel
+	}
+	return img.image(), []*genai.Content{user, model}, nil
+}
+
+// editPrompt asks for the change alone, keeping the rest of the image and the slide framing.
+func editPrompt(instruction string) string {
+	return "Edit this image: " + instruction + ". Keep everything else about it the same. No text, letters, logos, or watermarks."
+}
+
+// EditImage applies one instruction to image data; use an EditSession to refine over
+// several turns.
+func EditImage(ctx context.Context, data []byte, instruction, apiKey string, cfg ImageConfig) (*GeneratedImage, error) {
+	s, err := NewEditSession(ctx, data, apiKey, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return s.Edit(ctx, instruction)
+}
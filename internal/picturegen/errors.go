@@ -0,0 +1,62 @@
+package picturegen
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	genai "google.golang.org/genai"
+)
+
+// Error classes returned (wrapped) by the generators; test with errors.Is.
+var (
+	ErrQuota  = errors.New("image generation quota or rate limit exceeded")
+	ErrSafety = errors.New("image generation blocked by safety filters")
+	ErrModel  = errors.New("image model returned no image")
+)
+
+// classifyAPIError wraps quota failures in ErrQuota; other API errors pass through.
+func classifyAPIError(err error) error {
+	var apiErr genai.APIError
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusTooManyRequests {
+		return fmt.Errorf("%w: %v", ErrQuota, err)
+	}
+	s := strings.ToUpper(err.Error())
+	if strings.Contains(s, "RESOURCE_EXHAUSTED") || strings.Contains(s, "429") || strings.Contains(s, "QUOTA") {
+		return fmt.Errorf("%w: %v", ErrQuota, err)
+	}
+	return err
+}
+
+// checkBlocked reports prompt-level blocks and safety finish reasons as ErrSafety.
+func checkBlocked(res *genai.GenerateContentResponse) error {
+	if res == nil {
+		return nil
+	}
+	if res.PromptFeedback != nil && res.PromptFeedback.BlockReason != "" && res.PromptFeedback.BlockReason != genai.BlockedReasonUnspecified {
+		return fmt.Errorf("%w: prompt blocked (%s)", ErrSafety, res.PromptFeedback.BlockReason)
+	}
+	if len(res.Candidates) == 0 || res.Candidates[0] == nil {
+		return nil
+	}
+	switch r := res.Candidates[0].FinishReason; r {
+	case genai.FinishReasonSafety, genai.FinishReasonImageSafety, genai.FinishReasonProhibitedContent,
+		genai.FinishReasonBlocklist, genai.FinishReasonSPII:
+		return fmt.Errorf("%w: finish reason %s", ErrSafety, r)
+	}
+	return nil
+}
+
+// sensitiveTerms are stripped by softenPrompt; they commonly trip image safety filters
+// even when the slide topic itself is benign (e.g. "battle against cancer").
+var sensitiveTerms = regexp.MustCompile(`(?i)\b(blood(y)?|gore|violen(t|ce)|weapons?|guns?|kill(ing)?|dead|death|war|battle|attack|injur(y|ies)|wounds?|nude|naked|drugs?|explosions?|terror(ism|ist)?)\b`)
+
+// softenPrompt removes terms likely to trigger safety filters and asks for a neutral,
+// symbolic depiction.
+func softenPrompt(prompt string) string {
+	p := sensitiveTerms.ReplaceAllString(prompt, "")
+	p = strings.Join(strings.Fields(p), " ")
+	return p + " Use a neutral, family-friendly, symbolic depiction without graphic content or identifiable people."
+}
@@ -3,44 +3,155 @@ package picturegen
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/http"
 
 	genai "google.golang.org/genai"
+
+	"gogemini-practices/internal/llm"
 )
 
-// FlashPicgen generates an image using the Gemini image preview model.
-// It returns the raw bytes of the first image produced by the model.
-func FlashPicgen(ctx context.Context, prompt string, apiKey string) ([]byte, error) {
+// ImageModel is the Gemini model used for image generation.
+const ImageModel = "gemini-2.5-flash-image-preview"
+
+// FlashPicgen generates an image using the Gemini image preview model and returns the
+// first image produced, unchanged, with its MIME type and provenance.
+func FlashPicgen(ctx context.Context, prompt string, apiKey string) (*ImageResult, error) {
+	return Generate(ctx, prompt, apiKey, ImageConfig{})
+}
+
+// ImageResult is a generated image with what callers need to save and log it.
+type ImageResult struct {
+	Data          []byte
+	MIMEType      string // image/png, image/jpeg, ...; see Extension
+	Width         int
+	Height        int
+	SafetyRatings []SafetyRating
+	Model         string
+	Prompt        string // the prompt sent, softened when a safety block was retried
+}
+
+// SafetyRating is the model's rating of a generated image for one harm category.
+type SafetyRating struct {
+	Category    string `json:"category"`    // e.g. HARM_CATEGORY_DANGEROUS_CONTENT
+	Probability string `json:"probability"` // NEGLIGIBLE, LOW, MEDIUM, or HIGH
+	Blocked     bool   `json:"blocked,omitempty"`
+}
+
+// Extension returns the file extension for the image's MIME type, with the dot.
+func (r *ImageResult) Extension() string {
+	switch r.MIMEType {
+	case "image/png":
+		return ".png"
+	case "image/jpeg":
+		return ".jpg"
+	case "image/gif":
+		return ".gif"
+	case "image/webp":
+		return ".webp"
+	}
+	return ".img"
+}
+
+func (r *ImageResult) image() *GeneratedImage {
+	return &GeneratedImage{Data: r.Data, Width: r.Width, Height: r.Height}
+}
+
+// GeneratedImage holds image bytes and their decoded pixel dimensions.
+type GeneratedImage struct {
+	Data   []byte
+	Width  int
+	Height int
+}
+
+// GenerateImage generates an image and, when cfg sets an aspect ratio or width, center-crops
+// and scales it (re-encoding as PNG) so it fits slide layouts without distortion.
+func GenerateImage(ctx context.Context, prompt string, apiKey string, cfg ImageConfig) (*GeneratedImage, error) {
+	res, err := Generate(ctx, prompt, apiKey, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return res.image(), nil
+}
+
+// Generate is GenerateImage returning an ImageResult.
+func Generate(ctx context.Context, prompt string, apiKey string, cfg ImageConfig) (*ImageResult, error) {
 	if prompt == "" {
 		return nil, errors.New("prompt is required")
 	}
 	if apiKey == "" {
 		return nil, errors.New("apiKey is required")
 	}
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
 
-	client, err := genai.NewClient(ctx, &genai.ClientConfig{APIKey: apiKey, Backend: genai.BackendGeminiAPI})
+	client, err := llm.Open(ctx, apiKey, llm.Options{})
 	if err != nil {
 		return nil, err
 	}
+	return generateWithRetry(ctx, client, prompt, cfg)
+}
+
+// generateWithRetry retries once when the model refuses or returns nothing: safety
+// blocks are retried with a softened prompt, empty responses with the same prompt.
+// API errors are not retried here: the llm client already retried transient ones, and a
+// quota error that outlasted them is returned so callers can fall back to stock images.
+func generateWithRetry(ctx context.Context, client *llm.Client, prompt string, cfg ImageConfig) (*ImageResult, error) {
+	img, err := generateWithClient(ctx, client, prompt, cfg)
+	switch {
+	case err == nil:
+		return img, nil
+	case errors.Is(err, ErrSafety):
+		return generateWithClient(ctx, client, softenPrompt(prompt), cfg)
+	case errors.Is(err, ErrModel):
+		return generateWithClient(ctx, client, prompt, cfg)
+	default:
+		return nil, err
+	}
+}
 
-	res, err := client.Models.GenerateContent(
+func generateWithClient(ctx context.Context, client *llm.Client, prompt string, cfg ImageConfig) (*ImageResult, error) {
+	res, err := client.GenerateContent(
 		ctx,
-		"gemini-2.5-flash-image-preview",
-		genai.Text(prompt),
+		ImageModel,
+		genai.Text(cfg.promptWithFraming(prompt)),
 		nil,
 	)
 	if err != nil {
-		return nil, err
+		return nil, classifyAPIError(err)
 	}
+	return imageFromResponse(res, prompt, cfg)
+}
 
+// imageFromResponse returns the first image of a model response, framed by cfg, or an
+// ErrSafety or ErrModel error.
+func imageFromResponse(res *genai.GenerateContentResponse, prompt string, cfg ImageConfig) (*ImageResult, error) {
+	if err := checkBlocked(res); err != nil {
+		return nil, err
+	}
 	if res == nil || len(res.Candidates) == 0 || res.Candidates[0] == nil || res.Candidates[0].Content == nil {
-		return nil, errors.New("no candidates returned from model")
+		return nil, fmt.Errorf("%w: no candidates returned", ErrModel)
 	}
-
-	for _, part := range res.Candidates[0].Content.Parts {
-		if part.InlineData != nil && len(part.InlineData.Data) > 0 {
-			return part.InlineData.Data, nil
+	cand := res.Candidates[0]
+	for _, part := range cand.Content.Parts {
+		if part.InlineData == nil || len(part.InlineData.Data) == 0 {
+			continue
 		}
+		img, err := fitImage(part.InlineData.Data, cfg)
+		if err != nil {
+			return nil, err
+		}
+		out := &ImageResult{Data: img.Data, MIMEType: http.DetectContentType(img.Data), Width: img.Width, Height: img.Height, Model: ImageModel, Prompt: prompt}
+		if res.ModelVersion != "" {
+			out.Model = res.ModelVersion
+		}
+		for _, r := range cand.SafetyRatings {
+			if r != nil {
+				out.SafetyRatings = append(out.SafetyRatings, SafetyRating{Category: string(r.Category), Probability: string(r.Probability), Blocked: r.Blocked})
+			}
+		}
+		return out, nil
 	}
-
-	return nil, errors.New("no image data returned from model")
+	return nil, fmt.Errorf("%w: no image data (finish reason %q)", ErrModel, cand.FinishReason)
 }
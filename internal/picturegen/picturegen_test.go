@@ -1,13 +1,20 @@
 package picturegen
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
 	"github.com/joho/godotenv"
+	genai "google.golang.org/genai"
 )
 
 func TestFlashPicgen_GeneratesImageToTemp(t *testing.T) {
@@ -40,19 +47,21 @@ func TestFlashPicgen_GeneratesImageToTemp(t *testing.T) {
 	t.Logf("Prompt: %q", prompt)
 	t.Log("Calling FlashPicgen with gemini-2.5-flash-image-preview ...")
 
-	data, err := FlashPicgen(ctx, prompt, apiKey)
+	res, err := FlashPicgen(ctx, prompt, apiKey)
 	if err != nil {
 		// Skip gracefully on quota or rate-limit errors
-		errStr := err.Error()
-		if strings.Contains(errStr, "RESOURCE_EXHAUSTED") || strings.Contains(errStr, "429") || strings.Contains(errStr, "quota") {
+		if errors.Is(err, ErrQuota) {
 			t.Skipf("Skipping due to quota/rate limit: %v", err)
 		}
 		t.Fatalf("FlashPicgen returned error: %v", err)
 	}
-	if len(data) == 0 {
+	if len(res.Data) == 0 {
 		t.Fatalf("no image data returned")
 	}
-	t.Logf("Received image bytes: %d", len(data))
+	t.Logf("Received %s image, %dx%d, %d bytes, from %s", res.MIMEType, res.Width, res.Height, len(res.Data), res.Model)
+	for _, r := range res.SafetyRatings {
+		t.Logf("Safety: %s %s", r.Category, r.Probability)
+	}
 
 	// Write to a temp folder within the repo root (testing unit root)
 	tempDir := filepath.Join("..", "..", "tmp_test_output")
@@ -60,9 +69,288 @@ func TestFlashPicgen_GeneratesImageToTemp(t *testing.T) {
 	if err := os.MkdirAll(tempDir, 0o755); err != nil {
 		t.Fatalf("failed to create temp dir: %v", err)
 	}
-	outPath := filepath.Join(tempDir, "gemini_generated_image.png")
-	if err := os.WriteFile(outPath, data, 0o644); err != nil {
+	outPath := filepath.Join(tempDir, "gemini_generated_image"+res.Extension())
+	if err := os.WriteFile(outPath, res.Data, 0o644); err != nil {
 		t.Fatalf("failed to write image: %v", err)
 	}
 	t.Logf("Wrote image to: %s", outPath)
 }
+
+func TestImageFromResponse(t *testing.T) {
+	var jpg bytes.Buffer
+	if err := jpeg.Encode(&jpg, image.NewRGBA(image.Rect(0, 0, 32, 18)), nil); err != nil {
+		t.Fatal(err)
+	}
+	res := &genai.GenerateContentResponse{
+		ModelVersion: "gemini-2.5-flash-image-preview-001",
+		Candidates: []*genai.Candidate{{
+			Content: &genai.Content{Parts: []*genai.Part{genai.NewPartFromText("Here you go"), genai.NewPartFromBytes(jpg.Bytes(), "image/jpeg")}},
+			SafetyRatings: []*genai.SafetyRating{
+				{Category: genai.HarmCategoryDangerousContent, Probability: genai.HarmProbabilityNegligible},
+				{Category: genai.HarmCategoryHarassment, Probability: genai.HarmProbabilityLow},
+			},
+		}},
+	}
+
+	got, err := imageFromResponse(res, "a red bicycle", ImageConfig{})
+	if err != nil {
+		t.Fatalf("imageFromResponse() error = %v", err)
+	}
+	if got.MIMEType != "image/jpeg" || got.Extension() != ".jpg" || got.Width != 32 || got.Height != 18 {
+		t.Errorf("image = %s (%s) %dx%d, want a 32x18 JPEG", got.MIMEType, got.Extension(), got.Width, got.Height)
+	}
+	if got.Model != res.ModelVersion || got.Prompt != "a red bicycle" {
+		t.Errorf("provenance = %q, %q", got.Model, got.Prompt)
+	}
+	want := []SafetyRating{{Category: "HARM_CATEGORY_DANGEROUS_CONTENT", Probability: "NEGLIGIBLE"}, {Category: "HARM_CATEGORY_HARASSMENT", Probability: "LOW"}}
+	if !reflect.DeepEqual(got.SafetyRatings, want) {
+		t.Errorf("SafetyRatings = %+v, want %+v", got.SafetyRatings, want)
+	}
+
+	// Framing re-encodes as PNG
+	if got, err = imageFromResponse(res, "a red bicycle", ImageConfig{AspectRatio: AspectSquare}); err != nil || got.MIMEType != "image/png" || got.Extension() != ".png" {
+		t.Errorf("framed image = %v, %v; want a PNG", got, err)
+	}
+	if _, err := imageFromResponse(&genai.GenerateContentResponse{Candidates: []*genai.Candidate{{Content: &genai.Content{Parts: []*genai.Part{genai.NewPartFromText("no")}}}}}, "p", ImageConfig{}); !errors.Is(err, ErrModel) {
+		t.Errorf("text-only answer error = %v, want ErrModel", err)
+	}
+}
+
+func TestPromptSpec_Build(t *testing.T) {
+	prompt, err := PromptSpec{
+		Style:      StyleWatercolor,
+		Subject:    "Diagnostic imaging",
+		Context:    "AI in Healthcare",
+		Mood:       "hopeful",
+		ColorHints: []string{"#1A73E8", " ", "teal"},
+	}.Build()
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	for _, want := range []string{"watercolor", "Diagnostic imaging", "AI in Healthcare", "Mood: hopeful", "#1A73E8, teal", "No text"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt %q missing %q", prompt, want)
+		}
+	}
+
+	if _, err := (PromptSpec{Style: StyleIsometric}).Build(); err == nil {
+		t.Error("expected error for empty subject")
+	}
+	if _, err := (PromptSpec{Style: "pixel-art", Subject: "x"}).Build(); err == nil {
+		t.Error("expected error for unknown style")
+	}
+}
+
+func TestParseStyle(t *testing.T) {
+	if s, err := ParseStyle(""); err != nil || s != StylePhotorealistic {
+		t.Errorf("ParseStyle(\"\") = %q, %v", s, err)
+	}
+	if s, err := ParseStyle(" Flat-Illustration "); err != nil || s != StyleFlatIllustration {
+		t.Errorf("ParseStyle(flat) = %q, %v", s, err)
+	}
+	if _, err := ParseStyle("cubist"); err == nil {
+		t.Error("expected error for unknown style")
+	}
+}
+
+func TestFitImage(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 1000, 1000))); err != nil {
+		t.Fatal(err)
+	}
+	src := buf.Bytes()
+
+	tests := []struct {
+		name  string
+		cfg   ImageConfig
+		wantW int
+		wantH int
+	}{
+		{name: "unchanged", cfg: ImageConfig{}, wantW: 1000, wantH: 1000},
+		{name: "wide crop", cfg: ImageConfig{AspectRatio: AspectWide}, wantW: 1000, wantH: 562},
+		{name: "wide crop and scale", cfg: ImageConfig{AspectRatio: AspectWide, Width: 320}, wantW: 320, wantH: 179},
+		{name: "scale only", cfg: ImageConfig{Width: 200}, wantW: 200, wantH: 200},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, err := fitImage(src, tt.cfg)
+			if err != nil {
+				t.Fatalf("fitImage() error: %v", err)
+			}
+			if img.Width != tt.wantW || img.Height != tt.wantH {
+				t.Errorf("dimensions = %dx%d, want %dx%d", img.Width, img.Height, tt.wantW, tt.wantH)
+			}
+			cfg, err := png.DecodeConfig(bytes.NewReader(img.Data))
+			if err != nil {
+				t.Fatalf("decode output: %v", err)
+			}
+			if cfg.Width != img.Width || cfg.Height != img.Height {
+				t.Errorf("encoded %dx%d, reported %dx%d", cfg.Width, cfg.Height, img.Width, img.Height)
+			}
+		})
+	}
+}
+
+func TestImageConfig_Validate(t *testing.T) {
+	if err := (ImageConfig{AspectRatio: "21:9"}).validate(); err == nil {
+		t.Error("expected error for unsupported aspect ratio")
+	}
+	if err := (ImageConfig{Width: -1}).validate(); err == nil {
+		t.Error("expected error for negative width")
+	}
+}
+
+func TestParseScore(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    float64
+		wantErr bool
+	}{
+		{in: "7", want: 7},
+		{in: " 7.5/10\n", want: 7.5},
+		{in: "Score: 8.", want: 8},
+		{in: "eleven", wantErr: true},
+		{in: "42", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseScore(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseScore(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseScore(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCheckBlocked(t *testing.T) {
+	blocked := &genai.GenerateContentResponse{PromptFeedback: &genai.GenerateContentResponsePromptFeedback{BlockReason: genai.BlockedReasonSafety}}
+	if err := checkBlocked(blocked); !errors.Is(err, ErrSafety) {
+		t.Errorf("prompt block: got %v, want ErrSafety", err)
+	}
+	imageSafety := &genai.GenerateContentResponse{Candidates: []*genai.Candidate{{FinishReason: genai.FinishReasonImageSafety}}}
+	if err := checkBlocked(imageSafety); !errors.Is(err, ErrSafety) {
+		t.Errorf("image safety: got %v, want ErrSafety", err)
+	}
+	ok := &genai.GenerateContentResponse{Candidates: []*genai.Candidate{{FinishReason: genai.FinishReasonStop}}}
+	if err := checkBlocked(ok); err != nil {
+		t.Errorf("stop: got %v, want nil", err)
+	}
+}
+
+func TestClassifyAPIError(t *testing.T) {
+	if err := classifyAPIError(genai.APIError{Code: 429, Status: "RESOURCE_EXHAUSTED"}); !errors.Is(err, ErrQuota) {
+		t.Errorf("429: got %v, want ErrQuota", err)
+	}
+	if err := classifyAPIError(genai.APIError{Code: 500}); errors.Is(err, ErrQuota) {
+		t.Errorf("500 classified as quota: %v", err)
+	}
+}
+
+func TestSoftenPrompt(t *testing.T) {
+	got := softenPrompt("The battle against cancer: weapons of modern medicine")
+	if strings.Contains(strings.ToLower(got), "battle") || strings.Contains(strings.ToLower(got), "weapons") {
+		t.Errorf("softenPrompt kept sensitive terms: %q", got)
+	}
+	if !strings.Contains(got, "cancer") || !strings.Contains(got, "family-friendly") {
+		t.Errorf("softenPrompt = %q", got)
+	}
+}
+
+func TestCache_PutGet(t *testing.T) {
+	cache, err := NewCache(filepath.Join(t.TempDir(), "images"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 16, 9))); err != nil {
+		t.Fatal(err)
+	}
+	cfg := ImageConfig{AspectRatio: AspectWide}
+
+	if _, ok := cache.Get(ImageModel, "a prompt", cfg); ok {
+		t.Fatal("unexpected hit on empty cache")
+	}
+	if err := cache.Put(ImageModel, "a prompt", cfg, &GeneratedImage{Data: buf.Bytes(), Width: 16, Height: 9}); err != nil {
+		t.Fatalf("Put() error: %v", err)
+	}
+	img, ok := cache.Get(ImageModel, "a prompt", cfg)
+	if !ok {
+		t.Fatal("expected cache hit")
+	}
+	if img.Width != 16 || img.Height != 9 || !bytes.Equal(img.Data, buf.Bytes()) {
+		t.Errorf("cached image = %dx%d (%d bytes)", img.Width, img.Height, len(img.Data))
+	}
+	if _, ok := cache.Get(ImageModel, "a prompt", ImageConfig{AspectRatio: AspectSquare}); ok {
+		t.Error("different config should miss")
+	}
+	if _, ok := cache.Get("other-model", "a prompt", cfg); ok {
+		t.Error("different model should miss")
+	}
+}
+
+func TestEditSession(t *testing.T) {
+	pngOf := func(w, h int) []byte {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h))); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+	imageReply := &genai.GenerateContentResponse{Candidates: []*genai.Candidate{{
+		Content: &genai.Content{Parts: []*genai.Part{genai.NewPartFromBytes(pngOf(40, 40), "image/png")}},
+	}}}
+	blocked := &genai.GenerateContentResponse{Candidates: []*genai.Candidate{{FinishReason: genai.FinishReasonImageSafety}}}
+
+	var sent [][]*genai.Content
+	replies := []*genai.GenerateContentResponse{imageReply, blocked, imageReply, blocked, blocked}
+	s, err := newEditSession(pngOf(32, 18), ImageConfig{AspectRatio: AspectSquare}, func(_ context.Context, contents []*genai.Content) (*genai.GenerateContentResponse, error) {
+		sent = append(sent, contents)
+		res := replies[0]
+		replies = replies[1:]
+		return res, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img := s.Current(); img.Width != 32 || img.Height != 18 {
+		t.Fatalf("Current() = %dx%d, want the starting image", img.Width, img.Height)
+	}
+
+	// First turn: the starting image and the instruction
+	img, err := s.Edit(context.Background(), "make it blue-toned")
+	if err != nil {
+		t.Fatalf("Edit() error = %v", err)
+	}
+	if img.Width != img.Height || s.Current() != img || s.Turns() != 1 {
+		t.Errorf("after one edit: %dx%d, %d turns; want a square current image and 1 turn", img.Width, img.Height, s.Turns())
+	}
+	if len(sent[0]) != 1 || len(sent[0][0].Parts) != 2 || sent[0][0].Parts[0].InlineData == nil {
+		t.Errorf("first turn sent %d contents, want one with the image and the instruction", len(sent[0]))
+	}
+
+	// Second turn: blocked once, then retried softened with the history
+	if _, err := s.Edit(context.Background(), "add a weapon-free laptop on the desk"); err != nil {
+		t.Fatalf("Edit() error = %v", err)
+	}
+	retry := sent[2]
+	if len(retry) != 3 || retry[1].Role != genai.RoleModel || len(retry[2].Parts) != 1 {
+		t.Errorf("second turn sent %d contents, want the first turn's two and the instruction alone", len(retry))
+	}
+	if text := retry[2].Parts[0].Text; strings.Contains(text, "weapon") || !strings.Contains(text, "laptop") {
+		t.Errorf("retried instruction = %q, want it softened", text)
+	}
+
+	// Blocked twice: an error, and the session keeps its image
+	before := s.Current()
+	if _, err := s.Edit(context.Background(), "add a crowd"); !errors.Is(err, ErrSafety) {
+		t.Errorf("Edit() error = %v, want ErrSafety", err)
+	}
+	if s.Current() != before || s.Turns() != 2 {
+		t.Errorf("failed edit changed the session: %d turns", s.Turns())
+	}
+	if _, err := s.Edit(context.Background(), "  "); err == nil {
+		t.Error("Edit(\"  \") succeeded, want an error")
+	}
+}
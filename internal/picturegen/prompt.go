@@ -0,0 +1,91 @@
+package picturegen
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Style is a visual preset for generated slide images.
+type Style string
+
+const (
+	StylePhotorealistic   Style = "photorealistic"
+	StyleFlatIllustration Style = "flat-illustration"
+	StyleWatercolor       Style = "watercolor"
+	StyleIsometric        Style = "isometric"
+)
+
+// styleDirections holds the wording each preset contributes to the prompt.
+var styleDirections = map[Style]string{
+	StylePhotorealistic:   "A photorealistic, high-resolution photograph with natural lighting and shallow depth of field",
+	StyleFlatIllustration: "A clean flat vector illustration with simple geometric shapes, bold solid colors, and no gradients",
+	StyleWatercolor:       "A soft watercolor painting with visible paper texture, gentle color bleeds, and loose brush strokes",
+	StyleIsometric:        "A detailed isometric 3D illustration on a plain background with consistent 30-degree angles and soft shadows",
+}
+
+// ParseStyle maps a user-supplied name to a Style. Empty input returns StylePhotorealistic.
+func ParseStyle(s string) (Style, error) {
+	v := Style(strings.ToLower(strings.TrimSpace(s)))
+	if v == "" {
+		return StylePhotorealistic, nil
+	}
+	if _, ok := styleDirections[v]; !ok {
+		return "", fmt.Errorf("unknown image style %q (photorealistic|flat-illustration|watercolor|isometric)", s)
+	}
+	return v, nil
+}
+
+// PromptSpec describes the image wanted for a slide. Build turns it into a prompt so
+// every topic in a deck gets images with the same style and framing.
+type PromptSpec struct {
+	Style      Style
+	Subject    string   // what the image depicts, usually the topic title
+	Context    string   // optional deck subject, keeps per-topic images on theme
+	Mood       string   // optional, e.g. "optimistic", "serious"
+	ColorHints []string // optional, e.g. "#1A73E8" or "teal"
+}
+
+// Build renders the spec as a prompt for the image model.
+func (s PromptSpec) Build() (string, error) {
+	subject := strings.TrimSpace(s.Subject)
+	if subject == "" {
+		return "", errors.New("prompt subject is required")
+	}
+	style := s.Style
+	if style == "" {
+		style = StylePhotorealistic
+	}
+	direction, ok := styleDirections[style]
+	if !ok {
+		return "", fmt.Errorf("unknown image style %q", style)
+	}
+
+	var b strings.Builder
+	b.WriteString(direction)
+	b.WriteString(" depicting ")
+	b.WriteString(subject)
+	if c := strings.TrimSpace(s.Context); c != "" && !strings.EqualFold(c, subject) {
+		b.WriteString(", in the context of ")
+		b.WriteString(c)
+	}
+	b.WriteString(".")
+	if m := strings.TrimSpace(s.Mood); m != "" {
+		b.WriteString(" Mood: ")
+		b.WriteString(m)
+		b.WriteString(".")
+	}
+	var colors []string
+	for _, c := range s.ColorHints {
+		if c = strings.TrimSpace(c); c != "" {
+			colors = append(colors, c)
+		}
+	}
+	if len(colors) > 0 {
+		b.WriteString(" Color palette: ")
+		b.WriteString(strings.Join(colors, ", "))
+		b.WriteString(".")
+	}
+	b.WriteString(" Landscape composition suitable for a presentation slide. No text, letters, logos, or watermarks.")
+	return b.String(), nil
+}
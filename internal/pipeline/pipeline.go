@@ -0,0 +1,63 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// DefaultWorkers bounds concurrent calls when the caller does not choose a limit. It keeps
+// a 5-topic deck to two rounds of image lookups without tripping per-user API quotas.
+const DefaultWorkers = 4
+
+// Run calls fn for every index in [0, n) with at most workers calls in flight (workers <= 0
+// uses DefaultWorkers) and waits for all of them. Every index runs even when others fail, so
+// one bad topic does not cost the rest; once ctx is done, indexes not yet started fail with
+// ctx.Err() instead. The errors are joined in index order, and nil is returned when all
+// calls succeed.
+func Run(ctx context.Context, n, workers int, fn func(ctx context.Context, i int) error) error {
+	if n <= 0 {
+		return nil
+	}
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+	if workers > n {
+		workers = n
+	}
+
+	errs := make([]error, n)
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				if err := ctx.Err(); err != nil {
+					errs[i] = err
+					continue
+				}
+				errs[i] = fn(ctx, i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// Map is Run for calls that produce a value: results[i] holds fn's result for index i,
+// including the partial results of indexes that failed.
+func Map[T any](ctx context.Context, n, workers int, fn func(ctx context.Context, i int) (T, error)) ([]T, error) {
+	results := make([]T, max(n, 0))
+	err := Run(ctx, n, workers, func(ctx context.Context, i int) error {
+		var err error
+		results[i], err = fn(ctx, i)
+		return err
+	})
+	return results, err
+}
@@ -0,0 +1,104 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name    string
+		n       int
+		workers int
+		fail    map[int]bool
+		wantErr string
+	}{
+		{name: "empty", n: 0, workers: 2},
+		{name: "all succeed", n: 5, workers: 2},
+		{name: "default workers", n: 9, workers: 0},
+		{name: "more workers than items", n: 2, workers: 8},
+		{name: "errors joined in index order", n: 5, workers: 3, fail: map[int]bool{3: true, 1: true}, wantErr: "item 1\nitem 3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var inFlight, peak, calls atomic.Int32
+			err := Run(context.Background(), tt.n, tt.workers, func(ctx context.Context, i int) error {
+				calls.Add(1)
+				cur := inFlight.Add(1)
+				defer inFlight.Add(-1)
+				for {
+					p := peak.Load()
+					if cur <= p || peak.CompareAndSwap(p, cur) {
+						break
+					}
+				}
+				time.Sleep(5 * time.Millisecond)
+				if tt.fail[i] {
+					return fmt.Errorf("item %d", i)
+				}
+				return nil
+			})
+
+			if got := int(calls.Load()); got != tt.n {
+				t.Errorf("fn called %d times, want %d", got, tt.n)
+			}
+			limit := tt.workers
+			if limit <= 0 {
+				limit = DefaultWorkers
+			}
+			if got := int(peak.Load()); got > limit {
+				t.Errorf("peak concurrency %d exceeds %d workers", got, limit)
+			}
+			if tt.n > 1 && limit > 1 && peak.Load() < 2 {
+				t.Errorf("calls never overlapped")
+			}
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Run: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("Run error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRun_CanceledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var calls atomic.Int32
+	err := Run(ctx, 3, 2, func(ctx context.Context, i int) error {
+		calls.Add(1)
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run error = %v, want context.Canceled", err)
+	}
+	if calls.Load() != 0 {
+		t.Errorf("fn called %d times after cancel, want 0", calls.Load())
+	}
+}
+
+func TestMap(t *testing.T) {
+	got, err := Map(context.Background(), 4, 2, func(ctx context.Context, i int) (string, error) {
+		if i == 2 {
+			return "partial", errors.New("boom")
+		}
+		return fmt.Sprint(i * i), nil
+	})
+	if err == nil || err.Error() != "boom" {
+		t.Fatalf("Map error = %v, want boom", err)
+	}
+	want := []string{"0", "1", "partial", "9"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("results[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
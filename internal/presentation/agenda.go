@@ -0,0 +1,155 @@
+package presentation
+
+import (
+	"strings"
+
+	"google.golang.org/api/slides/v1"
+
+	"gogemini-practices/internal/formatting"
+	"gogemini-practices/internal/palette"
+)
+
+// agendaRequests creates the agenda slide: a heading and one numbered line per topic title.
+// The lines are linked to the topics' title slides by agendaLinkRequests once those exist.
+func (w *deckWriter) agendaRequests(topics []RichTopic) []*slides.Request {
+	w.agendaID = w.id("agenda_slide", -1)
+	w.agendaBodyID = w.id("agenda_body", -1)
+	requests := []*slides.Request{{CreateSlide: &slides.CreateSlideRequest{
+		ObjectId:             w.agendaID,
+		SlideLayoutReference: &slides.LayoutReference{PredefinedLayout: "BLANK"},
+	}}}
+
+	var color *slides.OpaqueColor
+	if w.opts.Palette != nil {
+		if r, g, b, err := palette.RGB(w.opts.Palette.Primary); err == nil {
+			color = &slides.OpaqueColor{RgbColor: &slides.RgbColor{Red: r, Green: g, Blue: b}}
+		}
+	}
+	requests = append(requests, textBoxRequests(w.id("agenda_heading", -1), w.agendaID, "Agenda", 50, 40, 600, 50, 28, true, color)...)
+
+	var lines []string
+	for _, t := range topics {
+		lines = append(lines, w.agendaLine(t))
+	}
+	requests = append(requests,
+		paddedBox(w.agendaBodyID, w.agendaID, rect{X: 100, Y: 110, W: 520, H: 260}, w.layout.Padding),
+		fitRequest(w.agendaBodyID, alignTop),
+		&slides.Request{InsertText: &slides.InsertTextRequest{ObjectId: w.agendaBodyID, Text: strings.Join(lines, "\n")}},
+		&slides.Request{UpdateTextStyle: &slides.UpdateTextStyleRequest{
+			ObjectId:  w.agendaBodyID,
+			Style:     &slides.TextStyle{FontSize: &slides.Dimension{Magnitude: 20, Unit: "PT"}},
+			Fields:    "fontSize",
+			TextRange: &slides.Range{Type: "ALL"},
+		}},
+		&slides.Request{CreateParagraphBullets: &slides.CreateParagraphBulletsRequest{
+			ObjectId:     w.agendaBodyID,
+			BulletPreset: "NUMBERED_DIGIT_PERIOD",
+			TextRange:    &slides.Range{Type: "ALL"},
+		}},
+	)
+	return requests
+}
+
+// agendaLinkRequests links each agenda line to its topic's title slide. It runs after
+// topicRequests, since a link can only point at a slide that already exists.
+func (w *deckWriter) agendaLinkRequests(topics []RichTopic) []*slides.Request {
+	var requests []*slides.Request
+	var start int64
+	for i, t := range topics {
+		n := utf16Len(w.agendaLine(t))
+		if id := w.titleSlides[i]; id != "" && n > 0 {
+			requests = append(requests, linkRequest(w.agendaBodyID, start, start+n, id))
+		}
+		start += n + 1 // the newline
+	}
+	return requests
+}
+
+// agendaLine is a topic's agenda entry: its title without markup, on one line.
+func (w *deckWriter) agendaLine(t RichTopic) string {
+	return strings.Join(strings.Fields(w.processor.CleanText(t.Title)), " ")
+}
+
+// agendaEntry locates the agenda line for one topic in a deck WriteDeck wrote.
+type agendaEntry struct {
+	slideID, bodyID string
+	start, end      int64 // the line's text, without its newline
+}
+
+// findAgendaEntry returns the agenda line for the 0-based topic index; ok is false when
+// the deck has no agenda or the agenda has no such line.
+func findAgendaEntry(pres *slides.Presentation, index int) (agendaEntry, bool) {
+	for _, sld := range pres.Slides {
+		if sld == nil || !strings.HasPrefix(sld.ObjectId, "auto_agenda_slide_") {
+			continue
+		}
+		for _, el := range sld.PageElements {
+			if el == nil || !strings.HasPrefix(el.ObjectId, "auto_agenda_body_") || el.Shape == nil || el.Shape.Text == nil {
+				continue
+			}
+			n := 0
+			for _, te := range el.Shape.Text.TextElements {
+				if te == nil || te.ParagraphMarker == nil {
+					continue
+				}
+				if n == index {
+					return agendaEntry{slideID: sld.ObjectId, bodyID: el.ObjectId, start: te.StartIndex, end: te.EndIndex - 1}, true
+				}
+				n++
+			}
+			return agendaEntry{}, false
+		}
+	}
+	return agendaEntry{}, false
+}
+
+// relinkRequests replaces the agenda line's text with line and links it to pageID.
+func (e agendaEntry) relinkRequests(line, pageID string) []*slides.Request {
+	var requests []*slides.Request
+	if e.end > e.start {
+		requests = append(requests, &slides.Request{DeleteText: &slides.DeleteTextRequest{
+			ObjectId:  e.bodyID,
+			TextRange: &slides.Range{Type: "FIXED_RANGE", StartIndex: &e.start, EndIndex: &e.end},
+		}})
+	}
+	if line == "" {
+		return requests
+	}
+	requests = append(requests, &slides.Request{InsertText: &slides.InsertTextRequest{ObjectId: e.bodyID, Text: line, InsertionIndex: e.start}})
+	return append(requests, linkRequest(e.bodyID, e.start, e.start+utf16Len(line), pageID))
+}
+
+// backLinkRect is the back link's text box in the top-right corner of the page, in points.
+func backLinkRect(l Layout) rect {
+	return rect{X: l.PageWidth - 140, Y: 10, W: 130, H: 20}
+}
+
+// backLinkRequests adds a small "Back to agenda" link in frame, the title slide's top-right
+// corner.
+func backLinkRequests(objectID, pageID, agendaID string, frame rect) []*slides.Request {
+	gray := &slides.OpaqueColor{RgbColor: &slides.RgbColor{Red: 0.4, Green: 0.4, Blue: 0.4}}
+	const text = "Back to agenda"
+	reqs := textBoxRequests(objectID, pageID, text, frame.X, frame.Y, frame.W, frame.H, 9, false, gray)
+	reqs = append(reqs, &slides.Request{UpdateParagraphStyle: &slides.UpdateParagraphStyleRequest{
+		ObjectId:  objectID,
+		Style:     &slides.ParagraphStyle{Alignment: "END"},
+		Fields:    "alignment",
+		TextRange: &slides.Range{Type: "ALL"},
+	}})
+	return append(reqs, linkRequest(objectID, 0, utf16Len(text), agendaID))
+}
+
+// linkRequest links the text range [start, end) of objectID to the slide pageID.
+func linkRequest(objectID string, start, end int64, pageID string) *slides.Request {
+	return &slides.Request{UpdateTextStyle: &slides.UpdateTextStyleRequest{
+		ObjectId:  objectID,
+		Style:     &slides.TextStyle{Link: &slides.Link{PageObjectId: pageID}},
+		Fields:    "link",
+		TextRange: &slides.Range{Type: "FIXED_RANGE", StartIndex: &start, EndIndex: &end},
+	}}
+}
+
+// utf16Len is formatting.UTF16Len as a Slides text index.
+func utf16Len(s string) int64 {
+	return int64(formatting.UTF16Len(s))
+}
@@ -0,0 +1,35 @@
+package presentation
+
+import (
+	"context"
+
+	"google.golang.org/api/slides/v1"
+)
+
+// SlidesAPI is the part of the Slides API the deck writers call. NewSlidesAPI adapts a
+// *slides.Service; tests pass an in-memory fake (see internal/fakeapi).
+type SlidesAPI interface {
+	Get(ctx context.Context, presentationID string) (*slides.Presentation, error)
+	BatchUpdate(ctx context.Context, presentationID string, requests []*slides.Request) error
+}
+
+// NewSlidesAPI wraps svc; a nil svc gives a nil SlidesAPI.
+func NewSlidesAPI(svc *slides.Service) SlidesAPI {
+	if svc == nil {
+		return nil
+	}
+	return slidesService{svc}
+}
+
+type slidesService struct {
+	svc *slides.Service
+}
+
+func (s slidesService) Get(ctx context.Context, presentationID string) (*slides.Presentation, error) {
+	return s.svc.Presentations.Get(presentationID).Context(ctx).Do()
+}
+
+func (s slidesService) BatchUpdate(ctx context.Context, presentationID string, requests []*slides.Request) error {
+	_, err := s.svc.Presentations.BatchUpdate(presentationID, &slides.BatchUpdatePresentationRequest{Requests: requests}).Context(ctx).Do()
+	return err
+}
@@ -0,0 +1,60 @@
+package presentation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"gogemini-practices/internal/fakeapi"
+	"gogemini-practices/internal/palette"
+)
+
+// benchLayouts are one topic of each layout WriteDeck builds.
+var benchLayouts = []struct {
+	name  string
+	topic RichTopic
+}{
+	{"text", RichTopic{Title: "Overview", Summary: "A short plain summary of the topic."}},
+	{"bullets", RichTopic{Title: "Why **AI**", Summary: "**AI** changes care:\n• **Diagnostics** - faster reads\n• Drug discovery\n  ◦ Protein folding\n  ◦ Simulation\n> Do no harm"}},
+	{"chart", RichTopic{Title: "Growth", Summary: "Users doubled.", Dataset: twoPoints()}},
+	{"flow", RichTopic{Title: "Release", Summary: "Every change ships the same way.", Steps: []string{"Open PR", "Review", "Merge", "Deploy"}}},
+	{"code", RichTopic{Title: "Config", Summary: "Set `timeout`:\n```\nclient := New()\n```", Code: "client := NewClient(\n    WithTimeout(10 * time.Second),\n)", CodeLanguage: "go"}},
+	{"table", RichTopic{Title: "Plans", Summary: "Compare plans:\n| Plan | Price |\n|---|---|\n| Free | 0 |\n| Pro | 10 |"}},
+	{"stat", RichTopic{Title: "Growth", Summary: "Users grew **40%**.", Stat: &Stat{Value: 40, Unit: "%", Caption: "more weekly users"}}},
+}
+
+// BenchmarkWriteDeck builds decks of each layout against the fakes and reports the Slides
+// requests and the batchUpdate payload bytes per topic alongside the build time.
+func BenchmarkWriteDeck(b *testing.B) {
+	pal := palette.Default()
+	opts := DeckOptions{RunID: "bench", Palette: &pal, GroupComposites: true}
+	for _, l := range benchLayouts {
+		for _, n := range []int{1, 25} {
+			b.Run(fmt.Sprintf("%s/%d", l.name, n), func(b *testing.B) {
+				topics := make([]RichTopic, n)
+				for i := range topics {
+					topics[i] = l.topic
+				}
+				var reqs, bytes int
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					slidesAPI := &fakeapi.Slides{}
+					if err := WriteDeck(context.Background(), slidesAPI, &fakeapi.Sheets{}, "sheet-1", "deck-1", topics, opts); err != nil {
+						b.Fatal(err)
+					}
+					if i == 0 {
+						payload, err := json.Marshal(slidesAPI.Batches)
+						if err != nil {
+							b.Fatal(err)
+						}
+						reqs, bytes = len(slidesAPI.Requests()), len(payload)
+					}
+				}
+				b.ReportMetric(float64(reqs)/float64(n), "reqs/topic")
+				b.ReportMetric(float64(bytes)/float64(n), "bytes/topic")
+			})
+		}
+	}
+}
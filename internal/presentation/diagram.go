@@ -0,0 +1,343 @@
+package presentation
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"gogemini-practices/internal/palette"
+
+	"google.golang.org/api/slides/v1"
+)
+
+// MaxFlowSteps is the most steps a flow diagram draws; later steps are dropped.
+const MaxFlowSteps = 6
+
+// flowArrowGap is the horizontal room for the arrow between two step boxes.
+const flowArrowGap = 24.0
+
+// flowRequests draws steps left to right as rounded boxes joined by arrows, filling the
+// frame at x, y (points). Boxes take the palette's primary color, or the default one.
+// Returns: the requests and the IDs of the boxes and arrows, for grouping.
+func flowRequests(idPrefix, pageID string, steps []string, x, y, width, height float64, p *palette.Palette) ([]*slides.Request, []string) {
+	if len(steps) > MaxFlowSteps {
+		steps = steps[:MaxFlowSteps]
+	}
+	if len(steps) < 2 {
+		return nil, nil
+	}
+	fill := palette.Default().Primary
+	if p != nil {
+		fill = p.Primary
+	}
+	r, g, b, err := palette.RGB(fill)
+	if err != nil {
+		r, g, b, _ = palette.RGB(palette.Default().Primary)
+	}
+	boxWidth := (width - flowArrowGap*float64(len(steps)-1)) / float64(len(steps))
+
+	var reqs []*slides.Request
+	var ids []string
+	for i, step := range steps {
+		boxID := fmt.Sprintf("%s_step_%d", idPrefix, i)
+		ids = append(ids, boxID)
+		left := x + float64(i)*(boxWidth+flowArrowGap)
+		reqs = append(reqs,
+			&slides.Request{CreateShape: &slides.CreateShapeRequest{
+				ObjectId:  boxID,
+				ShapeType: "ROUND_RECTANGLE",
+				ElementProperties: &slides.PageElementProperties{
+					PageObjectId: pageID,
+					Size: &slides.Size{
+						Width:  &slides.Dimension{Magnitude: boxWidth, Unit: "PT"},
+						Height: &slides.Dimension{Magnitude: height, Unit: "PT"},
+					},
+					Transform: &slides.AffineTransform{ScaleX: 1, ScaleY: 1, TranslateX: left, TranslateY: y, Unit: "PT"},
+				},
+			}},
+			&slides.Request{UpdateShapeProperties: &slides.UpdateShapePropertiesRequest{
+				ObjectId: boxID,
+				ShapeProperties: &slides.ShapeProperties{
+					ShapeBackgroundFill: &slides.ShapeBackgroundFill{SolidFill: &slides.SolidFill{Color: &slides.OpaqueColor{RgbColor: &slides.RgbColor{Red: r, Green: g, Blue: b}}}},
+					Outline:             &slides.Outline{PropertyState: "NOT_RENDERED"},
+					ContentAlignment:    "MIDDLE",
+				},
+				Fields: "shapeBackgroundFill.solidFill.color,outline.propertyState,contentAlignment",
+			}},
+			&slides.Request{InsertText: &slides.InsertTextRequest{ObjectId: boxID, Text: step}},
+			&slides.Request{UpdateTextStyle: &slides.UpdateTextStyleRequest{
+				ObjectId: boxID,
+				Style: &slides.TextStyle{
+					FontSize:        &slides.Dimension{Magnitude: 12, Unit: "PT"},
+					ForegroundColor: &slides.OptionalColor{OpaqueColor: &slides.OpaqueColor{RgbColor: &slides.RgbColor{Red: 1, Green: 1, Blue: 1}}},
+				},
+				Fields:    "fontSize,foregroundColor",
+				TextRange: &slides.Range{Type: "ALL"},
+			}},
+			&slides.Request{UpdateParagraphStyle: &slides.UpdateParagraphStyleRequest{
+				ObjectId:  boxID,
+				Style:     &slides.ParagraphStyle{Alignment: "CENTER"},
+				Fields:    "alignment",
+				TextRange: &slides.Range{Type: "ALL"},
+			}},
+		)
+		if i == 0 {
+			continue
+		}
+		arrowID := fmt.Sprintf("%s_arrow_%d", idPrefix, i)
+		ids = append(ids, arrowID)
+		reqs = append(reqs,
+			&slides.Request{CreateLine: &slides.CreateLineRequest{
+				ObjectId:     arrowID,
+				LineCategory: "STRAIGHT",
+				ElementProperties: &slides.PageElementProperties{
+					PageObjectId: pageID,
+					Size: &slides.Size{
+						Width:  &slides.Dimension{Magnitude: flowArrowGap - 6, Unit: "PT"},
+						Height: &slides.Dimension{Magnitude: 0, Unit: "PT"},
+					},
+					Transform: &slides.AffineTransform{ScaleX: 1, ScaleY: 1, TranslateX: left - flowArrowGap + 3, TranslateY: y + height/2, Unit: "PT"},
+				},
+			}},
+			&slides.Request{UpdateLineProperties: &slides.UpdateLinePropertiesRequest{
+				ObjectId: arrowID,
+				LineProperties: &slides.LineProperties{
+					EndArrow: "FILL_ARROW",
+					Weight:   &slides.Dimension{Magnitude: 2, Unit: "PT"},
+					LineFill: &slides.LineFill{SolidFill: &slides.SolidFill{Color: &slides.OpaqueColor{RgbColor: &slides.RgbColor{Red: 0.4, Green: 0.4, Blue: 0.4}}}},
+				},
+				Fields: "endArrow,weight,lineFill.solidFill.color",
+			}},
+		)
+	}
+	return reqs, ids
+}
+
+// Timeline modes for DeckOptions.Timeline.
+const (
+	TimelineOff     = ""
+	TimelineAdd     = "add"     // timeline under the chart
+	TimelineReplace = "replace" // timeline instead of the chart
+)
+
+// ParseTimeline maps off|add|replace to a Timeline mode.
+func ParseTimeline(s string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "off", "none":
+		return TimelineOff, nil
+	case TimelineAdd:
+		return TimelineAdd, nil
+	case TimelineReplace:
+		return TimelineReplace, nil
+	}
+	return "", fmt.Errorf("unknown timeline mode %q (want off|add|replace)", s)
+}
+
+// timelineMaxLabels is the most milestones labeled on a timeline; with more points, labels
+// are spread evenly and the last one is always kept.
+const timelineMaxLabels = 8
+
+// timelineRequests draws a dataset as a horizontal timeline at height y (points): a line
+// from x to x+width with one marker per point, its label above and its value below. A
+// non-empty title is written above the timeline. Multi-series points show no value.
+// Returns: the requests and the IDs of every element, for grouping.
+func timelineRequests(idPrefix, pageID, title string, ds *ChartDataset, x, y, width float64, p *palette.Palette) ([]*slides.Request, []string) {
+	if ds == nil || len(ds.Points) == 0 {
+		return nil, nil
+	}
+	marker := palette.Default().Primary
+	if p != nil {
+		marker = p.Primary
+	}
+	mr, mg, mb, err := palette.RGB(marker)
+	if err != nil {
+		mr, mg, mb, _ = palette.RGB(palette.Default().Primary)
+	}
+	gray := &slides.OpaqueColor{RgbColor: &slides.RgbColor{Red: 0.4, Green: 0.4, Blue: 0.4}}
+
+	var reqs []*slides.Request
+	var ids []string
+	if title != "" {
+		titleID := idPrefix + "_title"
+		ids = append(ids, titleID)
+		reqs = append(reqs, textBoxRequests(titleID, pageID, title, x, y-110, width, 30, 18, true, nil)...)
+	}
+	lineID := idPrefix + "_line"
+	ids = append(ids, lineID)
+	reqs = append(reqs,
+		&slides.Request{CreateLine: &slides.CreateLineRequest{
+			ObjectId:     lineID,
+			LineCategory: "STRAIGHT",
+			ElementProperties: &slides.PageElementProperties{
+				PageObjectId: pageID,
+				Size: &slides.Size{
+					Width:  &slides.Dimension{Magnitude: width, Unit: "PT"},
+					Height: &slides.Dimension{Magnitude: 0, Unit: "PT"},
+				},
+				Transform: &slides.AffineTransform{ScaleX: 1, ScaleY: 1, TranslateX: x, TranslateY: y, Unit: "PT"},
+			},
+		}},
+		&slides.Request{UpdateLineProperties: &slides.UpdateLinePropertiesRequest{
+			ObjectId: lineID,
+			LineProperties: &slides.LineProperties{
+				Weight:   &slides.Dimension{Magnitude: 2, Unit: "PT"},
+				LineFill: &slides.LineFill{SolidFill: &slides.SolidFill{Color: gray}},
+			},
+			Fields: "weight,lineFill.solidFill.color",
+		}},
+	)
+
+	const markerSize, labelWidth = 12.0, 80.0
+	n := len(ds.Points)
+	every := (n + timelineMaxLabels - 1) / timelineMaxLabels
+	for i, pt := range ds.Points {
+		cx := x + width/2
+		if n > 1 {
+			cx = x + width*float64(i)/float64(n-1)
+		}
+		markerID := fmt.Sprintf("%s_marker_%d", idPrefix, i)
+		ids = append(ids, markerID)
+		reqs = append(reqs,
+			&slides.Request{CreateShape: &slides.CreateShapeRequest{
+				ObjectId:  markerID,
+				ShapeType: "ELLIPSE",
+				ElementProperties: &slides.PageElementProperties{
+					PageObjectId: pageID,
+					Size: &slides.Size{
+						Width:  &slides.Dimension{Magnitude: markerSize, Unit: "PT"},
+						Height: &slides.Dimension{Magnitude: markerSize, Unit: "PT"},
+					},
+					Transform: &slides.AffineTransform{ScaleX: 1, ScaleY: 1, TranslateX: cx - markerSize/2, TranslateY: y - markerSize/2, Unit: "PT"},
+				},
+			}},
+			&slides.Request{UpdateShapeProperties: &slides.UpdateShapePropertiesRequest{
+				ObjectId: markerID,
+				ShapeProperties: &slides.ShapeProperties{
+					ShapeBackgroundFill: &slides.ShapeBackgroundFill{SolidFill: &slides.SolidFill{Color: &slides.OpaqueColor{RgbColor: &slides.RgbColor{Red: mr, Green: mg, Blue: mb}}}},
+					Outline:             &slides.Outline{PropertyState: "NOT_RENDERED"},
+				},
+				Fields: "shapeBackgroundFill.solidFill.color,outline.propertyState",
+			}},
+		)
+		if i%every != 0 && i != n-1 {
+			continue
+		}
+		labelID := fmt.Sprintf("%s_label_%d", idPrefix, i)
+		ids = append(ids, labelID)
+		reqs = append(reqs, textBoxRequests(labelID, pageID, pt.Label, cx-labelWidth/2, y-34, labelWidth, 22, 11, true, nil)...)
+		if len(pt.Values) == 0 {
+			valueID := fmt.Sprintf("%s_value_%d", idPrefix, i)
+			ids = append(ids, valueID)
+			reqs = append(reqs, textBoxRequests(valueID, pageID, compactNumber(pt.Value, ds.Unit), cx-labelWidth/2, y+12, labelWidth, 22, 10, false, gray)...)
+		}
+	}
+	return reqs, ids
+}
+
+// timelineRect is the area timelineRequests covers below its title: the labels above the
+// line, the values below it, and half a label past either end.
+func timelineRect(x, y, width float64) rect {
+	return rect{X: x - 40, Y: y - 34, W: width + 80, H: 68}
+}
+
+// textBoxRequests places one line of text, centered across and down the box; color nil
+// keeps the theme color.
+func textBoxRequests(objectID, pageID, text string, x, y, width, height, size float64, bold bool, color *slides.OpaqueColor) []*slides.Request {
+	style := &slides.TextStyle{Bold: bold, FontSize: &slides.Dimension{Magnitude: size, Unit: "PT"}}
+	fields := "bold,fontSize"
+	if color != nil {
+		style.ForegroundColor = &slides.OptionalColor{OpaqueColor: color}
+		fields += ",foregroundColor"
+	}
+	return []*slides.Request{
+		{CreateShape: &slides.CreateShapeRequest{
+			ObjectId:  objectID,
+			ShapeType: "TEXT_BOX",
+			ElementProperties: &slides.PageElementProperties{
+				PageObjectId: pageID,
+				Size: &slides.Size{
+					Width:  &slides.Dimension{Magnitude: width, Unit: "PT"},
+					Height: &slides.Dimension{Magnitude: height, Unit: "PT"},
+				},
+				Transform: &slides.AffineTransform{ScaleX: 1, ScaleY: 1, TranslateX: x, TranslateY: y, Unit: "PT"},
+			},
+		}},
+		fitRequest(objectID, alignMiddle),
+		{InsertText: &slides.InsertTextRequest{ObjectId: objectID, Text: text}},
+		{UpdateTextStyle: &slides.UpdateTextStyleRequest{ObjectId: objectID, Style: style, Fields: fields, TextRange: &slides.Range{Type: "ALL"}}},
+		{UpdateParagraphStyle: &slides.UpdateParagraphStyleRequest{
+			ObjectId:  objectID,
+			Style:     &slides.ParagraphStyle{Alignment: "CENTER"},
+			Fields:    "alignment",
+			TextRange: &slides.Range{Type: "ALL"},
+		}},
+	}
+}
+
+// compactNumber formats a value for a small label, e.g. 8300000 "people" -> "8.3M people".
+func compactNumber(v float64, unit string) string {
+	s := compactValue(v)
+	switch unit = strings.TrimSpace(unit); {
+	case unit == "":
+		return s
+	case unit == "%":
+		return s + unit
+	}
+	return s + " " + unit
+}
+
+// compactValue shortens large values to K/M/B with one decimal, e.g. 8300000 -> "8.3M".
+func compactValue(v float64) string {
+	abs := math.Abs(v)
+	switch {
+	case abs >= 1e9:
+		return strconv.FormatFloat(math.Round(v/1e8)/10, 'f', -1, 64) + "B"
+	case abs >= 1e6:
+		return strconv.FormatFloat(math.Round(v/1e5)/10, 'f', -1, 64) + "M"
+	case abs >= 1e4:
+		return strconv.FormatFloat(math.Round(v/1e2)/10, 'f', -1, 64) + "K"
+	}
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
+
+// Stat is a topic's headline figure for a big-number slide.
+type Stat struct {
+	Value   float64
+	Unit    string // shown under the number; "%" is attached to it instead
+	Caption string // one-line explanation
+}
+
+// statRequests lays out a big-number slide: the value in huge type in the palette's
+// primary color, the unit under it, and the caption in gray below.
+// Returns: the requests and the element IDs, for grouping.
+func statRequests(idPrefix, pageID string, st Stat, p *palette.Palette) ([]*slides.Request, []string) {
+	primary := palette.Default().Primary
+	if p != nil {
+		primary = p.Primary
+	}
+	var color *slides.OpaqueColor
+	if r, g, b, err := palette.RGB(primary); err == nil {
+		color = &slides.OpaqueColor{RgbColor: &slides.RgbColor{Red: r, Green: g, Blue: b}}
+	}
+	gray := &slides.OpaqueColor{RgbColor: &slides.RgbColor{Red: 0.4, Green: 0.4, Blue: 0.4}}
+
+	value, unit := compactValue(st.Value), strings.TrimSpace(st.Unit)
+	if unit == "%" {
+		value, unit = value+unit, ""
+	}
+	valueID := idPrefix + "_value"
+	reqs := textBoxRequests(valueID, pageID, value, 50, 100, 600, 120, 96, true, color)
+	ids := []string{valueID}
+	if unit != "" {
+		unitID := idPrefix + "_unit"
+		reqs = append(reqs, textBoxRequests(unitID, pageID, unit, 50, 225, 600, 36, 24, false, nil)...)
+		ids = append(ids, unitID)
+	}
+	if caption := strings.TrimSpace(st.Caption); caption != "" {
+		captionID := idPrefix + "_caption"
+		reqs = append(reqs, textBoxRequests(captionID, pageID, caption, 50, 275, 600, 50, 16, false, gray)...)
+		ids = append(ids, captionID)
+	}
+	return reqs, ids
+}
@@ -0,0 +1,316 @@
+package presentation
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/slides/v1"
+
+	"gogemini-practices/internal/palette"
+)
+
+// frames is the box of every shape and line the requests create, by object ID.
+func frames(reqs []*slides.Request) map[string]rect {
+	out := map[string]rect{}
+	add := func(id string, p *slides.PageElementProperties) {
+		out[id] = rect{X: p.Transform.TranslateX, Y: p.Transform.TranslateY, W: p.Size.Width.Magnitude, H: p.Size.Height.Magnitude}
+	}
+	for _, r := range reqs {
+		switch {
+		case r.CreateShape != nil:
+			add(r.CreateShape.ObjectId, r.CreateShape.ElementProperties)
+		case r.CreateLine != nil:
+			add(r.CreateLine.ObjectId, r.CreateLine.ElementProperties)
+		}
+	}
+	return out
+}
+
+// insertedText is the text the requests insert, by object ID.
+func insertedText(reqs []*slides.Request) map[string]string {
+	out := map[string]string{}
+	for _, r := range reqs {
+		if r.InsertText != nil {
+			out[r.InsertText.ObjectId] = r.InsertText.Text
+		}
+	}
+	return out
+}
+
+// timelineData is a timeseries dataset of n points labeled "P0", "P1", ...; multi gives
+// every point two series values.
+func timelineData(n int, multi bool) *ChartDataset {
+	ds := &ChartDataset{Title: "Users", Unit: "people", Type: "timeseries"}
+	for i := range n {
+		pt := struct {
+			Label  string
+			Value  float64
+			Values []float64
+		}{Label: fmt.Sprintf("P%d", i), Value: float64(i+1) * 1500}
+		if multi {
+			pt.Values = []float64{1, 2}
+		}
+		ds.Points = append(ds.Points, pt)
+	}
+	return ds
+}
+
+func TestFlowRequests(t *testing.T) {
+	tests := []struct {
+		name       string
+		steps      []string
+		x, width   float64
+		wantIDs    []string
+		wantFrames map[string]rect
+	}{
+		{
+			name: "boxes share the width left after the arrows", steps: []string{"Plan", "Build", "Ship"}, x: 60, width: 600,
+			wantIDs: []string{"f_step_0", "f_step_1", "f_arrow_1", "f_step_2", "f_arrow_2"},
+			wantFrames: map[string]rect{
+				"f_step_0":  {X: 60, Y: 200, W: 184, H: 50},
+				"f_step_1":  {X: 268, Y: 200, W: 184, H: 50},
+				"f_step_2":  {X: 476, Y: 200, W: 184, H: 50},
+				"f_arrow_1": {X: 247, Y: 225, W: 18, H: 0},
+				"f_arrow_2": {X: 455, Y: 225, W: 18, H: 0},
+			},
+		},
+		{
+			name: "two steps", steps: []string{"Ask", "Answer"}, x: 0, width: 424,
+			wantIDs: []string{"f_step_0", "f_step_1", "f_arrow_1"},
+			wantFrames: map[string]rect{
+				"f_step_0":  {X: 0, Y: 200, W: 200, H: 50},
+				"f_step_1":  {X: 224, Y: 200, W: 200, H: 50},
+				"f_arrow_1": {X: 203, Y: 225, W: 18, H: 0},
+			},
+		},
+		{
+			name: "steps past the cap are dropped", steps: []string{"1", "2", "3", "4", "5", "6", "7", "8"}, x: 0, width: 690,
+			wantIDs: []string{"f_step_0", "f_step_1", "f_arrow_1", "f_step_2", "f_arrow_2", "f_step_3", "f_arrow_3", "f_step_4", "f_arrow_4", "f_step_5", "f_arrow_5"},
+			wantFrames: map[string]rect{
+				"f_step_0":  {X: 0, Y: 200, W: 95, H: 50},
+				"f_step_5":  {X: 595, Y: 200, W: 95, H: 50},
+				"f_arrow_5": {X: 574, Y: 225, W: 18, H: 0},
+			},
+		},
+		{name: "one step draws nothing", steps: []string{"Only"}, x: 0, width: 600},
+		{name: "no steps", x: 0, width: 600},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reqs, ids := flowRequests("f", "page", tt.steps, tt.x, 200, tt.width, 50, nil)
+			if !slices.Equal(ids, tt.wantIDs) {
+				t.Fatalf("ids = %q, want %q", ids, tt.wantIDs)
+			}
+			got := frames(reqs)
+			if len(got) != len(tt.wantIDs) {
+				t.Errorf("%d elements created, want %d", len(got), len(tt.wantIDs))
+			}
+			for id, want := range tt.wantFrames {
+				if !near(got[id], want) {
+					t.Errorf("%s at %+v, want %+v", id, got[id], want)
+				}
+			}
+			text := insertedText(reqs)
+			for i, step := range tt.steps[:min(len(tt.steps), MaxFlowSteps)] {
+				if id := fmt.Sprintf("f_step_%d", i); len(tt.wantIDs) > 0 && text[id] != step {
+					t.Errorf("%s text = %q, want %q", id, text[id], step)
+				}
+			}
+		})
+	}
+}
+
+func TestFlowRequests_Fill(t *testing.T) {
+	fillOf := func(p *palette.Palette) *slides.RgbColor {
+		reqs, _ := flowRequests("f", "page", []string{"a", "b"}, 0, 0, 300, 40, p)
+		for _, r := range reqs {
+			if u := r.UpdateShapeProperties; u != nil {
+				return u.ShapeProperties.ShapeBackgroundFill.SolidFill.Color.RgbColor
+			}
+		}
+		t.Fatal("no box fill requested")
+		return nil
+	}
+	r, g, b, _ := palette.RGB(palette.Default().Primary)
+	want := &slides.RgbColor{Red: r, Green: g, Blue: b}
+	for name, p := range map[string]*palette.Palette{"no palette": nil, "invalid primary": {Primary: "not a color"}} {
+		if got := fillOf(p); got.Red != want.Red || got.Green != want.Green || got.Blue != want.Blue {
+			t.Errorf("%s: fill = %+v, want the default primary %+v", name, *got, *want)
+		}
+	}
+	if got := fillOf(&palette.Palette{Primary: "#FF0000"}); got.Red != 1 || got.Green != 0 || got.Blue != 0 {
+		t.Errorf("fill = %+v, want the palette's red", *got)
+	}
+}
+
+func TestTimelineRequests(t *testing.T) {
+	tests := []struct {
+		name       string
+		ds         *ChartDataset
+		title      string
+		wantLabels []int // points labeled
+		wantValues []int // points with a value under the marker
+		wantFrames map[string]rect
+	}{
+		{
+			name: "one point sits in the middle", ds: timelineData(1, false),
+			wantLabels: []int{0}, wantValues: []int{0},
+			wantFrames: map[string]rect{
+				"t_line":     {X: 100, Y: 300, W: 400, H: 0},
+				"t_marker_0": {X: 294, Y: 294, W: 12, H: 12},
+				"t_label_0":  {X: 260, Y: 266, W: 80, H: 22},
+				"t_value_0":  {X: 260, Y: 312, W: 80, H: 22},
+			},
+		},
+		{
+			name: "markers span the line end to end", ds: timelineData(3, false), title: "Growth",
+			wantLabels: []int{0, 1, 2}, wantValues: []int{0, 1, 2},
+			wantFrames: map[string]rect{
+				"t_title":    {X: 100, Y: 190, W: 400, H: 30},
+				"t_marker_0": {X: 94, Y: 294, W: 12, H: 12},
+				"t_marker_1": {X: 294, Y: 294, W: 12, H: 12},
+				"t_marker_2": {X: 494, Y: 294, W: 12, H: 12},
+				"t_label_2":  {X: 460, Y: 266, W: 80, H: 22},
+			},
+		},
+		{
+			name: "labels thin out past eight points and keep the last", ds: timelineData(10, false),
+			wantLabels: []int{0, 2, 4, 6, 8, 9}, wantValues: []int{0, 2, 4, 6, 8, 9},
+			wantFrames: map[string]rect{
+				"t_marker_9": {X: 494, Y: 294, W: 12, H: 12},
+				"t_label_8":  {X: 100 + 400*8.0/9 - 40, Y: 266, W: 80, H: 22},
+			},
+		},
+		{
+			name: "multi-series points show no value", ds: timelineData(2, true),
+			wantLabels: []int{0, 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reqs, ids := timelineRequests("t", "page", tt.title, tt.ds, 100, 300, 400, nil)
+			got := frames(reqs)
+			if len(got) != len(ids) {
+				t.Errorf("%d elements created, %d IDs returned: want every element grouped", len(got), len(ids))
+			}
+			var markers, labels, values []int
+			for i := range tt.ds.Points {
+				if _, ok := got[fmt.Sprintf("t_marker_%d", i)]; ok {
+					markers = append(markers, i)
+				}
+				if _, ok := got[fmt.Sprintf("t_label_%d", i)]; ok {
+					labels = append(labels, i)
+				}
+				if _, ok := got[fmt.Sprintf("t_value_%d", i)]; ok {
+					values = append(values, i)
+				}
+			}
+			if len(markers) != len(tt.ds.Points) {
+				t.Errorf("markers on points %v, want one per point", markers)
+			}
+			if !slices.Equal(labels, tt.wantLabels) || !slices.Equal(values, tt.wantValues) {
+				t.Errorf("labels on %v, values on %v; want %v and %v", labels, values, tt.wantLabels, tt.wantValues)
+			}
+			if _, ok := got["t_title"]; ok != (tt.title != "") {
+				t.Errorf("title drawn = %v, want %v", ok, tt.title != "")
+			}
+			for id, want := range tt.wantFrames {
+				if !near(got[id], want) {
+					t.Errorf("%s at %+v, want %+v", id, got[id], want)
+				}
+			}
+			// Every label and value lies inside the area placement reserves
+			area := timelineRect(100, 300, 400)
+			for id, f := range got {
+				if id == "t_title" {
+					continue
+				}
+				if f.X < area.X-0.01 || f.Y < area.Y-0.01 || f.X+f.W > area.X+area.W+0.01 || f.Y+f.H > area.Y+area.H+0.01 {
+					t.Errorf("%s at %+v leaves the timeline area %+v", id, f, area)
+				}
+			}
+		})
+	}
+}
+
+func TestTimelineRequests_Text(t *testing.T) {
+	ds := timelineData(2, false)
+	ds.Points[1].Value = 8300000
+	reqs, _ := timelineRequests("t", "page", "", ds, 0, 200, 600, nil)
+	text := insertedText(reqs)
+	if text["t_label_1"] != "P1" || text["t_value_0"] != "1500 people" || text["t_value_1"] != "8.3M people" {
+		t.Errorf("text = %v, want the labels and compact values with the unit", text)
+	}
+	for _, r := range reqs {
+		if u := r.UpdateShapeProperties; u != nil && strings.HasPrefix(u.ObjectId, "t_label") && u.ShapeProperties.Autofit.AutofitType != "NONE" {
+			t.Errorf("%s autofit = %q, want NONE so long labels don't resize the box", u.ObjectId, u.ShapeProperties.Autofit.AutofitType)
+		}
+	}
+
+	for _, empty := range []*ChartDataset{nil, {Title: "Empty"}} {
+		if reqs, ids := timelineRequests("t", "page", "Title", empty, 0, 200, 600, nil); reqs != nil || ids != nil {
+			t.Errorf("timelineRequests(%v) = %d requests, want none", empty, len(reqs))
+		}
+	}
+}
+
+func TestCompactNumber(t *testing.T) {
+	tests := []struct {
+		v    float64
+		unit string
+		want string
+	}{
+		{v: 42, want: "42"},
+		{v: 3.14159, want: "3.14"},
+		{v: 9999, unit: "people", want: "9999 people"},
+		{v: 12345, unit: "people", want: "12.3K people"},
+		{v: 8300000, unit: " USD ", want: "8.3M USD"},
+		{v: -2500000000, want: "-2.5B"},
+		{v: 12.5, unit: "%", want: "12.5%"},
+	}
+	for _, tt := range tests {
+		if got := compactNumber(tt.v, tt.unit); got != tt.want {
+			t.Errorf("compactNumber(%g, %q) = %q, want %q", tt.v, tt.unit, got, tt.want)
+		}
+	}
+}
+
+func TestStatRequests(t *testing.T) {
+	tests := []struct {
+		name     string
+		stat     Stat
+		wantIDs  []string
+		wantText map[string]string
+	}{
+		{
+			name: "value, unit and caption", stat: Stat{Value: 8300000, Unit: "users", Caption: " Monthly actives "},
+			wantIDs:  []string{"s_value", "s_unit", "s_caption"},
+			wantText: map[string]string{"s_value": "8.3M", "s_unit": "users", "s_caption": "Monthly actives"},
+		},
+		{
+			name: "percent joins the value", stat: Stat{Value: 42, Unit: "%"},
+			wantIDs:  []string{"s_value"},
+			wantText: map[string]string{"s_value": "42%"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reqs, ids := statRequests("s", "page", tt.stat, nil)
+			if !slices.Equal(ids, tt.wantIDs) {
+				t.Fatalf("ids = %q, want %q", ids, tt.wantIDs)
+			}
+			text := insertedText(reqs)
+			for id, want := range tt.wantText {
+				if text[id] != want {
+					t.Errorf("%s text = %q, want %q", id, text[id], want)
+				}
+			}
+			got := frames(reqs)
+			if want := (rect{X: 50, Y: 100, W: 600, H: 120}); !near(got["s_value"], want) {
+				t.Errorf("value at %+v, want %+v", got["s_value"], want)
+			}
+		})
+	}
+}
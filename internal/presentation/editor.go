@@ -2,13 +2,16 @@ package presentation
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 
 	"gogemini-practices/internal/charts"
 	"gogemini-practices/internal/formatting"
+	"gogemini-practices/internal/palette"
+	"gogemini-practices/internal/pipeline"
 
 	"github.com/google/uuid"
-	"google.golang.org/api/sheets/v4"
 	"google.golang.org/api/slides/v1"
 )
 
@@ -19,12 +22,22 @@ type Topic struct {
 
 // ChartDataset mirrors a small chart-friendly dataset.
 type ChartDataset struct {
-	Title  string
-	Unit   string
-	Type   string // timeseries | category | comparison
-	Points []struct {
-		Label string
-		Value float64
+	Title       string
+	Unit        string
+	Type        string   // timeseries | category | comparison | composition
+	Series      []string // optional series names for multi-series datasets
+	Stack       string   // optional stacking hint: stacked | percent | none
+	Trend       string   // optional overlay for timeseries: charts.TrendLinear | charts.TrendMovingAverage
+	TrendWindow int      // moving-average window; charts default when <= 1
+	SourceRange string   // optional existing range of the chart spreadsheet the chart reads; see charts.ReadRange
+	LogScale    bool     // optional log value axis for growth curves; see charts.DatasetSpec.LogScale
+	AxisMin     *float64 // optional start of the value axis, e.g. 0
+	Source      string   // optional provenance shown under the chart title; see charts.DatasetSpec.Source
+	Estimate    bool     // marks the chart with an "Estimate" badge, for figures to take with caution
+	Points      []struct {
+		Label  string
+		Value  float64
+		Values []float64 // one value per Series entry
 	}
 }
 
@@ -34,14 +47,100 @@ type RichTopic struct {
 	Summary  string
 	Dataset  *ChartDataset
 	ImageURL string
+	IconURL  string // optional small icon placed to the right of the title
+	// Steps, for a topic describing a sequence, are drawn as a flow diagram under the summary.
+	Steps []string
+	// Stat, when set, replaces the chart slide with a big-number slide.
+	Stat *Stat
+	// Code is an example snippet shown on its own slide after the summary; CodeLanguage
+	// labels it.
+	Code         string
+	CodeLanguage string
+	// ImageCaption is an optional line under the image, e.g. a credit; ignored without ImageURL.
+	ImageCaption string
+	// ImageMissing marks a topic whose image search or processing came up empty, so
+	// ImageURL is a default image or none; its status is StatusBuiltWithoutImage.
+	ImageMissing bool
 }
 
-func WriteTopics(ctx context.Context, svc *slides.Service, presentationID string, topics []Topic) error {
+// InlineChartMaxPoints is the largest dataset DeckOptions.InlineSmallCharts puts on the summary slide.
+const InlineChartMaxPoints = 5
+
+// DeckOptions carries deck-wide styling for WriteDeck. The zero value keeps default styling.
+type DeckOptions struct {
+	Palette *palette.Palette    // optional colors for titles, body, accents, dividers, and chart series
+	RunID   string              // prefix for per-topic chart tabs; a short random ID is used when empty
+	Chart   charts.ChartOptions // data labels, legend, and axis bounds applied to every chart
+	// ChartFallback, when set, supplies a public image URL for a dataset whose Sheets chart
+	// can't be created: cause is nil when no spreadsheet was given, otherwise the Sheets error.
+	// The image is inserted in the chart's place on the chart slide.
+	ChartFallback func(ctx context.Context, ds charts.DatasetSpec, cause error) (string, error)
+	// UnlinkedCharts embeds Sheets charts as images (NOT_LINKED_IMAGE) rather than linked
+	// charts, so viewers without access to the spreadsheet still see them. They no longer
+	// refresh from the spreadsheet and aren't verified after embedding.
+	UnlinkedCharts bool
+	// InlineSmallCharts places charts for datasets of at most InlineChartMaxPoints points beside
+	// the summary text instead of on a dedicated chart slide.
+	InlineSmallCharts bool
+	// Paragraphs overrides the spacing and alignment of titles and summaries;
+	// nil keeps formatting.DefaultParagraphStyles.
+	Paragraphs *formatting.ParagraphStyles
+	// Placeholders puts each summary, under its topic title, into the title and body
+	// placeholders of the deck's TITLE_AND_BODY layout so the theme's typography applies.
+	// Summaries with code, a table, or an inline chart keep the free text box layout, as do
+	// decks without such a layout.
+	Placeholders bool
+	// GroupComposites groups elements that belong together (title with divider and icon,
+	// image with caption) so they move as a unit when the deck is edited by hand.
+	GroupComposites bool
+	// Timeline draws timeseries datasets of 2+ points as a milestone timeline on the chart
+	// slide, under the chart (TimelineAdd) or in its place (TimelineReplace).
+	Timeline string
+	// Quote, when set, adds a pull-quote slide after the topics. ReplaceTopic ignores it.
+	Quote *Quote
+	// Risks, when set, adds a "Risks & counterarguments" slide after the topics, before
+	// the quote. ReplaceTopic ignores it.
+	Risks []string
+	// QA, when set, adds a last slide of likely audience questions, skipped in
+	// presentation mode, with suggested answers in its speaker notes. ReplaceTopic ignores it.
+	QA []QA
+	// Agenda adds a first slide listing the topics, each linked to its title slide, and a
+	// link back to it on every title slide. Decks with fewer than two topics get none.
+	Agenda bool
+	// WordsPerMinute, when positive, writes a speaking-time estimate into the speaker notes
+	// of every generated slide whose notes are empty (see SpeakingTime).
+	WordsPerMinute float64
+	// Footer, when set, is written in small gray type in the bottom-left corner of every
+	// generated slide, e.g. "As of Oct 15, 2026" on a deck rebuilt on a schedule.
+	Footer string
+	// ImagePosition puts each title slide's image on the left (the default), right, or
+	// center; see ParseImagePosition. Images and charts are moved or shrunk clear of the
+	// text already on their slide.
+	ImagePosition string
+	// TitleStyle sets the size, font, and color of topic titles; nil keeps
+	// DefaultTitleStyle.
+	TitleStyle *TitleStyle
+	// Layout places the title, summary, image, and chart boxes of topic slides on its page;
+	// nil keeps DefaultLayout. Every slide is laid out on that page, then stretched to the
+	// deck's own page size.
+	Layout *Layout
+	// Workers bounds the chart fallback images rendered and uploaded at once;
+	// 0 uses pipeline.DefaultWorkers.
+	Workers int
+	// Manifest, when set, is filled with the objects the write created once its batch is
+	// applied; ReplaceTopic lists only the replaced topic's.
+	Manifest *Manifest
+	// Status, when set, is filled with each topic's TopicStatus once the batch is applied,
+	// including topics whose chart failed; ReplaceTopic reports only the replaced topic.
+	Status *[]TopicStatus
+}
+
+func WriteTopics(ctx context.Context, svc SlidesAPI, presentationID string, topics []Topic) error {
 	if len(topics) == 0 {
 		return nil
 	}
 
-	pres, err := svc.Presentations.Get(presentationID).Context(ctx).Do()
+	pres, err := svc.Get(ctx, presentationID)
 	if err != nil {
 		return fmt.Errorf("get presentation: %w", err)
 	}
@@ -81,53 +180,37 @@ func WriteTopics(ctx context.Context, svc *slides.Service, presentationID string
 		bodyID := fmt.Sprintf("auto_body_%d_%s", i, suffix)
 
 		// Create title text box
-		requests = append(requests,
-			&slides.Request{CreateShape: &slides.CreateShapeRequest{
-				ObjectId:  titleID,
-				ShapeType: "TEXT_BOX",
-				ElementProperties: &slides.PageElementProperties{
-					PageObjectId: slideID,
-					Size: &slides.Size{
-						Width:  &slides.Dimension{Magnitude: 600, Unit: "PT"},
-						Height: &slides.Dimension{Magnitude: 60, Unit: "PT"},
-					},
-					Transform: &slides.AffineTransform{ScaleX: 1, ScaleY: 1, TranslateX: 50, TranslateY: 50, Unit: "PT"},
-				},
-			}},
-		)
+		layout := DefaultLayout()
+		requests = append(requests, paddedBox(titleID, slideID, layout.Title.rect(), layout.Padding), fitRequest(titleID, alignMiddle))
 
 		// Process title formatting
 		titleSegments := processor.ParseMarkup(topics[i].Title)
-		titleRequests := processor.ToSlidesRequests(titleSegments, titleID)
-		requests = append(requests, titleRequests...)
+		titleRequests := processor.TitleRequests(titleSegments, titleID)
+		requests = append(requests, DefaultTitleStyle().styleTitle(titleRequests, titleID, headingFont(pres), nil)...)
 
-		// Create body text box
-		requests = append(requests,
-			&slides.Request{CreateShape: &slides.CreateShapeRequest{
-				ObjectId:  bodyID,
-				ShapeType: "TEXT_BOX",
-				ElementProperties: &slides.PageElementProperties{
-					PageObjectId: slideID,
-					Size: &slides.Size{
-						Width:  &slides.Dimension{Magnitude: 600, Unit: "PT"},
-						Height: &slides.Dimension{Magnitude: 300, Unit: "PT"},
-					},
-					Transform: &slides.AffineTransform{ScaleX: 1, ScaleY: 1, TranslateX: 50, TranslateY: 130, Unit: "PT"},
-				},
-			}},
-		)
+		// Create body text box, shortened when fenced code or a table needs room below it
+		summary, codeBlocks := processor.SplitCodeBlocks(topics[i].Summary)
+		summary, tables := processor.SplitTables(summary)
+		body := layout.Body
+		bodyHeight := body.H
+		if len(codeBlocks) > 0 || len(tables) > 0 {
+			bodyHeight = body.shortened()
+		}
+		requests = append(requests, paddedBox(bodyID, slideID, rect{X: body.X, Y: body.Y, W: body.W, H: bodyHeight}, layout.Padding), fitRequest(bodyID, alignTop))
 
 		// Process body formatting
-		bodySegments := processor.ParseMarkup(topics[i].Summary)
+		bodySegments := processor.ParseMarkup(summary)
 		bodyRequests := processor.ToSlidesRequests(bodySegments, bodyID)
 		requests = append(requests, bodyRequests...)
+		requests = append(requests, lowerBoxRequests(processor, i, suffix, slideID, codeBlocks, tables, body.lower())...)
 	}
 
 	if len(requests) == 0 {
 		return nil
 	}
+	scaleToPage(requests, DefaultLayout(), pres)
 
-	_, err = svc.Presentations.BatchUpdate(presentationID, &slides.BatchUpdatePresentationRequest{Requests: requests}).Context(ctx).Do()
+	err = svc.BatchUpdate(ctx, presentationID, requests)
 	if err != nil {
 		return fmt.Errorf("batch update: %w", err)
 	}
@@ -136,170 +219,971 @@ func WriteTopics(ctx context.Context, svc *slides.Service, presentationID string
 
 // WriteTopicsWithCharts behaves like WriteTopics but also embeds a chart for any topic with a dataset.
 // It requires both Slides and Sheets services.
-func WriteTopicsWithCharts(ctx context.Context, slidesSvc *slides.Service, sheetsSvc *sheets.Service, spreadsheetID string, presentationID string, topics []RichTopic) error {
+func WriteTopicsWithCharts(ctx context.Context, slidesSvc SlidesAPI, sheetsSvc charts.SheetsAPI, spreadsheetID string, presentationID string, topics []RichTopic) error {
+	return WriteDeck(ctx, slidesSvc, sheetsSvc, spreadsheetID, presentationID, topics, DeckOptions{})
+}
+
+// WriteDeck is WriteTopicsWithCharts with deck-wide styling options.
+func WriteDeck(ctx context.Context, slidesSvc SlidesAPI, sheetsSvc charts.SheetsAPI, spreadsheetID string, presentationID string, topics []RichTopic, opts DeckOptions) error {
 	if len(topics) == 0 {
 		return nil
 	}
-	if slidesSvc == nil {
-		return fmt.Errorf("slides service is nil")
-	}
-	if sheetsSvc == nil {
-		return fmt.Errorf("sheets service is nil")
+	if err := checkServices(slidesSvc, sheetsSvc, spreadsheetID, opts); err != nil {
+		return err
 	}
 
-	pres, err := slidesSvc.Presentations.Get(presentationID).Context(ctx).Do()
+	pres, err := slidesSvc.Get(ctx, presentationID)
 	if err != nil {
 		return fmt.Errorf("get presentation: %w", err)
 	}
 
-	existing := len(pres.Slides)
-	need := len(topics)
-
 	var requests []*slides.Request
-	processor := formatting.NewTextProcessor()
+	// Charts are created together after the slide loop; see placeCharts
+	var pending []pendingChart
+	w := newDeckWriter(pres, opts)
 
-	// Full cleanup of existing slides: remove all existing slides
-	if existing > 0 {
-		var delReqs []*slides.Request
-		for _, sld := range pres.Slides {
-			if sld != nil && sld.ObjectId != "" {
-				delReqs = append(delReqs, &slides.Request{DeleteObject: &slides.DeleteObjectRequest{ObjectId: sld.ObjectId}})
-			}
+	// Create slides sequentially per topic below
+	if opts.Agenda && len(topics) >= 2 {
+		requests = append(requests, w.agendaRequests(topics)...)
+	}
+	for i, t := range topics {
+		reqs, chart := w.topicRequests(i, t, -1)
+		requests = append(requests, reqs...)
+		if chart != nil {
+			pending = append(pending, *chart)
 		}
-		if len(delReqs) > 0 {
-			if _, err := slidesSvc.Presentations.BatchUpdate(presentationID, &slides.BatchUpdatePresentationRequest{Requests: delReqs}).Context(ctx).Do(); err != nil {
-				return fmt.Errorf("delete existing slides: %w", err)
+	}
+	if w.agendaID != "" {
+		requests = append(requests, w.agendaLinkRequests(topics)...)
+	}
+	requests = append(requests, w.risksRequests(opts.Risks)...)
+	if opts.Quote != nil {
+		requests = append(requests, quoteRequests(*opts.Quote, opts, w.runID)...)
+	}
+	qaReqs := w.qaRequests(opts.QA)
+	requests = append(requests, qaReqs...)
+	if opts.Footer != "" {
+		requests = append(requests, footerRequests(requests, opts.Footer, w.layout)...)
+	}
+
+	// Full cleanup of the existing slides and the chart build touch different APIs, so they
+	// run side by side; the new slides go in once both are done.
+	var chartRequests []*slides.Request
+	var failed map[int]error
+	stages := []func(context.Context) error{
+		func(ctx context.Context) error {
+			return deleteSlides(ctx, slidesSvc, presentationID, pres.Slides)
+		},
+		func(ctx context.Context) error {
+			build := func(ctx context.Context, sheetsSvc charts.SheetsAPI, spreadsheetID string, jobs []charts.ChartJob) ([]int64, error) {
+				return charts.BuildDeckCharts(ctx, sheetsSvc, spreadsheetID, presentationID, jobs)
+			}
+			chartRequests, failed = placeCharts(ctx, sheetsSvc, spreadsheetID, pending, build, opts.ChartFallback, opts.Workers)
+			if opts.UnlinkedCharts {
+				unlinkCharts(chartRequests)
 			}
+			return nil
+		},
+	}
+	if err := pipeline.Run(ctx, len(stages), len(stages), func(ctx context.Context, i int) error { return stages[i](ctx) }); err != nil {
+		return err
+	}
+	requests = append(requests, chartRequests...)
+	scaleToPage(requests, w.layout, pres)
+
+	if len(requests) == 0 {
+		return nil
+	}
+
+	err = slidesSvc.BatchUpdate(ctx, presentationID, requests)
+	if err != nil {
+		return fmt.Errorf("batch update: %w", err)
+	}
+	if opts.Manifest != nil {
+		*opts.Manifest = buildManifest(presentationID, w.runID, requests)
+	}
+	chartErr := chartFailures(failed, func(i int) string { return topics[i].Title })
+	if len(qaReqs) > 0 {
+		if err := writeQANotes(ctx, slidesSvc, presentationID, w.id("qa_slide", -1), opts.QA); err != nil {
+			chartErr = errors.Join(chartErr, err)
+		}
+	}
+	if opts.WordsPerMinute > 0 {
+		if err := writeTimingNotes(ctx, slidesSvc, presentationID, opts.WordsPerMinute); err != nil {
+			return errors.Join(chartErr, err)
 		}
-		existing = 0
 	}
+	broken, err := verifyCharts(ctx, slidesSvc, presentationID, spreadsheetID, linkedChartIDs(requests))
+	markUnloaded(failed, broken, w.runID)
+	if opts.Status != nil {
+		*opts.Status = topicStatuses(topics, 0, failed)
+	}
+	return errors.Join(chartErr, err)
+}
 
-	// Spreadsheet cleanup: remove prior generated tabs and all chart sheets
-	if err := charts.CleanupSpreadsheetForCharts(ctx, sheetsSvc, spreadsheetID); err != nil {
+// ReplaceTopic rebuilds one topic of a deck written by WriteDeck and leaves the rest alone.
+// index is the topic's 0-based position in that deck: its title, summary, and chart slides
+// are found by object ID, deleted, and recreated in the same place. The new chart gets a
+// data tab of its own without cleaning up earlier runs (see charts.AddCharts), so the
+// replaced chart's tab stays until the next full WriteDeck removes it. If the deck has an
+// agenda, the topic's line is retitled and linked to the new title slide.
+func ReplaceTopic(ctx context.Context, slidesSvc SlidesAPI, sheetsSvc charts.SheetsAPI, spreadsheetID string, presentationID string, index int, topic RichTopic, opts DeckOptions) error {
+	if err := checkServices(slidesSvc, sheetsSvc, spreadsheetID, opts); err != nil {
 		return err
 	}
+	pres, err := slidesSvc.Get(ctx, presentationID)
+	if err != nil {
+		return fmt.Errorf("get presentation: %w", err)
+	}
+	slideIDs, at := topicSlides(pres, index)
+	if len(slideIDs) == 0 {
+		return fmt.Errorf("no slides found for topic %d; rebuild the whole deck instead", index+1)
+	}
 
-	// Create slides sequentially per topic below
+	var requests []*slides.Request
+	for _, id := range slideIDs {
+		requests = append(requests, &slides.Request{DeleteObject: &slides.DeleteObjectRequest{ObjectId: id}})
+	}
+	w := newDeckWriter(pres, opts)
+	entry, hasAgenda := findAgendaEntry(pres, index)
+	if hasAgenda {
+		w.agendaID = entry.slideID
+	}
+	reqs, chart := w.topicRequests(index, topic, at)
+	requests = append(requests, reqs...)
+	if hasAgenda {
+		requests = append(requests, entry.relinkRequests(w.agendaLine(topic), w.titleSlides[index])...)
+	}
+	if opts.Footer != "" {
+		requests = append(requests, footerRequests(reqs, opts.Footer, w.layout)...)
+	}
+	failed := map[int]error{}
+	if chart != nil {
+		var chartRequests []*slides.Request
+		chartRequests, failed = placeCharts(ctx, sheetsSvc, spreadsheetID, []pendingChart{*chart}, charts.AddCharts, opts.ChartFallback, opts.Workers)
+		if opts.UnlinkedCharts {
+			unlinkCharts(chartRequests)
+		}
+		requests = append(requests, chartRequests...)
+	}
+	scaleToPage(requests, w.layout, pres)
 
-	for i := 0; i < need; i++ {
-		// 1) Title + image slide
-		suffix := uuid.New().String()[:8]
-		titleSlideID := ""
-		if i < existing {
-			slide := pres.Slides[i]
-			if slide != nil {
-				for _, el := range slide.PageElements {
-					if el == nil || el.ObjectId == "" {
-						continue
-					}
-					requests = append(requests, &slides.Request{DeleteObject: &slides.DeleteObjectRequest{ObjectId: el.ObjectId}})
+	err = slidesSvc.BatchUpdate(ctx, presentationID, requests)
+	if err != nil {
+		return fmt.Errorf("batch update: %w", err)
+	}
+	if opts.Manifest != nil {
+		*opts.Manifest = buildManifest(presentationID, w.runID, requests)
+	}
+	chartErr := chartFailures(failed, func(int) string { return topic.Title })
+	if opts.WordsPerMinute > 0 {
+		if err := writeTimingNotes(ctx, slidesSvc, presentationID, opts.WordsPerMinute); err != nil {
+			return errors.Join(chartErr, err)
+		}
+	}
+	broken, err := verifyCharts(ctx, slidesSvc, presentationID, spreadsheetID, linkedChartIDs(requests))
+	markUnloaded(failed, broken, w.runID)
+	if opts.Status != nil {
+		*opts.Status = topicStatuses([]RichTopic{topic}, index, failed)
+	}
+	return errors.Join(chartErr, err)
+}
+
+// deleteSlides removes every slide in one batch.
+func deleteSlides(ctx context.Context, svc SlidesAPI, presentationID string, pages []*slides.Page) error {
+	var delReqs []*slides.Request
+	for _, sld := range pages {
+		if sld != nil && sld.ObjectId != "" {
+			delReqs = append(delReqs, &slides.Request{DeleteObject: &slides.DeleteObjectRequest{ObjectId: sld.ObjectId}})
+		}
+	}
+	if len(delReqs) == 0 {
+		return nil
+	}
+	if err := svc.BatchUpdate(ctx, presentationID, delReqs); err != nil {
+		return fmt.Errorf("delete existing slides: %w", err)
+	}
+	return nil
+}
+
+// DeleteGenerated removes everything WriteTopics and WriteDeck created in the presentation:
+// slides with generated object IDs, and generated text boxes, images, and charts on slides
+// WriteTopics re-used. Other slides and elements are kept; content the writers replaced
+// is not restored. Returns: the number of slides and elements deleted, error.
+func DeleteGenerated(ctx context.Context, svc SlidesAPI, presentationID string) (int, error) {
+	if svc == nil {
+		return 0, fmt.Errorf("slides service is nil")
+	}
+	pres, err := svc.Get(ctx, presentationID)
+	if err != nil {
+		return 0, fmt.Errorf("get presentation: %w", err)
+	}
+	var requests []*slides.Request
+	for _, sld := range pres.Slides {
+		if sld == nil {
+			continue
+		}
+		if isGenerated(sld.ObjectId) {
+			requests = append(requests, &slides.Request{DeleteObject: &slides.DeleteObjectRequest{ObjectId: sld.ObjectId}})
+			continue
+		}
+		for _, el := range sld.PageElements {
+			if el != nil && isGenerated(el.ObjectId) {
+				requests = append(requests, &slides.Request{DeleteObject: &slides.DeleteObjectRequest{ObjectId: el.ObjectId}})
+			}
+		}
+	}
+	if len(requests) == 0 {
+		return 0, nil
+	}
+	if err := svc.BatchUpdate(ctx, presentationID, requests); err != nil {
+		return 0, fmt.Errorf("delete generated objects: %w", err)
+	}
+	return len(requests), nil
+}
+
+// isGenerated reports whether an object ID follows the writers' auto_<role>_<n>_<suffix> scheme.
+func isGenerated(objectID string) bool {
+	return strings.HasPrefix(objectID, "auto_")
+}
+
+// topicSlides returns the IDs of the slides WriteDeck created for the 0-based topic index,
+// and the position of the first one.
+func topicSlides(pres *slides.Presentation, index int) ([]string, int) {
+	prefixes := []string{
+		fmt.Sprintf("auto_slide_%d_", index),
+		fmt.Sprintf("auto_summary_%d_", index),
+		fmt.Sprintf("auto_chart_slide_%d_", index),
+		fmt.Sprintf("auto_stat_slide_%d_", index),
+		fmt.Sprintf("auto_code_slide_%d_", index),
+	}
+	var ids []string
+	at := -1
+	for i, sld := range pres.Slides {
+		if sld == nil {
+			continue
+		}
+		for _, p := range prefixes {
+			if strings.HasPrefix(sld.ObjectId, p) {
+				ids = append(ids, sld.ObjectId)
+				if at < 0 {
+					at = i
 				}
-				titleSlideID = slide.ObjectId
+				break
 			}
 		}
-		if titleSlideID == "" {
-			titleSlideID = fmt.Sprintf("auto_slide_%d_%s", i, suffix)
-			requests = append(requests, &slides.Request{CreateSlide: &slides.CreateSlideRequest{
-				ObjectId:             titleSlideID,
-				SlideLayoutReference: &slides.LayoutReference{PredefinedLayout: "BLANK"},
-			}})
+	}
+	return ids, at
+}
+
+// checkServices validates the services and chart settings WriteDeck and ReplaceTopic need.
+func checkServices(slidesSvc SlidesAPI, sheetsSvc charts.SheetsAPI, spreadsheetID string, opts DeckOptions) error {
+	if slidesSvc == nil {
+		return fmt.Errorf("slides service is nil")
+	}
+	useSheets := spreadsheetID != ""
+	if useSheets && sheetsSvc == nil {
+		return fmt.Errorf("sheets service is nil")
+	}
+	if !useSheets && opts.ChartFallback == nil {
+		return fmt.Errorf("spreadsheet ID is required without a chart image fallback")
+	}
+	return nil
+}
+
+// deckWriter holds what the requests of every topic in a deck share.
+type deckWriter struct {
+	processor   *formatting.TextProcessor
+	paragraphs  formatting.ParagraphStyles
+	opts        DeckOptions
+	runID       string
+	deck        string // presentation ID, tagged on chart sheets; see charts.MetadataDeckKey
+	bodyLayout  string // TITLE_AND_BODY layout for summaries; "" uses free text boxes
+	layout      Layout
+	place       *placer
+	titleStyle  TitleStyle
+	headingFont string // the theme's title font; see headingFont
+
+	// With an agenda, title slides link back to agendaID, whose agendaBodyID lines link
+	// to titleSlides (title slide ID by topic index).
+	agendaID     string
+	agendaBodyID string
+	titleSlides  map[int]string
+}
+
+func newDeckWriter(pres *slides.Presentation, opts DeckOptions) *deckWriter {
+	w := &deckWriter{processor: formatting.NewTextProcessor(), paragraphs: formatting.DefaultParagraphStyles(), opts: opts, runID: opts.RunID, deck: pres.PresentationId, titleSlides: map[int]string{}, layout: DefaultLayout()}
+	if opts.Palette != nil {
+		if r, g, b, err := palette.RGB(opts.Palette.Accent); err == nil {
+			w.processor.SetBoldColor(r, g, b)
 		}
+	}
+	if opts.Paragraphs != nil {
+		w.paragraphs = *opts.Paragraphs
+		w.processor.SetParagraphStyles(w.paragraphs)
+	}
+	if opts.Layout != nil {
+		w.layout = *opts.Layout
+	}
+	w.titleStyle = DefaultTitleStyle()
+	if opts.TitleStyle != nil {
+		w.titleStyle = *opts.TitleStyle
+	}
+	w.headingFont = headingFont(pres)
+	w.place = newPlacer(w.layout.PageWidth, w.layout.PageHeight)
+	if w.runID == "" {
+		w.runID = uuid.New().String()[:8]
+	}
+	if opts.Placeholders {
+		w.bodyLayout = titleAndBodyLayout(pres)
+	}
+	return w
+}
 
-		titleID := fmt.Sprintf("auto_title_%d_%s", i, suffix)
-		imageID := fmt.Sprintf("auto_image_%d_%s", i, suffix)
+// id is the deterministic object ID of a generated object: auto_<role>_<topic>_<run ID> for
+// one of the 0-based topic, or auto_<role>_<run ID> for a deck-level one (topic < 0). A
+// rebuild with the same run ID gives every object the same ID again.
+func (w *deckWriter) id(role string, topic int) string {
+	if topic < 0 {
+		return "auto_" + role + "_" + w.runID
+	}
+	return fmt.Sprintf("auto_%s_%d_%s", role, topic, w.runID)
+}
 
+// titleAndBodyLayout returns the ID of the deck's TITLE_AND_BODY layout, or "" when it has
+// none or the layout lacks a title or body placeholder.
+func titleAndBodyLayout(pres *slides.Presentation) string {
+	for _, layout := range pres.Layouts {
+		if layout == nil || layout.LayoutProperties == nil || layout.LayoutProperties.Name != "TITLE_AND_BODY" {
+			continue
+		}
+		found := map[string]bool{}
+		for _, el := range layout.PageElements {
+			if el != nil && el.Shape != nil && el.Shape.Placeholder != nil && el.Shape.Placeholder.Index == 0 {
+				found[el.Shape.Placeholder.Type] = true
+			}
+		}
+		if found["TITLE"] && found["BODY"] {
+			return layout.ObjectId
+		}
+	}
+	return ""
+}
+
+// topicRequests builds the slides for topic i: a title slide, a summary slide, and, for a
+// dataset, a chart slide unless the chart sits inline. Slides are appended to the deck when
+// insertAt is negative and inserted from that position otherwise. The chart itself is
+// returned for placeCharts.
+func (w *deckWriter) topicRequests(i int, t RichTopic, insertAt int) ([]*slides.Request, *pendingChart) {
+	processor, paragraphs, opts, runID, layout := w.processor, w.paragraphs, w.opts, w.runID, w.layout
+	var requests []*slides.Request
+	var chart *pendingChart
+	createSlide := func(id string) *slides.Request {
+		req := &slides.CreateSlideRequest{
+			ObjectId:             id,
+			SlideLayoutReference: &slides.LayoutReference{PredefinedLayout: "BLANK"},
+		}
+		if insertAt >= 0 {
+			req.InsertionIndex = int64(insertAt)
+			req.ForceSendFields = []string{"InsertionIndex"} // 0 is the first slide
+			insertAt++
+		}
+		if opts.Footer != "" {
+			w.place.reserve(id, footerRect(layout))
+		}
+		return &slides.Request{CreateSlide: req}
+	}
+
+	// 1) Title + image slide
+	titleSlideID := w.id("slide", i)
+	requests = append(requests, createSlide(titleSlideID))
+	w.titleSlides[i] = titleSlideID
+	if w.agendaID != "" {
+		backLink := backLinkRect(layout)
+		requests = append(requests, backLinkRequests(w.id("back", i), titleSlideID, w.agendaID, backLink)...)
+		w.place.reserve(titleSlideID, backLink)
+	}
+
+	titleID := w.id("title", i)
+	imageID := w.id("image", i)
+	iconID := w.id("icon", i)
+
+	requests = append(requests, paddedBox(titleID, titleSlideID, layout.Title.rect(), layout.Padding), fitRequest(titleID, alignMiddle))
+
+	w.place.reserve(titleSlideID, layout.Title.rect())
+
+	titleSegments := processor.ParseMarkup(t.Title)
+	titleRequests := processor.TitleRequests(titleSegments, titleID)
+	requests = append(requests, w.titleStyle.styleTitle(titleRequests, titleID, w.headingFont, opts.Palette)...)
+
+	titleBlock := []string{titleID}
+	if opts.Palette != nil {
+		dividerID := w.id("divider", i)
+		divider := dividerRect(layout.Title, paragraphs.TitleAlignment())
+		if reqs := dividerRequests(dividerID, titleSlideID, opts.Palette.Accent, divider); len(reqs) > 0 {
+			requests = append(requests, reqs...)
+			titleBlock = append(titleBlock, dividerID)
+			w.place.reserve(titleSlideID, divider)
+		}
+	}
+
+	if t.IconURL != "" {
+		icon := iconRect(layout.Title)
 		requests = append(requests,
-			&slides.Request{CreateShape: &slides.CreateShapeRequest{
-				ObjectId:  titleID,
-				ShapeType: "TEXT_BOX",
+			&slides.Request{CreateImage: &slides.CreateImageRequest{
+				ObjectId: iconID,
+				Url:      t.IconURL,
 				ElementProperties: &slides.PageElementProperties{
 					PageObjectId: titleSlideID,
 					Size: &slides.Size{
-						Width:  &slides.Dimension{Magnitude: 600, Unit: "PT"},
-						Height: &slides.Dimension{Magnitude: 60, Unit: "PT"},
+						Width:  &slides.Dimension{Magnitude: icon.W, Unit: "PT"},
+						Height: &slides.Dimension{Magnitude: icon.H, Unit: "PT"},
 					},
-					Transform: &slides.AffineTransform{ScaleX: 1, ScaleY: 1, TranslateX: 50, TranslateY: 50, Unit: "PT"},
+					Transform: &slides.AffineTransform{ScaleX: 1, ScaleY: 1, TranslateX: icon.X, TranslateY: icon.Y, Unit: "PT"},
 				},
 			}},
 		)
+		titleBlock = append(titleBlock, iconID)
+		w.place.reserve(titleSlideID, icon)
+	}
+	if opts.GroupComposites {
+		requests = append(requests, groupRequests(w.id("title_group", i), titleBlock)...)
+	}
 
-		titleSegments := processor.ParseMarkup(topics[i].Title)
-		titleRequests := processor.ToSlidesRequests(titleSegments, titleID)
-		requests = append(requests, titleRequests...)
-
-		if topics[i].ImageURL != "" {
-			requests = append(requests,
-				&slides.Request{CreateImage: &slides.CreateImageRequest{
-					ObjectId: imageID,
-					Url:      topics[i].ImageURL,
-					ElementProperties: &slides.PageElementProperties{
-						PageObjectId: titleSlideID,
-						Size: &slides.Size{
-							Width:  &slides.Dimension{Magnitude: 400, Unit: "PT"},
-							Height: &slides.Dimension{Magnitude: 300, Unit: "PT"},
-						},
-						Transform: &slides.AffineTransform{ScaleX: 1, ScaleY: 1, TranslateX: 50, TranslateY: 130, Unit: "PT"},
-					},
-				}},
-			)
-		}
-
-		// 2) Summary slide
-		summarySlideID := fmt.Sprintf("auto_summary_%d_%s", i, suffix)
-		requests = append(requests, &slides.Request{CreateSlide: &slides.CreateSlideRequest{
-			ObjectId:             summarySlideID,
-			SlideLayoutReference: &slides.LayoutReference{PredefinedLayout: "BLANK"},
-		}})
-		bodyID := fmt.Sprintf("auto_summary_body_%d_%s", i, suffix)
+	if t.ImageURL != "" {
+		img, capt := w.place.imageRects(titleSlideID, opts.ImagePosition, layout.Image.rect(), layout.Title.rect(), t.ImageCaption != "")
 		requests = append(requests,
-			&slides.Request{CreateShape: &slides.CreateShapeRequest{
-				ObjectId:  bodyID,
-				ShapeType: "TEXT_BOX",
+			&slides.Request{CreateImage: &slides.CreateImageRequest{
+				ObjectId: imageID,
+				Url:      t.ImageURL,
 				ElementProperties: &slides.PageElementProperties{
-					PageObjectId: summarySlideID,
+					PageObjectId: titleSlideID,
 					Size: &slides.Size{
-						Width:  &slides.Dimension{Magnitude: 600, Unit: "PT"},
-						Height: &slides.Dimension{Magnitude: 300, Unit: "PT"},
+						Width:  &slides.Dimension{Magnitude: img.W, Unit: "PT"},
+						Height: &slides.Dimension{Magnitude: img.H, Unit: "PT"},
 					},
-					Transform: &slides.AffineTransform{ScaleX: 1, ScaleY: 1, TranslateX: 50, TranslateY: 130, Unit: "PT"},
+					Transform: &slides.AffineTransform{ScaleX: 1, ScaleY: 1, TranslateX: img.X, TranslateY: img.Y, Unit: "PT"},
 				},
 			}},
 		)
-		bodySegments := processor.ParseMarkup(topics[i].Summary)
-		bodyRequests := processor.ToSlidesRequests(bodySegments, bodyID)
-		requests = append(requests, bodyRequests...)
+		if t.ImageCaption != "" {
+			captionID := w.id("caption", i)
+			requests = append(requests, captionRequests(captionID, titleSlideID, t.ImageCaption, capt)...)
+			if opts.GroupComposites {
+				requests = append(requests, groupRequests(w.id("image_group", i), []string{imageID, captionID})...)
+			}
+		}
+	}
 
-		// If dataset present, write data to provided spreadsheet and embed the chart
-		// 3) Chart slide
-		if topics[i].Dataset != nil && len(topics[i].Dataset.Points) > 0 {
-			chartSlideID := fmt.Sprintf("auto_chart_slide_%d_%s", i, suffix)
-			requests = append(requests, &slides.Request{CreateSlide: &slides.CreateSlideRequest{
-				ObjectId:             chartSlideID,
-				SlideLayoutReference: &slides.LayoutReference{PredefinedLayout: "BLANK"},
-			}})
-			ds := charts.DatasetSpec{Title: topics[i].Dataset.Title, Unit: topics[i].Dataset.Unit, Type: topics[i].Dataset.Type}
-			for _, p := range topics[i].Dataset.Points {
-				ds.Points = append(ds.Points, charts.Point{Label: p.Label, Value: p.Value})
+	// 2) Summary slide
+	summarySlideID := w.id("summary", i)
+	summarySlide := createSlide(summarySlideID)
+	requests = append(requests, summarySlide)
+	bodyID := w.id("summary_body", i)
+	timeline := opts.Timeline != TimelineOff && t.Dataset != nil && strings.EqualFold(t.Dataset.Type, "timeseries") && len(t.Dataset.Points) >= 2
+	inline := opts.InlineSmallCharts && !timeline && t.Stat == nil && t.Dataset != nil && len(t.Dataset.Points) > 0 && len(t.Dataset.Points) <= InlineChartMaxPoints
+	bodyWidth := layout.Body.W
+	if inline {
+		// Leave the right side of the slide for the mini chart
+		bodyWidth = layout.Body.W * inlineBodyShare
+	}
+	summary, codeBlocks := processor.SplitCodeBlocks(t.Summary)
+	summary, tables := processor.SplitTables(summary)
+	// A flow diagram takes the area under the summary unless code or a table needs it
+	flow := len(t.Steps) >= 2 && len(codeBlocks) == 0 && len(tables) == 0
+	bodyHeight := layout.Body.H
+	shortened := len(codeBlocks) > 0 || len(tables) > 0 || flow
+	if shortened {
+		bodyHeight = layout.Body.shortened()
+	}
+	body := rect{X: layout.Body.X, Y: layout.Body.Y, W: bodyWidth, H: bodyHeight}
+	if w.bodyLayout != "" && !inline && !flow && len(codeBlocks) == 0 && len(tables) == 0 {
+		// The layout positions and styles both boxes; only the text is ours
+		summaryTitleID := w.id("summary_title", i)
+		summarySlide.CreateSlide.SlideLayoutReference = &slides.LayoutReference{LayoutId: w.bodyLayout}
+		summarySlide.CreateSlide.PlaceholderIdMappings = []*slides.LayoutPlaceholderIdMapping{
+			{LayoutPlaceholder: &slides.Placeholder{Type: "TITLE"}, ObjectId: summaryTitleID},
+			{LayoutPlaceholder: &slides.Placeholder{Type: "BODY"}, ObjectId: bodyID},
+		}
+		titleRequests := processor.TitleRequests(processor.ParseMarkup(t.Title), summaryTitleID)
+		requests = append(requests, withTextColor(titleRequests, summaryTitleID, opts.Palette, func(p *palette.Palette) string { return p.Primary })...)
+	} else {
+		requests = append(requests, paddedBox(bodyID, summarySlideID, body, layout.Padding), fitRequest(bodyID, alignTop))
+	}
+	w.place.reserve(summarySlideID, body)
+	bodySegments := processor.ParseMarkup(summary)
+	bodyRequests := processor.ToSlidesRequests(bodySegments, bodyID)
+	requests = append(requests, withTextColor(bodyRequests, bodyID, opts.Palette, func(p *palette.Palette) string { return p.Text })...)
+	lower := layout.Body.lower()
+	lower.W = bodyWidth
+	requests = append(requests, lowerBoxRequests(processor, i, runID, summarySlideID, codeBlocks, tables, lower)...)
+	if shortened {
+		w.place.reserve(summarySlideID, lower)
+	}
+	if flow {
+		flowReqs, flowIDs := flowRequests(w.id("flow", i), summarySlideID, t.Steps, lower.X, lower.Y, lower.W, lower.H, opts.Palette)
+		requests = append(requests, flowReqs...)
+		if opts.GroupComposites {
+			requests = append(requests, groupRequests(w.id("flow_group", i), flowIDs)...)
+		}
+	}
+
+	if strings.TrimSpace(t.Code) != "" {
+		// Code slide: the snippet fills a dark box under a small heading
+		codeSlideID := w.id("code_slide", i)
+		requests = append(requests, createSlide(codeSlideID))
+		heading := t.Title
+		if t.CodeLanguage != "" {
+			heading += " · " + t.CodeLanguage
+		}
+		gray := &slides.OpaqueColor{RgbColor: &slides.RgbColor{Red: 0.4, Green: 0.4, Blue: 0.4}}
+		requests = append(requests, textBoxRequests(w.id("code_heading", i), codeSlideID, heading, layout.Body.X, 15, layout.Body.W, 30, 14, false, gray)...)
+		requests = append(requests, codeBoxRequests(processor, w.id("code_snippet", i), codeSlideID, []string{t.Code}, layout.Body.X, codeSlideTop, layout.Body.W, codeSlideHeight)...)
+	}
+
+	if t.Stat != nil {
+		// 3) Big-number slide in place of the chart
+		statSlideID := w.id("stat_slide", i)
+		requests = append(requests, createSlide(statSlideID))
+		statReqs, statIDs := statRequests(w.id("stat", i), statSlideID, *t.Stat, opts.Palette)
+		requests = append(requests, statReqs...)
+		if opts.GroupComposites {
+			requests = append(requests, groupRequests(w.id("stat_group", i), statIDs)...)
+		}
+		return requests, nil
+	}
+
+	// If dataset present, write data to provided spreadsheet and embed the chart
+	// 3) Chart slide, or a mini chart beside the summary for tiny datasets
+	if t.Dataset != nil && len(t.Dataset.Points) > 0 {
+		chartSlideID := summarySlideID
+		frame := layout.Chart.frame()
+		if inline {
+			frame = layout.InlineChart.frame()
+		} else {
+			chartSlideID = w.id("chart_slide", i)
+			requests = append(requests, createSlide(chartSlideID))
+		}
+		if timeline {
+			y, title := timelineUnderChart, ""
+			if opts.Timeline == TimelineReplace {
+				y, title = timelineAlone, t.Dataset.Title
+				if title == "" {
+					title = t.Title
+				}
+			}
+			tlReqs, tlIDs := timelineRequests(w.id("timeline", i), chartSlideID, title, t.Dataset, 60, y, 600, opts.Palette)
+			requests = append(requests, tlReqs...)
+			if opts.GroupComposites {
+				requests = append(requests, groupRequests(w.id("timeline_group", i), tlIDs)...)
 			}
-			// Use a per-topic sheet title to avoid collisions
-			perSheet := fmt.Sprintf("Data_%d", i+1)
-			chartID, err := charts.CreateSheetsChart(ctx, sheetsSvc, spreadsheetID, perSheet, ds)
-			if err != nil {
-				return fmt.Errorf("create sheets chart for topic %q: %w", topics[i].Title, err)
+			if opts.Timeline == TimelineReplace {
+				return requests, nil
 			}
-			chartObjectID := fmt.Sprintf("auto_chart_%d_%s", i, suffix)
-			embed := charts.BuildEmbedRequests(spreadsheetID, chartID, chartSlideID, chartObjectID, 100000.0, 160000.0, 4000000.0, 3000000.0)
-			requests = append(requests, embed...)
+			w.place.reserve(chartSlideID, timelineRect(60, y, 600))
+		}
+		frame = w.place.placeFrame(chartSlideID, frame)
+		ds := charts.DatasetSpec{Title: t.Dataset.Title, Unit: t.Dataset.Unit, Type: t.Dataset.Type, Series: t.Dataset.Series}
+		ds.Stacked = charts.StackedType(t.Dataset.Type, t.Dataset.Stack, len(t.Dataset.Series))
+		ds.Trend, ds.TrendWindow = t.Dataset.Trend, t.Dataset.TrendWindow
+		ds.SourceRange = t.Dataset.SourceRange
+		ds.LogScale, ds.MinValue = t.Dataset.LogScale, t.Dataset.AxisMin
+		ds.Source = t.Dataset.Source
+		ds.Options = opts.Chart
+		if opts.Palette != nil {
+			ds.SeriesColors = opts.Palette.SeriesColors()
+		}
+		if inline && len(ds.Series) < 2 {
+			// A single-series legend only repeats the title at mini size
+			ds.Options.Legend = "NO_LEGEND"
+		}
+		for _, p := range t.Dataset.Points {
+			ds.Points = append(ds.Points, charts.Point{Label: p.Label, Value: p.Value, Values: p.Values})
+		}
+		chart = &pendingChart{
+			job:      charts.ChartJob{SheetTitle: charts.SheetTitle(runID, i+1, t.Title), Tag: charts.ChartTag{RunID: runID, Index: i + 1, Topic: t.Title, Deck: w.deck}, Dataset: ds},
+			slideID:  chartSlideID,
+			objectID: w.id("chart", i),
+			frame:    frame,
+		}
+		if t.Dataset.Estimate {
+			chart.badgeID = w.id("estimate_badge", i)
 		}
 	}
+	return requests, chart
+}
 
-	if len(requests) == 0 {
+// pendingChart is a topic chart waiting for its Sheets chart (or fallback image).
+type pendingChart struct {
+	job      charts.ChartJob
+	slideID  string
+	objectID string
+	frame    chartFrame
+	badgeID  string // set for an estimate, whose badge goes over the chart once it's embedded
+}
+
+// badge returns the requests adding the chart's estimate badge, if it has one.
+func (pc pendingChart) badge() []*slides.Request {
+	if pc.badgeID == "" {
 		return nil
 	}
+	return estimateBadgeRequests(pc.badgeID, pc.slideID, pc.frame)
+}
 
-	_, err = slidesSvc.Presentations.BatchUpdate(presentationID, &slides.BatchUpdatePresentationRequest{Requests: requests}).Context(ctx).Do()
+// placeCharts builds every Sheets chart in one batched pass through build (BuildCharts also
+// cleans up prior runs) and returns the Slides requests embedding them. Without a
+// spreadsheet, or when the batch fails and a fallback is set, each chart becomes a
+// fallback image instead, with up to workers images made at once. Charts Sheets can't
+// draw (see charts.DatasetSpec.NeedsImage) become fallback images whenever one is set.
+// A chart that ends up with neither is left out and its error returned under its topic's
+// 0-based index, so one bad chart doesn't cost the rest of the deck.
+func placeCharts(ctx context.Context, sheetsSvc charts.SheetsAPI, spreadsheetID string, pending []pendingChart, build func(context.Context, charts.SheetsAPI, string, []charts.ChartJob) ([]int64, error), fallback func(context.Context, charts.DatasetSpec, error) (string, error), workers int) ([]*slides.Request, map[int]error) {
+	var requests []*slides.Request
+	failed := map[int]error{}
+	var chartErr error
+	images := pending
+	if spreadsheetID != "" {
+		var built []pendingChart
+		images = nil
+		for _, pc := range pending {
+			if fallback != nil && pc.job.Dataset.NeedsImage() {
+				images = append(images, pc)
+			} else {
+				built = append(built, pc)
+			}
+		}
+		jobs := make([]charts.ChartJob, len(built))
+		for i, pc := range built {
+			jobs[i] = pc.job
+		}
+		ids, err := build(ctx, sheetsSvc, spreadsheetID, jobs)
+		switch {
+		case err == nil:
+			for i, pc := range built {
+				requests = append(requests, charts.BuildEmbedRequests(spreadsheetID, ids[i], pc.slideID, pc.objectID, pc.frame.X, pc.frame.Y, pc.frame.W, pc.frame.H)...)
+				requests = append(requests, pc.badge()...)
+			}
+		case fallback == nil:
+			for _, pc := range built {
+				failed[pc.job.Tag.Index-1] = fmt.Errorf("create sheets charts: %w", err)
+			}
+		default:
+			chartErr = fmt.Errorf("create sheets charts: %w", err)
+			requests, images = nil, pending
+		}
+	}
+	if len(images) == 0 {
+		return requests, failed
+	}
+	urls := make([]string, len(images))
+	errs := make([]error, len(images))
+	_ = pipeline.Run(ctx, len(images), workers, func(ctx context.Context, i int) error {
+		urls[i], errs[i] = fallback(ctx, images[i].job.Dataset, chartErr)
+		return errs[i]
+	})
+	for i, pc := range images {
+		if errs[i] == nil && urls[i] != "" {
+			requests = append(requests, chartImageRequest(pc.objectID, pc.slideID, urls[i], pc.frame))
+			requests = append(requests, pc.badge()...)
+			continue
+		}
+		err := errs[i]
+		if err == nil {
+			err = ctx.Err() // never started
+		}
+		if err == nil {
+			err = errors.New("fallback returned no image URL")
+		}
+		if chartErr != nil {
+			err = fmt.Errorf("%w (image fallback: %v)", chartErr, err)
+		} else {
+			err = fmt.Errorf("chart image: %w", err)
+		}
+		failed[pc.job.Tag.Index-1] = err
+	}
+	return requests, failed
+}
+
+// chartFrame is a chart's position and size on its slide, in EMU.
+type chartFrame struct {
+	X, Y, W, H float64
+}
+
+// Timeline heights on the chart slide, in points: under the full chart frame, or centered
+// when it replaces the chart.
+const (
+	timelineUnderChart = 320.0
+	timelineAlone      = 220.0
+)
+
+// chartImageRequest places a rendered chart image in the same frame BuildEmbedRequests uses for Sheets charts.
+func chartImageRequest(objectID, slideID, url string, frame chartFrame) *slides.Request {
+	return &slides.Request{CreateImage: &slides.CreateImageRequest{
+		ObjectId: objectID,
+		Url:      url,
+		ElementProperties: &slides.PageElementProperties{
+			PageObjectId: slideID,
+			Size: &slides.Size{
+				Width:  &slides.Dimension{Magnitude: frame.W, Unit: "EMU"},
+				Height: &slides.Dimension{Magnitude: frame.H, Unit: "EMU"},
+			},
+			Transform: &slides.AffineTransform{ScaleX: 1, ScaleY: 1, TranslateX: frame.X, TranslateY: frame.Y, Unit: "EMU"},
+		},
+	}}
+}
+
+// withTextColor colors a whole text box right after its InsertText request, so later
+// per-range styles (e.g. accent-colored bold) still take precedence.
+func withTextColor(textRequests []*slides.Request, objectID string, p *palette.Palette, pick func(*palette.Palette) string) []*slides.Request {
+	if p == nil {
+		return textRequests
+	}
+	return withColor(textRequests, objectID, pick(p))
+}
+
+// withColor colors the whole text of textRequests hex, right after it is inserted; an
+// empty or invalid hex leaves the requests as they are.
+func withColor(textRequests []*slides.Request, objectID, hex string) []*slides.Request {
+	if len(textRequests) == 0 || textRequests[0].InsertText == nil || textRequests[0].InsertText.Text == "" {
+		return textRequests
+	}
+	r, g, b, err := palette.RGB(hex)
 	if err != nil {
-		return fmt.Errorf("batch update: %w", err)
+		return textRequests
+	}
+	colorReq := &slides.Request{UpdateTextStyle: &slides.UpdateTextStyleRequest{
+		ObjectId:  objectID,
+		Style:     &slides.TextStyle{ForegroundColor: &slides.OptionalColor{OpaqueColor: &slides.OpaqueColor{RgbColor: &slides.RgbColor{Red: r, Green: g, Blue: b}}}},
+		Fields:    "foregroundColor",
+		TextRange: &slides.Range{Type: "ALL"},
+	}}
+	out := make([]*slides.Request, 0, len(textRequests)+1)
+	out = append(out, textRequests[0], colorReq)
+	return append(out, textRequests[1:]...)
+}
+
+// Fenced code and table layout: the summary text shrinks to half the body box and the code
+// box or table takes the third of it below that, lowerBoxGap under the text.
+const (
+	lowerBoxGap = 10.0
+	// inlineBodyShare is the part of the body box's width the summary keeps beside an
+	// inline chart.
+	inlineBodyShare = 0.6
+
+	// A code slide's snippet box spans the slide under a one-line heading.
+	codeSlideTop    = 50.0
+	codeSlideHeight = 330.0
+)
+
+// shortened is the height of the summary text when code, a table, or a flow diagram
+// takes the lower part of the body box.
+func (b Box) shortened() float64 {
+	return b.H / 2
+}
+
+// lower is the area under a shortened summary.
+func (b Box) lower() rect {
+	return rect{X: b.X, Y: b.Y + b.shortened() + lowerBoxGap, W: b.W, H: b.H / 3}
+}
+
+// lowerBoxRequests fills area, under a shortened summary. A table and a code box share it
+// side by side; either alone spans the full width. Only the first table is rendered.
+func lowerBoxRequests(processor *formatting.TextProcessor, i int, suffix, pageID string, codeBlocks []string, tables []formatting.Table, area rect) []*slides.Request {
+	codeID := fmt.Sprintf("auto_code_%d_%s", i, suffix)
+	if len(tables) == 0 {
+		return codeBoxRequests(processor, codeID, pageID, codeBlocks, area.X, area.Y, area.W, area.H)
+	}
+	tableWidth := area.W
+	if len(codeBlocks) > 0 {
+		tableWidth = (area.W - lowerBoxGap) / 2
+	}
+	props := &slides.PageElementProperties{
+		PageObjectId: pageID,
+		Size: &slides.Size{
+			Width:  &slides.Dimension{Magnitude: tableWidth, Unit: "PT"},
+			Height: &slides.Dimension{Magnitude: area.H, Unit: "PT"},
+		},
+		Transform: &slides.AffineTransform{ScaleX: 1, ScaleY: 1, TranslateX: area.X, TranslateY: area.Y, Unit: "PT"},
+	}
+	reqs := processor.TableRequests(tables[0], fmt.Sprintf("auto_table_%d_%s", i, suffix), props)
+	return append(reqs, codeBoxRequests(processor, codeID, pageID, codeBlocks, area.X+tableWidth+lowerBoxGap, area.Y, area.W-tableWidth-lowerBoxGap, area.H)...)
+}
+
+// codeBoxRequests places fenced code blocks, joined by blank lines, in one dark text box
+// with its top-left corner at (x, y): under the summary text, or across a code slide.
+func codeBoxRequests(processor *formatting.TextProcessor, objectID, pageID string, blocks []string, x, y, width, height float64) []*slides.Request {
+	code := strings.Join(blocks, "\n\n")
+	if strings.TrimSpace(code) == "" {
+		return nil
+	}
+	reqs := []*slides.Request{
+		{CreateShape: &slides.CreateShapeRequest{
+			ObjectId:  objectID,
+			ShapeType: "TEXT_BOX",
+			ElementProperties: &slides.PageElementProperties{
+				PageObjectId: pageID,
+				Size: &slides.Size{
+					Width:  &slides.Dimension{Magnitude: width, Unit: "PT"},
+					Height: &slides.Dimension{Magnitude: height, Unit: "PT"},
+				},
+				Transform: &slides.AffineTransform{ScaleX: 1, ScaleY: 1, TranslateX: x, TranslateY: y, Unit: "PT"},
+			},
+		}},
+		{UpdateShapeProperties: &slides.UpdateShapePropertiesRequest{
+			ObjectId: objectID,
+			ShapeProperties: &slides.ShapeProperties{
+				ShapeBackgroundFill: &slides.ShapeBackgroundFill{SolidFill: &slides.SolidFill{Color: &slides.OpaqueColor{RgbColor: &slides.RgbColor{Red: 0.15, Green: 0.2, Blue: 0.22}}}},
+				Outline:             &slides.Outline{PropertyState: "NOT_RENDERED"},
+			},
+			Fields: "shapeBackgroundFill.solidFill.color,outline.propertyState",
+		}},
+		fitRequest(objectID, alignTop),
+	}
+	return append(reqs, processor.CodeBlockRequests(code, objectID)...)
+}
+
+// captionRequests adds small italic gray text in the frame imageRects gives the title
+// slide's image caption.
+func captionRequests(objectID, pageID, text string, frame rect) []*slides.Request {
+	return []*slides.Request{
+		{CreateShape: &slides.CreateShapeRequest{
+			ObjectId:  objectID,
+			ShapeType: "TEXT_BOX",
+			ElementProperties: &slides.PageElementProperties{
+				PageObjectId: pageID,
+				Size: &slides.Size{
+					Width:  &slides.Dimension{Magnitude: frame.W, Unit: "PT"},
+					Height: &slides.Dimension{Magnitude: frame.H, Unit: "PT"},
+				},
+				Transform: &slides.AffineTransform{ScaleX: 1, ScaleY: 1, TranslateX: frame.X, TranslateY: frame.Y, Unit: "PT"},
+			},
+		}},
+		fitRequest(objectID, alignMiddle),
+		{InsertText: &slides.InsertTextRequest{ObjectId: objectID, Text: text}},
+		{UpdateTextStyle: &slides.UpdateTextStyleRequest{
+			ObjectId: objectID,
+			Style: &slides.TextStyle{
+				Italic:          true,
+				FontSize:        &slides.Dimension{Magnitude: 9, Unit: "PT"},
+				ForegroundColor: &slides.OptionalColor{OpaqueColor: &slides.OpaqueColor{RgbColor: &slides.RgbColor{Red: 0.4, Green: 0.4, Blue: 0.4}}},
+			},
+			Fields:    "italic,fontSize,foregroundColor",
+			TextRange: &slides.Range{Type: "ALL"},
+		}},
+	}
+}
+
+// Estimate badge size, in EMU (72 x 20 pt), and its inset from the chart frame's corner.
+const (
+	badgeWidth  = 914400
+	badgeHeight = 254000
+	badgeInset  = 50800
+)
+
+// estimateBadgeRequests adds a small amber "Estimate" tag in the top-right corner of the
+// chart frame; created after the chart, it sits on top of it.
+func estimateBadgeRequests(objectID, pageID string, frame chartFrame) []*slides.Request {
+	return []*slides.Request{
+		{CreateShape: &slides.CreateShapeRequest{
+			ObjectId:  objectID,
+			ShapeType: "ROUND_RECTANGLE",
+			ElementProperties: &slides.PageElementProperties{
+				PageObjectId: pageID,
+				Size: &slides.Size{
+					Width:  &slides.Dimension{Magnitude: badgeWidth, Unit: "EMU"},
+					Height: &slides.Dimension{Magnitude: badgeHeight, Unit: "EMU"},
+				},
+				Transform: &slides.AffineTransform{ScaleX: 1, ScaleY: 1, TranslateX: frame.X + frame.W - badgeWidth - badgeInset, TranslateY: frame.Y + badgeInset, Unit: "EMU"},
+			},
+		}},
+		{UpdateShapeProperties: &slides.UpdateShapePropertiesRequest{
+			ObjectId: objectID,
+			ShapeProperties: &slides.ShapeProperties{
+				ShapeBackgroundFill: &slides.ShapeBackgroundFill{SolidFill: &slides.SolidFill{Color: &slides.OpaqueColor{RgbColor: &slides.RgbColor{Red: 0.98, Green: 0.74, Blue: 0.02}}}},
+				Outline:             &slides.Outline{PropertyState: "NOT_RENDERED"},
+				ContentAlignment:    "MIDDLE",
+			},
+			Fields: "shapeBackgroundFill.solidFill.color,outline.propertyState,contentAlignment",
+		}},
+		{InsertText: &slides.InsertTextRequest{ObjectId: objectID, Text: "Estimate"}},
+		{UpdateTextStyle: &slides.UpdateTextStyleRequest{
+			ObjectId: objectID,
+			Style: &slides.TextStyle{
+				Bold:            true,
+				FontSize:        &slides.Dimension{Magnitude: 9, Unit: "PT"},
+				ForegroundColor: &slides.OptionalColor{OpaqueColor: &slides.OpaqueColor{RgbColor: &slides.RgbColor{Red: 0.13, Green: 0.13, Blue: 0.14}}},
+			},
+			Fields:    "bold,fontSize,foregroundColor",
+			TextRange: &slides.Range{Type: "ALL"},
+		}},
+		{UpdateParagraphStyle: &slides.UpdateParagraphStyleRequest{
+			ObjectId:  objectID,
+			Style:     &slides.ParagraphStyle{Alignment: "CENTER"},
+			Fields:    "alignment",
+			TextRange: &slides.Range{Type: "ALL"},
+		}},
+	}
+}
+
+// groupRequests groups the elements into one object; a single element needs no group.
+func groupRequests(groupID string, children []string) []*slides.Request {
+	if len(children) < 2 {
+		return nil
+	}
+	return []*slides.Request{{GroupObjects: &slides.GroupObjectsRequest{GroupObjectId: groupID, ChildrenObjectIds: children}}}
+}
+
+// dividerRect puts the divider dividerGap under the title box, lined up with the title text.
+func dividerRect(title Box, alignment string) rect {
+	r := rect{X: title.X, Y: title.Y + title.H + dividerGap, W: dividerWidth, H: dividerHeight}
+	switch alignment {
+	case "CENTER":
+		r.X = title.X + (title.W-dividerWidth)/2
+	case "END":
+		r.X = title.X + title.W - dividerWidth
+	}
+	return r
+}
+
+// The accent bar under titles: its length, thickness, and distance from the title box in
+// points.
+const (
+	dividerWidth  = 120.0
+	dividerHeight = 4.0
+	dividerGap    = 6.0
+)
+
+// iconRect puts a topic's icon just right of the title box, a little below its top.
+func iconRect(title Box) rect {
+	return rect{X: title.X + title.W + 10, Y: title.Y + 10, W: 40, H: 40}
+}
+
+// dividerRequests draws a thin accent bar in frame, under the title text box.
+func dividerRequests(objectID, pageID, hex string, frame rect) []*slides.Request {
+	r, g, b, err := palette.RGB(hex)
+	if err != nil {
+		return nil
+	}
+	return []*slides.Request{
+		{CreateShape: &slides.CreateShapeRequest{
+			ObjectId:  objectID,
+			ShapeType: "RECTANGLE",
+			ElementProperties: &slides.PageElementProperties{
+				PageObjectId: pageID,
+				Size: &slides.Size{
+					Width:  &slides.Dimension{Magnitude: frame.W, Unit: "PT"},
+					Height: &slides.Dimension{Magnitude: frame.H, Unit: "PT"},
+				},
+				Transform: &slides.AffineTransform{ScaleX: 1, ScaleY: 1, TranslateX: frame.X, TranslateY: frame.Y, Unit: "PT"},
+			},
+		}},
+		{UpdateShapeProperties: &slides.UpdateShapePropertiesRequest{
+			ObjectId: objectID,
+			ShapeProperties: &slides.ShapeProperties{
+				ShapeBackgroundFill: &slides.ShapeBackgroundFill{SolidFill: &slides.SolidFill{Color: &slides.OpaqueColor{RgbColor: &slides.RgbColor{Red: r, Green: g, Blue: b}}}},
+				Outline:             &slides.Outline{PropertyState: "NOT_RENDERED"},
+			},
+			Fields: "shapeBackgroundFill.solidFill.color,outline.propertyState",
+		}},
 	}
-	return nil
 }
@@ -0,0 +1,397 @@
+package presentation
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/api/slides/v1"
+
+	"gogemini-practices/internal/charts"
+	"gogemini-practices/internal/fakeapi"
+)
+
+var (
+	_ SlidesAPI        = (*fakeapi.Slides)(nil)
+	_ charts.SheetsAPI = (*fakeapi.Sheets)(nil)
+)
+
+// twoPoints is a small timeseries dataset.
+func twoPoints() *ChartDataset {
+	ds := &ChartDataset{Title: "Users", Unit: "people", Type: "timeseries"}
+	for _, p := range []struct {
+		label string
+		value float64
+	}{{"2023", 10}, {"2024", 20}} {
+		ds.Points = append(ds.Points, struct {
+			Label  string
+			Value  float64
+			Values []float64
+		}{Label: p.label, Value: p.value})
+	}
+	return ds
+}
+
+// createdSlides is the object IDs of the slides the requests create, with the random suffix cut.
+func createdSlides(reqs []*slides.Request) []string {
+	var ids []string
+	for _, r := range reqs {
+		if r.CreateSlide != nil {
+			id := r.CreateSlide.ObjectId
+			ids = append(ids, id[:strings.LastIndex(id, "_")+1])
+		}
+	}
+	return ids
+}
+
+func TestWriteTopicsWithCharts(t *testing.T) {
+	tests := []struct {
+		name       string
+		topics     []RichTopic
+		sheetsErr  error
+		wantSlides []string
+		wantCharts int // CreateSheetsChart requests
+		wantErr    bool
+	}{
+		{
+			name:       "text only",
+			topics:     []RichTopic{{Title: "Intro", Summary: "• **One** point"}},
+			wantSlides: []string{"auto_slide_0_", "auto_summary_0_"},
+		},
+		{
+			name:       "dataset adds a chart slide",
+			topics:     []RichTopic{{Title: "Intro", Summary: "Hello"}, {Title: "Growth", Summary: "Users doubled", Dataset: twoPoints()}},
+			wantSlides: []string{"auto_slide_0_", "auto_summary_0_", "auto_slide_1_", "auto_summary_1_", "auto_chart_slide_1_"},
+			wantCharts: 1,
+		},
+		{
+			name:       "sheets failure without a fallback leaves out the chart",
+			topics:     []RichTopic{{Title: "Intro", Summary: "Hello"}, {Title: "Growth", Summary: "Users doubled", Dataset: twoPoints()}},
+			sheetsErr:  errors.New("quota exceeded"),
+			wantSlides: []string{"auto_slide_0_", "auto_summary_0_", "auto_slide_1_", "auto_summary_1_", "auto_chart_slide_1_"},
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slidesAPI := &fakeapi.Slides{Presentation: &slides.Presentation{Slides: []*slides.Page{{ObjectId: "user_slide"}}}}
+			sheetsAPI := &fakeapi.Sheets{Err: tt.sheetsErr}
+			err := WriteTopicsWithCharts(context.Background(), slidesAPI, sheetsAPI, "sheet-1", "deck-1", tt.topics)
+			if (err != nil) != tt.wantErr || (err != nil && !errors.Is(err, ErrChartFailed)) {
+				t.Fatalf("WriteTopicsWithCharts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			// The existing slides are deleted in a batch of their own first
+			if len(slidesAPI.Batches) == 0 || len(slidesAPI.Batches[0]) != 1 || slidesAPI.Batches[0][0].DeleteObject == nil || slidesAPI.Batches[0][0].DeleteObject.ObjectId != "user_slide" {
+				t.Fatalf("first batch = %+v, want a delete of user_slide", slidesAPI.Batches)
+			}
+			if len(slidesAPI.Batches) != 2 {
+				t.Fatalf("got %d batches, want 2", len(slidesAPI.Batches))
+			}
+			reqs := slidesAPI.Batches[1]
+			if got := createdSlides(reqs); strings.Join(got, ",") != strings.Join(tt.wantSlides, ",") {
+				t.Errorf("created slides = %v, want %v", got, tt.wantSlides)
+			}
+			var embeds []*slides.CreateSheetsChartRequest
+			for _, r := range reqs {
+				if r.CreateSheetsChart != nil {
+					embeds = append(embeds, r.CreateSheetsChart)
+				}
+			}
+			if len(embeds) != tt.wantCharts {
+				t.Fatalf("got %d embedded charts, want %d", len(embeds), tt.wantCharts)
+			}
+			for i, e := range embeds {
+				if e.SpreadsheetId != "sheet-1" || e.ChartId != int64(i+1) {
+					t.Errorf("chart %d embeds %s/%d, want sheet-1/%d", i, e.SpreadsheetId, e.ChartId, i+1)
+				}
+			}
+			if len(sheetsAPI.Values) != tt.wantCharts {
+				t.Errorf("wrote %d data tables, want %d", len(sheetsAPI.Values), tt.wantCharts)
+			}
+			if got := slidesAPI.Presentation.Slides; len(got) != len(tt.wantSlides) {
+				t.Errorf("deck has %d slides, want %d", len(got), len(tt.wantSlides))
+			}
+		})
+	}
+}
+
+func TestWriteDeck_UnloadedChart(t *testing.T) {
+	defer func(d time.Duration) { chartVerifyDelay = d }(chartVerifyDelay)
+	chartVerifyDelay = 0
+	topics := []RichTopic{{Title: "Growth", Summary: "Users doubled", Dataset: twoPoints()}}
+	for _, unloaded := range []bool{false, true} {
+		slidesAPI := &fakeapi.Slides{UnloadedCharts: unloaded}
+		err := WriteDeck(context.Background(), slidesAPI, &fakeapi.Sheets{}, "sheet-1", "deck-1", topics, DeckOptions{})
+		if !unloaded {
+			if err != nil {
+				t.Fatalf("WriteDeck() error = %v", err)
+			}
+			continue
+		}
+		if !errors.Is(err, ErrChartNotLoaded) || !strings.Contains(err.Error(), "sheet-1") {
+			t.Fatalf("WriteDeck() error = %v, want ErrChartNotLoaded naming the spreadsheet", err)
+		}
+		if len(slidesAPI.Batches) != 1 {
+			t.Errorf("got %d batches, want the deck written before the check", len(slidesAPI.Batches))
+		}
+	}
+}
+
+func TestWriteDeck_UnlinkedCharts(t *testing.T) {
+	topics := []RichTopic{{Title: "Growth", Summary: "Users doubled", Dataset: twoPoints()}}
+	slidesAPI := &fakeapi.Slides{UnloadedCharts: true}
+	if err := WriteDeck(context.Background(), slidesAPI, &fakeapi.Sheets{}, "sheet-1", "deck-1", topics, DeckOptions{UnlinkedCharts: true}); err != nil {
+		t.Fatalf("WriteDeck() error = %v, want unlinked charts left unverified", err)
+	}
+	embedded := 0
+	for _, r := range slidesAPI.Batches[0] {
+		if c := r.CreateSheetsChart; c != nil {
+			embedded++
+			if c.LinkingMode != "NOT_LINKED_IMAGE" {
+				t.Errorf("chart %s linking mode = %q, want NOT_LINKED_IMAGE", c.ObjectId, c.LinkingMode)
+			}
+		}
+	}
+	if embedded != 1 {
+		t.Errorf("embedded %d charts, want 1", embedded)
+	}
+}
+
+func TestWriteDeck_EstimateBadge(t *testing.T) {
+	estimate := twoPoints()
+	estimate.Estimate = true
+	topics := []RichTopic{{Title: "Growth", Summary: "Users doubled", Dataset: estimate}, {Title: "Churn", Summary: "Churn fell", Dataset: twoPoints()}}
+	slidesAPI := &fakeapi.Slides{}
+	if err := WriteDeck(context.Background(), slidesAPI, &fakeapi.Sheets{}, "sheet-1", "deck-1", topics, DeckOptions{RunID: "run1"}); err != nil {
+		t.Fatalf("WriteDeck() error = %v", err)
+	}
+	var order []string // created charts and badges
+	for _, r := range slidesAPI.Requests() {
+		switch {
+		case r.CreateSheetsChart != nil:
+			order = append(order, r.CreateSheetsChart.ObjectId)
+		case r.CreateShape != nil && strings.Contains(r.CreateShape.ObjectId, "estimate_badge"):
+			order = append(order, r.CreateShape.ObjectId)
+		}
+	}
+	want := []string{"auto_chart_0_run1", "auto_estimate_badge_0_run1", "auto_chart_1_run1"}
+	if !slices.Equal(order, want) {
+		t.Errorf("charts and badges = %v, want %v: one badge, created over its chart", order, want)
+	}
+}
+
+func TestWriteDeck_Risks(t *testing.T) {
+	topics := []RichTopic{{Title: "Pricing", Summary: "Raise prices 5%"}}
+	slidesAPI := &fakeapi.Slides{}
+	opts := DeckOptions{RunID: "run1", Risks: []string{"Churn may rise", "  ", "Competitors may undercut"}, Quote: &Quote{Text: "Price is what you pay."}}
+	if err := WriteDeck(context.Background(), slidesAPI, &fakeapi.Sheets{}, "sheet-1", "deck-1", topics, opts); err != nil {
+		t.Fatalf("WriteDeck() error = %v", err)
+	}
+	var slideIDs []string
+	var body string
+	for _, r := range slidesAPI.Requests() {
+		if r.CreateSlide != nil {
+			slideIDs = append(slideIDs, r.CreateSlide.ObjectId)
+		}
+		if r.InsertText != nil && r.InsertText.ObjectId == "auto_risks_body_run1" {
+			body = r.InsertText.Text
+		}
+	}
+	if n := len(slideIDs); n < 2 || slideIDs[n-2] != "auto_risks_slide_run1" || slideIDs[n-1] != "auto_quote_slide_run1" {
+		t.Errorf("slides = %v, want the risks slide just before the quote", slideIDs)
+	}
+	if body != "Churn may rise\nCompetitors may undercut" {
+		t.Errorf("risks body = %q, want the non-blank risks one per line", body)
+	}
+}
+
+func TestWriteDeck_QA(t *testing.T) {
+	topics := []RichTopic{{Title: "Pricing", Summary: "Raise prices 5%"}}
+	slidesAPI := &fakeapi.Slides{}
+	opts := DeckOptions{
+		RunID: "run1",
+		Quote: &Quote{Text: "Price is what you pay."},
+		QA: []QA{
+			{Question: "Why 5%?", Answer: "It matches inflation."},
+			{Question: " ", Answer: "dropped"},
+			{Question: "What about churn?\n"},
+		},
+	}
+	if err := WriteDeck(context.Background(), slidesAPI, &fakeapi.Sheets{}, "sheet-1", "deck-1", topics, opts); err != nil {
+		t.Fatalf("WriteDeck() error = %v", err)
+	}
+	var slideIDs []string
+	var skipped bool
+	var body, notes string
+	for _, r := range slidesAPI.Requests() {
+		switch {
+		case r.CreateSlide != nil:
+			slideIDs = append(slideIDs, r.CreateSlide.ObjectId)
+		case r.UpdateSlideProperties != nil:
+			skipped = r.UpdateSlideProperties.ObjectId == "auto_qa_slide_run1" && r.UpdateSlideProperties.SlideProperties.IsSkipped
+		case r.InsertText != nil && r.InsertText.ObjectId == "auto_qa_body_run1":
+			body = r.InsertText.Text
+		case r.InsertText != nil && r.InsertText.ObjectId == "auto_qa_slide_run1_notes":
+			notes = r.InsertText.Text
+		}
+	}
+	if n := len(slideIDs); n < 2 || slideIDs[n-2] != "auto_quote_slide_run1" || slideIDs[n-1] != "auto_qa_slide_run1" {
+		t.Errorf("slides = %v, want the Q&A slide last, after the quote", slideIDs)
+	}
+	if !skipped {
+		t.Error("want the Q&A slide skipped in presentation mode")
+	}
+	if body != "Why 5%?\nWhat about churn?" {
+		t.Errorf("Q&A body = %q, want the non-blank questions one per line", body)
+	}
+	if want := "1. Why 5%?\nAnswer: It matches inflation.\n\n2. What about churn?\nAnswer: (none suggested)"; notes != want {
+		t.Errorf("Q&A notes = %q, want %q", notes, want)
+	}
+}
+
+func TestWriteTopicsWithCharts_NilServices(t *testing.T) {
+	topics := []RichTopic{{Title: "Intro", Summary: "Hello"}}
+	if err := WriteTopicsWithCharts(context.Background(), nil, &fakeapi.Sheets{}, "sheet-1", "deck-1", topics); err == nil {
+		t.Error("nil slides API: want an error")
+	}
+	if err := WriteTopicsWithCharts(context.Background(), &fakeapi.Slides{}, nil, "sheet-1", "deck-1", topics); err == nil {
+		t.Error("nil sheets API with a spreadsheet ID: want an error")
+	}
+}
+
+func TestPlaceCharts_Fallback(t *testing.T) {
+	var pending []pendingChart
+	for i, topic := range []string{"A", "B", "C", "D"} {
+		pending = append(pending, pendingChart{
+			job:      charts.ChartJob{Tag: charts.ChartTag{Index: i + 1, Topic: topic}, Dataset: charts.DatasetSpec{Title: topic}},
+			slideID:  "slide_" + topic,
+			objectID: "chart_" + topic,
+		})
+	}
+	buildErr := errors.New("quota exceeded")
+	build := func(context.Context, charts.SheetsAPI, string, []charts.ChartJob) ([]int64, error) {
+		return nil, buildErr
+	}
+
+	tests := []struct {
+		name string
+		fail string // dataset title whose image fails
+	}{
+		{name: "images keep topic order"},
+		{name: "a failed image leaves out only its chart", fail: "C"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fallback := func(ctx context.Context, ds charts.DatasetSpec, cause error) (string, error) {
+				if !errors.Is(cause, buildErr) {
+					t.Errorf("fallback cause = %v, want the Sheets error", cause)
+				}
+				if ds.Title == tt.fail {
+					return "", errors.New("upload failed")
+				}
+				return "https://img/" + ds.Title, nil
+			}
+			reqs, failed := placeCharts(context.Background(), &fakeapi.Sheets{}, "sheet-1", pending, build, fallback, 2)
+			var placed []pendingChart
+			for i, pc := range pending {
+				err, ok := failed[i]
+				if pc.job.Dataset.Title != tt.fail {
+					if ok {
+						t.Errorf("topic %s failed: %v", pc.job.Tag.Topic, err)
+					}
+					placed = append(placed, pc)
+					continue
+				}
+				if !errors.Is(err, buildErr) || !strings.Contains(err.Error(), "upload failed") {
+					t.Errorf("topic %s error = %v, want the Sheets error with its image error", pc.job.Tag.Topic, err)
+				}
+			}
+			if len(reqs) != len(placed) {
+				t.Fatalf("got %d requests, want %d", len(reqs), len(placed))
+			}
+			for i, r := range reqs {
+				img := r.CreateImage
+				if img == nil || img.ObjectId != placed[i].objectID || img.Url != "https://img/"+placed[i].job.Dataset.Title {
+					t.Errorf("request %d = %+v, want %s's image", i, img, placed[i].objectID)
+				}
+			}
+		})
+	}
+}
+
+func TestPlaceCharts_NeedsImage(t *testing.T) {
+	growth := []charts.Point{{Label: "2020", Value: 5}, {Label: "2021", Value: 500}}
+	pending := []pendingChart{
+		{job: charts.ChartJob{Tag: charts.ChartTag{Topic: "Linear"}, Dataset: charts.DatasetSpec{Title: "Linear", Type: "timeseries", Points: growth}}, slideID: "slide_a", objectID: "chart_a"},
+		{job: charts.ChartJob{Tag: charts.ChartTag{Topic: "Log"}, Dataset: charts.DatasetSpec{Title: "Log", Type: "timeseries", LogScale: true, Points: growth}}, slideID: "slide_b", objectID: "chart_b"},
+	}
+	var built []string
+	build := func(_ context.Context, _ charts.SheetsAPI, _ string, jobs []charts.ChartJob) ([]int64, error) {
+		var ids []int64
+		for i, j := range jobs {
+			built = append(built, j.Dataset.Title)
+			ids = append(ids, int64(i+1))
+		}
+		return ids, nil
+	}
+	fallback := func(ctx context.Context, ds charts.DatasetSpec, cause error) (string, error) {
+		if cause != nil {
+			t.Errorf("fallback cause = %v, want none", cause)
+		}
+		return "https://img/" + ds.Title, nil
+	}
+	reqs, failed := placeCharts(context.Background(), &fakeapi.Sheets{}, "sheet-1", pending, build, fallback, 2)
+	if len(failed) > 0 {
+		t.Fatalf("placeCharts failed charts: %v", failed)
+	}
+	if len(built) != 1 || built[0] != "Linear" {
+		t.Errorf("built %v, want only the linear chart in Sheets", built)
+	}
+	var images []string
+	for _, r := range reqs {
+		if r.CreateImage != nil {
+			images = append(images, r.CreateImage.ObjectId)
+		}
+	}
+	if len(images) != 1 || images[0] != "chart_b" {
+		t.Errorf("images = %v, want the log chart as an image", images)
+	}
+}
+
+func TestWriteDeck_TextBoxFit(t *testing.T) {
+	topics := []RichTopic{{Title: "Pricing", Summary: "Raise prices 5%\n```\nprice *= 1.05\n```"}}
+	slidesAPI := &fakeapi.Slides{}
+	if err := WriteDeck(context.Background(), slidesAPI, &fakeapi.Sheets{}, "sheet-1", "deck-1", topics, DeckOptions{RunID: "run1", Footer: "As of today"}); err != nil {
+		t.Fatalf("WriteDeck() error = %v", err)
+	}
+	created := map[string]bool{}
+	fit := map[string]string{}
+	for _, r := range slidesAPI.Requests() {
+		if c := r.CreateShape; c != nil && c.ShapeType == "TEXT_BOX" {
+			created[c.ObjectId] = true
+		}
+		if u := r.UpdateShapeProperties; u != nil && u.ShapeProperties.Autofit != nil {
+			if !created[u.ObjectId] {
+				t.Errorf("fit for %s before the box is created", u.ObjectId)
+			}
+			if u.ShapeProperties.Autofit.AutofitType != "NONE" {
+				t.Errorf("%s autofit = %q, want NONE", u.ObjectId, u.ShapeProperties.Autofit.AutofitType)
+			}
+			fit[u.ObjectId] = u.ShapeProperties.ContentAlignment
+		}
+	}
+	for id := range created {
+		if fit[id] == "" {
+			t.Errorf("text box %s has no content alignment", id)
+		}
+	}
+	for id, want := range map[string]string{"auto_title_0_run1": "MIDDLE", "auto_summary_body_0_run1": "TOP", "auto_code_0_run1": "TOP", "auto_footer_slide_0_run1": "MIDDLE"} {
+		if fit[id] != want {
+			t.Errorf("%s content alignment = %q, want %q", id, fit[id], want)
+		}
+	}
+}
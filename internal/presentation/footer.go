@@ -0,0 +1,42 @@
+package presentation
+
+import (
+	"strings"
+
+	"google.golang.org/api/slides/v1"
+)
+
+// footerPrefix starts the object ID of every footer, followed by its slide's ID without
+// the "auto_" prefix.
+const footerPrefix = "auto_footer_"
+
+// footerRect is the footer's text box in the bottom-left corner of the page, in points:
+// 320x18 at 20pt from the left and 25pt from the bottom of the default page, stretched
+// with the page like scaleGeometry stretches the rest of the slide.
+func footerRect(l Layout) rect {
+	sx, sy := l.PageWidth/slideWidth, l.PageHeight/slideHeight
+	return rect{X: 20 * sx, Y: l.PageHeight - 25*sy, W: 320 * sx, H: 18 * sy}
+}
+
+// footerRequests adds a small gray footer line in the bottom-left corner of every slide the
+// requests create.
+func footerRequests(requests []*slides.Request, text string, l Layout) []*slides.Request {
+	footer := footerRect(l)
+	gray := &slides.OpaqueColor{RgbColor: &slides.RgbColor{Red: 0.4, Green: 0.4, Blue: 0.4}}
+	var reqs []*slides.Request
+	for _, r := range requests {
+		if r.CreateSlide == nil {
+			continue
+		}
+		slideID := r.CreateSlide.ObjectId
+		objectID := footerPrefix + strings.TrimPrefix(slideID, "auto_")
+		reqs = append(reqs, textBoxRequests(objectID, slideID, text, footer.X, footer.Y, footer.W, footer.H, 9, false, gray)...)
+		reqs = append(reqs, &slides.Request{UpdateParagraphStyle: &slides.UpdateParagraphStyleRequest{
+			ObjectId:  objectID,
+			Style:     &slides.ParagraphStyle{Alignment: "START"},
+			Fields:    "alignment",
+			TextRange: &slides.Range{Type: "ALL"},
+		}})
+	}
+	return reqs
+}
@@ -0,0 +1,91 @@
+package presentation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gogemini-practices/internal/fakeapi"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden request files in testdata/golden")
+
+// TestWriteDeck_Golden writes each plan in testdata/plans through the fakes and compares
+// every Slides and Sheets request sent with testdata/golden. After an intended layout
+// change, run go test ./internal/presentation -run Golden -update and review the diff.
+func TestWriteDeck_Golden(t *testing.T) {
+	plans, err := filepath.Glob(filepath.Join("testdata", "plans", "*.json"))
+	if err != nil || len(plans) == 0 {
+		t.Fatalf("no fixture plans: %v", err)
+	}
+	for _, path := range plans {
+		name := strings.TrimSuffix(filepath.Base(path), ".json")
+		t.Run(name, func(t *testing.T) {
+			b, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var plan struct {
+				Options DeckOptions
+				Topics  []RichTopic
+			}
+			if err := json.Unmarshal(b, &plan); err != nil {
+				t.Fatalf("parse %s: %v", path, err)
+			}
+			plan.Options.RunID = "golden"
+
+			slidesAPI, sheetsAPI := &fakeapi.Slides{}, &fakeapi.Sheets{}
+			if err := WriteDeck(context.Background(), slidesAPI, sheetsAPI, "sheet-1", "deck-1", plan.Topics, plan.Options); err != nil {
+				t.Fatalf("WriteDeck() error = %v", err)
+			}
+			got, err := json.MarshalIndent(map[string]any{
+				"slides":        slidesAPI.Batches,
+				"sheets":        sheetsAPI.Batches,
+				"sheets_values": sheetsAPI.Values,
+			}, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, '\n')
+
+			golden := filepath.Join("testdata", "golden", name+".json")
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v (run with -update to create it)", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("requests differ from %s; run with -update and review the diff if the change is intended\n%s", golden, firstDiff(got, want))
+			}
+		})
+	}
+}
+
+// firstDiff shows the first differing line of two golden outputs.
+func firstDiff(got, want []byte) string {
+	g, w := strings.Split(string(got), "\n"), strings.Split(string(want), "\n")
+	for i := 0; i < len(g) || i < len(w); i++ {
+		var gl, wl string
+		if i < len(g) {
+			gl = g[i]
+		}
+		if i < len(w) {
+			wl = w[i]
+		}
+		if gl != wl {
+			return fmt.Sprintf("line %d:\n got: %s\nwant: %s", i+1, gl, wl)
+		}
+	}
+	return ""
+}
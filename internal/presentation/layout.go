@@ -0,0 +1,274 @@
+package presentation
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"google.golang.org/api/slides/v1"
+)
+
+// Layout is where WriteDeck puts the main boxes of topic slides, in points on a page of
+// PageWidth x PageHeight. What hangs off them follows: the accent divider and the icon sit
+// under and beside Title; code, tables, and flow diagrams take the lower part of Body, which
+// narrows for an inline chart; and the footer and the agenda back link keep to the page's
+// corners. Deck-level slides (agenda, risks, quote, Q&A) keep their own layout. A deck
+// whose page isn't PageWidth x PageHeight gets the whole layout stretched to fit it.
+type Layout struct {
+	PageWidth  float64
+	PageHeight float64
+	Title      Box // the title slide's title
+	Body       Box // the summary text box
+	// Image is the title slide's image at ImageLeft; ImageRight mirrors it within the
+	// title box's width and ImageCenter centers it on the page.
+	Image       Box
+	Chart       Box // the chart on a chart slide
+	InlineChart Box // the chart beside the summary with DeckOptions.InlineSmallCharts
+	// Padding is the space between the title and summary boxes, and the body text of
+	// deck-level slides, and their text.
+	Padding float64
+}
+
+// Box is a rectangle on the page, in points from its top-left corner.
+type Box struct {
+	X, Y, W, H float64
+}
+
+func (b Box) rect() rect { return rect(b) }
+
+// frame is the box as a chart frame, in EMU.
+func (b Box) frame() chartFrame {
+	return chartFrame{X: b.X * emuPerPt, Y: b.Y * emuPerPt, W: b.W * emuPerPt, H: b.H * emuPerPt}
+}
+
+// DefaultLayout is the layout of a 16:9 deck of the default 720 x 405 pt page.
+func DefaultLayout() Layout {
+	return Layout{
+		PageWidth:   slideWidth,
+		PageHeight:  slideHeight,
+		Title:       Box{X: 50, Y: 50, W: 600, H: 60},
+		Body:        Box{X: 50, Y: 130, W: 600, H: 300},
+		Image:       Box{X: 50, Y: 130, W: 400, H: 300},
+		Chart:       Box{X: 100000 / emuPerPt, Y: 160000 / emuPerPt, W: 4000000 / emuPerPt, H: 3000000 / emuPerPt},
+		InlineChart: Box{X: 430, Y: 130, W: 260, H: 195},
+		Padding:     6,
+	}
+}
+
+// layoutFile is the JSON form of a Layout. Every field is optional.
+type layoutFile struct {
+	Page *struct {
+		Width  float64 `json:"width"`
+		Height float64 `json:"height"`
+	} `json:"page"`
+	Title       *boxFile `json:"title"`
+	Body        *boxFile `json:"body"`
+	Image       *boxFile `json:"image"`
+	Chart       *boxFile `json:"chart"`
+	InlineChart *boxFile `json:"inline_chart"`
+	Padding     *float64 `json:"padding"`
+}
+
+// boxFile is a box placed by a named anchor: the point of the page that x and y are
+// measured from, inward, to the same point of the box.
+type boxFile struct {
+	Anchor string  `json:"anchor"`
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// ParseLayout reads a layout file: JSON with an optional "page" size and any of the boxes
+// "title", "body", "image", "chart", and "inline_chart", each {"anchor", "x", "y",
+// "width", "height"} in points. The anchor is top-left (the default), top, top-right,
+// left, center, right, bottom-left, bottom, or bottom-right; {"anchor": "bottom-right",
+// "x": 20, "y": 20} puts a box 20pt in from the bottom and right edges, and "top" with x 0
+// centers it across the page. Boxes left out keep DefaultLayout's; every box given must
+// fit on the page. "padding" replaces the default text padding.
+func ParseLayout(data []byte) (*Layout, error) {
+	var f layoutFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("invalid layout JSON: %w", err)
+	}
+	l := DefaultLayout()
+	if f.Page != nil {
+		if f.Page.Width <= 0 || f.Page.Height <= 0 {
+			return nil, fmt.Errorf("page size must be positive, got %gx%g", f.Page.Width, f.Page.Height)
+		}
+		l.PageWidth, l.PageHeight = f.Page.Width, f.Page.Height
+	}
+	for _, b := range []struct {
+		name string
+		file *boxFile
+		box  *Box
+	}{
+		{"title", f.Title, &l.Title},
+		{"body", f.Body, &l.Body},
+		{"image", f.Image, &l.Image},
+		{"chart", f.Chart, &l.Chart},
+		{"inline_chart", f.InlineChart, &l.InlineChart},
+	} {
+		if b.file != nil {
+			box, err := b.file.resolve(l.PageWidth, l.PageHeight)
+			if err != nil {
+				return nil, fmt.Errorf("%s box: %w", b.name, err)
+			}
+			if err := box.check(l.PageWidth, l.PageHeight); err != nil {
+				return nil, fmt.Errorf("%s box: %w", b.name, err)
+			}
+			*b.box = box
+		}
+	}
+	if f.Padding != nil {
+		if *f.Padding < 0 {
+			return nil, fmt.Errorf("padding must not be negative, got %g", *f.Padding)
+		}
+		l.Padding = *f.Padding
+	}
+	for _, b := range []struct {
+		name string
+		box  Box
+	}{{"title", l.Title}, {"body", l.Body}} {
+		if 2*l.Padding >= min(b.box.W, b.box.H) {
+			return nil, fmt.Errorf("padding %g leaves no room in the %gx%g %s box", l.Padding, b.box.W, b.box.H, b.name)
+		}
+	}
+	return &l, nil
+}
+
+// resolve turns the anchored box into page coordinates.
+func (f boxFile) resolve(pageW, pageH float64) (Box, error) {
+	if f.Width <= 0 || f.Height <= 0 {
+		return Box{}, fmt.Errorf("width and height must be positive, got %gx%g", f.Width, f.Height)
+	}
+	b := Box{X: f.X, Y: f.Y, W: f.Width, H: f.Height}
+	vertical, horizontal, ok := parseAnchor(f.Anchor)
+	if !ok {
+		return Box{}, fmt.Errorf("unknown anchor %q (want top-left|top|top-right|left|center|right|bottom-left|bottom|bottom-right)", f.Anchor)
+	}
+	switch horizontal {
+	case "center":
+		b.X = (pageW-b.W)/2 + f.X
+	case "right":
+		b.X = pageW - b.W - f.X
+	}
+	switch vertical {
+	case "center":
+		b.Y = (pageH-b.H)/2 + f.Y
+	case "bottom":
+		b.Y = pageH - b.H - f.Y
+	}
+	return b, nil
+}
+
+// parseAnchor splits an anchor name into its vertical (top|center|bottom) and horizontal
+// (left|center|right) parts.
+func parseAnchor(s string) (vertical, horizontal string, ok bool) {
+	switch s = strings.ToLower(strings.TrimSpace(s)); s {
+	case "", "top-left":
+		return "top", "left", true
+	case "top", "bottom":
+		return s, "center", true
+	case "left", "right":
+		return "center", s, true
+	case "center":
+		return "center", "center", true
+	}
+	v, h, found := strings.Cut(s, "-")
+	if !found || (v != "top" && v != "bottom") || (h != "left" && h != "right") {
+		return "", "", false
+	}
+	return v, h, true
+}
+
+// check reports a box that doesn't fit on the page.
+func (b Box) check(pageW, pageH float64) error {
+	const eps = 1e-6
+	if b.X < -eps || b.Y < -eps || b.X+b.W > pageW+eps || b.Y+b.H > pageH+eps {
+		return fmt.Errorf("%gx%g at (%g, %g) doesn't fit the %gx%gpt page", b.W, b.H, b.X, b.Y, pageW, pageH)
+	}
+	return nil
+}
+
+// pageScale is how much wider and taller pres's page is than l's: 1, 1 for a presentation
+// without a page size.
+func (l Layout) pageScale(pres *slides.Presentation) (sx, sy float64) {
+	if pres == nil || pres.PageSize == nil {
+		return 1, 1
+	}
+	w, h := dimensionPt(pres.PageSize.Width), dimensionPt(pres.PageSize.Height)
+	if w <= 0 || h <= 0 {
+		return 1, 1
+	}
+	return w / l.PageWidth, h / l.PageHeight
+}
+
+// dimensionPt is d in points; Slides reports sizes in EMU or PT.
+func dimensionPt(d *slides.Dimension) float64 {
+	if d == nil {
+		return 0
+	}
+	if d.Unit == "EMU" {
+		return d.Magnitude / emuPerPt
+	}
+	return d.Magnitude
+}
+
+// scaleToPage fits requests laid out on l's page to pres's page (see scaleGeometry).
+func scaleToPage(requests []*slides.Request, l Layout, pres *slides.Presentation) {
+	sx, sy := l.pageScale(pres)
+	scaleGeometry(requests, sx, sy)
+}
+
+// scaleGeometry stretches every element requests create, across by sx and down by sy, so a
+// deck laid out for one page size fills another: a 16:9 layout on a 4:3 page keeps its
+// widths and grows a third taller. Positions and sizes scale in whatever unit they're in,
+// EMU chart frames included; text sizes, line weights, and indents don't. Slides fits
+// images and charts into their stretched frames without distorting them. Properties are
+// copied before they change, so requests sharing them aren't scaled twice.
+func scaleGeometry(requests []*slides.Request, sx, sy float64) {
+	const eps = 1e-9
+	if math.Abs(sx-1) < eps && math.Abs(sy-1) < eps {
+		return
+	}
+	for _, r := range requests {
+		var props **slides.PageElementProperties
+		switch {
+		case r.CreateShape != nil:
+			props = &r.CreateShape.ElementProperties
+		case r.CreateImage != nil:
+			props = &r.CreateImage.ElementProperties
+		case r.CreateSheetsChart != nil:
+			props = &r.CreateSheetsChart.ElementProperties
+		case r.CreateLine != nil:
+			props = &r.CreateLine.ElementProperties
+		case r.CreateTable != nil:
+			props = &r.CreateTable.ElementProperties
+		}
+		if props == nil || *props == nil {
+			continue
+		}
+		p := **props
+		if p.Size != nil {
+			p.Size = &slides.Size{Width: scaleDimension(p.Size.Width, sx), Height: scaleDimension(p.Size.Height, sy)}
+		}
+		if p.Transform != nil {
+			t := *p.Transform
+			t.TranslateX *= sx
+			t.TranslateY *= sy
+			p.Transform = &t
+		}
+		*props = &p
+	}
+}
+
+func scaleDimension(d *slides.Dimension, by float64) *slides.Dimension {
+	if d == nil {
+		return nil
+	}
+	scaled := *d
+	scaled.Magnitude *= by
+	return &scaled
+}
@@ -0,0 +1,170 @@
+package presentation
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/slides/v1"
+
+	"gogemini-practices/internal/fakeapi"
+)
+
+func TestParseLayout(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		check   func(Layout) bool
+		wantErr string
+	}{
+		{name: "empty keeps defaults", json: `{}`, check: func(l Layout) bool { return l == DefaultLayout() }},
+		{
+			name: "top-left box",
+			json: `{"title": {"x": 30, "y": 20, "width": 500, "height": 50}}`,
+			check: func(l Layout) bool {
+				return l.Title == Box{X: 30, Y: 20, W: 500, H: 50} && l.Body == DefaultLayout().Body
+			},
+		},
+		{
+			name:  "bottom-right anchor",
+			json:  `{"chart": {"anchor": "bottom-right", "x": 20, "y": 10, "width": 300, "height": 200}}`,
+			check: func(l Layout) bool { return l.Chart == Box{X: 400, Y: 195, W: 300, H: 200} },
+		},
+		{
+			name:  "top centers across the page",
+			json:  `{"title": {"anchor": "Top", "y": 40, "width": 600, "height": 60}}`,
+			check: func(l Layout) bool { return l.Title == Box{X: 60, Y: 40, W: 600, H: 60} },
+		},
+		{
+			name:  "center on a 4:3 page",
+			json:  `{"page": {"width": 720, "height": 540}, "image": {"anchor": "center", "width": 400, "height": 300}}`,
+			check: func(l Layout) bool { return l.PageHeight == 540 && l.Image == Box{X: 160, Y: 120, W: 400, H: 300} },
+		},
+		{name: "padding", json: `{"padding": 0}`, check: func(l Layout) bool { return l.Padding == 0 && l.Title == DefaultLayout().Title }},
+		{name: "negative padding", json: `{"padding": -2}`, wantErr: "padding must not be negative"},
+		{name: "padding too large for a box", json: `{"title": {"width": 600, "height": 20}, "padding": 10}`, wantErr: "padding 10 leaves no room in the 600x20 title box"},
+		{name: "bad anchor", json: `{"body": {"anchor": "middle", "width": 10, "height": 10}}`, wantErr: `body box: unknown anchor "middle"`},
+		{name: "no size", json: `{"image": {"x": 10}}`, wantErr: "image box: width and height must be positive"},
+		{name: "off the page", json: `{"inline_chart": {"x": 500, "width": 260, "height": 100}}`, wantErr: "inline_chart box: 260x100 at (500, 0) doesn't fit"},
+		{name: "bad page", json: `{"page": {"width": 720}}`, wantErr: "page size must be positive"},
+		{name: "not JSON", json: `title: top`, wantErr: "invalid layout JSON"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := ParseLayout([]byte(tt.json))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseLayout() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseLayout() error = %v", err)
+			}
+			if !tt.check(*l) {
+				t.Errorf("ParseLayout() = %+v", *l)
+			}
+		})
+	}
+}
+
+func TestWriteDeck_Layout(t *testing.T) {
+	l, err := ParseLayout([]byte(`{"page": {"width": 720, "height": 540}, "title": {"anchor": "top", "y": 30, "width": 500, "height": 80}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	topics := []RichTopic{{Title: "Pricing", Summary: "Raise prices 5%"}}
+	slidesAPI := &fakeapi.Slides{}
+	if err := WriteDeck(context.Background(), slidesAPI, &fakeapi.Sheets{}, "sheet-1", "deck-1", topics, DeckOptions{RunID: "run1", Footer: "Acme", Layout: l}); err != nil {
+		t.Fatalf("WriteDeck() error = %v", err)
+	}
+	footers := 0
+	for _, r := range slidesAPI.Requests() {
+		c := r.CreateShape
+		if c == nil {
+			continue
+		}
+		tr := c.ElementProperties.Transform
+		switch {
+		case c.ObjectId == "auto_title_0_run1":
+			if tr.TranslateX != 116 || tr.TranslateY != 36 || c.ElementProperties.Size.Width.Magnitude != 488 {
+				t.Errorf("title at (%g, %g) width %g, want (116, 36) width 488: the box at (110, 30) width 500, padded", tr.TranslateX, tr.TranslateY, c.ElementProperties.Size.Width.Magnitude)
+			}
+		case strings.HasPrefix(c.ObjectId, footerPrefix):
+			footers++
+			// 25pt from the bottom and 18pt tall on a 405pt page, a third more on 540pt
+			if h := c.ElementProperties.Size.Height.Magnitude; math.Abs(tr.TranslateY-540*(1-25.0/405)) > 1e-9 || math.Abs(h-24) > 1e-9 || tr.TranslateX != 20 {
+				t.Errorf("footer %s at (%g, %g) height %g, want (20, 506.67) height 24 on a 540pt page", c.ObjectId, tr.TranslateX, tr.TranslateY, h)
+			}
+		}
+	}
+	if footers == 0 {
+		t.Error("no footers created")
+	}
+}
+
+func TestWriteDeck_PageSize(t *testing.T) {
+	fourThree := &slides.Size{Width: &slides.Dimension{Magnitude: 9144000, Unit: "EMU"}, Height: &slides.Dimension{Magnitude: 6858000, Unit: "EMU"}}
+	matching, err := ParseLayout([]byte(`{"page": {"width": 720, "height": 540}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		layout *Layout
+		sy     float64
+	}{
+		{name: "16:9 layout stretched to 4:3", sy: 540.0 / 405},
+		{name: "4:3 layout kept", layout: matching, sy: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			topics := []RichTopic{{Title: "Growth", Summary: "Users doubled", Dataset: twoPoints()}}
+			slidesAPI := &fakeapi.Slides{Presentation: &slides.Presentation{PresentationId: "deck-1", PageSize: fourThree}}
+			if err := WriteDeck(context.Background(), slidesAPI, &fakeapi.Sheets{}, "sheet-1", "deck-1", topics, DeckOptions{RunID: "run1", Layout: tt.layout}); err != nil {
+				t.Fatalf("WriteDeck() error = %v", err)
+			}
+			var title, chart *slides.PageElementProperties
+			for _, r := range slidesAPI.Requests() {
+				switch {
+				case r.CreateShape != nil && r.CreateShape.ObjectId == "auto_title_0_run1":
+					title = r.CreateShape.ElementProperties
+				case r.CreateSheetsChart != nil:
+					chart = r.CreateSheetsChart.ElementProperties
+				}
+			}
+			if title == nil || chart == nil {
+				t.Fatalf("title %v, chart %v: want both created", title, chart)
+			}
+			near := func(got, want float64) bool { return math.Abs(got-want) < 1e-6 }
+			// The padded title box: 588x48 at (56, 56) on the layout page
+			if !near(title.Transform.TranslateX, 56) || !near(title.Transform.TranslateY, 56*tt.sy) || !near(title.Size.Height.Magnitude, 48*tt.sy) || !near(title.Size.Width.Magnitude, 588) {
+				t.Errorf("title at (%g, %g) size %gx%g, want (56, %g) size 588x%g", title.Transform.TranslateX, title.Transform.TranslateY, title.Size.Width.Magnitude, title.Size.Height.Magnitude, 56*tt.sy, 48*tt.sy)
+			}
+			if chart.Transform.Unit != "EMU" || !near(chart.Transform.TranslateY, 160000*tt.sy) || !near(chart.Size.Height.Magnitude, 3000000*tt.sy) {
+				t.Errorf("chart at y %g %s height %g, want y %g EMU height %g", chart.Transform.TranslateY, chart.Transform.Unit, chart.Size.Height.Magnitude, 160000*tt.sy, 3000000*tt.sy)
+			}
+		})
+	}
+}
+
+func TestScaleGeometry_SharedProperties(t *testing.T) {
+	props := &slides.PageElementProperties{
+		Size:      &slides.Size{Width: &slides.Dimension{Magnitude: 100, Unit: "PT"}, Height: &slides.Dimension{Magnitude: 50, Unit: "PT"}},
+		Transform: &slides.AffineTransform{ScaleX: 1, ScaleY: 1, TranslateX: 10, TranslateY: 20, Unit: "PT"},
+	}
+	requests := []*slides.Request{
+		{CreateShape: &slides.CreateShapeRequest{ObjectId: "a", ElementProperties: props}},
+		{CreateImage: &slides.CreateImageRequest{ObjectId: "b", ElementProperties: props}},
+	}
+	scaleGeometry(requests, 2, 3)
+	for _, got := range []*slides.PageElementProperties{requests[0].CreateShape.ElementProperties, requests[1].CreateImage.ElementProperties} {
+		if got.Size.Width.Magnitude != 200 || got.Size.Height.Magnitude != 150 || got.Transform.TranslateX != 20 || got.Transform.TranslateY != 60 {
+			t.Errorf("scaled to %gx%g at (%g, %g), want 200x150 at (20, 60) once", got.Size.Width.Magnitude, got.Size.Height.Magnitude, got.Transform.TranslateX, got.Transform.TranslateY)
+		}
+	}
+	if props.Size.Width.Magnitude != 100 || props.Transform.TranslateY != 20 {
+		t.Error("shared properties modified in place")
+	}
+}
@@ -0,0 +1,77 @@
+package presentation
+
+import (
+	"strconv"
+	"strings"
+
+	"google.golang.org/api/slides/v1"
+)
+
+// Manifest lists the objects a run created in a deck by topic and role, so later runs and
+// tools can find, diff, or remove a topic's slides and elements without guessing from
+// their position. Pass one in DeckOptions.Manifest to have it filled.
+type Manifest struct {
+	PresentationID string           `json:"presentation_id"`
+	RunID          string           `json:"run_id"`
+	Objects        []ManifestObject `json:"objects"`
+}
+
+// ManifestObject is one slide or page element a run created.
+type ManifestObject struct {
+	ID    string `json:"id"`
+	Role  string `json:"role"`            // e.g. "slide", "summary_body", "chart", "flow_step_1"
+	Topic int    `json:"topic,omitempty"` // 1-based; 0 for deck-level objects like the agenda
+}
+
+// buildManifest lists the objects requests create, reading each one's topic and role back
+// from its ID (see deckWriter.id). Objects with IDs of another scheme are left out.
+func buildManifest(presentationID, runID string, requests []*slides.Request) Manifest {
+	m := Manifest{PresentationID: presentationID, RunID: runID}
+	for _, id := range createdIDs(requests) {
+		if obj, ok := parseObjectID(id, runID); ok {
+			m.Objects = append(m.Objects, obj)
+		}
+	}
+	return m
+}
+
+// createdIDs returns the object IDs requests create, in order.
+func createdIDs(requests []*slides.Request) []string {
+	var ids []string
+	for _, r := range requests {
+		switch {
+		case r.CreateSlide != nil:
+			ids = append(ids, r.CreateSlide.ObjectId)
+		case r.CreateShape != nil:
+			ids = append(ids, r.CreateShape.ObjectId)
+		case r.CreateImage != nil:
+			ids = append(ids, r.CreateImage.ObjectId)
+		case r.CreateLine != nil:
+			ids = append(ids, r.CreateLine.ObjectId)
+		case r.CreateTable != nil:
+			ids = append(ids, r.CreateTable.ObjectId)
+		case r.CreateSheetsChart != nil:
+			ids = append(ids, r.CreateSheetsChart.ObjectId)
+		case r.GroupObjects != nil:
+			ids = append(ids, r.GroupObjects.GroupObjectId)
+		}
+	}
+	return ids
+}
+
+// parseObjectID reads a generated ID of run runID: auto_<role>_<topic>_<run ID>, with any
+// part after the run ID (a flow's "_step_1") added to the role, or auto_<role>_<run ID>.
+func parseObjectID(id, runID string) (ManifestObject, bool) {
+	head, tail, ok := strings.Cut(strings.TrimPrefix(id, "auto_"), "_"+runID)
+	if !ok || !strings.HasPrefix(id, "auto_") || (tail != "" && tail[0] != '_') {
+		return ManifestObject{}, false
+	}
+	obj := ManifestObject{ID: id, Role: head}
+	if i := strings.LastIndex(head, "_"); i >= 0 {
+		if n, err := strconv.Atoi(head[i+1:]); err == nil {
+			obj.Role, obj.Topic = head[:i], n+1
+		}
+	}
+	obj.Role += tail
+	return obj, obj.Role != ""
+}
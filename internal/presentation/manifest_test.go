@@ -0,0 +1,71 @@
+package presentation
+
+import (
+	"context"
+	"testing"
+
+	"gogemini-practices/internal/fakeapi"
+)
+
+func TestParseObjectID(t *testing.T) {
+	tests := []struct {
+		id     string
+		want   ManifestObject
+		wantOK bool
+	}{
+		{id: "auto_slide_0_run1", want: ManifestObject{ID: "auto_slide_0_run1", Role: "slide", Topic: 1}, wantOK: true},
+		{id: "auto_summary_body_2_run1", want: ManifestObject{ID: "auto_summary_body_2_run1", Role: "summary_body", Topic: 3}, wantOK: true},
+		{id: "auto_flow_1_run1_step_0", want: ManifestObject{ID: "auto_flow_1_run1_step_0", Role: "flow_step_0", Topic: 2}, wantOK: true},
+		{id: "auto_agenda_slide_run1", want: ManifestObject{ID: "auto_agenda_slide_run1", Role: "agenda_slide"}, wantOK: true},
+		{id: "auto_footer_chart_slide_0_run1", want: ManifestObject{ID: "auto_footer_chart_slide_0_run1", Role: "footer_chart_slide", Topic: 1}, wantOK: true},
+		{id: "auto_slide_0_run2", wantOK: false},
+		{id: "auto_slide_0_run12", wantOK: false},
+		{id: "g12345_run1", wantOK: false},
+	}
+	for _, tt := range tests {
+		got, ok := parseObjectID(tt.id, "run1")
+		if ok != tt.wantOK || (ok && got != tt.want) {
+			t.Errorf("parseObjectID(%q) = %+v, %v; want %+v, %v", tt.id, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestWriteDeck_Manifest(t *testing.T) {
+	topics := []RichTopic{
+		{Title: "Intro", Summary: "Hello"},
+		{Title: "Growth", Summary: "Users doubled", Dataset: twoPoints()},
+	}
+	write := func() Manifest {
+		var m Manifest
+		if err := WriteDeck(context.Background(), &fakeapi.Slides{}, &fakeapi.Sheets{}, "sheet-1", "deck-1", topics, DeckOptions{RunID: "r1", Agenda: true, Manifest: &m}); err != nil {
+			t.Fatalf("WriteDeck() error = %v", err)
+		}
+		return m
+	}
+	m := write()
+	if m.PresentationID != "deck-1" || m.RunID != "r1" {
+		t.Errorf("manifest = %s/%s, want deck-1/r1", m.PresentationID, m.RunID)
+	}
+	roles := map[string]int{}
+	for _, o := range m.Objects {
+		roles[o.Role]++
+		if o.Role == "chart" && (o.Topic != 2 || o.ID != "auto_chart_1_r1") {
+			t.Errorf("chart = %+v, want auto_chart_1_r1 of topic 2", o)
+		}
+	}
+	for role, want := range map[string]int{"agenda_slide": 1, "slide": 2, "summary": 2, "chart_slide": 1, "chart": 1} {
+		if roles[role] != want {
+			t.Errorf("%d %q objects, want %d (all: %v)", roles[role], role, want, roles)
+		}
+	}
+
+	again := write()
+	if len(again.Objects) != len(m.Objects) {
+		t.Fatalf("rebuild listed %d objects, want %d", len(again.Objects), len(m.Objects))
+	}
+	for i := range m.Objects {
+		if again.Objects[i] != m.Objects[i] {
+			t.Errorf("rebuild object %d = %+v, want the same ID as %+v", i, again.Objects[i], m.Objects[i])
+		}
+	}
+}
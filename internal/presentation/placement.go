@@ -0,0 +1,191 @@
+package presentation
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Title-slide image positions for DeckOptions.ImagePosition.
+const (
+	ImageLeft   = "left"
+	ImageRight  = "right"
+	ImageCenter = "center"
+)
+
+// ParseImagePosition validates an image position flag value; empty means ImageLeft.
+func ParseImagePosition(s string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", ImageLeft:
+		return ImageLeft, nil
+	case ImageRight:
+		return ImageRight, nil
+	case ImageCenter:
+		return ImageCenter, nil
+	}
+	return "", fmt.Errorf("unknown image position %q (want left|right|center)", s)
+}
+
+// Slide geometry in points: the default 16:9 page and the margin nothing placed may cross.
+const (
+	slideWidth  = 720.0
+	slideHeight = 405.0
+	safeMargin  = 5.0
+	// placeGap is the space kept between a moved element and the one it avoids.
+	placeGap = 8.0
+	// maxShift is how far, in points, an element may move before it is shrunk instead.
+	maxShift = 80.0
+	// emuPerPt converts points to the EMU chart frames use.
+	emuPerPt = 12700.0
+)
+
+// rect is an element's bounds on a slide, in points.
+type rect struct {
+	X, Y, W, H float64
+}
+
+func (r rect) overlaps(o rect) bool {
+	return r.X < o.X+o.W && o.X < r.X+r.W && r.Y < o.Y+o.H && o.Y < r.Y+r.H
+}
+
+// clamp shifts r into area, shrinking it first (keeping its aspect ratio) when it is larger.
+func (r rect) clamp(area rect) rect {
+	if s := math.Min(area.W/r.W, area.H/r.H); s < 1 {
+		r.W, r.H = r.W*s, r.H*s
+	}
+	r.X = math.Max(area.X, math.Min(r.X, area.X+area.W-r.W))
+	r.Y = math.Max(area.Y, math.Min(r.Y, area.Y+area.H-r.H))
+	return r
+}
+
+// inset is r shrunk by d on every side.
+func (r rect) inset(d float64) rect {
+	return rect{X: r.X + d, Y: r.Y + d, W: r.W - 2*d, H: r.H - 2*d}
+}
+
+// placer tracks the bounds of what is already on each slide, so images and charts can be
+// moved or shrunk clear of titles, text, and footers instead of covering them.
+type placer struct {
+	placed map[string][]rect // by slide ID
+	width  float64           // of the page
+	safe   rect              // the part of the page inside the margin
+}
+
+func newPlacer(pageWidth, pageHeight float64) *placer {
+	return &placer{
+		placed: map[string][]rect{},
+		width:  pageWidth,
+		safe:   rect{X: safeMargin, Y: safeMargin, W: pageWidth - 2*safeMargin, H: pageHeight - 2*safeMargin},
+	}
+}
+
+// reserve records an element that stays where it is, such as a text box.
+func (p *placer) reserve(slideID string, r rect) {
+	p.placed[slideID] = append(p.placed[slideID], r)
+}
+
+// place finds room for an element that wants to sit at want, records it, and returns where
+// it goes. It keeps the element inside the safe area; if it then overlaps something, it is
+// moved just past the element in the way, by at most maxShift, or shrunk in 10% steps down to
+// half size until it fits. A shrinking element keeps its top edge and the point anchor of the
+// way across its width: 0 keeps its left edge, 0.5 its center, and 1 its right edge. An
+// element that fits nowhere keeps its clamped position.
+func (p *placer) place(slideID string, want rect, anchor float64) rect {
+	fit := want.clamp(p.safe)
+	for scale := 1.0; scale >= 0.5-1e-9; scale -= 0.1 {
+		w := fit.W * scale
+		r := rect{X: want.X + (want.W-w)*anchor, Y: want.Y, W: w, H: fit.H * scale}.clamp(p.safe)
+		if got, ok := p.free(slideID, r); ok {
+			p.reserve(slideID, got)
+			return got
+		}
+	}
+	p.reserve(slideID, fit)
+	return fit
+}
+
+// free returns the spot for r nearest its position that overlaps nothing on the slide: r
+// itself, or r moved just above, below, left, or right of an element it overlaps.
+func (p *placer) free(slideID string, r rect) (rect, bool) {
+	candidates := []rect{r}
+	for _, o := range p.placed[slideID] {
+		if !r.overlaps(o) {
+			continue
+		}
+		for _, c := range []rect{
+			{X: r.X, Y: o.Y - placeGap - r.H, W: r.W, H: r.H},
+			{X: r.X, Y: o.Y + o.H + placeGap, W: r.W, H: r.H},
+			{X: o.X - placeGap - r.W, Y: r.Y, W: r.W, H: r.H},
+			{X: o.X + o.W + placeGap, Y: r.Y, W: r.W, H: r.H},
+		} {
+			candidates = append(candidates, c.clamp(p.safe))
+		}
+	}
+	best, bestShift := rect{}, math.Inf(1)
+	for _, c := range candidates {
+		shift := math.Hypot(c.X-r.X, c.Y-r.Y)
+		if shift > maxShift || shift >= bestShift || p.collides(slideID, c) {
+			continue
+		}
+		best, bestShift = c, shift
+	}
+	return best, !math.IsInf(bestShift, 1)
+}
+
+func (p *placer) collides(slideID string, r rect) bool {
+	for _, o := range p.placed[slideID] {
+		if r.overlaps(o) {
+			return true
+		}
+	}
+	return false
+}
+
+// placeFrame is place for a chart frame, which is in EMU.
+func (p *placer) placeFrame(slideID string, f chartFrame) chartFrame {
+	r := p.place(slideID, rect{X: f.X / emuPerPt, Y: f.Y / emuPerPt, W: f.W / emuPerPt, H: f.H / emuPerPt}, 0)
+	return chartFrame{X: math.Round(r.X * emuPerPt), Y: math.Round(r.Y * emuPerPt), W: math.Round(r.W * emuPerPt), H: math.Round(r.H * emuPerPt)}
+}
+
+// Title-slide image caption size, in points: the caption sits beside the image's lower
+// edge, or under it when the image is centered.
+const (
+	captionWidth  = 240.0
+	captionHeight = 30.0
+	captionGap    = 10.0
+)
+
+// imageRects places a title slide's image and its caption, if any, avoiding what is
+// already on the slide. want is the image's spot at ImageLeft: ImageRight keeps it as far
+// in from the right edge of content (the title box) as it is from its left edge, and
+// ImageCenter centers it on the page.
+func (p *placer) imageRects(slideID, position string, want, content rect, caption bool) (image, capt rect) {
+	anchor := 0.0
+	switch position {
+	case ImageRight:
+		want.X, anchor = 2*content.X+content.W-want.X-want.W, 1
+	case ImageCenter:
+		want.X, anchor = (p.width-want.W)/2, 0.5
+	}
+	if !caption {
+		return p.place(slideID, want, anchor), rect{}
+	}
+	if position == ImageCenter {
+		// Place the image and the caption strip under it as one block
+		want.H += captionGap + captionHeight
+		block := p.place(slideID, want, anchor)
+		image = rect{X: block.X, Y: block.Y, W: block.W, H: block.H - captionGap - captionHeight}
+		return image, rect{X: block.X, Y: image.Y + image.H + captionGap, W: block.W, H: captionHeight}
+	}
+	image = p.place(slideID, want, anchor)
+	capt = rect{X: image.X + image.W + captionGap, Y: image.Y + image.H - captionHeight, W: captionWidth, H: captionHeight}
+	if position == ImageRight {
+		capt.X = image.X - captionGap - captionWidth
+	}
+	capt = capt.clamp(p.safe)
+	if got, ok := p.free(slideID, capt); ok {
+		capt = got
+	}
+	p.reserve(slideID, capt)
+	return image, capt
+}
@@ -0,0 +1,142 @@
+package presentation
+
+import (
+	"math"
+	"testing"
+)
+
+// titleRect is the title text box every title slide reserves.
+var titleRect = rect{X: 50, Y: 50, W: 600, H: 60}
+
+func near(a, b rect) bool {
+	const eps = 0.01
+	return math.Abs(a.X-b.X) < eps && math.Abs(a.Y-b.Y) < eps && math.Abs(a.W-b.W) < eps && math.Abs(a.H-b.H) < eps
+}
+
+func TestPlacer_ImageRects(t *testing.T) {
+	tests := []struct {
+		name      string
+		position  string
+		footer    bool
+		caption   bool
+		wantImage rect
+		wantCapt  rect
+	}{
+		{
+			name: "left shrinks to stay on the slide", position: ImageLeft,
+			wantImage: rect{X: 50, Y: 130, W: 360, H: 270},
+		},
+		{
+			name: "left caption beside the lower edge", position: ImageLeft, caption: true,
+			wantImage: rect{X: 50, Y: 130, W: 360, H: 270},
+			wantCapt:  rect{X: 420, Y: 370, W: 240, H: 30},
+		},
+		{
+			name: "footer shrinks the image further", position: ImageLeft, footer: true, caption: true,
+			wantImage: rect{X: 50, Y: 130, W: 320, H: 240},
+			wantCapt:  rect{X: 380, Y: 340, W: 240, H: 30},
+		},
+		{
+			name: "right keeps the right edge", position: ImageRight,
+			wantImage: rect{X: 290, Y: 130, W: 360, H: 270},
+		},
+		{
+			name: "right moves past the footer and the caption above it", position: ImageRight, footer: true, caption: true,
+			wantImage: rect{X: 348, Y: 130, W: 360, H: 270},
+			wantCapt:  rect{X: 98, Y: 342, W: 240, H: 30},
+		},
+		{
+			name: "center keeps the center", position: ImageCenter,
+			wantImage: rect{X: 180, Y: 130, W: 360, H: 270},
+		},
+		{
+			name: "center caption under the image", position: ImageCenter, caption: true,
+			wantImage: rect{X: 200, Y: 128, W: 320, H: 232},
+			wantCapt:  rect{X: 200, Y: 370, W: 320, H: 30},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newPlacer(slideWidth, slideHeight)
+			p.reserve("s", titleRect)
+			if tt.footer {
+				p.reserve("s", footerRect(DefaultLayout()))
+			}
+			img, capt := p.imageRects("s", tt.position, DefaultLayout().Image.rect(), titleRect, tt.caption)
+			if !near(img, tt.wantImage) || !near(capt, tt.wantCapt) {
+				t.Errorf("imageRects() = %v, %v; want %v, %v", img, capt, tt.wantImage, tt.wantCapt)
+			}
+			for _, placed := range []rect{img, capt} {
+				if placed.W == 0 {
+					continue
+				}
+				if placed.overlaps(titleRect) || tt.footer && placed.overlaps(footerRect(DefaultLayout())) {
+					t.Errorf("%v overlaps the title or footer", placed)
+				}
+			}
+		})
+	}
+}
+
+func TestPlacer_Place(t *testing.T) {
+	tests := []struct {
+		name     string
+		reserved []rect
+		want     rect
+		anchor   float64
+		wantRect rect
+	}{
+		{
+			name:     "free spot is kept",
+			reserved: []rect{{X: 50, Y: 130, W: 360, H: 300}},
+			want:     rect{X: 430, Y: 130, W: 260, H: 195},
+			wantRect: rect{X: 430, Y: 130, W: 260, H: 195},
+		},
+		{
+			name:     "moves below a box it overlaps a little",
+			reserved: []rect{{X: 50, Y: 50, W: 600, H: 60}},
+			want:     rect{X: 100, Y: 100, W: 200, H: 100},
+			wantRect: rect{X: 100, Y: 118, W: 200, H: 100},
+		},
+		{
+			name:     "shrinks around its center when moving is too far",
+			reserved: []rect{{X: 0, Y: 0, W: 720, H: 100}, {X: 0, Y: 350, W: 720, H: 55}},
+			want:     rect{X: 200, Y: 100, W: 300, H: 300},
+			anchor:   0.5,
+			wantRect: rect{X: 230, Y: 100, W: 240, H: 240},
+		},
+		{
+			name:     "larger than the slide is scaled into the safe area",
+			want:     rect{X: 0, Y: 0, W: 1440, H: 810},
+			wantRect: rect{X: safeMargin, Y: safeMargin, W: (slideHeight - 2*safeMargin) * 1440 / 810, H: slideHeight - 2*safeMargin},
+		},
+		{
+			name:     "no room keeps the clamped spot",
+			reserved: []rect{{X: 0, Y: 0, W: 720, H: 405}},
+			want:     rect{X: 100, Y: 100, W: 200, H: 100},
+			wantRect: rect{X: 100, Y: 100, W: 200, H: 100},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newPlacer(slideWidth, slideHeight)
+			for _, r := range tt.reserved {
+				p.reserve("s", r)
+			}
+			if got := p.place("s", tt.want, tt.anchor); !near(got, tt.wantRect) {
+				t.Errorf("place() = %v, want %v", got, tt.wantRect)
+			}
+		})
+	}
+}
+
+func TestParseImagePosition(t *testing.T) {
+	for in, want := range map[string]string{"": ImageLeft, "Right": ImageRight, " center ": ImageCenter} {
+		if got, err := ParseImagePosition(in); err != nil || got != want {
+			t.Errorf("ParseImagePosition(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseImagePosition("top"); err == nil {
+		t.Error("ParseImagePosition(top) succeeded, want an error")
+	}
+}
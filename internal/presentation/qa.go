@@ -0,0 +1,124 @@
+package presentation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/api/slides/v1"
+
+	"gogemini-practices/internal/palette"
+)
+
+// QA is a question the audience is likely to ask, with a suggested answer for the
+// presenter.
+type QA struct {
+	Question string
+	Answer   string
+}
+
+// qaRequests builds the Q&A prep slide, last in the deck and skipped in presentation mode:
+// a heading and the numbered questions. The answers go into its speaker notes, which only
+// exist once the slide does; see writeQANotes.
+func (w *deckWriter) qaRequests(qa []QA) []*slides.Request {
+	var lines []string
+	for _, q := range qa {
+		if q := oneLine(q.Question); q != "" {
+			lines = append(lines, q)
+		}
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	slideID, bodyID := w.id("qa_slide", -1), w.id("qa_body", -1)
+	requests := []*slides.Request{
+		{CreateSlide: &slides.CreateSlideRequest{
+			ObjectId:             slideID,
+			SlideLayoutReference: &slides.LayoutReference{PredefinedLayout: "BLANK"},
+		}},
+		{UpdateSlideProperties: &slides.UpdateSlidePropertiesRequest{
+			ObjectId:        slideID,
+			SlideProperties: &slides.SlideProperties{IsSkipped: true},
+			Fields:          "isSkipped",
+		}},
+	}
+
+	var color *slides.OpaqueColor
+	if w.opts.Palette != nil {
+		if r, g, b, err := palette.RGB(w.opts.Palette.Primary); err == nil {
+			color = &slides.OpaqueColor{RgbColor: &slides.RgbColor{Red: r, Green: g, Blue: b}}
+		}
+	}
+	requests = append(requests, textBoxRequests(w.id("qa_heading", -1), slideID, "Anticipated questions", 50, 40, 600, 50, 28, true, color)...)
+	requests = append(requests,
+		paddedBox(bodyID, slideID, rect{X: 80, Y: 110, W: 560, H: 260}, w.layout.Padding),
+		fitRequest(bodyID, alignTop),
+		&slides.Request{InsertText: &slides.InsertTextRequest{ObjectId: bodyID, Text: strings.Join(lines, "\n")}},
+		&slides.Request{UpdateTextStyle: &slides.UpdateTextStyleRequest{
+			ObjectId:  bodyID,
+			Style:     &slides.TextStyle{FontSize: &slides.Dimension{Magnitude: 16, Unit: "PT"}},
+			Fields:    "fontSize",
+			TextRange: &slides.Range{Type: "ALL"},
+		}},
+		&slides.Request{CreateParagraphBullets: &slides.CreateParagraphBulletsRequest{
+			ObjectId:     bodyID,
+			BulletPreset: "NUMBERED_DIGIT_PERIOD",
+			TextRange:    &slides.Range{Type: "ALL"},
+		}},
+	)
+	return requests
+}
+
+// qaNotes is the Q&A slide's speaker notes: each question, numbered as on the slide, with
+// its suggested answer.
+func qaNotes(qa []QA) string {
+	var b strings.Builder
+	n := 0
+	for _, q := range qa {
+		question := oneLine(q.Question)
+		if question == "" {
+			continue
+		}
+		n++
+		if n > 1 {
+			b.WriteString("\n\n")
+		}
+		fmt.Fprintf(&b, "%d. %s\n", n, question)
+		if answer := strings.TrimSpace(q.Answer); answer != "" {
+			b.WriteString("Answer: " + answer)
+		} else {
+			b.WriteString("Answer: (none suggested)")
+		}
+	}
+	return b.String()
+}
+
+// writeQANotes puts the suggested answers into the Q&A slide's speaker notes. It runs
+// before writeTimingNotes, which then leaves the slide's notes alone.
+func writeQANotes(ctx context.Context, svc SlidesAPI, presentationID, slideID string, qa []QA) error {
+	pres, err := svc.Get(ctx, presentationID)
+	if err != nil {
+		return fmt.Errorf("get presentation for Q&A notes: %w", err)
+	}
+	for _, sld := range pres.Slides {
+		if sld == nil || sld.ObjectId != slideID || sld.SlideProperties == nil || sld.SlideProperties.NotesPage == nil {
+			continue
+		}
+		props := sld.SlideProperties.NotesPage.NotesProperties
+		if props == nil || props.SpeakerNotesObjectId == "" {
+			break
+		}
+		// The notes shape may not exist yet; inserting text with its ID creates it
+		err := svc.BatchUpdate(ctx, presentationID, []*slides.Request{{InsertText: &slides.InsertTextRequest{ObjectId: props.SpeakerNotesObjectId, Text: qaNotes(qa)}}})
+		if err != nil {
+			return fmt.Errorf("write Q&A notes: %w", err)
+		}
+		return nil
+	}
+	return fmt.Errorf("write Q&A notes: slide %s has no speaker notes", slideID)
+}
+
+// oneLine collapses whitespace, including newlines, to single spaces.
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,64 @@
+package presentation
+
+import (
+	"strings"
+
+	"google.golang.org/api/slides/v1"
+
+	"gogemini-practices/internal/palette"
+)
+
+// Quote is a deck's pull quote, shown on a slide of its own after the topics.
+type Quote struct {
+	Text        string
+	Attribution string // speaker or source; omitted when empty
+}
+
+// quoteRequests builds the quote slide: the text in large italic type in the palette's
+// primary color, wrapped in curly quotes, with the attribution right-aligned under it.
+func quoteRequests(q Quote, opts DeckOptions, runID string) []*slides.Request {
+	text := strings.Trim(strings.TrimSpace(q.Text), `"“”`)
+	if text == "" {
+		return nil
+	}
+	slideID := "auto_quote_slide_" + runID
+	requests := []*slides.Request{{CreateSlide: &slides.CreateSlideRequest{
+		ObjectId:             slideID,
+		SlideLayoutReference: &slides.LayoutReference{PredefinedLayout: "BLANK"},
+	}}}
+
+	primary := palette.Default().Primary
+	if opts.Palette != nil {
+		primary = opts.Palette.Primary
+	}
+	var color *slides.OpaqueColor
+	if r, g, b, err := palette.RGB(primary); err == nil {
+		color = &slides.OpaqueColor{RgbColor: &slides.RgbColor{Red: r, Green: g, Blue: b}}
+	}
+	textID := "auto_quote_text_" + runID
+	requests = append(requests, textBoxRequests(textID, slideID, "“"+text+"”", 70, 90, 580, 180, 32, false, color)...)
+	requests = append(requests, &slides.Request{UpdateTextStyle: &slides.UpdateTextStyleRequest{
+		ObjectId:  textID,
+		Style:     &slides.TextStyle{Italic: true},
+		Fields:    "italic",
+		TextRange: &slides.Range{Type: "ALL"},
+	}})
+	ids := []string{textID}
+
+	if who := strings.TrimSpace(q.Attribution); who != "" {
+		byID := "auto_quote_by_" + runID
+		gray := &slides.OpaqueColor{RgbColor: &slides.RgbColor{Red: 0.4, Green: 0.4, Blue: 0.4}}
+		requests = append(requests, textBoxRequests(byID, slideID, "— "+who, 70, 280, 580, 30, 16, false, gray)...)
+		requests = append(requests, &slides.Request{UpdateParagraphStyle: &slides.UpdateParagraphStyleRequest{
+			ObjectId:  byID,
+			Style:     &slides.ParagraphStyle{Alignment: "END"},
+			Fields:    "alignment",
+			TextRange: &slides.Range{Type: "ALL"},
+		}})
+		ids = append(ids, byID)
+	}
+	if opts.GroupComposites {
+		requests = append(requests, groupRequests("auto_quote_group_"+runID, ids)...)
+	}
+	return requests
+}
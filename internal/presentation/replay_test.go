@@ -0,0 +1,64 @@
+package presentation
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/option"
+	"google.golang.org/api/sheets/v4"
+	"google.golang.org/api/slides/v1"
+
+	"gogemini-practices/internal/charts"
+	"gogemini-practices/internal/vcr"
+)
+
+// TestWriteDeck_Replay writes a two-topic deck with a Sheets chart through the real API
+// clients, replaying a recorded cassette. Record it with VCR_MODE=record, service account
+// JSON in TEST_SA_JSON (a path or the raw JSON), and a scratch deck and spreadsheet shared
+// with that account in VCR_PRESENTATION_ID and VCR_SHEET_ID; both are overwritten.
+func TestWriteDeck_Replay(t *testing.T) {
+	ctx := context.Background()
+	var real http.RoundTripper
+	if vcr.ModeFromEnv() == vcr.Record {
+		creds := os.Getenv("TEST_SA_JSON")
+		if b, err := os.ReadFile(creds); err == nil {
+			creds = string(b)
+		}
+		cfg, err := google.JWTConfigFromJSON([]byte(creds), slides.PresentationsScope, sheets.SpreadsheetsScope)
+		if err != nil {
+			t.Fatalf("recording needs service account JSON in TEST_SA_JSON: %v", err)
+		}
+		real = cfg.Client(ctx).Transport
+	}
+	rec := vcr.ForTest(t, filepath.Join("testdata", "cassettes", "write_deck.json"), real)
+	presentationID, sheetID := rec.Var("presentation_id"), rec.Var("sheet_id")
+	if rec.Mode() == vcr.Record {
+		presentationID, sheetID = os.Getenv("VCR_PRESENTATION_ID"), os.Getenv("VCR_SHEET_ID")
+		if presentationID == "" || sheetID == "" {
+			t.Fatal("recording needs VCR_PRESENTATION_ID and VCR_SHEET_ID")
+		}
+		rec.SetVar("presentation_id", presentationID)
+		rec.SetVar("sheet_id", sheetID)
+	}
+
+	opts := []option.ClientOption{option.WithHTTPClient(rec.Client())}
+	slidesSvc, err := slides.NewService(ctx, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sheetsSvc, err := sheets.NewService(ctx, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	topics := []RichTopic{
+		{Title: "Why it matters", Summary: "• **Faster** triage\n• Fewer errors"},
+		{Title: "Adoption", Summary: "Users doubled in a year", Dataset: twoPoints()},
+	}
+	if err := WriteDeck(ctx, NewSlidesAPI(slidesSvc), charts.NewSheetsAPI(sheetsSvc), sheetID, presentationID, topics, DeckOptions{RunID: "vcr00001"}); err != nil {
+		t.Fatalf("WriteDeck() error = %v", err)
+	}
+}
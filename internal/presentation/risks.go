@@ -0,0 +1,53 @@
+package presentation
+
+import (
+	"strings"
+
+	"google.golang.org/api/slides/v1"
+
+	"gogemini-practices/internal/palette"
+)
+
+// risksRequests builds the "Risks & counterarguments" slide: a heading and one bullet per
+// risk. Blank risks are skipped; with none left there is no slide.
+func (w *deckWriter) risksRequests(risks []string) []*slides.Request {
+	var lines []string
+	for _, r := range risks {
+		if r = strings.Join(strings.Fields(r), " "); r != "" {
+			lines = append(lines, r)
+		}
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	slideID, bodyID := w.id("risks_slide", -1), w.id("risks_body", -1)
+	requests := []*slides.Request{{CreateSlide: &slides.CreateSlideRequest{
+		ObjectId:             slideID,
+		SlideLayoutReference: &slides.LayoutReference{PredefinedLayout: "BLANK"},
+	}}}
+
+	var color *slides.OpaqueColor
+	if w.opts.Palette != nil {
+		if r, g, b, err := palette.RGB(w.opts.Palette.Primary); err == nil {
+			color = &slides.OpaqueColor{RgbColor: &slides.RgbColor{Red: r, Green: g, Blue: b}}
+		}
+	}
+	requests = append(requests, textBoxRequests(w.id("risks_heading", -1), slideID, "Risks & counterarguments", 50, 40, 600, 50, 28, true, color)...)
+	requests = append(requests,
+		paddedBox(bodyID, slideID, rect{X: 80, Y: 110, W: 560, H: 260}, w.layout.Padding),
+		fitRequest(bodyID, alignTop),
+		&slides.Request{InsertText: &slides.InsertTextRequest{ObjectId: bodyID, Text: strings.Join(lines, "\n")}},
+		&slides.Request{UpdateTextStyle: &slides.UpdateTextStyleRequest{
+			ObjectId:  bodyID,
+			Style:     &slides.TextStyle{FontSize: &slides.Dimension{Magnitude: 18, Unit: "PT"}},
+			Fields:    "fontSize",
+			TextRange: &slides.Range{Type: "ALL"},
+		}},
+		&slides.Request{CreateParagraphBullets: &slides.CreateParagraphBulletsRequest{
+			ObjectId:     bodyID,
+			BulletPreset: "BULLET_DISC_CIRCLE_SQUARE",
+			TextRange:    &slides.Range{Type: "ALL"},
+		}},
+	)
+	return requests
+}
@@ -0,0 +1,72 @@
+package presentation
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Topic statuses a write reports in DeckOptions.Status.
+const (
+	StatusBuilt             = "built"
+	StatusBuiltWithoutImage = "built-without-image" // RichTopic.ImageMissing: the search found nothing usable
+	StatusChartFailed       = "chart-failed"        // the chart slide has no chart, or one Slides can't load
+)
+
+// ErrChartFailed reports topic charts that could be neither built in Sheets nor replaced
+// by a fallback image. WriteDeck and ReplaceTopic leave their chart slides empty, write the
+// rest of the deck, and return it wrapped, joined with any ErrChartNotLoaded.
+var ErrChartFailed = errors.New("chart could not be created")
+
+// TopicStatus is how one topic of a write came out.
+type TopicStatus struct {
+	Topic  int    `json:"topic"` // 1-based position in the deck
+	Title  string `json:"title"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"` // why the chart failed
+}
+
+// topicStatuses reports each of topics, the first of which is the deck's topic first
+// (0-based), given the chart failures by topic index.
+func topicStatuses(topics []RichTopic, first int, failed map[int]error) []TopicStatus {
+	statuses := make([]TopicStatus, len(topics))
+	for i, t := range topics {
+		s := TopicStatus{Topic: first + i + 1, Title: t.Title, Status: StatusBuilt}
+		if err, ok := failed[first+i]; ok {
+			s.Status, s.Error = StatusChartFailed, err.Error()
+		} else if t.ImageMissing {
+			s.Status = StatusBuiltWithoutImage
+		}
+		statuses[i] = s
+	}
+	return statuses
+}
+
+// chartFailures wraps the charts placeCharts left out, by topic, in ErrChartFailed; nil
+// when there are none.
+func chartFailures(failed map[int]error, title func(int) string) error {
+	if len(failed) == 0 {
+		return nil
+	}
+	var idx []int
+	for i := range failed {
+		idx = append(idx, i)
+	}
+	sort.Ints(idx)
+	var parts []string
+	for _, i := range idx {
+		parts = append(parts, fmt.Sprintf("topic %d %q: %v", i+1, title(i), failed[i]))
+	}
+	return fmt.Errorf("%w for %d topics (%s)", ErrChartFailed, len(idx), strings.Join(parts, "; "))
+}
+
+// markUnloaded records the linked charts verifyCharts found broken as failures of their
+// topics, read back from the chart object IDs.
+func markUnloaded(failed map[int]error, broken []string, runID string) {
+	for _, id := range broken {
+		if obj, ok := parseObjectID(id, runID); ok && obj.Topic > 0 {
+			failed[obj.Topic-1] = ErrChartNotLoaded
+		}
+	}
+}
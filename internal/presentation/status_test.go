@@ -0,0 +1,71 @@
+package presentation
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gogemini-practices/internal/fakeapi"
+)
+
+func TestWriteDeck_Status(t *testing.T) {
+	defer func(d time.Duration) { chartVerifyDelay = d }(chartVerifyDelay)
+	chartVerifyDelay = 0
+	topics := []RichTopic{
+		{Title: "Intro", Summary: "Hello", ImageMissing: true},
+		{Title: "Growth", Summary: "Users doubled", Dataset: twoPoints()},
+		{Title: "Outro", Summary: "Bye", ImageURL: "https://img/outro"},
+	}
+	tests := []struct {
+		name    string
+		slides  *fakeapi.Slides
+		sheets  *fakeapi.Sheets
+		want    []string
+		wantErr error
+	}{
+		{
+			name:   "all built",
+			slides: &fakeapi.Slides{},
+			sheets: &fakeapi.Sheets{},
+			want:   []string{StatusBuiltWithoutImage, StatusBuilt, StatusBuilt},
+		},
+		{
+			name:    "sheets failure fails only its topic",
+			slides:  &fakeapi.Slides{},
+			sheets:  &fakeapi.Sheets{Err: errors.New("quota exceeded")},
+			want:    []string{StatusBuiltWithoutImage, StatusChartFailed, StatusBuilt},
+			wantErr: ErrChartFailed,
+		},
+		{
+			name:    "unloaded linked chart",
+			slides:  &fakeapi.Slides{UnloadedCharts: true},
+			sheets:  &fakeapi.Sheets{},
+			want:    []string{StatusBuiltWithoutImage, StatusChartFailed, StatusBuilt},
+			wantErr: ErrChartNotLoaded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var status []TopicStatus
+			err := WriteDeck(context.Background(), tt.slides, tt.sheets, "sheet-1", "deck-1", topics, DeckOptions{RunID: "r1", Status: &status})
+			if tt.wantErr == nil && err != nil || tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("WriteDeck() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(tt.slides.Batches) != 1 {
+				t.Errorf("got %d batches, want the deck written", len(tt.slides.Batches))
+			}
+			if len(status) != len(tt.want) {
+				t.Fatalf("got %d statuses, want %d", len(status), len(tt.want))
+			}
+			for i, s := range status {
+				if s.Topic != i+1 || s.Title != topics[i].Title || s.Status != tt.want[i] {
+					t.Errorf("status %d = %+v, want %q of topic %d", i, s, tt.want[i], i+1)
+				}
+				if (s.Status == StatusChartFailed) != (s.Error != "") {
+					t.Errorf("status %d error = %q, want one only for a failed chart", i, s.Error)
+				}
+			}
+		})
+	}
+}
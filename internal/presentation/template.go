@@ -0,0 +1,26 @@
+package presentation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/api/drive/v3"
+)
+
+// CopyTemplate copies a template deck through Drive so WriteDeck can fill the copy: its
+// slides are replaced, but masters, layouts, and the theme carry over. The copy belongs to
+// the authenticated account. Returns: the new presentation ID, error.
+func CopyTemplate(ctx context.Context, driveSvc *drive.Service, templateID, name string) (string, error) {
+	if driveSvc == nil {
+		return "", fmt.Errorf("drive service is nil")
+	}
+	if strings.TrimSpace(templateID) == "" {
+		return "", fmt.Errorf("template presentation ID is required")
+	}
+	f, err := driveSvc.Files.Copy(templateID, &drive.File{Name: name}).Fields("id").SupportsAllDrives(true).Context(ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("copy template %s: %w", templateID, err)
+	}
+	return f.Id, nil
+}
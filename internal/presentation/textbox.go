@@ -0,0 +1,43 @@
+package presentation
+
+import "google.golang.org/api/slides/v1"
+
+// Content alignments of a text box: where its text sits between the top and bottom edges.
+const (
+	alignTop    = "TOP"
+	alignMiddle = "MIDDLE"
+)
+
+// fitRequest anchors a text box's text to its top or middle and turns autofit off, so the
+// box keeps the size placement reserved for it and its text keeps the size its style sets.
+// The Slides API only lets requests turn autofit off; text too long for its box overflows
+// it rather than shrinking.
+func fitRequest(objectID, alignment string) *slides.Request {
+	return &slides.Request{UpdateShapeProperties: &slides.UpdateShapePropertiesRequest{
+		ObjectId: objectID,
+		ShapeProperties: &slides.ShapeProperties{
+			ContentAlignment: alignment,
+			Autofit:          &slides.Autofit{AutofitType: "NONE"},
+		},
+		Fields: "contentAlignment,autofit.autofitType",
+	}}
+}
+
+// paddedBox is a transparent text box padding points inside frame. The Slides API has no
+// text insets to set, so a box without fill or outline is drawn smaller instead, which
+// looks the same; placement still reserves the whole frame.
+func paddedBox(objectID, pageID string, frame rect, padding float64) *slides.Request {
+	box := frame.inset(padding)
+	return &slides.Request{CreateShape: &slides.CreateShapeRequest{
+		ObjectId:  objectID,
+		ShapeType: "TEXT_BOX",
+		ElementProperties: &slides.PageElementProperties{
+			PageObjectId: pageID,
+			Size: &slides.Size{
+				Width:  &slides.Dimension{Magnitude: box.W, Unit: "PT"},
+				Height: &slides.Dimension{Magnitude: box.H, Unit: "PT"},
+			},
+			Transform: &slides.AffineTransform{ScaleX: 1, ScaleY: 1, TranslateX: box.X, TranslateY: box.Y, Unit: "PT"},
+		},
+	}}
+}
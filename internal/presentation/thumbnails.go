@@ -0,0 +1,146 @@
+package presentation
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gogemini-practices/internal/pipeline"
+
+	"google.golang.org/api/slides/v1"
+)
+
+// ThumbnailAPI is a SlidesAPI that also renders slide thumbnails.
+type ThumbnailAPI interface {
+	SlidesAPI
+	// GetThumbnail renders a PNG of the page at size (SMALL, MEDIUM, or LARGE) and returns
+	// its short-lived URL.
+	GetThumbnail(ctx context.Context, presentationID, pageID, size string) (*slides.Thumbnail, error)
+}
+
+// NewThumbnailAPI wraps svc; a nil svc gives a nil ThumbnailAPI.
+func NewThumbnailAPI(svc *slides.Service) ThumbnailAPI {
+	if svc == nil {
+		return nil
+	}
+	return slidesService{svc}
+}
+
+func (s slidesService) GetThumbnail(ctx context.Context, presentationID, pageID, size string) (*slides.Thumbnail, error) {
+	return s.svc.Presentations.Pages.GetThumbnail(presentationID, pageID).
+		ThumbnailPropertiesMimeType("PNG").
+		ThumbnailPropertiesThumbnailSize(size).
+		Context(ctx).Do()
+}
+
+// ThumbnailWorkers bounds the thumbnails rendered at once. Thumbnails count against the
+// Slides API's lower quota for expensive reads, so it stays below pipeline.DefaultWorkers.
+const ThumbnailWorkers = 2
+
+// ThumbnailOptions controls Thumbnails.
+type ThumbnailOptions struct {
+	Size   string       // SMALL (200px wide), MEDIUM (800px), or LARGE (1600px); empty is MEDIUM
+	Dir    string       // when set, each PNG is downloaded to <Dir>/<presentation ID>-<nn>.png
+	Client *http.Client // downloads; nil uses a client with a 20s timeout
+	// KeepData downloads each PNG into Thumbnail.Data even without Dir, e.g. to compose
+	// a contact sheet.
+	KeepData bool
+}
+
+// Thumbnail is one slide's rendered image.
+type Thumbnail struct {
+	Slide  int    `json:"slide"` // 1-based position in the deck
+	PageID string `json:"page_id"`
+	URL    string `json:"url,omitempty"` // valid for about 30 minutes
+	Path   string `json:"path,omitempty"`
+	Width  int64  `json:"width,omitempty"`
+	Height int64  `json:"height,omitempty"`
+	Error  string `json:"error,omitempty"`
+	Data   []byte `json:"-"` // the PNG, with ThumbnailOptions.KeepData
+}
+
+// ParseThumbnailSize validates a --thumbnail-size value, case-insensitively.
+func ParseThumbnailSize(s string) (string, error) {
+	switch v := strings.ToUpper(strings.TrimSpace(s)); v {
+	case "":
+		return "MEDIUM", nil
+	case "SMALL", "MEDIUM", "LARGE":
+		return v, nil
+	}
+	return "", fmt.Errorf("unknown thumbnail size %q (small|medium|large)", s)
+}
+
+// Thumbnails renders every slide of the deck, in order, and with opts.Dir downloads each
+// one. A slide whose thumbnail fails keeps its error in the result and the others are
+// still fetched; the error returned is only for a deck that can't be read or a Dir that
+// can't be created.
+func Thumbnails(ctx context.Context, api ThumbnailAPI, presentationID string, opts ThumbnailOptions) ([]Thumbnail, error) {
+	size, err := ParseThumbnailSize(opts.Size)
+	if err != nil {
+		return nil, err
+	}
+	pres, err := api.Get(ctx, presentationID)
+	if err != nil {
+		return nil, fmt.Errorf("read deck for thumbnails: %w", err)
+	}
+	if opts.Dir != "" {
+		if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+			return nil, fmt.Errorf("thumbnail dir: %w", err)
+		}
+	}
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{Timeout: 20 * time.Second}
+	}
+	thumbs := make([]Thumbnail, len(pres.Slides))
+	_ = pipeline.Run(ctx, len(pres.Slides), ThumbnailWorkers, func(ctx context.Context, i int) error {
+		th := &thumbs[i]
+		th.Slide, th.PageID = i+1, pres.Slides[i].ObjectId
+		t, err := api.GetThumbnail(ctx, presentationID, th.PageID, size)
+		if err == nil {
+			th.URL, th.Width, th.Height = t.ContentUrl, t.Width, t.Height
+			var data []byte
+			if opts.Dir != "" || opts.KeepData {
+				data, err = download(ctx, client, th.URL)
+			}
+			if err == nil && opts.Dir != "" {
+				th.Path = filepath.Join(opts.Dir, fmt.Sprintf("%s-%02d.png", presentationID, th.Slide))
+				err = os.WriteFile(th.Path, data, 0o644)
+			}
+			if err == nil && opts.KeepData {
+				th.Data = data
+			}
+		}
+		if err != nil {
+			th.Path, th.Data, th.Error = "", nil, err.Error()
+		}
+		return nil
+	})
+	return thumbs, nil
+}
+
+// download returns the body of a GET of url.
+func download(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("download thumbnail: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download thumbnail: http %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("download thumbnail: %w", err)
+	}
+	return data, nil
+}
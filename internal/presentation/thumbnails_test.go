@@ -0,0 +1,91 @@
+package presentation
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gogemini-practices/internal/fakeapi"
+
+	"google.golang.org/api/slides/v1"
+)
+
+func TestThumbnails(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/gone.png" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("png:" + r.URL.Path))
+	}))
+	defer srv.Close()
+	deck := func() *slides.Presentation {
+		return &slides.Presentation{Slides: []*slides.Page{{ObjectId: "s1"}, {ObjectId: "s2"}, {ObjectId: "gone"}, {ObjectId: "s4"}}}
+	}
+
+	t.Run("urls only", func(t *testing.T) {
+		api := &fakeapi.Slides{Presentation: deck(), ThumbnailBase: srv.URL, ThumbnailErr: map[string]error{"s2": errors.New("quota")}}
+		got, err := Thumbnails(context.Background(), api, "deck-1", ThumbnailOptions{})
+		if err != nil {
+			t.Fatalf("Thumbnails() error = %v", err)
+		}
+		if len(got) != 4 {
+			t.Fatalf("got %d thumbnails, want one per slide", len(got))
+		}
+		if want := (Thumbnail{Slide: 1, PageID: "s1", URL: srv.URL + "/s1.png", Width: 800, Height: 450}); !reflect.DeepEqual(got[0], want) {
+			t.Errorf("thumbnail 1 = %+v", got[0])
+		}
+		if got[1].Error == "" || got[1].URL != "" {
+			t.Errorf("thumbnail 2 = %+v, want its error", got[1])
+		}
+		if got[3].Slide != 4 || got[3].Error != "" {
+			t.Errorf("thumbnail 4 = %+v, want it fetched after a failure", got[3])
+		}
+	})
+
+	t.Run("saved", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "thumbs")
+		api := &fakeapi.Slides{Presentation: deck(), ThumbnailBase: srv.URL}
+		got, err := Thumbnails(context.Background(), api, "deck-1", ThumbnailOptions{Size: "large", Dir: dir})
+		if err != nil {
+			t.Fatalf("Thumbnails() error = %v", err)
+		}
+		want := filepath.Join(dir, "deck-1-01.png")
+		if got[0].Path != want {
+			t.Errorf("path = %q, want %q", got[0].Path, want)
+		}
+		if b, err := os.ReadFile(want); err != nil || string(b) != "png:/s1.png" {
+			t.Errorf("saved %q, %v", b, err)
+		}
+		if got[2].Path != "" || got[2].Error == "" {
+			t.Errorf("failed download = %+v, want an error and no path", got[2])
+		}
+	})
+
+	t.Run("kept in memory", func(t *testing.T) {
+		api := &fakeapi.Slides{Presentation: deck(), ThumbnailBase: srv.URL}
+		got, err := Thumbnails(context.Background(), api, "deck-1", ThumbnailOptions{KeepData: true})
+		if err != nil {
+			t.Fatalf("Thumbnails() error = %v", err)
+		}
+		if string(got[3].Data) != "png:/s4.png" || got[3].Path != "" {
+			t.Errorf("thumbnail 4 = %+v, want its bytes and no path", got[3])
+		}
+		if got[2].Data != nil || got[2].Error == "" {
+			t.Errorf("failed download = %+v, want an error and no data", got[2])
+		}
+	})
+
+	if _, err := Thumbnails(context.Background(), &fakeapi.Slides{Presentation: deck()}, "deck-1", ThumbnailOptions{Size: "huge"}); err == nil {
+		t.Error("unknown size: want an error")
+	}
+	if _, err := Thumbnails(context.Background(), &fakeapi.Slides{Err: errors.New("404")}, "deck-1", ThumbnailOptions{}); err == nil {
+		t.Error("unreadable deck: want an error")
+	}
+}
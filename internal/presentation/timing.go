@@ -0,0 +1,133 @@
+package presentation
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"google.golang.org/api/slides/v1"
+
+	"gogemini-practices/internal/formatting"
+)
+
+// MinSlideTime is the shortest a slide is expected to stay up, however few words it has.
+const MinSlideTime = 15 * time.Second
+
+// SpeakingTime estimates how long presenting words takes at wordsPerMinute, rounded to
+// 5 seconds and never below MinSlideTime.
+func SpeakingTime(words int, wordsPerMinute float64) time.Duration {
+	if wordsPerMinute <= 0 {
+		return MinSlideTime
+	}
+	secs := math.Round(float64(words)*60/wordsPerMinute/5) * 5
+	d := time.Duration(secs) * time.Second
+	if d < MinSlideTime {
+		return MinSlideTime
+	}
+	return d
+}
+
+// FormatSpeakingTime renders an estimate for speaker notes, e.g. "≈ 1m 30s" or "≈ 45s".
+func FormatSpeakingTime(d time.Duration) string {
+	d = d.Round(time.Second)
+	m, s := int(d/time.Minute), int((d%time.Minute)/time.Second)
+	switch {
+	case m == 0:
+		return fmt.Sprintf("≈ %ds", s)
+	case s == 0:
+		return fmt.Sprintf("≈ %dm", m)
+	}
+	return fmt.Sprintf("≈ %dm %ds", m, s)
+}
+
+// writeTimingNotes puts a speaking-time estimate, from the words on each generated slide,
+// into the slide's speaker notes. Notes that already have text are left alone, so a
+// rebuilt topic only fills in its new slides.
+func writeTimingNotes(ctx context.Context, svc SlidesAPI, presentationID string, wordsPerMinute float64) error {
+	pres, err := svc.Get(ctx, presentationID)
+	if err != nil {
+		return fmt.Errorf("get presentation for notes: %w", err)
+	}
+	var requests []*slides.Request
+	for _, sld := range pres.Slides {
+		if sld == nil || !isGenerated(sld.ObjectId) || sld.SlideProperties == nil || sld.SlideProperties.NotesPage == nil {
+			continue
+		}
+		notes := sld.SlideProperties.NotesPage
+		if notes.NotesProperties == nil || notes.NotesProperties.SpeakerNotesObjectId == "" {
+			continue
+		}
+		notesID := notes.NotesProperties.SpeakerNotesObjectId
+		if strings.TrimSpace(elementsText(notes.PageElements, notesID)) != "" {
+			continue
+		}
+		words := formatting.WordCount(elementsText(sld.PageElements, ""))
+		// The notes shape may not exist yet; inserting text with its ID creates it
+		requests = append(requests, &slides.Request{InsertText: &slides.InsertTextRequest{
+			ObjectId: notesID,
+			Text:     FormatSpeakingTime(SpeakingTime(words, wordsPerMinute)),
+		}})
+	}
+	if len(requests) == 0 {
+		return nil
+	}
+	if err := svc.BatchUpdate(ctx, presentationID, requests); err != nil {
+		return fmt.Errorf("write speaker notes: %w", err)
+	}
+	return nil
+}
+
+// elementsText is the text of the elements, looking inside groups and table cells; with a
+// non-empty onlyID, just that element's text. Footers are not read aloud, so they are skipped.
+func elementsText(elements []*slides.PageElement, onlyID string) string {
+	var b strings.Builder
+	var walk func([]*slides.PageElement)
+	walk = func(els []*slides.PageElement) {
+		for _, el := range els {
+			if el == nil {
+				continue
+			}
+			if el.ElementGroup != nil {
+				walk(el.ElementGroup.Children)
+				continue
+			}
+			if onlyID != "" && el.ObjectId != onlyID {
+				continue
+			}
+			if strings.HasPrefix(el.ObjectId, footerPrefix) {
+				continue
+			}
+			if el.Shape != nil {
+				writeText(&b, el.Shape.Text)
+			}
+			if el.Table != nil {
+				for _, row := range el.Table.TableRows {
+					if row == nil {
+						continue
+					}
+					for _, cell := range row.TableCells {
+						if cell != nil {
+							writeText(&b, cell.Text)
+						}
+					}
+				}
+			}
+		}
+	}
+	walk(elements)
+	return b.String()
+}
+
+func writeText(b *strings.Builder, t *slides.TextContent) {
+	if t == nil {
+		return
+	}
+	for _, te := range t.TextElements {
+		if te != nil && te.TextRun != nil {
+			b.WriteString(te.TextRun.Content)
+			b.WriteString(" ")
+		}
+	}
+}
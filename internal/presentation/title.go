@@ -0,0 +1,119 @@
+package presentation
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/api/slides/v1"
+
+	"gogemini-practices/internal/palette"
+)
+
+// TitleStyle is the character style of topic titles, a profile of its own so titles read
+// as headings instead of summary-sized text. Markup in a title still styles its own words.
+type TitleStyle struct {
+	FontSize float64 // points; 0 keeps the text box default
+	// FontFamily is the title font; empty uses the font of the theme's title placeholder,
+	// or the text box default for a theme without one.
+	FontFamily string
+	// Color is TitleColorAccent for the palette's accent or a #RRGGBB color; empty colors
+	// titles with the palette's primary color, or leaves the theme color without a palette.
+	Color string
+}
+
+// TitleColorAccent colors titles with the palette's accent (the default palette's without
+// one).
+const TitleColorAccent = "accent"
+
+// DefaultTitleStyle sets titles in 28pt type in the theme's heading font.
+func DefaultTitleStyle() TitleStyle {
+	return TitleStyle{FontSize: 28}
+}
+
+// ParseTitleColor checks a --title-color value: primary (or empty), accent, or #RRGGBB.
+// It returns the TitleStyle.Color for it.
+func ParseTitleColor(s string) (string, error) {
+	switch s = strings.ToLower(strings.TrimSpace(s)); s {
+	case "", "primary":
+		return "", nil
+	case TitleColorAccent:
+		return s, nil
+	}
+	if _, _, _, err := palette.RGB(s); err != nil || !strings.HasPrefix(s, "#") {
+		return "", fmt.Errorf("unknown title color %q (want primary|accent|#RRGGBB)", s)
+	}
+	return s, nil
+}
+
+// color is the hex color titles get with palette p, or "" for the theme's.
+func (s TitleStyle) color(p *palette.Palette) string {
+	switch {
+	case s.Color == TitleColorAccent && p != nil:
+		return p.Accent
+	case s.Color == TitleColorAccent:
+		return palette.Default().Accent
+	case s.Color != "":
+		return s.Color
+	case p != nil:
+		return p.Primary
+	}
+	return ""
+}
+
+// styleTitle adds the title style to a title box's text requests, in the theme's heading
+// font headingFont unless the style names one. It goes right after the text is inserted,
+// under the markup styles, so bold or code words in a title keep their own look.
+func (s TitleStyle) styleTitle(textRequests []*slides.Request, objectID, headingFont string, p *palette.Palette) []*slides.Request {
+	if len(textRequests) == 0 || textRequests[0].InsertText == nil || textRequests[0].InsertText.Text == "" {
+		return textRequests
+	}
+	style := &slides.TextStyle{FontFamily: s.FontFamily}
+	if style.FontFamily == "" {
+		style.FontFamily = headingFont
+	}
+	var fields []string
+	if s.FontSize > 0 {
+		style.FontSize = &slides.Dimension{Magnitude: s.FontSize, Unit: "PT"}
+		fields = append(fields, "fontSize")
+	}
+	if style.FontFamily != "" {
+		fields = append(fields, "fontFamily")
+	}
+	requests := withColor(textRequests, objectID, s.color(p))
+	if len(fields) == 0 {
+		return requests
+	}
+	styleReq := &slides.Request{UpdateTextStyle: &slides.UpdateTextStyleRequest{
+		ObjectId:  objectID,
+		Style:     style,
+		Fields:    strings.Join(fields, ","),
+		TextRange: &slides.Range{Type: "ALL"},
+	}}
+	out := make([]*slides.Request, 0, len(requests)+1)
+	out = append(out, requests[0], styleReq)
+	return append(out, requests[1:]...)
+}
+
+// headingFont is the font of the title placeholder of pres's first master that sets one,
+// which is what the theme's own title slides use; "" when none does.
+func headingFont(pres *slides.Presentation) string {
+	for _, master := range pres.Masters {
+		if master == nil {
+			continue
+		}
+		for _, el := range master.PageElements {
+			if el == nil || el.Shape == nil || el.Shape.Placeholder == nil || el.Shape.Text == nil {
+				continue
+			}
+			if t := el.Shape.Placeholder.Type; t != "TITLE" && t != "CENTERED_TITLE" {
+				continue
+			}
+			for _, te := range el.Shape.Text.TextElements {
+				if te.TextRun != nil && te.TextRun.Style != nil && te.TextRun.Style.FontFamily != "" {
+					return te.TextRun.Style.FontFamily
+				}
+			}
+		}
+	}
+	return ""
+}
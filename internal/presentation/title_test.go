@@ -0,0 +1,99 @@
+package presentation
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/slides/v1"
+
+	"gogemini-practices/internal/fakeapi"
+	"gogemini-practices/internal/palette"
+)
+
+func TestParseTitleColor(t *testing.T) {
+	tests := []struct {
+		in, want string
+		wantErr  bool
+	}{
+		{in: "", want: ""},
+		{in: "Primary", want: ""},
+		{in: " accent ", want: TitleColorAccent},
+		{in: "#1A73E8", want: "#1a73e8"},
+		{in: "1A73E8", wantErr: true},
+		{in: "#12345", wantErr: true},
+		{in: "blue", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseTitleColor(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseTitleColor(%q) = %q, %v; want %q, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+// titleMaster is a master whose title placeholder is set in font.
+func titleMaster(font string) *slides.Page {
+	return &slides.Page{PageElements: []*slides.PageElement{
+		{Shape: &slides.Shape{Placeholder: &slides.Placeholder{Type: "BODY"}, Text: &slides.TextContent{TextElements: []*slides.TextElement{{TextRun: &slides.TextRun{Content: "Body", Style: &slides.TextStyle{FontFamily: "Arial"}}}}}}},
+		{Shape: &slides.Shape{Placeholder: &slides.Placeholder{Type: "TITLE"}, Text: &slides.TextContent{TextElements: []*slides.TextElement{
+			{ParagraphMarker: &slides.ParagraphMarker{}},
+			{TextRun: &slides.TextRun{Content: "Title", Style: &slides.TextStyle{FontFamily: font}}},
+		}}}},
+	}}
+}
+
+func TestWriteDeck_TitleStyle(t *testing.T) {
+	pal := palette.Default()
+	tests := []struct {
+		name      string
+		style     *TitleStyle
+		pal       *palette.Palette
+		wantStyle string // fields of the title-wide style request
+		wantFont  string
+		wantColor string
+	}{
+		{name: "default", wantStyle: "fontSize,fontFamily", wantFont: "Oswald"},
+		{name: "palette primary", pal: &pal, wantStyle: "fontSize,fontFamily", wantFont: "Oswald", wantColor: pal.Primary},
+		{name: "accent", style: &TitleStyle{FontSize: 36, Color: TitleColorAccent}, pal: &pal, wantStyle: "fontSize,fontFamily", wantFont: "Oswald", wantColor: pal.Accent},
+		{name: "explicit font and color", style: &TitleStyle{FontFamily: "Lora", Color: "#102030"}, wantStyle: "fontFamily", wantFont: "Lora", wantColor: "#102030"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			topics := []RichTopic{{Title: "Pricing **now**", Summary: "Raise prices 5%"}}
+			slidesAPI := &fakeapi.Slides{Presentation: &slides.Presentation{PresentationId: "deck-1", Masters: []*slides.Page{titleMaster("Oswald")}}}
+			if err := WriteDeck(context.Background(), slidesAPI, &fakeapi.Sheets{}, "sheet-1", "deck-1", topics, DeckOptions{RunID: "run1", Palette: tt.pal, TitleStyle: tt.style}); err != nil {
+				t.Fatalf("WriteDeck() error = %v", err)
+			}
+			var styles []*slides.UpdateTextStyleRequest
+			for _, r := range slidesAPI.Requests() {
+				if u := r.UpdateTextStyle; u != nil && u.ObjectId == "auto_title_0_run1" {
+					styles = append(styles, u)
+				}
+			}
+			if len(styles) == 0 || styles[0].Fields != tt.wantStyle || styles[0].TextRange.Type != "ALL" {
+				t.Fatalf("first title style = %+v, want the title-wide %q before markup", styles, tt.wantStyle)
+			}
+			size := DefaultTitleStyle().FontSize
+			if tt.style != nil {
+				size = tt.style.FontSize
+			}
+			if got := styles[0].Style; got.FontFamily != tt.wantFont || (size > 0 && got.FontSize.Magnitude != size) {
+				t.Errorf("title style font %q size %v, want %q %g", got.FontFamily, got.FontSize, tt.wantFont, size)
+			}
+			var color string
+			for _, u := range styles {
+				if u.Fields == "foregroundColor" && u.TextRange.Type == "ALL" {
+					rgb := u.Style.ForegroundColor.OpaqueColor.RgbColor
+					color = palette.Hex(rgb.Red, rgb.Green, rgb.Blue)
+				}
+			}
+			if !strings.EqualFold(color, tt.wantColor) {
+				t.Errorf("title color = %q, want %q", color, tt.wantColor)
+			}
+			if last := styles[len(styles)-1]; last.TextRange.Type == "ALL" {
+				t.Errorf("last title style %q covers the whole title, want the bold markup styled last", last.Fields)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package presentation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/api/slides/v1"
+)
+
+// ErrChartNotLoaded reports linked Sheets charts Slides could not render: the deck shows
+// "chart couldn't be loaded" in their place. It wraps the error WriteDeck and ReplaceTopic
+// return after writing the rest of the deck.
+var ErrChartNotLoaded = errors.New("linked chart could not be loaded")
+
+// chartVerifyAttempts is how many times the deck is read while waiting for linked charts
+// to render; the wait between reads starts at chartVerifyDelay and doubles.
+const chartVerifyAttempts = 4
+
+var chartVerifyDelay = time.Second
+
+// verifyCharts reads the deck back until every linked chart in ids has a rendered image
+// (a contentUrl). Slides gives a chart none when it can't open the chart, most often because
+// the deck's owner has no access to the spreadsheet; charts still without one after
+// chartVerifyAttempts reads, or missing from the deck, are returned and reported with
+// ErrChartNotLoaded.
+func verifyCharts(ctx context.Context, svc SlidesAPI, presentationID, spreadsheetID string, ids []string) ([]string, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	delay := chartVerifyDelay
+	var broken []string
+	for attempt := 0; attempt < chartVerifyAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(delay):
+			}
+			delay *= 2
+		}
+		pres, err := svc.Get(ctx, presentationID)
+		if err != nil {
+			return nil, fmt.Errorf("verify charts: get presentation: %w", err)
+		}
+		loaded := map[string]bool{}
+		for _, page := range pres.Slides {
+			markLoaded(page.PageElements, loaded)
+		}
+		broken = broken[:0]
+		for _, id := range ids {
+			if !loaded[id] {
+				broken = append(broken, id)
+			}
+		}
+		if len(broken) == 0 {
+			return nil, nil
+		}
+	}
+	return broken, fmt.Errorf("%w: %d of %d charts (%s); share spreadsheet %s with the presentation's owner and viewers, or embed chart images instead",
+		ErrChartNotLoaded, len(broken), len(ids), strings.Join(broken, ", "), spreadsheetID)
+}
+
+// markLoaded records the linked charts among elements, including grouped ones, that have a
+// rendered image.
+func markLoaded(elements []*slides.PageElement, loaded map[string]bool) {
+	for _, el := range elements {
+		switch {
+		case el.SheetsChart != nil && el.SheetsChart.ContentUrl != "":
+			loaded[el.ObjectId] = true
+		case el.ElementGroup != nil:
+			markLoaded(el.ElementGroup.Children, loaded)
+		}
+	}
+}
+
+// linkedChartIDs returns the object IDs of the linked Sheets charts requests embed.
+func linkedChartIDs(requests []*slides.Request) []string {
+	var ids []string
+	for _, r := range requests {
+		if c := r.CreateSheetsChart; c != nil && c.LinkingMode == "LINKED" {
+			ids = append(ids, c.ObjectId)
+		}
+	}
+	return ids
+}
+
+// unlinkCharts switches the charts requests embed to static images (see DeckOptions.UnlinkedCharts).
+func unlinkCharts(requests []*slides.Request) {
+	for _, r := range requests {
+		if c := r.CreateSheetsChart; c != nil {
+			c.LinkingMode = "NOT_LINKED_IMAGE"
+		}
+	}
+}
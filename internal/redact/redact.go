@@ -0,0 +1,176 @@
+// Package redact masks personal data and credentials (email addresses, phone numbers, API
+// keys, tokens, and private keys) in source text before it is sent to a model, and keeps a
+// report of what it masked. Each match is replaced with a placeholder such as [EMAIL], so
+// the model still sees that something was there.
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Kinds of redacted data, as reported in a Finding.
+const (
+	Email  = "email"
+	Phone  = "phone"
+	APIKey = "api_key"
+	Secret = "secret" // a value labeled password, token, secret, or api key
+)
+
+// placeholders replace matches of each kind.
+var placeholders = map[string]string{
+	Email:  "[EMAIL]",
+	Phone:  "[PHONE]",
+	APIKey: "[API_KEY]",
+	Secret: "[SECRET]",
+}
+
+// Finding is one redacted match: where it was, its kind, and a masked preview that tells
+// it apart from others without repeating it.
+type Finding struct {
+	Source string `json:"source"`
+	Kind   string `json:"kind"`
+	Masked string `json:"masked"`
+}
+
+// Report collects the findings of every text it redacts. The zero value is ready to use.
+type Report struct {
+	Findings []Finding `json:"findings"`
+}
+
+// pattern finds one kind of data. group, when positive, is the submatch to replace, so a
+// label like "password: " is kept.
+type pattern struct {
+	kind  string
+	re    *regexp.Regexp
+	group int
+}
+
+// patterns run in order, so a private key block or a labeled secret is masked whole before
+// the narrower key and email patterns see its parts.
+var patterns = []pattern{
+	{kind: APIKey, re: regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`)},
+	{kind: Secret, re: regexp.MustCompile(`(?i)\b(?:api[_-]?key|secret|token|password|passwd|pwd)\b["']?\s*[:=]\s*["']?([^\s"',;]{6,})`), group: 1},
+	{kind: APIKey, re: regexp.MustCompile(`\b(?:AIza[0-9A-Za-z_\-]{35}|AKIA[0-9A-Z]{16}|gh[pousr]_[A-Za-z0-9]{36,}|xox[abprs]-[A-Za-z0-9-]{10,}|sk-[A-Za-z0-9_\-]{20,}|[rs]k_(?:live|test)_[A-Za-z0-9]{16,}|eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,})`)},
+	{kind: Email, re: regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)},
+	{kind: Phone, re: regexp.MustCompile(`(?:\+\d{1,3}[\s.\-]?)?(?:\(\d{1,4}\)[\s.\-]?)?\d{2,4}(?:[\s.\-]\d{2,4}){1,4}`)},
+}
+
+// Text returns s with every match replaced by its placeholder, recording the matches under
+// source (e.g. "brief").
+func (r *Report) Text(source, s string) string {
+	for _, p := range patterns {
+		s = r.replace(source, s, p)
+	}
+	return s
+}
+
+func (r *Report) replace(source, s string, p pattern) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range p.re.FindAllStringSubmatchIndex(s, -1) {
+		start, end := m[0], m[1]
+		if p.group > 0 {
+			start, end = m[2*p.group], m[2*p.group+1]
+		}
+		match := s[start:end]
+		if p.kind == Phone && !isPhone(s, start, end) {
+			continue
+		}
+		b.WriteString(s[last:start])
+		b.WriteString(placeholders[p.kind])
+		last = end
+		r.Findings = append(r.Findings, Finding{Source: source, Kind: p.kind, Masked: mask(p.kind, match)})
+	}
+	if last == 0 {
+		return s
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// isoDate matches a match that starts with a date such as 2024-01-15 or 15.01.2024.
+var isoDate = regexp.MustCompile(`^(?:\d{4}[-.]\d{1,2}[-.]\d{1,2}|\d{1,2}[-.]\d{1,2}[-.]\d{4})\b`)
+
+// isPhone tells a phone number from other digit runs: it must stand alone (no letter or
+// digit touching it), have 9-15 digits, and not be a list of years such as "2019 2020 2021"
+// or a date and time such as "2024-01-15 10:30".
+func isPhone(s string, start, end int) bool {
+	if r, _ := utf8.DecodeLastRuneInString(s[:start]); start > 0 && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == ',' || r == ':') {
+		return false
+	}
+	if r, _ := utf8.DecodeRuneInString(s[end:]); end < len(s) && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == ':') {
+		return false
+	}
+	match := s[start:end]
+	if isoDate.MatchString(match) {
+		return false
+	}
+	digits := 0
+	for _, r := range match {
+		if unicode.IsDigit(r) {
+			digits++
+		}
+	}
+	if digits < 9 || digits > 15 {
+		return false
+	}
+	years := true
+	for _, g := range strings.FieldsFunc(match, func(r rune) bool { return !unicode.IsDigit(r) }) {
+		if len(g) != 4 || (!strings.HasPrefix(g, "19") && !strings.HasPrefix(g, "20")) {
+			years = false
+		}
+	}
+	return !years
+}
+
+// mask previews a match: an email keeps its first letter and domain ("a…@example.com"), a
+// phone number its last two digits ("…42"), and a key or secret its first four characters.
+func mask(kind, s string) string {
+	switch kind {
+	case Email:
+		at := strings.LastIndex(s, "@")
+		first, _ := utf8.DecodeRuneInString(s)
+		return string(first) + "…" + s[at:]
+	case Phone:
+		return "…" + s[len(s)-2:]
+	}
+	if strings.HasPrefix(s, "-----BEGIN") {
+		return "-----BEGIN…"
+	}
+	n := 0
+	for i := range s {
+		if n == 4 {
+			return s[:i] + "…"
+		}
+		n++
+	}
+	return "…"
+}
+
+// Summary counts the findings by kind, e.g. "2 emails, 1 phone number", or "" without any.
+func (r *Report) Summary() string {
+	counts := map[string]int{}
+	for _, f := range r.Findings {
+		counts[f.Kind]++
+	}
+	names := map[string]string{Email: "email", Phone: "phone number", APIKey: "API key", Secret: "secret"}
+	var kinds []string
+	for k := range counts {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+	var parts []string
+	for _, k := range kinds {
+		name := names[k]
+		if counts[k] != 1 {
+			name += "s"
+		}
+		parts = append(parts, fmt.Sprintf("%d %s", counts[k], name))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,159 @@
+// Package schedule parses cron-style schedules for recurring deck builds.
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Spec is a parsed five-field cron schedule: minute, hour, day of month, month, and day of
+// week. Each field is a bit set of the values it allows.
+type Spec struct {
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar record a day field starting with "*" ("*" or "*/2"): as in cron,
+	// when both day fields are restricted a time matches if either does.
+	domStar, dowStar bool
+}
+
+type field struct {
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField = field{min: 0, max: 59}
+	hourField   = field{min: 0, max: 23}
+	domField    = field{min: 1, max: 31}
+	monthField  = field{min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	dowField = field{min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// shorthands are the predefined schedules.
+var shorthands = map[string]string{
+	"@yearly":  "0 0 1 1 *",
+	"@monthly": "0 0 1 * *",
+	"@weekly":  "0 0 * * 0",
+	"@daily":   "0 0 * * *",
+	"@hourly":  "0 * * * *",
+}
+
+// Parse reads a schedule such as "0 9 * * MON" (9:00 every Monday), "*/15 8-18 * * 1-5",
+// or a shorthand (@hourly, @daily, @weekly, @monthly, @yearly). Fields accept *, numbers,
+// lists (1,15), ranges (1-5), steps (*/2, 10-30/5), and three-letter month and weekday
+// names; 7 is Sunday like 0.
+func Parse(s string) (Spec, error) {
+	s = strings.TrimSpace(strings.ToLower(s))
+	if full, ok := shorthands[s]; ok {
+		s = full
+	}
+	parts := strings.Fields(s)
+	if len(parts) != 5 {
+		return Spec{}, fmt.Errorf("cron %q: want 5 fields (minute hour day month weekday), got %d", s, len(parts))
+	}
+	var spec Spec
+	var err error
+	for i, f := range []struct {
+		dst *uint64
+		def field
+	}{{&spec.minute, minuteField}, {&spec.hour, hourField}, {&spec.dom, domField}, {&spec.month, monthField}, {&spec.dow, dowField}} {
+		if *f.dst, err = parseField(parts[i], f.def); err != nil {
+			return Spec{}, fmt.Errorf("cron %q: %w", s, err)
+		}
+	}
+	if spec.dow&(1<<7) != 0 {
+		spec.dow |= 1 // 7 is Sunday
+	}
+	spec.domStar, spec.dowStar = strings.HasPrefix(parts[2], "*"), strings.HasPrefix(parts[4], "*")
+	return spec, nil
+}
+
+func parseField(s string, f field) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(s, ",") {
+		rng, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("bad step in %q", part)
+			}
+			rng, step = part[:i], n
+		}
+		lo, hi := f.min, f.max
+		if rng != "*" {
+			var err error
+			a, b, isRange := strings.Cut(rng, "-")
+			if lo, err = f.value(a); err != nil {
+				return 0, err
+			}
+			hi = lo
+			if isRange {
+				if hi, err = f.value(b); err != nil {
+					return 0, err
+				}
+			} else if step > 1 {
+				hi = f.max // "5/10" means from 5 on
+			}
+			if hi < lo {
+				return 0, fmt.Errorf("range %q runs backwards", rng)
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func (f field) value(s string) (int, error) {
+	if v, ok := f.names[s]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("%q is not a value from %d to %d", s, f.min, f.max)
+	}
+	return v, nil
+}
+
+// Next returns the first time after t, to the minute and in t's location, that the
+// schedule matches; the zero time if none does within five years (e.g. February 30).
+func (s Spec) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s Spec) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
@@ -0,0 +1,48 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse_Errors(t *testing.T) {
+	for _, s := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8", "*/0 * * * *", "5-1 * * * *", "* * * * funday"} {
+		if _, err := Parse(s); err == nil {
+			t.Errorf("Parse(%q): want an error", s)
+		}
+	}
+}
+
+func TestSpec_Next(t *testing.T) {
+	// Thursday 2026-10-15 10:30
+	from := time.Date(2026, 10, 15, 10, 30, 0, 0, time.UTC)
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 10, 15, 10, 31, 0, 0, time.UTC)},
+		{"0 9 * * MON", time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)},
+		{"30 10 * * 4", time.Date(2026, 10, 22, 10, 30, 0, 0, time.UTC)}, // strictly after
+		{"*/15 8-18 * * 1-5", time.Date(2026, 10, 15, 10, 45, 0, 0, time.UTC)},
+		{"0 9 * * sun", time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)},
+		{"0 9 * * 7", time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 6 1,15 * *", time.Date(2026, 11, 1, 6, 0, 0, 0, time.UTC)},
+		{"0 0 1 jan *", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		// both day fields restricted: either matches (the 20th or the next Friday)
+		{"0 12 20 * fri", time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)},
+		// "*/2" is a star field, so both must match: an odd day that is a Friday
+		{"0 12 */2 * fri", time.Date(2026, 10, 23, 12, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		s, err := Parse(tt.spec)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.spec, err)
+		}
+		if got := s.Next(from); !got.Equal(tt.want) {
+			t.Errorf("Next(%q) = %v, want %v", tt.spec, got, tt.want)
+		}
+	}
+}
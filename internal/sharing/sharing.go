@@ -0,0 +1,167 @@
+// Package sharing checks that the people who can open a deck can also open the spreadsheet
+// its linked charts read: Slides renders a linked chart with the viewer's own access, so a
+// viewer without it sees "chart couldn't be loaded" in its place.
+package sharing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/api/drive/v3"
+)
+
+// Modes for the --sheet-access step; see ParseMode.
+const (
+	Off   = ""      // no check
+	Check = "check" // report deck viewers without spreadsheet access
+	Grant = "grant" // give them reader access to the spreadsheet
+	Image = "image" // embed charts as unlinked images, which need no spreadsheet access
+)
+
+// ParseMode validates a --sheet-access flag value; empty and "off" mean Off.
+func ParseMode(s string) (string, error) {
+	switch m := strings.ToLower(strings.TrimSpace(s)); m {
+	case "", "off":
+		return Off, nil
+	case Check, Grant, Image:
+		return m, nil
+	}
+	return "", fmt.Errorf("unknown sheet access mode %q (want off|check|grant|image)", s)
+}
+
+// PermissionsAPI is the part of the Drive API the check calls. NewPermissionsAPI adapts a
+// *drive.Service; tests pass an in-memory fake.
+type PermissionsAPI interface {
+	// List returns every permission on the file.
+	List(ctx context.Context, fileID string) ([]*drive.Permission, error)
+	// Create adds p to the file without emailing its grantee.
+	Create(ctx context.Context, fileID string, p *drive.Permission) error
+}
+
+// NewPermissionsAPI wraps svc; a nil svc gives a nil PermissionsAPI.
+func NewPermissionsAPI(svc *drive.Service) PermissionsAPI {
+	if svc == nil {
+		return nil
+	}
+	return driveService{svc}
+}
+
+type driveService struct {
+	svc *drive.Service
+}
+
+func (d driveService) List(ctx context.Context, fileID string) ([]*drive.Permission, error) {
+	var all []*drive.Permission
+	err := d.svc.Permissions.List(fileID).
+		Fields("nextPageToken", "permissions(type,role,emailAddress,domain)").
+		SupportsAllDrives(true).
+		Pages(ctx, func(page *drive.PermissionList) error {
+			all = append(all, page.Permissions...)
+			return nil
+		})
+	return all, err
+}
+
+func (d driveService) Create(ctx context.Context, fileID string, p *drive.Permission) error {
+	call := d.svc.Permissions.Create(fileID, p).SupportsAllDrives(true).Context(ctx)
+	if p.Type == "user" || p.Type == "group" {
+		call = call.SendNotificationEmail(false)
+	}
+	_, err := call.Do()
+	return err
+}
+
+// Missing returns the principals that can open the deck but not the spreadsheet, as reader
+// permissions ready to add to it. Any role opens a file. A spreadsheet shared with anyone
+// covers everybody, and one shared with a domain covers its users and groups.
+func Missing(deck, sheet []*drive.Permission) []*drive.Permission {
+	var missing []*drive.Permission
+	seen := map[string]bool{}
+	for _, p := range deck {
+		key := principal(p)
+		if key == "" || seen[key] || covered(p, sheet) {
+			continue
+		}
+		seen[key] = true
+		missing = append(missing, &drive.Permission{Type: p.Type, Role: "reader", EmailAddress: p.EmailAddress, Domain: p.Domain})
+	}
+	return missing
+}
+
+// principal identifies who p grants access to, e.g. "user:ana@example.com", or "" for a
+// permission without a grantee.
+func principal(p *drive.Permission) string {
+	switch p.Type {
+	case "user", "group":
+		if p.EmailAddress != "" {
+			return p.Type + ":" + strings.ToLower(p.EmailAddress)
+		}
+	case "domain":
+		if p.Domain != "" {
+			return "domain:" + strings.ToLower(p.Domain)
+		}
+	case "anyone":
+		return "anyone"
+	}
+	return ""
+}
+
+// covered reports whether one of sheet's permissions lets p's grantee open the spreadsheet.
+func covered(p *drive.Permission, sheet []*drive.Permission) bool {
+	key := principal(p)
+	for _, s := range sheet {
+		switch {
+		case s.Type == "anyone", principal(s) == key:
+			return true
+		case s.Type == "domain" && p.Type != "anyone" && strings.EqualFold(s.Domain, domainOf(p)):
+			return true
+		}
+	}
+	return false
+}
+
+// domainOf is the domain a user, group, or domain permission belongs to.
+func domainOf(p *drive.Permission) string {
+	if p.Type == "domain" {
+		return p.Domain
+	}
+	if _, domain, ok := strings.Cut(p.EmailAddress, "@"); ok {
+		return domain
+	}
+	return ""
+}
+
+// Describe names a permission's grantee for a log line, e.g. "user ana@example.com".
+func Describe(p *drive.Permission) string {
+	switch p.Type {
+	case "domain":
+		return "domain " + p.Domain
+	case "anyone":
+		return "anyone with the link"
+	}
+	return p.Type + " " + p.EmailAddress
+}
+
+// CheckAccess lists the deck's viewers who can't open the spreadsheet (see Missing).
+func CheckAccess(ctx context.Context, api PermissionsAPI, presentationID, spreadsheetID string) ([]*drive.Permission, error) {
+	deck, err := api.List(ctx, presentationID)
+	if err != nil {
+		return nil, fmt.Errorf("list presentation permissions: %w", err)
+	}
+	sheet, err := api.List(ctx, spreadsheetID)
+	if err != nil {
+		return nil, fmt.Errorf("list spreadsheet permissions: %w", err)
+	}
+	return Missing(deck, sheet), nil
+}
+
+// GrantAccess adds each of missing to the spreadsheet. It stops at the first failure.
+func GrantAccess(ctx context.Context, api PermissionsAPI, spreadsheetID string, missing []*drive.Permission) error {
+	for _, p := range missing {
+		if err := api.Create(ctx, spreadsheetID, p); err != nil {
+			return fmt.Errorf("grant %s: %w", Describe(p), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package sharing
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"google.golang.org/api/drive/v3"
+)
+
+func user(email, role string) *drive.Permission {
+	return &drive.Permission{Type: "user", Role: role, EmailAddress: email}
+}
+
+func TestMissing(t *testing.T) {
+	tests := []struct {
+		name        string
+		deck, sheet []*drive.Permission
+		want        []string
+	}{
+		{
+			name:  "same users",
+			deck:  []*drive.Permission{user("ana@example.com", "owner"), user("bo@example.com", "reader")},
+			sheet: []*drive.Permission{user("Ana@example.com", "writer"), user("bo@example.com", "commenter")},
+		},
+		{
+			name:  "viewer without access",
+			deck:  []*drive.Permission{user("ana@example.com", "owner"), user("bo@other.org", "reader")},
+			sheet: []*drive.Permission{user("ana@example.com", "owner")},
+			want:  []string{"user bo@other.org"},
+		},
+		{
+			name:  "domain covers its users",
+			deck:  []*drive.Permission{user("bo@example.com", "reader"), {Type: "group", EmailAddress: "team@example.com"}, user("cy@other.org", "reader")},
+			sheet: []*drive.Permission{{Type: "domain", Role: "reader", Domain: "example.com"}},
+			want:  []string{"user cy@other.org"},
+		},
+		{
+			name:  "anyone covers everybody",
+			deck:  []*drive.Permission{user("bo@other.org", "reader"), {Type: "anyone", Role: "reader"}},
+			sheet: []*drive.Permission{{Type: "anyone", Role: "reader"}},
+		},
+		{
+			name:  "public deck",
+			deck:  []*drive.Permission{{Type: "anyone", Role: "reader"}, {Type: "domain", Domain: "example.com"}},
+			sheet: []*drive.Permission{{Type: "domain", Role: "reader", Domain: "example.com"}},
+			want:  []string{"anyone with the link"},
+		},
+		{
+			name: "duplicates reported once",
+			deck: []*drive.Permission{user("bo@other.org", "reader"), user("BO@other.org", "commenter")},
+			want: []string{"user bo@other.org"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, p := range Missing(tt.deck, tt.sheet) {
+				if p.Role != "reader" {
+					t.Errorf("%s: role %q, want reader", Describe(p), p.Role)
+				}
+				got = append(got, Describe(p))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Missing = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type fakePermissions struct {
+	files   map[string][]*drive.Permission
+	failFor string
+}
+
+func (f *fakePermissions) List(_ context.Context, fileID string) ([]*drive.Permission, error) {
+	return f.files[fileID], nil
+}
+
+func (f *fakePermissions) Create(_ context.Context, fileID string, p *drive.Permission) error {
+	if p.EmailAddress == f.failFor {
+		return errors.New("forbidden")
+	}
+	f.files[fileID] = append(f.files[fileID], p)
+	return nil
+}
+
+func TestCheckAndGrantAccess(t *testing.T) {
+	ctx := context.Background()
+	api := &fakePermissions{files: map[string][]*drive.Permission{
+		"deck":  {user("ana@example.com", "owner"), user("bo@other.org", "reader")},
+		"sheet": {user("ana@example.com", "owner")},
+	}}
+	missing, err := CheckAccess(ctx, api, "deck", "sheet")
+	if err != nil || len(missing) != 1 {
+		t.Fatalf("CheckAccess = %v, %v; want one missing viewer", missing, err)
+	}
+	if err := GrantAccess(ctx, api, "sheet", missing); err != nil {
+		t.Fatalf("GrantAccess: %v", err)
+	}
+	if missing, _ := CheckAccess(ctx, api, "deck", "sheet"); len(missing) != 0 {
+		t.Errorf("after grant, missing = %v", missing)
+	}
+
+	api.failFor = "cy@other.org"
+	if err := GrantAccess(ctx, api, "sheet", []*drive.Permission{user("cy@other.org", "reader")}); err == nil {
+		t.Error("GrantAccess: want error for a refused grant")
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	for in, want := range map[string]string{"": Off, "off": Off, "Check": Check, "grant": Grant, " image ": Image} {
+		if got, err := ParseMode(in); err != nil || got != want {
+			t.Errorf("ParseMode(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseMode("share"); err == nil {
+		t.Error("ParseMode(share): want error")
+	}
+}
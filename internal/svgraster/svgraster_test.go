@@ -0,0 +1,84 @@
+package svgraster
+
+import (
+	"bytes"
+	"compress/gzip"
+	"image/png"
+	"testing"
+)
+
+const sampleSVG = `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 200 100"><rect x="10" y="10" width="180" height="80" fill="#1a73e8"/></svg>`
+
+func TestIsSVG(t *testing.T) {
+	tests := []struct {
+		name string
+		ct   string
+		data []byte
+		want bool
+	}{
+		{name: "content type", ct: "image/svg+xml; charset=utf-8", want: true},
+		{name: "sniffed body", ct: "application/octet-stream", data: []byte(`<?xml version="1.0"?>` + sampleSVG), want: true},
+		{name: "png", ct: "image/png", data: []byte{0x89, 'P', 'N', 'G'}, want: false},
+		{name: "gzipped body", ct: "application/octet-stream", data: gzipped(t, sampleSVG), want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsSVG(tt.ct, tt.data); got != tt.want {
+				t.Errorf("IsSVG() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsSVGURL(t *testing.T) {
+	if !IsSVGURL("https://example.com/logo.SVG?v=2") {
+		t.Error("expected .SVG with query to be detected")
+	}
+	if !IsSVGURL("https://example.com/logo.svgz") {
+		t.Error("expected .svgz to be detected")
+	}
+	if IsSVGURL("https://example.com/svg/photo.png") {
+		t.Error("png path should not be detected as svg")
+	}
+}
+
+func TestToPNG_PreservesAspectRatio(t *testing.T) {
+	out, err := ToPNG([]byte(sampleSVG), 400)
+	if err != nil {
+		t.Fatalf("ToPNG() error: %v", err)
+	}
+	cfg, err := png.DecodeConfig(bytes.NewReader(out))
+	if err != nil {
+		t.Fatalf("decode png: %v", err)
+	}
+	if cfg.Width != 400 || cfg.Height != 200 {
+		t.Errorf("dimensions = %dx%d, want 400x200", cfg.Width, cfg.Height)
+	}
+}
+
+func TestToPNG_Gzipped(t *testing.T) {
+	out, err := ToPNG(gzipped(t, sampleSVG), 400)
+	if err != nil {
+		t.Fatalf("ToPNG() error: %v", err)
+	}
+	cfg, err := png.DecodeConfig(bytes.NewReader(out))
+	if err != nil {
+		t.Fatalf("decode png: %v", err)
+	}
+	if cfg.Width != 400 || cfg.Height != 200 {
+		t.Errorf("dimensions = %dx%d, want 400x200", cfg.Width, cfg.Height)
+	}
+}
+
+func gzipped(t *testing.T, s string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
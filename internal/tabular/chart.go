@@ -0,0 +1,328 @@
+package tabular
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"gogemini-practices/internal/charts"
+)
+
+// MaxPoints is the most labels a chart keeps, and MaxSeries the most series a Split makes.
+const (
+	MaxPoints = 20
+	MaxSeries = 5
+)
+
+// ChartSpec says how to aggregate the table into one chart, as chosen by the model.
+type ChartSpec struct {
+	Title  string   `json:"title"`
+	Unit   string   `json:"unit,omitempty"`
+	Label  string   `json:"label"`            // column whose values become the chart's labels
+	Values []string `json:"values,omitempty"` // numeric columns, one series each; ignored by count
+	Agg    string   `json:"agg,omitempty"`    // sum (default) | mean | count | min | max
+	Period string   `json:"period,omitempty"` // date labels: day | week | month | quarter | year; default from the span
+	Split  string   `json:"split,omitempty"`  // optional text column whose values become series of the one value column
+	Type   string   `json:"type,omitempty"`   // category | comparison | composition; date labels are always timeseries
+	Limit  int      `json:"limit,omitempty"`  // keep the top labels by the first series; default and cap MaxPoints
+}
+
+// group accumulates one label's values per series.
+type group struct {
+	label string
+	sort  time.Time // bucket start, for date labels
+	aggs  []*agg
+}
+
+type agg struct {
+	sum, min, max float64
+	n             int
+}
+
+func (a *agg) add(v float64) {
+	if a.n == 0 || v < a.min {
+		a.min = v
+	}
+	if a.n == 0 || v > a.max {
+		a.max = v
+	}
+	a.sum += v
+	a.n++
+}
+
+func (a *agg) value(kind string) float64 {
+	switch kind {
+	case "count":
+		return float64(a.n)
+	case "mean":
+		if a.n == 0 {
+			return 0
+		}
+		return a.sum / float64(a.n)
+	case "min":
+		return a.min
+	case "max":
+		return a.max
+	}
+	return a.sum
+}
+
+// Dataset aggregates the table per spec. Date labels are bucketed by period and kept in
+// time order, the latest MaxPoints buckets at most; other labels are ordered by the first
+// series, largest first, and cut to the limit. Rows with an empty label or a non-numeric
+// value are left out of that series.
+func (t *Table) Dataset(spec ChartSpec) (charts.DatasetSpec, error) {
+	labelCol, err := t.column(spec.Label)
+	if err != nil {
+		return charts.DatasetSpec{}, fmt.Errorf("label: %w", err)
+	}
+	kind := strings.ToLower(strings.TrimSpace(spec.Agg))
+	switch kind {
+	case "":
+		kind = "sum"
+	case "sum", "mean", "count", "min", "max":
+	default:
+		return charts.DatasetSpec{}, fmt.Errorf("unknown agg %q", spec.Agg)
+	}
+
+	// Value columns; count needs none and counts rows
+	var valueCols []int
+	var names []string
+	if kind != "count" {
+		if len(spec.Values) == 0 {
+			return charts.DatasetSpec{}, fmt.Errorf("%s needs at least one value column", kind)
+		}
+		for _, v := range spec.Values {
+			i, err := t.column(v)
+			if err != nil {
+				return charts.DatasetSpec{}, fmt.Errorf("value: %w", err)
+			}
+			if t.Kind(i) != KindNumber {
+				return charts.DatasetSpec{}, fmt.Errorf("value column %q is not numeric", t.Header[i])
+			}
+			valueCols = append(valueCols, i)
+			names = append(names, t.Header[i])
+		}
+	}
+
+	// A split turns one value column into a series per split value
+	splitCol := -1
+	if strings.TrimSpace(spec.Split) != "" {
+		if splitCol, err = t.column(spec.Split); err != nil {
+			return charts.DatasetSpec{}, fmt.Errorf("split: %w", err)
+		}
+		if len(valueCols) > 1 {
+			return charts.DatasetSpec{}, fmt.Errorf("split takes one value column, got %d", len(valueCols))
+		}
+		names = t.topSplits(splitCol, valueCols)
+	}
+	seriesCount := max(len(names), 1)
+
+	dates := t.Kind(labelCol) == KindDate
+	period := strings.ToLower(strings.TrimSpace(spec.Period))
+	if dates {
+		if period, err = t.period(labelCol, period); err != nil {
+			return charts.DatasetSpec{}, err
+		}
+	}
+
+	groups := map[string]*group{}
+	var order []*group
+	for _, row := range t.Rows {
+		label := row[labelCol]
+		if label == "" {
+			continue
+		}
+		var start time.Time
+		if dates {
+			d, _ := parseDate(label)
+			start, label = bucket(d, period)
+		}
+		series := 0
+		if splitCol >= 0 {
+			series = indexOf(names, row[splitCol])
+			if series < 0 {
+				continue
+			}
+		}
+		g := groups[label]
+		if g == nil {
+			g = &group{label: label, sort: start, aggs: make([]*agg, seriesCount)}
+			for i := range g.aggs {
+				g.aggs[i] = &agg{}
+			}
+			groups[label] = g
+			order = append(order, g)
+		}
+		if kind == "count" {
+			g.aggs[series].add(1)
+			continue
+		}
+		for i, col := range valueCols {
+			v, ok := parseNumber(row[col])
+			if !ok {
+				continue
+			}
+			if splitCol >= 0 {
+				g.aggs[series].add(v)
+			} else {
+				g.aggs[i].add(v)
+			}
+		}
+	}
+	if len(order) == 0 {
+		return charts.DatasetSpec{}, fmt.Errorf("no rows with a %q label", t.Header[labelCol])
+	}
+
+	value := func(g *group, i int) float64 { return g.aggs[i].value(kind) }
+	limit := spec.Limit
+	if limit <= 0 || limit > MaxPoints {
+		limit = MaxPoints
+	}
+	if dates {
+		sort.Slice(order, func(i, j int) bool { return order[i].sort.Before(order[j].sort) })
+		if len(order) > MaxPoints {
+			order = order[len(order)-MaxPoints:]
+		}
+	} else {
+		sort.SliceStable(order, func(i, j int) bool { return value(order[i], 0) > value(order[j], 0) })
+		if len(order) > limit {
+			order = order[:limit]
+		}
+	}
+
+	ds := charts.DatasetSpec{Title: strings.TrimSpace(spec.Title), Unit: strings.TrimSpace(spec.Unit), Type: strings.ToLower(strings.TrimSpace(spec.Type))}
+	if ds.Title == "" {
+		ds.Title = t.Header[labelCol]
+	}
+	switch {
+	case dates:
+		ds.Type = "timeseries"
+	case ds.Type != "category" && ds.Type != "comparison" && ds.Type != "composition":
+		ds.Type = "category"
+	}
+	if seriesCount > 1 {
+		ds.Series = names
+	}
+	for _, g := range order {
+		p := charts.Point{Label: g.label}
+		if seriesCount > 1 {
+			for i := range g.aggs {
+				p.Values = append(p.Values, round(value(g, i)))
+			}
+		} else {
+			p.Value = round(value(g, 0))
+		}
+		ds.Points = append(ds.Points, p)
+	}
+	return ds, nil
+}
+
+// topSplits returns the split column's MaxSeries most significant values: by the value
+// column's total, or by row count without one.
+func (t *Table) topSplits(splitCol int, valueCols []int) []string {
+	totals := map[string]float64{}
+	for _, row := range t.Rows {
+		key := row[splitCol]
+		if key == "" {
+			continue
+		}
+		w := 1.0
+		if len(valueCols) == 1 {
+			v, ok := parseNumber(row[valueCols[0]])
+			if !ok {
+				continue
+			}
+			w = math.Abs(v)
+		}
+		totals[key] += w
+	}
+	keys := make([]string, 0, len(totals))
+	for k := range totals {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if totals[keys[i]] != totals[keys[j]] {
+			return totals[keys[i]] > totals[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	if len(keys) > MaxSeries {
+		keys = keys[:MaxSeries]
+	}
+	return keys
+}
+
+// period validates a requested date period, or picks one from the column's span.
+func (t *Table) period(col int, requested string) (string, error) {
+	switch requested {
+	case "day", "week", "month", "quarter", "year":
+		return requested, nil
+	case "":
+	default:
+		return "", fmt.Errorf("unknown period %q", requested)
+	}
+	var first, last time.Time
+	seen := false
+	for _, row := range t.Rows {
+		d, ok := parseDate(row[col])
+		if !ok {
+			continue
+		}
+		if !seen || d.Before(first) {
+			first = d
+		}
+		if !seen || d.After(last) {
+			last = d
+		}
+		seen = true
+	}
+	switch days := last.Sub(first).Hours() / 24; {
+	case days < MaxPoints:
+		return "day", nil
+	case days < 7*MaxPoints:
+		return "week", nil
+	case days < 31*MaxPoints:
+		return "month", nil
+	case days < 92*MaxPoints:
+		return "quarter", nil
+	}
+	return "year", nil
+}
+
+// bucket returns the start and label of the period d falls in.
+func bucket(d time.Time, period string) (time.Time, string) {
+	d = time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, time.UTC)
+	switch period {
+	case "week":
+		start := d.AddDate(0, 0, -((int(d.Weekday()) + 6) % 7))
+		return start, start.Format(time.DateOnly)
+	case "month":
+		start := time.Date(d.Year(), d.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.Format("2006-01")
+	case "quarter":
+		q := (int(d.Month()) - 1) / 3
+		start := time.Date(d.Year(), time.Month(q*3+1), 1, 0, 0, 0, 0, time.UTC)
+		return start, fmt.Sprintf("Q%d %d", q+1, d.Year())
+	case "year":
+		start := time.Date(d.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+		return start, start.Format("2006")
+	}
+	return d, d.Format(time.DateOnly)
+}
+
+func indexOf(list []string, s string) int {
+	for i, v := range list {
+		if v == s {
+			return i
+		}
+	}
+	return -1
+}
+
+// round keeps charts and prompts readable: at most two decimals.
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,175 @@
+package tabular
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Column kinds, as Profile reports them.
+const (
+	KindNumber = "number"
+	KindDate   = "date"
+	KindText   = "text"
+)
+
+// dateLayouts are the date formats a date column may use.
+var dateLayouts = []string{
+	time.DateOnly, "2006-01-02 15:04", "2006-01-02 15:04:05", time.RFC3339, "2006/01/02",
+	"1/2/2006", "01/02/2006", "2006-01", "Jan 2006", "January 2006", "Jan 2, 2006", "2 Jan 2006",
+}
+
+// parseDate reads a date in any of dateLayouts.
+func parseDate(s string) (time.Time, bool) {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseNumber reads a number, allowing thousands separators, a leading currency sign,
+// and a trailing percent sign: "$1,234.50" -> 1234.5, "12%" -> 12.
+func parseNumber(s string) (float64, bool) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimSuffix(s, "%")
+	s = strings.TrimLeft(s, "$€£¥")
+	s = strings.ReplaceAll(s, ",", "")
+	if s == "" {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, false
+	}
+	return v, true
+}
+
+// Kind classifies column i: a number or date column when every non-empty value parses as
+// one, text otherwise. Four-digit whole numbers that could be years count as numbers.
+func (t *Table) Kind(i int) string {
+	numbers, dates, values := 0, 0, 0
+	for _, row := range t.Rows {
+		v := row[i]
+		if v == "" {
+			continue
+		}
+		values++
+		if _, ok := parseNumber(v); ok {
+			numbers++
+		} else if _, ok := parseDate(v); ok {
+			dates++
+		}
+	}
+	switch {
+	case values == 0:
+		return KindText
+	case numbers == values:
+		return KindNumber
+	case dates == values:
+		return KindDate
+	}
+	return KindText
+}
+
+// Profile describes the table for a prompt: its size, each column's kind with a summary
+// (range and mean for numbers, span for dates, most common values for text), and the
+// first sample rows.
+func (t *Table) Profile(sampleRows int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Table %q: %d rows, %d columns.\n", t.Name, len(t.Rows), len(t.Header))
+	for i, h := range t.Header {
+		kind := t.Kind(i)
+		var present []string
+		for _, row := range t.Rows {
+			if row[i] != "" {
+				present = append(present, row[i])
+			}
+		}
+		fmt.Fprintf(&b, "- %s (%s, %d values", h, kind, len(present))
+		switch kind {
+		case KindNumber:
+			minV, maxV, sum := math.Inf(1), math.Inf(-1), 0.0
+			for _, v := range present {
+				n, _ := parseNumber(v)
+				minV, maxV, sum = math.Min(minV, n), math.Max(maxV, n), sum+n
+			}
+			if len(present) > 0 {
+				fmt.Fprintf(&b, "): min %s, max %s, mean %s, sum %s\n", formatNumber(minV), formatNumber(maxV), formatNumber(sum/float64(len(present))), formatNumber(sum))
+			} else {
+				b.WriteString(")\n")
+			}
+		case KindDate:
+			var first, last time.Time
+			for j, v := range present {
+				d, _ := parseDate(v)
+				if j == 0 || d.Before(first) {
+					first = d
+				}
+				if j == 0 || d.After(last) {
+					last = d
+				}
+			}
+			fmt.Fprintf(&b, "): %s to %s\n", first.Format(time.DateOnly), last.Format(time.DateOnly))
+		default:
+			counts := map[string]int{}
+			for _, v := range present {
+				counts[v]++
+			}
+			top := topKeys(counts, 5)
+			parts := make([]string, len(top))
+			for j, k := range top {
+				parts[j] = fmt.Sprintf("%s (%d)", truncate(k, 40), counts[k])
+			}
+			fmt.Fprintf(&b, ", %d distinct): %s\n", len(counts), strings.Join(parts, ", "))
+		}
+	}
+	if n := min(sampleRows, len(t.Rows)); n > 0 {
+		b.WriteString("Sample rows:\n")
+		b.WriteString(strings.Join(t.Header, " | "))
+		b.WriteString("\n")
+		for _, row := range t.Rows[:n] {
+			cells := make([]string, len(row))
+			for j, v := range row {
+				cells[j] = truncate(v, 40)
+			}
+			b.WriteString(strings.Join(cells, " | "))
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// topKeys returns up to n keys with the highest counts, ties broken alphabetically.
+func topKeys(counts map[string]int, n int) []string {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	if len(keys) > n {
+		keys = keys[:n]
+	}
+	return keys
+}
+
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
@@ -0,0 +1,159 @@
+// Package tabular reads CSV, TSV, and Excel files, profiles their columns for a prompt, and
+// aggregates them into chart datasets, so a deck's charts come from the file itself.
+package tabular
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// Limits on what Read accepts; larger files should be aggregated before charting.
+const (
+	MaxRows    = 50000
+	MaxColumns = 100
+)
+
+// Table is a file's rows as text, after the header row. Rows are padded or cut to the
+// header's width.
+type Table struct {
+	Name   string // file name without directory or extension
+	Header []string
+	Rows   [][]string
+}
+
+// Read loads a .csv, .tsv, .txt, or .xlsx file. CSV files may use commas, semicolons, or
+// tabs; the first row is the header. Excel files use their first worksheet.
+func Read(path string) (*Table, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	var records [][]string
+	var err error
+	switch ext {
+	case ".csv", ".tsv", ".txt":
+		var b []byte
+		if b, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("read data: %w", err)
+		}
+		records, err = readDelimited(b)
+	case ".xlsx":
+		records, err = readXLSX(path)
+	case ".xls":
+		return nil, fmt.Errorf("%s: legacy .xls files aren't supported; save it as .xlsx or .csv", path)
+	default:
+		return nil, fmt.Errorf("%s: want a .csv, .tsv, or .xlsx file", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	t, err := newTable(records)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	t.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return t, nil
+}
+
+// readDelimited parses CSV text, picking the delimiter that splits the first line most.
+func readDelimited(b []byte) ([][]string, error) {
+	b = bytes.TrimPrefix(b, []byte("\xef\xbb\xbf")) // Excel's UTF-8 byte order mark
+	if !utf8.Valid(b) {
+		return nil, fmt.Errorf("not UTF-8 text")
+	}
+	first, _, _ := bytes.Cut(b, []byte("\n"))
+	delim, most := ',', 0
+	for _, d := range []rune{',', ';', '\t'} {
+		if n := bytes.Count(first, []byte(string(d))); n > most {
+			delim, most = d, n
+		}
+	}
+	r := csv.NewReader(bytes.NewReader(b))
+	r.Comma = delim
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	var records [][]string
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, rec)
+		if len(records) > MaxRows+1 {
+			return nil, fmt.Errorf("more than %d rows", MaxRows)
+		}
+	}
+	return records, nil
+}
+
+// newTable takes the first non-empty record as the header and drops blank rows.
+func newTable(records [][]string) (*Table, error) {
+	for len(records) > 0 && blank(records[0]) {
+		records = records[1:]
+	}
+	if len(records) < 2 {
+		return nil, fmt.Errorf("want a header row and at least one data row")
+	}
+	header := records[0]
+	for len(header) > 0 && strings.TrimSpace(header[len(header)-1]) == "" {
+		header = header[:len(header)-1]
+	}
+	if len(header) > MaxColumns {
+		return nil, fmt.Errorf("%d columns; the most is %d", len(header), MaxColumns)
+	}
+	t := &Table{}
+	seen := map[string]bool{}
+	for i, h := range header {
+		h = strings.Join(strings.Fields(h), " ")
+		if h == "" {
+			h = fmt.Sprintf("Column %d", i+1)
+		}
+		if seen[strings.ToLower(h)] {
+			return nil, fmt.Errorf("duplicate column %q", h)
+		}
+		seen[strings.ToLower(h)] = true
+		t.Header = append(t.Header, h)
+	}
+	for _, rec := range records[1:] {
+		if blank(rec) {
+			continue
+		}
+		row := make([]string, len(t.Header))
+		for i := range row {
+			if i < len(rec) {
+				row[i] = strings.TrimSpace(rec[i])
+			}
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	if len(t.Rows) > MaxRows {
+		return nil, fmt.Errorf("more than %d rows", MaxRows)
+	}
+	return t, nil
+}
+
+func blank(rec []string) bool {
+	for _, f := range rec {
+		if strings.TrimSpace(f) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// column finds a column by name, ignoring case and surrounding space.
+func (t *Table) column(name string) (int, error) {
+	name = strings.TrimSpace(name)
+	for i, h := range t.Header {
+		if strings.EqualFold(h, name) {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("no column %q", name)
+}
@@ -0,0 +1,219 @@
+package tabular
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gogemini-practices/internal/charts"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRead_Delimited(t *testing.T) {
+	tests := []struct {
+		name, file, content string
+		wantHeader          []string
+		wantRows            [][]string
+		wantErr             bool
+	}{
+		{
+			name: "comma with BOM and blank rows", file: "sales.csv",
+			content:    "\xef\xbb\xbfMonth,Revenue\n\n2026-01,\"1,200\"\n2026-02,900\n",
+			wantHeader: []string{"Month", "Revenue"},
+			wantRows:   [][]string{{"2026-01", "1,200"}, {"2026-02", "900"}},
+		},
+		{
+			name: "semicolons and short rows", file: "regions.csv",
+			content:    "Region;Orders;Returns\nEMEA;10\nUS;8;1\n",
+			wantHeader: []string{"Region", "Orders", "Returns"},
+			wantRows:   [][]string{{"EMEA", "10", ""}, {"US", "8", "1"}},
+		},
+		{
+			name: "tabs and an unnamed column", file: "x.tsv",
+			content:    "a\t\tc\n1\t2\t3\n",
+			wantHeader: []string{"a", "Column 2", "c"},
+			wantRows:   [][]string{{"1", "2", "3"}},
+		},
+		{name: "header only", file: "h.csv", content: "a,b\n", wantErr: true},
+		{name: "duplicate column", file: "d.csv", content: "a,A\n1,2\n", wantErr: true},
+		{name: "unsupported type", file: "d.json", content: "[]", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table, err := Read(writeFile(t, tt.file, tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Read() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(table.Header, tt.wantHeader) || !reflect.DeepEqual(table.Rows, tt.wantRows) {
+				t.Errorf("Read() = %q %q, want %q %q", table.Header, table.Rows, tt.wantHeader, tt.wantRows)
+			}
+		})
+	}
+}
+
+func TestRead_XLSX(t *testing.T) {
+	parts := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="Data" sheetId="1" r:id="rId7"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships><Relationship Id="rId7" Target="worksheets/data.xml"/></Relationships>`,
+		"xl/sharedStrings.xml":       `<sst><si><t>Day</t></si><si><t>Visits</t></si><si><r><t>Note</t></r><r><t>s</t></r></si></sst>`,
+		"xl/styles.xml":              `<styleSheet><numFmts><numFmt numFmtId="164" formatCode="dd&quot;/&quot;mm&quot;/&quot;yyyy"/></numFmts><cellXfs><xf numFmtId="0"/><xf numFmtId="14"/><xf numFmtId="164"/></cellXfs></styleSheet>`,
+		"xl/worksheets/data.xml": `<worksheet><sheetData>` +
+			`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="D1" t="s"><v>2</v></c></row>` +
+			`<row r="2"><c r="A2" s="1"><v>46296</v></c><c r="B2"><v>12.5</v></c><c t="b"><v>1</v></c><c r="D2" t="inlineStr"><is><t>ok</t></is></c></row>` +
+			`<row r="3"><c r="A3" s="2"><v>46297.5</v></c><c r="B3"><v>7</v></c></row>` +
+			`</sheetData></worksheet>`,
+	}
+	path := filepath.Join(t.TempDir(), "visits.xlsx")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for name, body := range parts {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(body))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	table, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantHeader := []string{"Day", "Visits", "Column 3", "Notes"}
+	wantRows := [][]string{{"2026-10-01", "12.5", "TRUE", "ok"}, {"2026-10-02 12:00", "7", "", ""}}
+	if table.Name != "visits" || !reflect.DeepEqual(table.Header, wantHeader) || !reflect.DeepEqual(table.Rows, wantRows) {
+		t.Errorf("Read() = %s %q %q, want visits %q %q", table.Name, table.Header, table.Rows, wantHeader, wantRows)
+	}
+}
+
+func TestIsDateFormat(t *testing.T) {
+	tests := map[string]bool{
+		"yyyy-mm-dd":        true,
+		"mmm yy":            true,
+		"0.00":              false,
+		"#,##0 \"days\"":    false,
+		"[h]:mm":            false,
+		"[$-409]d-mmm-yyyy": true,
+	}
+	for code, want := range tests {
+		if got := isDateFormat(code); got != want {
+			t.Errorf("isDateFormat(%q) = %v, want %v", code, got, want)
+		}
+	}
+}
+
+// orders is a small order log: a date, a region, a product, and two amounts.
+func orders() *Table {
+	return &Table{
+		Name:   "orders",
+		Header: []string{"Date", "Region", "Product", "Revenue", "Units"},
+		Rows: [][]string{
+			{"2026-01-05", "EMEA", "A", "$100", "1"},
+			{"2026-01-20", "US", "B", "250", "2"},
+			{"2026-02-03", "EMEA", "B", "50", "1"},
+			{"2026-02-14", "US", "A", "1,000", "4"},
+			{"2026-03-01", "APAC", "A", "", "3"},
+			{"2026-03-09", "", "A", "75", "1"},
+		},
+	}
+}
+
+func TestTable_Kind(t *testing.T) {
+	want := []string{KindDate, KindText, KindText, KindNumber, KindNumber}
+	tbl := orders()
+	for i := range tbl.Header {
+		if got := tbl.Kind(i); got != want[i] {
+			t.Errorf("Kind(%s) = %q, want %q", tbl.Header[i], got, want[i])
+		}
+	}
+}
+
+func TestTable_Profile(t *testing.T) {
+	got := orders().Profile(2)
+	for _, want := range []string{
+		`Table "orders": 6 rows, 5 columns.`,
+		"- Date (date, 6 values): 2026-01-05 to 2026-03-09",
+		"- Region (text, 5 values, 3 distinct): EMEA (2), US (2), APAC (1)",
+		"- Revenue (number, 5 values): min 50, max 1000, mean 295, sum 1475",
+		"Date | Region | Product | Revenue | Units\n2026-01-05 | EMEA | A | $100 | 1\n2026-01-20 |",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Profile() missing %q in:\n%s", want, got)
+		}
+	}
+}
+
+func TestTable_Dataset(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    ChartSpec
+		want    charts.DatasetSpec
+		wantErr bool
+	}{
+		{
+			name: "monthly sum",
+			spec: ChartSpec{Title: "Revenue", Unit: "USD", Label: "date", Values: []string{"Revenue"}, Period: "month"},
+			want: charts.DatasetSpec{Title: "Revenue", Unit: "USD", Type: "timeseries", Points: []charts.Point{
+				{Label: "2026-01", Value: 350}, {Label: "2026-02", Value: 1050}, {Label: "2026-03", Value: 75},
+			}},
+		},
+		{
+			name: "count by region, largest first",
+			spec: ChartSpec{Label: "Region", Agg: "count", Limit: 2},
+			want: charts.DatasetSpec{Title: "Region", Type: "category", Points: []charts.Point{
+				{Label: "EMEA", Value: 2}, {Label: "US", Value: 2},
+			}},
+		},
+		{
+			name: "mean of two columns",
+			spec: ChartSpec{Title: "Per product", Label: "Product", Values: []string{"Revenue", "Units"}, Agg: "mean", Type: "comparison"},
+			want: charts.DatasetSpec{Title: "Per product", Type: "comparison", Series: []string{"Revenue", "Units"}, Points: []charts.Point{
+				{Label: "A", Values: []float64{391.67, 2.25}}, {Label: "B", Values: []float64{150, 1.5}},
+			}},
+		},
+		{
+			name: "split by region per quarter",
+			spec: ChartSpec{Title: "Units", Label: "Date", Values: []string{"Units"}, Split: "Region", Period: "quarter"},
+			want: charts.DatasetSpec{Title: "Units", Type: "timeseries", Series: []string{"US", "APAC", "EMEA"}, Points: []charts.Point{
+				{Label: "Q1 2026", Values: []float64{6, 3, 2}},
+			}},
+		},
+		{name: "unknown column", spec: ChartSpec{Label: "Country", Agg: "count"}, wantErr: true},
+		{name: "text values", spec: ChartSpec{Label: "Date", Values: []string{"Region"}}, wantErr: true},
+		{name: "sum without values", spec: ChartSpec{Label: "Region"}, wantErr: true},
+		{name: "unknown agg", spec: ChartSpec{Label: "Region", Values: []string{"Units"}, Agg: "median"}, wantErr: true},
+		{name: "split of two columns", spec: ChartSpec{Label: "Date", Values: []string{"Units", "Revenue"}, Split: "Region"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := orders().Dataset(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Dataset() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Dataset() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
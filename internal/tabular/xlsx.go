@@ -0,0 +1,257 @@
+package tabular
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxXMLPart caps the size of one decompressed workbook part.
+const maxXMLPart = 64 << 20
+
+// readXLSX reads the first worksheet of an Office Open XML workbook. Shared and inline
+// strings, numbers, and booleans are read as displayed text; numbers in a date format
+// become YYYY-MM-DD (or YYYY-MM-DD HH:MM with a time). Formulas give their cached value.
+func readXLSX(name string) ([][]string, error) {
+	zr, err := zip.OpenReader(name)
+	if err != nil {
+		return nil, fmt.Errorf("open workbook: %w", err)
+	}
+	defer zr.Close()
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+	var shared []string
+	if f := files["xl/sharedStrings.xml"]; f != nil {
+		if shared, err = readSharedStrings(f); err != nil {
+			return nil, err
+		}
+	}
+	var dateStyles []bool
+	if f := files["xl/styles.xml"]; f != nil {
+		if dateStyles, err = readDateStyles(f); err != nil {
+			return nil, err
+		}
+	}
+	f := files[sheetPath]
+	if f == nil {
+		return nil, fmt.Errorf("workbook has no %s", sheetPath)
+	}
+	var ws struct {
+		Rows []struct {
+			Cells []struct {
+				Ref    string   `xml:"r,attr"`
+				Type   string   `xml:"t,attr"`
+				Style  int      `xml:"s,attr"`
+				Value  string   `xml:"v"`
+				Inline string   `xml:"is>t"`
+				Runs   []string `xml:"is>r>t"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := decodePart(f, &ws); err != nil {
+		return nil, err
+	}
+	var records [][]string
+	for _, row := range ws.Rows {
+		var rec []string
+		for _, c := range row.Cells {
+			col := len(rec) // cells without a reference follow the previous one
+			if c.Ref != "" {
+				if col, err = refColumn(c.Ref); err != nil {
+					return nil, err
+				}
+			}
+			if col >= MaxColumns*2 {
+				continue
+			}
+			for len(rec) <= col {
+				rec = append(rec, "")
+			}
+			switch c.Type {
+			case "s":
+				idx, err := strconv.Atoi(c.Value)
+				if err != nil || idx < 0 || idx >= len(shared) {
+					return nil, fmt.Errorf("cell %s: bad shared string %q", c.Ref, c.Value)
+				}
+				rec[col] = shared[idx]
+			case "inlineStr":
+				rec[col] = c.Inline + strings.Join(c.Runs, "")
+			case "b":
+				rec[col] = map[string]string{"0": "FALSE", "1": "TRUE"}[c.Value]
+			case "str", "e":
+				rec[col] = c.Value
+			default:
+				rec[col] = c.Value
+				if c.Style >= 0 && c.Style < len(dateStyles) && dateStyles[c.Style] {
+					rec[col] = excelDate(c.Value)
+				}
+			}
+		}
+		records = append(records, rec)
+		if len(records) > MaxRows+1 {
+			return nil, fmt.Errorf("more than %d rows", MaxRows)
+		}
+	}
+	return records, nil
+}
+
+// firstSheetPath resolves the workbook's first sheet through its relationships.
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	wbFile := files["xl/workbook.xml"]
+	relsFile := files["xl/_rels/workbook.xml.rels"]
+	if wbFile == nil || relsFile == nil {
+		return "", fmt.Errorf("not an Excel workbook")
+	}
+	var wb struct {
+		Sheets []struct {
+			RelID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := decodePart(wbFile, &wb); err != nil {
+		return "", err
+	}
+	if len(wb.Sheets) == 0 {
+		return "", fmt.Errorf("workbook has no sheets")
+	}
+	var rels struct {
+		Rels []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := decodePart(relsFile, &rels); err != nil {
+		return "", err
+	}
+	for _, r := range rels.Rels {
+		if r.ID == wb.Sheets[0].RelID {
+			if strings.HasPrefix(r.Target, "/") {
+				return strings.TrimPrefix(r.Target, "/"), nil
+			}
+			return path.Join("xl", r.Target), nil
+		}
+	}
+	return "", fmt.Errorf("workbook's first sheet has no part")
+}
+
+func readSharedStrings(f *zip.File) ([]string, error) {
+	var sst struct {
+		Items []struct {
+			Text string   `xml:"t"`
+			Runs []string `xml:"r>t"`
+		} `xml:"si"`
+	}
+	if err := decodePart(f, &sst); err != nil {
+		return nil, err
+	}
+	out := make([]string, len(sst.Items))
+	for i, si := range sst.Items {
+		out[i] = si.Text + strings.Join(si.Runs, "")
+	}
+	return out, nil
+}
+
+// readDateStyles reports, per cell style index, whether its number format shows a date.
+func readDateStyles(f *zip.File) ([]bool, error) {
+	var styles struct {
+		NumFmts []struct {
+			ID   int    `xml:"numFmtId,attr"`
+			Code string `xml:"formatCode,attr"`
+		} `xml:"numFmts>numFmt"`
+		Xfs []struct {
+			NumFmtID int `xml:"numFmtId,attr"`
+		} `xml:"cellXfs>xf"`
+	}
+	if err := decodePart(f, &styles); err != nil {
+		return nil, err
+	}
+	custom := map[int]bool{}
+	for _, nf := range styles.NumFmts {
+		custom[nf.ID] = isDateFormat(nf.Code)
+	}
+	out := make([]bool, len(styles.Xfs))
+	for i, xf := range styles.Xfs {
+		id := xf.NumFmtID
+		// Built-in date and time formats
+		out[i] = id >= 14 && id <= 22 || id >= 45 && id <= 47 || custom[id]
+	}
+	return out, nil
+}
+
+// isDateFormat reports whether a custom number format code shows a date: it has day,
+// month, or year tokens outside quoted text and brackets.
+func isDateFormat(code string) bool {
+	var b strings.Builder
+	quoted, bracket := false, false
+	for _, r := range code {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case !quoted && r == '[':
+			bracket = true
+		case !quoted && r == ']':
+			bracket = false
+		case !quoted && !bracket:
+			b.WriteRune(r)
+		}
+	}
+	s := strings.ToLower(b.String())
+	return strings.ContainsAny(s, "dy") || strings.Contains(s, "mmm")
+}
+
+// excelDate converts a 1900-system serial day number to text; invalid serials are kept.
+func excelDate(v string) string {
+	serial, err := strconv.ParseFloat(v, 64)
+	if err != nil || serial < 1 || serial > 2958465 {
+		return v
+	}
+	t := time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC)
+	days := math.Floor(serial)
+	secs := math.Round((serial - days) * 86400)
+	t = t.AddDate(0, 0, int(days)).Add(time.Duration(secs) * time.Second)
+	if secs == 0 {
+		return t.Format(time.DateOnly)
+	}
+	return t.Format("2006-01-02 15:04")
+}
+
+// refColumn is the zero-based column of a cell reference such as "AB12".
+func refColumn(ref string) (int, error) {
+	col := 0
+	n := 0
+	for _, r := range strings.ToUpper(ref) {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = col*26 + int(r-'A'+1)
+		n++
+	}
+	if n == 0 || n > 3 {
+		return 0, fmt.Errorf("bad cell reference %q", ref)
+	}
+	return col - 1, nil
+}
+
+func decodePart(f *zip.File, v any) error {
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("open %s: %w", f.Name, err)
+	}
+	defer rc.Close()
+	if err := xml.NewDecoder(io.LimitReader(rc, maxXMLPart)).Decode(v); err != nil {
+		return fmt.Errorf("parse %s: %w", f.Name, err)
+	}
+	return nil
+}
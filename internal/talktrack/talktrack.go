@@ -0,0 +1,174 @@
+// Package talktrack renders a presenter's script for a deck: an opening, a spoken
+// paragraph per slide with the line that leads into the next, and a closing, as Markdown
+// for rehearsing in an editor or as HTML for importing into a Google Doc.
+package talktrack
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// Script is the talk track of one deck.
+type Script struct {
+	Title    string // usually the deck's subject
+	Audience string // optional
+	Estimate string // optional total speaking time, e.g. "≈ 6m 30s"
+	Opening  string
+	Sections []Section
+	Closing  string
+	// Questions are likely audience questions, listed after the closing with their
+	// suggested answers.
+	Questions []Question
+}
+
+// Section is what the presenter says over one slide.
+type Section struct {
+	Title      string
+	Text       string // paragraphs separated by blank lines
+	Transition string // optional: the sentence that leads into the next slide
+	Estimate   string // optional speaking time for Text
+}
+
+// Question is an anticipated audience question with its suggested answer.
+type Question struct {
+	Question string
+	Answer   string
+}
+
+// Markdown renders the script as a Markdown document: a heading per slide, numbered as in
+// the deck, with the transition set off in italics.
+func (s Script) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", oneLine(firstNonEmpty(s.Title, "Talk track")))
+	if meta := s.meta(); meta != "" {
+		fmt.Fprintf(&b, "\n_%s_\n", meta)
+	}
+	section := func(heading, text string) {
+		if text = paragraphs(text); text != "" {
+			fmt.Fprintf(&b, "\n## %s\n\n%s\n", heading, text)
+		}
+	}
+	section("Opening", s.Opening)
+	for i, sec := range s.Sections {
+		fmt.Fprintf(&b, "\n## %d. %s\n", i+1, oneLine(sec.Title))
+		if sec.Estimate != "" {
+			fmt.Fprintf(&b, "\n_%s_\n", sec.Estimate)
+		}
+		if text := paragraphs(sec.Text); text != "" {
+			fmt.Fprintf(&b, "\n%s\n", text)
+		}
+		if t := oneLine(sec.Transition); t != "" {
+			fmt.Fprintf(&b, "\n> _Transition:_ %s\n", t)
+		}
+	}
+	section("Closing", s.Closing)
+	if qs := s.questions(); len(qs) > 0 {
+		b.WriteString("\n## Anticipated questions\n")
+		for i, q := range qs {
+			fmt.Fprintf(&b, "\n%d. **%s**\n", i+1, q.Question)
+			if q.Answer != "" {
+				fmt.Fprintf(&b, "   %s\n", oneLine(q.Answer))
+			}
+		}
+	}
+	return b.String()
+}
+
+// HTML renders the script as a standalone HTML document with the same structure as
+// Markdown. All text is escaped.
+func (s Script) HTML() string {
+	var b strings.Builder
+	title := html.EscapeString(oneLine(firstNonEmpty(s.Title, "Talk track")))
+	fmt.Fprintf(&b, "<!DOCTYPE html>\n<html><head><meta charset=\"utf-8\"><title>%s</title></head><body>\n<h1>%s</h1>\n", title, title)
+	if meta := s.meta(); meta != "" {
+		fmt.Fprintf(&b, "<p><em>%s</em></p>\n", html.EscapeString(meta))
+	}
+	section := func(heading, text string) {
+		if text = paragraphs(text); text != "" {
+			fmt.Fprintf(&b, "<h2>%s</h2>\n%s", html.EscapeString(heading), htmlParagraphs(text))
+		}
+	}
+	section("Opening", s.Opening)
+	for i, sec := range s.Sections {
+		fmt.Fprintf(&b, "<h2>%d. %s</h2>\n", i+1, html.EscapeString(oneLine(sec.Title)))
+		if sec.Estimate != "" {
+			fmt.Fprintf(&b, "<p><em>%s</em></p>\n", html.EscapeString(sec.Estimate))
+		}
+		b.WriteString(htmlParagraphs(paragraphs(sec.Text)))
+		if t := oneLine(sec.Transition); t != "" {
+			fmt.Fprintf(&b, "<blockquote><em>Transition:</em> %s</blockquote>\n", html.EscapeString(t))
+		}
+	}
+	section("Closing", s.Closing)
+	if qs := s.questions(); len(qs) > 0 {
+		b.WriteString("<h2>Anticipated questions</h2>\n<ol>\n")
+		for _, q := range qs {
+			fmt.Fprintf(&b, "<li><strong>%s</strong>", html.EscapeString(q.Question))
+			if q.Answer != "" {
+				fmt.Fprintf(&b, "<br>%s", html.EscapeString(oneLine(q.Answer)))
+			}
+			b.WriteString("</li>\n")
+		}
+		b.WriteString("</ol>\n")
+	}
+	b.WriteString("</body></html>\n")
+	return b.String()
+}
+
+// meta is the line under the title: the audience and the total speaking time.
+func (s Script) meta() string {
+	var parts []string
+	if a := oneLine(s.Audience); a != "" {
+		parts = append(parts, "Audience: "+a)
+	}
+	if s.Estimate != "" {
+		parts = append(parts, "Speaking time "+s.Estimate)
+	}
+	return strings.Join(parts, " · ")
+}
+
+// questions drops blank questions and flattens the rest to one line.
+func (s Script) questions() []Question {
+	var qs []Question
+	for _, q := range s.Questions {
+		if q.Question = oneLine(q.Question); q.Question != "" {
+			qs = append(qs, q)
+		}
+	}
+	return qs
+}
+
+// paragraphs normalizes text to paragraphs of one line each, separated by a blank line.
+func paragraphs(text string) string {
+	var out []string
+	for _, p := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n\n") {
+		if p = oneLine(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return strings.Join(out, "\n\n")
+}
+
+func htmlParagraphs(text string) string {
+	var b strings.Builder
+	for _, p := range strings.Split(text, "\n\n") {
+		if p != "" {
+			fmt.Fprintf(&b, "<p>%s</p>\n", html.EscapeString(p))
+		}
+	}
+	return b.String()
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,77 @@
+package talktrack
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScript_Markdown(t *testing.T) {
+	s := Script{
+		Title:    "Q3 pricing",
+		Audience: "executives",
+		Estimate: "≈ 2m",
+		Opening:  "Good morning.\n\nToday: pricing.",
+		Sections: []Section{
+			{Title: "Why now", Text: "Costs rose\n 8%.", Transition: "So what do we do?", Estimate: "≈ 45s"},
+			{Title: "The plan", Text: "Raise prices 5%."},
+		},
+		Closing:   "Thank you.",
+		Questions: []Question{{Question: "Why 5%?", Answer: "It matches inflation."}, {Question: "  "}},
+	}
+	want := `# Q3 pricing
+
+_Audience: executives · Speaking time ≈ 2m_
+
+## Opening
+
+Good morning.
+
+Today: pricing.
+
+## 1. Why now
+
+_≈ 45s_
+
+Costs rose 8%.
+
+> _Transition:_ So what do we do?
+
+## 2. The plan
+
+Raise prices 5%.
+
+## Closing
+
+Thank you.
+
+## Anticipated questions
+
+1. **Why 5%?**
+   It matches inflation.
+`
+	if got := s.Markdown(); got != want {
+		t.Errorf("Markdown() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestScript_HTML(t *testing.T) {
+	s := Script{
+		Title:    "R&D <review>",
+		Sections: []Section{{Title: "A & B", Text: "one\n\ntwo", Transition: "next <up>"}},
+	}
+	got := s.HTML()
+	for _, want := range []string{
+		"<title>R&amp;D &lt;review&gt;</title>",
+		"<h2>1. A &amp; B</h2>\n<p>one</p>\n<p>two</p>\n",
+		"<blockquote><em>Transition:</em> next &lt;up&gt;</blockquote>",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("HTML() missing %q:\n%s", want, got)
+		}
+	}
+	for _, unwanted := range []string{"<h2>Opening</h2>", "<h2>Closing</h2>", "Anticipated questions"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("HTML() has %q for an empty part:\n%s", unwanted, got)
+		}
+	}
+}
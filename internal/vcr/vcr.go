@@ -0,0 +1,226 @@
+// Package vcr records HTTP interactions to a cassette file once and replays them later, so
+// tests of the Slides, Sheets, and Custom Search pipeline run in CI without credentials or
+// quota. Requests are matched in order by method and URL (with API keys redacted); bodies
+// are kept for reading but not compared, since they carry random object IDs.
+package vcr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// Mode selects whether a Recorder talks to the real service.
+type Mode int
+
+const (
+	Replay Mode = iota // serve responses from the cassette; no network
+	Record             // pass requests through and save them to the cassette
+)
+
+// ModeFromEnv is Record when VCR_MODE=record and Replay otherwise.
+func ModeFromEnv() Mode {
+	if os.Getenv("VCR_MODE") == "record" {
+		return Record
+	}
+	return Replay
+}
+
+// redactedParams are query parameters never written to a cassette or used for matching.
+var redactedParams = []string{"key", "access_token"}
+
+// Interaction is one recorded request and its response.
+type Interaction struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	RequestBody string      `json:"request_body,omitempty"`
+	Status      int         `json:"status"`
+	Header      http.Header `json:"header,omitempty"`
+	Body        string      `json:"body"`
+}
+
+// Cassette is the file format: the interactions in order, plus named values (such as the
+// deck and spreadsheet IDs a recording used) that a replaying test needs again.
+type Cassette struct {
+	Vars         map[string]string `json:"vars,omitempty"`
+	Interactions []Interaction     `json:"interactions"`
+}
+
+// ErrNoCassette is returned by New in Replay mode when the cassette file doesn't exist yet.
+var ErrNoCassette = errors.New("cassette not recorded")
+
+// Recorder is an http.RoundTripper that records to or replays from one cassette.
+type Recorder struct {
+	mode     Mode
+	path     string
+	real     http.RoundTripper
+	mu       sync.Mutex
+	cassette Cassette
+	next     int // next interaction to replay
+}
+
+// New opens the cassette at path. In Record mode requests go through real (the default
+// transport when nil) and the cassette is written by Stop; in Replay mode the file must exist.
+func New(path string, mode Mode, real http.RoundTripper) (*Recorder, error) {
+	r := &Recorder{mode: mode, path: path, real: real, cassette: Cassette{Vars: map[string]string{}}}
+	if r.real == nil {
+		r.real = http.DefaultTransport
+	}
+	if mode == Record {
+		return r, nil
+	}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%s: %w", path, ErrNoCassette)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read cassette: %w", err)
+	}
+	if err := json.Unmarshal(b, &r.cassette); err != nil {
+		return nil, fmt.Errorf("parse cassette %s: %w", path, err)
+	}
+	return r, nil
+}
+
+// ForTest opens the cassette at path in the mode ModeFromEnv picks and stops the recorder
+// when the test ends, failing it if the cassette could not be saved or was not fully
+// replayed. A missing cassette fails the test in Replay mode, so a deleted or never
+// committed recording can't pass CI unnoticed.
+func ForTest(t testing.TB, path string, real http.RoundTripper) *Recorder {
+	t.Helper()
+	r, err := New(path, ModeFromEnv(), real)
+	if errors.Is(err, ErrNoCassette) {
+		t.Fatalf("%v; run with VCR_MODE=record and credentials to record it", err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := r.Stop(); err != nil {
+			t.Error(err)
+		}
+	})
+	return r
+}
+
+// Mode is the recorder's mode.
+func (r *Recorder) Mode() Mode { return r.mode }
+
+// Client is an HTTP client sending through the recorder.
+func (r *Recorder) Client() *http.Client { return &http.Client{Transport: r} }
+
+// SetVar stores a named value in the cassette while recording.
+func (r *Recorder) SetVar(name, value string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Vars[name] = value
+}
+
+// Var returns a named value stored while recording.
+func (r *Recorder) Var(name string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cassette.Vars[name]
+}
+
+// RoundTrip records or replays req.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		b, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("read request body: %w", err)
+		}
+		reqBody = b
+		req.Body = io.NopCloser(bytes.NewReader(b))
+	}
+	u := redactURL(req.URL)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.mode == Replay {
+		if r.next >= len(r.cassette.Interactions) {
+			return nil, fmt.Errorf("vcr: unexpected request %s %s after %d recorded interactions", req.Method, u, len(r.cassette.Interactions))
+		}
+		in := r.cassette.Interactions[r.next]
+		if in.Method != req.Method || in.URL != u {
+			return nil, fmt.Errorf("vcr: request %d is %s %s, recorded %s %s", r.next+1, req.Method, u, in.Method, in.URL)
+		}
+		r.next++
+		return &http.Response{
+			Status:        http.StatusText(in.Status),
+			StatusCode:    in.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        in.Header.Clone(),
+			Body:          io.NopCloser(bytes.NewReader([]byte(in.Body))),
+			ContentLength: int64(len(in.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	resp, err := r.real.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	header := resp.Header.Clone()
+	header.Del("Set-Cookie")
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Method: req.Method, URL: u, RequestBody: string(reqBody),
+		Status: resp.StatusCode, Header: header, Body: string(body),
+	})
+	return resp, nil
+}
+
+// Stop saves the cassette in Record mode. In Replay mode it reports recorded interactions
+// that were never requested, which means the code under test changed its calls.
+func (r *Recorder) Stop() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.mode == Replay {
+		if left := len(r.cassette.Interactions) - r.next; left > 0 {
+			return fmt.Errorf("vcr: %d recorded interactions were not replayed", left)
+		}
+		return nil
+	}
+	b, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("create cassette dir: %w", err)
+	}
+	if err := os.WriteFile(r.path, append(b, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write cassette: %w", err)
+	}
+	return nil
+}
+
+// redactURL is u without credential query parameters.
+func redactURL(u *url.URL) string {
+	c := *u
+	q := c.Query()
+	for _, p := range redactedParams {
+		if q.Has(p) {
+			q.Set(p, "REDACTED")
+		}
+	}
+	c.RawQuery = q.Encode()
+	return c.String()
+}
@@ -0,0 +1,139 @@
+package vcr
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// failTransport fails every request, proving replay never touches the network.
+type failTransport struct{}
+
+func (failTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("network used during replay")
+}
+
+func post(t *testing.T, c *http.Client, u string) string {
+	t.Helper()
+	resp, err := c.Post(u, "application/json", strings.NewReader(`{"q":1}`))
+	if err != nil {
+		t.Fatalf("POST %s: %v", u, err)
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	return string(b)
+}
+
+func TestRecordThenReplay(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Set-Cookie", "session=secret")
+		io.WriteString(w, "reply to "+r.URL.Path)
+	}))
+	defer srv.Close()
+	path := filepath.Join(t.TempDir(), "cassettes", "calls.json")
+
+	rec, err := New(path, Record, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.SetVar("deck", "deck-1")
+	if got := post(t, rec.Client(), srv.URL+"/a?key=secret-key&q=x"); got != "reply to /a" {
+		t.Fatalf("recorded body = %q", got)
+	}
+	post(t, rec.Client(), srv.URL+"/b")
+	if err := rec.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"secret-key", "session=secret"} {
+		if strings.Contains(string(b), secret) {
+			t.Errorf("cassette contains %q", secret)
+		}
+	}
+
+	play, err := New(path, Replay, failTransport{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := play.Var("deck"); got != "deck-1" {
+		t.Errorf("Var(deck) = %q, want deck-1", got)
+	}
+	// A different key still matches, since keys are redacted before comparing
+	if got := post(t, play.Client(), srv.URL+"/a?key=other&q=x"); got != "reply to /a" {
+		t.Errorf("replayed body = %q", got)
+	}
+	if err := play.Stop(); err == nil {
+		t.Error("Stop() with an unplayed interaction: want an error")
+	}
+	post(t, play.Client(), srv.URL+"/b")
+	if err := play.Stop(); err != nil {
+		t.Errorf("Stop() = %v", err)
+	}
+	if _, err := play.Client().Get(srv.URL + "/c"); err == nil {
+		t.Error("request past the cassette: want an error")
+	}
+}
+
+func TestReplay_Mismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "calls.json")
+	if err := os.WriteFile(path, []byte(`{"interactions":[{"method":"GET","url":"https://example.com/a","status":200,"body":"ok"}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rec, err := New(path, Replay, failTransport{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rec.Client().Get("https://example.com/b"); err == nil || !strings.Contains(err.Error(), "recorded GET https://example.com/a") {
+		t.Errorf("mismatched request error = %v", err)
+	}
+}
+
+func TestNew_MissingCassette(t *testing.T) {
+	_, err := New(filepath.Join(t.TempDir(), "none.json"), Replay, nil)
+	if !errors.Is(err, ErrNoCassette) {
+		t.Errorf("New() error = %v, want ErrNoCassette", err)
+	}
+}
+
+// fatalTB records the message of the first Fatal and Skip call and ends the goroutine, as
+// testing.T does.
+type fatalTB struct {
+	testing.TB
+	fatal, skip string
+}
+
+func (f *fatalTB) Helper() {}
+
+func (f *fatalTB) Fatalf(format string, args ...any) {
+	f.fatal = fmt.Sprintf(format, args...)
+	runtime.Goexit()
+}
+
+func (f *fatalTB) Skipf(format string, args ...any) {
+	f.skip = fmt.Sprintf(format, args...)
+	runtime.Goexit()
+}
+
+func TestForTest_MissingCassette(t *testing.T) {
+	t.Setenv("VCR_MODE", "")
+	tb := &fatalTB{TB: t}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ForTest(tb, filepath.Join(t.TempDir(), "none.json"), nil)
+	}()
+	<-done
+	if tb.skip != "" || !strings.Contains(tb.fatal, "VCR_MODE=record") {
+		t.Errorf("ForTest() without a cassette: fatal %q, skip %q; want the test failed with how to record", tb.fatal, tb.skip)
+	}
+}
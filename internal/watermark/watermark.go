@@ -0,0 +1,138 @@
+package watermark
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif" // register decoders for searched images
+	_ "image/jpeg"
+	"image/png"
+	"strings"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+	_ "golang.org/x/image/webp"
+)
+
+// Position is the corner where the mark is placed.
+type Position string
+
+const (
+	BottomRight Position = "bottom-right"
+	BottomLeft  Position = "bottom-left"
+	TopRight    Position = "top-right"
+	TopLeft     Position = "top-left"
+)
+
+// Options configures the overlay. Logo takes precedence over Text when both are set.
+type Options struct {
+	Logo     []byte   // PNG/JPEG/GIF logo bytes (optional)
+	Text     string   // label such as "AI-generated" (optional)
+	Position Position // default BottomRight
+	Scale    float64  // mark width relative to image width, default 0.15
+	Opacity  float64  // 0..1, default 0.8
+}
+
+// Enabled reports whether there is anything to overlay.
+func (o Options) Enabled() bool {
+	return len(o.Logo) > 0 || strings.TrimSpace(o.Text) != ""
+}
+
+// ParsePosition validates a corner name; empty input returns BottomRight.
+func ParsePosition(s string) (Position, error) {
+	switch p := Position(strings.ToLower(strings.TrimSpace(s))); p {
+	case "":
+		return BottomRight, nil
+	case BottomRight, BottomLeft, TopRight, TopLeft:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown watermark position %q (bottom-right|bottom-left|top-right|top-left)", s)
+	}
+}
+
+// Apply composites the logo or text mark onto the image and returns PNG bytes.
+func Apply(data []byte, o Options) ([]byte, error) {
+	if !o.Enabled() {
+		return nil, errors.New("watermark has no logo or text")
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode image: %w", err)
+	}
+	var mark image.Image
+	if len(o.Logo) > 0 {
+		mark, _, err = image.Decode(bytes.NewReader(o.Logo))
+		if err != nil {
+			return nil, fmt.Errorf("decode logo: %w", err)
+		}
+	} else {
+		mark = renderLabel(strings.TrimSpace(o.Text))
+	}
+	if o.Scale <= 0 || o.Scale > 1 {
+		o.Scale = 0.15
+	}
+	if o.Opacity <= 0 || o.Opacity > 1 {
+		o.Opacity = 0.8
+	}
+
+	b := src.Bounds()
+	out := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(out, out.Bounds(), src, b.Min, draw.Src)
+
+	mb := mark.Bounds()
+	w := int(float64(b.Dx()) * o.Scale)
+	if w < 1 {
+		w = 1
+	}
+	h := mb.Dy() * w / mb.Dx()
+	if h < 1 {
+		h = 1
+	}
+	scaled := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(scaled, scaled.Bounds(), mark, mb, draw.Over, nil)
+
+	margin := b.Dx() / 50
+	var x, y int
+	switch o.Position {
+	case TopLeft:
+		x, y = margin, margin
+	case TopRight:
+		x, y = b.Dx()-w-margin, margin
+	case BottomLeft:
+		x, y = margin, b.Dy()-h-margin
+	default:
+		x, y = b.Dx()-w-margin, b.Dy()-h-margin
+	}
+	dst := image.Rect(x, y, x+w, y+h)
+	alpha := image.NewUniform(color.Alpha{A: uint8(o.Opacity * 255)})
+	draw.DrawMask(out, dst, scaled, image.Point{}, alpha, image.Point{}, draw.Over)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, out); err != nil {
+		return nil, fmt.Errorf("encode png: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// renderLabel draws white text on a translucent dark pill using the built-in bitmap font;
+// Apply scales it up to the requested width.
+func renderLabel(text string) image.Image {
+	face := basicfont.Face7x13
+	const pad = 4
+	w := font.MeasureString(face, text).Ceil() + 2*pad
+	h := face.Height + 2*pad
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.RGBA{A: 140}), image.Point{}, draw.Src)
+	d := &font.Drawer{
+		Dst:  img,
+		Src:  image.White,
+		Face: face,
+		Dot:  fixed.P(pad, pad+face.Ascent),
+	}
+	d.DrawString(text)
+	return img
+}
@@ -0,0 +1,75 @@
+package watermark
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func whitePNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.White)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestApply_TextBottomRight(t *testing.T) {
+	out, err := Apply(whitePNG(t, 400, 200), Options{Text: "AI-generated", Scale: 0.3})
+	if err != nil {
+		t.Fatalf("Apply() error: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds().Dx() != 400 || img.Bounds().Dy() != 200 {
+		t.Fatalf("size changed to %v", img.Bounds())
+	}
+	if r, _, _, _ := img.At(5, 5).RGBA(); r != 0xffff {
+		t.Errorf("top-left pixel altered: r=%x", r)
+	}
+	// The label pill darkens the area just inside the bottom-right margin.
+	if r, _, _, _ := img.At(400-10, 200-10).RGBA(); r == 0xffff {
+		t.Error("bottom-right corner not watermarked")
+	}
+}
+
+func TestApply_LogoTopLeft(t *testing.T) {
+	logo := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			logo.Set(x, y, color.RGBA{R: 255, A: 255})
+		}
+	}
+	var lb bytes.Buffer
+	if err := png.Encode(&lb, logo); err != nil {
+		t.Fatal(err)
+	}
+	out, err := Apply(whitePNG(t, 200, 200), Options{Logo: lb.Bytes(), Position: TopLeft, Opacity: 1})
+	if err != nil {
+		t.Fatalf("Apply() error: %v", err)
+	}
+	img, _ := png.Decode(bytes.NewReader(out))
+	if _, g, _, _ := img.At(8, 8).RGBA(); g != 0 {
+		t.Errorf("expected red logo near top-left, got g=%x", g)
+	}
+}
+
+func TestParsePosition(t *testing.T) {
+	if p, err := ParsePosition(""); err != nil || p != BottomRight {
+		t.Errorf("ParsePosition(\"\") = %q, %v", p, err)
+	}
+	if _, err := ParsePosition("middle"); err == nil {
+		t.Error("expected error for unknown position")
+	}
+}
@@ -0,0 +1,97 @@
+package webdata
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gogemini-practices/internal/charts"
+
+	"google.golang.org/api/analyticsdata/v1beta"
+)
+
+// AnalyticsAPI is the Google Analytics Data API call the connector makes. NewAnalyticsAPI
+// adapts the generated client; tests pass a fake.
+type AnalyticsAPI interface {
+	RunReport(ctx context.Context, property string, req *analyticsdata.RunReportRequest) (*analyticsdata.RunReportResponse, error)
+}
+
+// NewAnalyticsAPI wraps svc; a nil svc gives a nil AnalyticsAPI.
+func NewAnalyticsAPI(svc *analyticsdata.Service) AnalyticsAPI {
+	if svc == nil {
+		return nil
+	}
+	return analyticsService{svc}
+}
+
+type analyticsService struct{ svc *analyticsdata.Service }
+
+func (s analyticsService) RunReport(ctx context.Context, property string, req *analyticsdata.RunReportRequest) (*analyticsdata.RunReportResponse, error) {
+	return s.svc.Properties.RunReport("properties/"+property, req).Context(ctx).Do()
+}
+
+// timeDimensions are the GA4 dimensions that bucket a report by Granularity, so rates and
+// averages are aggregated by Analytics rather than re-averaged here.
+var timeDimensions = map[string]string{Daily: "date", Weekly: "isoYearIsoWeek", Monthly: "yearMonth"}
+
+func (c Client) fetchAnalytics(ctx context.Context, spec dataset, r Range) ([]charts.Point, error) {
+	req := &analyticsdata.RunReportRequest{
+		DateRanges: []*analyticsdata.DateRange{{StartDate: r.Start.Format(time.DateOnly), EndDate: r.End.Format(time.DateOnly)}},
+		Metrics:    []*analyticsdata.Metric{{Name: spec.metric}},
+	}
+	dimension := spec.dimension
+	if dimension == "" {
+		dimension = timeDimensions[r.Granularity()]
+		req.OrderBys = []*analyticsdata.OrderBy{{Dimension: &analyticsdata.DimensionOrderBy{DimensionName: dimension}}}
+	} else {
+		req.OrderBys = []*analyticsdata.OrderBy{{Metric: &analyticsdata.MetricOrderBy{MetricName: spec.metric}, Desc: true}}
+		req.Limit = TopN
+	}
+	req.Dimensions = []*analyticsdata.Dimension{{Name: dimension}}
+
+	resp, err := c.Analytics.RunReport(ctx, c.Property, req)
+	if err != nil {
+		return nil, err
+	}
+	var points []charts.Point
+	for _, row := range resp.Rows {
+		if row == nil || len(row.DimensionValues) == 0 || len(row.MetricValues) == 0 || row.DimensionValues[0] == nil || row.MetricValues[0] == nil {
+			continue
+		}
+		v, err := strconv.ParseFloat(row.MetricValues[0].Value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("metric %s: %w", spec.metric, err)
+		}
+		label := row.DimensionValues[0].Value
+		if spec.dimension == "" {
+			label = analyticsLabel(label, dimension)
+		}
+		points = append(points, charts.Point{Label: label, Value: v})
+	}
+	return points, nil
+}
+
+// analyticsLabel turns a GA4 time dimension value into the bucket labels used for Search
+// Console: "20261005" -> "2026-10-05", ISO week "202641" -> its Monday, "202610" -> "2026-10".
+func analyticsLabel(v, dimension string) string {
+	switch dimension {
+	case "date":
+		if t, err := time.Parse("20060102", v); err == nil {
+			return t.Format(time.DateOnly)
+		}
+	case "isoYearIsoWeek":
+		year, errY := strconv.Atoi(v[:min(4, len(v))])
+		week, errW := strconv.Atoi(v[min(4, len(v)):])
+		if errY == nil && errW == nil && len(v) == 6 {
+			// January 4th is always in ISO week 1
+			jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, time.UTC)
+			return bucket(jan4.AddDate(0, 0, 7*(week-1)), Weekly)
+		}
+	case "yearMonth":
+		if t, err := time.Parse("200601", v); err == nil {
+			return t.Format("2006-01")
+		}
+	}
+	return v
+}
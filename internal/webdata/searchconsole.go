@@ -0,0 +1,111 @@
+package webdata
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"gogemini-practices/internal/charts"
+
+	"google.golang.org/api/searchconsole/v1"
+)
+
+// SearchConsoleAPI is the Search Console call the connector makes. NewSearchConsoleAPI
+// adapts the generated client; tests pass a fake.
+type SearchConsoleAPI interface {
+	Query(ctx context.Context, site string, req *searchconsole.SearchAnalyticsQueryRequest) (*searchconsole.SearchAnalyticsQueryResponse, error)
+}
+
+// NewSearchConsoleAPI wraps svc; a nil svc gives a nil SearchConsoleAPI.
+func NewSearchConsoleAPI(svc *searchconsole.Service) SearchConsoleAPI {
+	if svc == nil {
+		return nil
+	}
+	return searchConsoleService{svc}
+}
+
+type searchConsoleService struct{ svc *searchconsole.Service }
+
+func (s searchConsoleService) Query(ctx context.Context, site string, req *searchconsole.SearchAnalyticsQueryRequest) (*searchconsole.SearchAnalyticsQueryResponse, error) {
+	return s.svc.Searchanalytics.Query(site, req).Context(ctx).Do()
+}
+
+func (c Client) fetchSearchConsole(ctx context.Context, spec dataset, r Range) ([]charts.Point, error) {
+	req := &searchconsole.SearchAnalyticsQueryRequest{
+		StartDate:  r.Start.Format(time.DateOnly),
+		EndDate:    r.End.Format(time.DateOnly),
+		Dimensions: []string{"date"},
+		RowLimit:   int64(r.Days()),
+	}
+	if spec.dimension != "" {
+		// Search Console sorts by clicks, descending
+		req.Dimensions, req.RowLimit = []string{spec.dimension}, TopN
+	}
+	resp, err := c.SearchConsole.Query(ctx, c.Site, req)
+	if err != nil {
+		return nil, err
+	}
+	if spec.dimension != "" {
+		var points []charts.Point
+		for _, row := range resp.Rows {
+			if row != nil && len(row.Keys) > 0 {
+				points = append(points, charts.Point{Label: row.Keys[0], Value: searchValue(spec.metric, row.Clicks, row.Impressions, row.Ctr, row.Position)})
+			}
+		}
+		return points, nil
+	}
+	return bucketSearchRows(resp.Rows, spec.metric, r.Granularity()), nil
+}
+
+// bucketSearchRows sums daily rows into buckets. Click-through rate is recomputed from the
+// sums and position is averaged weighted by impressions, as Search Console does.
+func bucketSearchRows(rows []*searchconsole.ApiDataRow, metric, granularity string) []charts.Point {
+	type sums struct{ clicks, impressions, weightedPosition float64 }
+	byBucket := map[string]*sums{}
+	for _, row := range rows {
+		if row == nil || len(row.Keys) == 0 {
+			continue
+		}
+		day, err := time.Parse(time.DateOnly, row.Keys[0])
+		if err != nil {
+			continue
+		}
+		label := bucket(day, granularity)
+		s := byBucket[label]
+		if s == nil {
+			s = &sums{}
+			byBucket[label] = s
+		}
+		s.clicks += row.Clicks
+		s.impressions += row.Impressions
+		s.weightedPosition += row.Position * row.Impressions
+	}
+	labels := make([]string, 0, len(byBucket))
+	for label := range byBucket {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	points := make([]charts.Point, 0, len(labels))
+	for _, label := range labels {
+		s := byBucket[label]
+		var ctr, position float64
+		if s.impressions > 0 {
+			ctr, position = s.clicks/s.impressions, s.weightedPosition/s.impressions
+		}
+		points = append(points, charts.Point{Label: label, Value: searchValue(metric, s.clicks, s.impressions, ctr, position)})
+	}
+	return points
+}
+
+// searchValue picks the Search Console metric out of a row's fields.
+func searchValue(metric string, clicks, impressions, ctr, position float64) float64 {
+	switch metric {
+	case "impressions":
+		return impressions
+	case "ctr":
+		return ctr
+	case "position":
+		return position
+	}
+	return clicks
+}
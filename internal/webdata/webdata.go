@@ -0,0 +1,210 @@
+// Package webdata pulls traffic and engagement metrics from Google Analytics 4 and Search
+// Console and turns them into chart datasets for marketing-report decks.
+package webdata
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gogemini-practices/internal/charts"
+)
+
+// Sources of a dataset, the prefix of its name.
+const (
+	Analytics     = "ga"
+	SearchConsole = "gsc"
+)
+
+// TopN is how many rows a breakdown dataset (top pages, channels, ...) keeps.
+const TopN = 10
+
+// dataset is one selectable dataset: a metric over time, or broken down by a dimension
+// when dimension is set.
+type dataset struct {
+	source    string
+	title     string
+	unit      string
+	metric    string  // GA4 metric, or Search Console clicks | impressions | ctr | position
+	dimension string  // breakdown dimension; "" charts the metric over time
+	scale     float64 // multiplier for display, e.g. 100 for a 0-1 rate shown in %
+}
+
+// catalog lists the datasets Fetch accepts, by "<source>:<name>".
+var catalog = map[string]dataset{
+	"ga:sessions":        {source: Analytics, title: "Sessions", metric: "sessions"},
+	"ga:users":           {source: Analytics, title: "Active users", metric: "activeUsers"},
+	"ga:new-users":       {source: Analytics, title: "New users", metric: "newUsers"},
+	"ga:pageviews":       {source: Analytics, title: "Page views", metric: "screenPageViews"},
+	"ga:engagement-rate": {source: Analytics, title: "Engagement rate", unit: "%", metric: "engagementRate", scale: 100},
+	"ga:session-length":  {source: Analytics, title: "Average session duration", unit: "s", metric: "averageSessionDuration"},
+	"ga:key-events":      {source: Analytics, title: "Key events", metric: "keyEvents"},
+	"ga:channels":        {source: Analytics, title: "Sessions by channel", metric: "sessions", dimension: "sessionDefaultChannelGroup"},
+	"ga:top-pages":       {source: Analytics, title: "Top pages by views", metric: "screenPageViews", dimension: "pagePath"},
+	"ga:countries":       {source: Analytics, title: "Sessions by country", metric: "sessions", dimension: "country"},
+	"gsc:clicks":         {source: SearchConsole, title: "Search clicks", metric: "clicks"},
+	"gsc:impressions":    {source: SearchConsole, title: "Search impressions", metric: "impressions"},
+	"gsc:ctr":            {source: SearchConsole, title: "Search click-through rate", unit: "%", metric: "ctr", scale: 100},
+	"gsc:position":       {source: SearchConsole, title: "Average search position", metric: "position"},
+	"gsc:top-queries":    {source: SearchConsole, title: "Top search queries by clicks", metric: "clicks", dimension: "query"},
+	"gsc:top-pages":      {source: SearchConsole, title: "Top pages by search clicks", metric: "clicks", dimension: "page"},
+}
+
+// Names lists the dataset names Fetch accepts, sorted.
+func Names() []string {
+	names := make([]string, 0, len(catalog))
+	for name := range catalog {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Source returns the source ("ga" or "gsc") of a dataset name, or an error naming the
+// accepted ones.
+func Source(name string) (string, error) {
+	ds, ok := catalog[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return "", fmt.Errorf("unknown web dataset %q (want one of %s)", name, strings.Join(Names(), ", "))
+	}
+	return ds.source, nil
+}
+
+// Range is an inclusive range of whole days.
+type Range struct {
+	Start, End time.Time
+}
+
+// MaxDays is the longest range; monthly buckets keep it to about 20 points.
+const MaxDays = 550
+
+var relativeRange = regexp.MustCompile(`^([0-9]+)d$`)
+
+// ParseRange reads "28d" (the 28 days up to yesterday, the last complete day before now) or
+// "2026-09-01..2026-09-30", of at most MaxDays.
+func ParseRange(s string, now time.Time) (Range, error) {
+	s = strings.TrimSpace(s)
+	if m := relativeRange.FindStringSubmatch(s); m != nil {
+		n, err := strconv.Atoi(m[1])
+		if err != nil || n < 1 || n > MaxDays {
+			return Range{}, fmt.Errorf("date range %q: want 1 to %d days", s, MaxDays)
+		}
+		end := time.Date(now.Year(), now.Month(), now.Day()-1, 0, 0, 0, 0, time.UTC)
+		return Range{Start: end.AddDate(0, 0, 1-n), End: end}, nil
+	}
+	from, to, ok := strings.Cut(s, "..")
+	if !ok {
+		return Range{}, fmt.Errorf("date range %q: want <n>d or YYYY-MM-DD..YYYY-MM-DD", s)
+	}
+	start, err := time.Parse(time.DateOnly, strings.TrimSpace(from))
+	if err != nil {
+		return Range{}, fmt.Errorf("date range %q: %w", s, err)
+	}
+	end, err := time.Parse(time.DateOnly, strings.TrimSpace(to))
+	if err != nil {
+		return Range{}, fmt.Errorf("date range %q: %w", s, err)
+	}
+	if end.Before(start) {
+		return Range{}, fmt.Errorf("date range %q ends before it starts", s)
+	}
+	r := Range{Start: start, End: end}
+	if r.Days() > MaxDays {
+		return Range{}, fmt.Errorf("date range %q spans %d days; the most is %d", s, r.Days(), MaxDays)
+	}
+	return r, nil
+}
+
+// Days is the number of days in the range.
+func (r Range) Days() int {
+	return int(r.End.Sub(r.Start).Hours()/24) + 1
+}
+
+func (r Range) String() string {
+	return r.Start.Format(time.DateOnly) + " to " + r.End.Format(time.DateOnly)
+}
+
+// Time buckets of a timeseries, picked by the range length so a chart keeps at most
+// 20 points, counting the partial weeks or months at either end.
+const (
+	Daily   = "day"
+	Weekly  = "week"
+	Monthly = "month"
+)
+
+// Granularity is the bucket size for a timeseries over the range.
+func (r Range) Granularity() string {
+	switch days := r.Days(); {
+	case days <= 20:
+		return Daily
+	case days <= 19*7:
+		return Weekly
+	}
+	return Monthly
+}
+
+// bucket is the label of the bucket day falls in: the day itself, the Monday starting its
+// ISO week, or its month.
+func bucket(day time.Time, granularity string) string {
+	switch granularity {
+	case Weekly:
+		offset := (int(day.Weekday()) + 6) % 7 // days since Monday
+		return day.AddDate(0, 0, -offset).Format(time.DateOnly)
+	case Monthly:
+		return day.Format("2006-01")
+	}
+	return day.Format(time.DateOnly)
+}
+
+// Client fetches datasets; each API is only needed for its source's datasets.
+type Client struct {
+	Analytics     AnalyticsAPI
+	Property      string // GA4 property ID, e.g. "123456789"
+	SearchConsole SearchConsoleAPI
+	Site          string // Search Console property, e.g. "https://example.com/" or "sc-domain:example.com"
+}
+
+// Fetch pulls the named dataset (see Names) over the range. Metrics over time become a
+// timeseries bucketed by the range's Granularity; breakdowns become a category dataset of
+// the TopN rows. The title ends with the range so the deck says which period it covers.
+func (c Client) Fetch(ctx context.Context, name string, r Range) (charts.DatasetSpec, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	spec, ok := catalog[name]
+	if !ok {
+		_, err := Source(name)
+		return charts.DatasetSpec{}, err
+	}
+	var points []charts.Point
+	var err error
+	switch spec.source {
+	case Analytics:
+		if c.Analytics == nil || c.Property == "" {
+			return charts.DatasetSpec{}, fmt.Errorf("%s needs a Google Analytics property", name)
+		}
+		points, err = c.fetchAnalytics(ctx, spec, r)
+	case SearchConsole:
+		if c.SearchConsole == nil || c.Site == "" {
+			return charts.DatasetSpec{}, fmt.Errorf("%s needs a Search Console site", name)
+		}
+		points, err = c.fetchSearchConsole(ctx, spec, r)
+	}
+	if err != nil {
+		return charts.DatasetSpec{}, fmt.Errorf("%s: %w", name, err)
+	}
+	if len(points) == 0 {
+		return charts.DatasetSpec{}, fmt.Errorf("%s: no data for %s", name, r)
+	}
+	if spec.scale != 0 {
+		for i := range points {
+			points[i].Value *= spec.scale
+		}
+	}
+	ds := charts.DatasetSpec{Title: spec.title + ", " + r.String(), Unit: spec.unit, Type: "timeseries", Points: points}
+	if spec.dimension != "" {
+		ds.Type = "category"
+	}
+	return ds, nil
+}
@@ -0,0 +1,206 @@
+package webdata
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/api/analyticsdata/v1beta"
+	"google.golang.org/api/searchconsole/v1"
+
+	"gogemini-practices/internal/charts"
+)
+
+type fakeAnalytics struct {
+	req  *analyticsdata.RunReportRequest
+	resp *analyticsdata.RunReportResponse
+}
+
+func (f *fakeAnalytics) RunReport(ctx context.Context, property string, req *analyticsdata.RunReportRequest) (*analyticsdata.RunReportResponse, error) {
+	f.req = req
+	return f.resp, nil
+}
+
+type fakeSearchConsole struct {
+	req  *searchconsole.SearchAnalyticsQueryRequest
+	rows []*searchconsole.ApiDataRow
+}
+
+func (f *fakeSearchConsole) Query(ctx context.Context, site string, req *searchconsole.SearchAnalyticsQueryRequest) (*searchconsole.SearchAnalyticsQueryResponse, error) {
+	f.req = req
+	return &searchconsole.SearchAnalyticsQueryResponse{Rows: f.rows}, nil
+}
+
+func day(s string) time.Time {
+	t, _ := time.Parse(time.DateOnly, s)
+	return t
+}
+
+func TestParseRange(t *testing.T) {
+	now := time.Date(2026, 10, 15, 9, 30, 0, 0, time.UTC)
+	tests := []struct {
+		in      string
+		want    Range
+		wantErr bool
+	}{
+		{in: "28d", want: Range{Start: day("2026-09-17"), End: day("2026-10-14")}},
+		{in: "1d", want: Range{Start: day("2026-10-14"), End: day("2026-10-14")}},
+		{in: "2026-09-01..2026-09-30", want: Range{Start: day("2026-09-01"), End: day("2026-09-30")}},
+		{in: "0d", wantErr: true},
+		{in: "900d", wantErr: true},
+		{in: "2026-09-30..2026-09-01", wantErr: true},
+		{in: "2024-01-01..2026-01-01", wantErr: true},
+		{in: "last month", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseRange(tt.in, now)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseRange(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("ParseRange(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRange_Granularity(t *testing.T) {
+	tests := []struct {
+		days int
+		want string
+	}{{7, Daily}, {20, Daily}, {28, Weekly}, {133, Weekly}, {180, Monthly}, {MaxDays, Monthly}}
+	for _, tt := range tests {
+		r := Range{Start: day("2026-01-01")}
+		r.End = r.Start.AddDate(0, 0, tt.days-1)
+		if got := r.Granularity(); got != tt.want {
+			t.Errorf("%d days: Granularity() = %q, want %q", tt.days, got, tt.want)
+		}
+	}
+}
+
+func TestAnalyticsLabel(t *testing.T) {
+	tests := []struct{ v, dimension, want string }{
+		{"20261005", "date", "2026-10-05"},
+		{"202641", "isoYearIsoWeek", "2026-10-05"},
+		{"202601", "isoYearIsoWeek", "2025-12-29"},
+		{"202610", "yearMonth", "2026-10"},
+		{"(other)", "date", "(other)"},
+	}
+	for _, tt := range tests {
+		if got := analyticsLabel(tt.v, tt.dimension); got != tt.want {
+			t.Errorf("analyticsLabel(%q, %q) = %q, want %q", tt.v, tt.dimension, got, tt.want)
+		}
+	}
+}
+
+func TestFetch_Analytics(t *testing.T) {
+	row := func(dim, metric string) *analyticsdata.Row {
+		return &analyticsdata.Row{
+			DimensionValues: []*analyticsdata.DimensionValue{{Value: dim}},
+			MetricValues:    []*analyticsdata.MetricValue{{Value: metric}},
+		}
+	}
+	r := Range{Start: day("2026-09-17"), End: day("2026-10-14")}
+	tests := []struct {
+		name          string
+		dataset       string
+		rows          []*analyticsdata.Row
+		wantDimension string
+		want          charts.DatasetSpec
+	}{
+		{
+			name:          "rate over weeks",
+			dataset:       "ga:engagement-rate",
+			rows:          []*analyticsdata.Row{row("202638", "0.5"), row("202639", "0.625")},
+			wantDimension: "isoYearIsoWeek",
+			want: charts.DatasetSpec{Title: "Engagement rate, 2026-09-17 to 2026-10-14", Unit: "%", Type: "timeseries", Points: []charts.Point{
+				{Label: "2026-09-14", Value: 50}, {Label: "2026-09-21", Value: 62.5},
+			}},
+		},
+		{
+			name:          "breakdown",
+			dataset:       "GA:Channels",
+			rows:          []*analyticsdata.Row{row("Organic Search", "120"), row("Direct", "80")},
+			wantDimension: "sessionDefaultChannelGroup",
+			want: charts.DatasetSpec{Title: "Sessions by channel, 2026-09-17 to 2026-10-14", Type: "category", Points: []charts.Point{
+				{Label: "Organic Search", Value: 120}, {Label: "Direct", Value: 80},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &fakeAnalytics{resp: &analyticsdata.RunReportResponse{Rows: tt.rows}}
+			got, err := Client{Analytics: api, Property: "123"}.Fetch(context.Background(), tt.dataset, r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Fetch() = %+v, want %+v", got, tt.want)
+			}
+			if d := api.req.Dimensions[0].Name; d != tt.wantDimension {
+				t.Errorf("dimension = %q, want %q", d, tt.wantDimension)
+			}
+			if dr := api.req.DateRanges[0]; dr.StartDate != "2026-09-17" || dr.EndDate != "2026-10-14" {
+				t.Errorf("date range = %+v", dr)
+			}
+		})
+	}
+}
+
+func TestFetch_SearchConsoleBuckets(t *testing.T) {
+	rows := []*searchconsole.ApiDataRow{
+		{Keys: []string{"2026-10-05"}, Clicks: 10, Impressions: 100, Position: 4},
+		{Keys: []string{"2026-10-11"}, Clicks: 30, Impressions: 300, Position: 8},
+		{Keys: []string{"2026-10-12"}, Clicks: 5, Impressions: 50, Position: 2},
+	}
+	r := Range{Start: day("2026-09-17"), End: day("2026-10-14")}
+	tests := []struct {
+		dataset string
+		want    []float64
+	}{
+		{"gsc:clicks", []float64{40, 5}},
+		{"gsc:ctr", []float64{10, 10}},
+		{"gsc:position", []float64{7, 2}}, // (4*100 + 8*300) / 400
+	}
+	for _, tt := range tests {
+		api := &fakeSearchConsole{rows: rows}
+		ds, err := Client{SearchConsole: api, Site: "sc-domain:example.com"}.Fetch(context.Background(), tt.dataset, r)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.dataset, err)
+		}
+		var labels []string
+		var got []float64
+		for _, p := range ds.Points {
+			labels = append(labels, p.Label)
+			got = append(got, math.Round(p.Value*1000)/1000)
+		}
+		if strings.Join(labels, ",") != "2026-10-05,2026-10-12" || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: points %v = %v, want weeks of 2026-10-05 and 2026-10-12 = %v", tt.dataset, labels, got, tt.want)
+		}
+		if api.req.Dimensions[0] != "date" || api.req.RowLimit != 28 {
+			t.Errorf("%s: request = %+v, want daily rows for 28 days", tt.dataset, api.req)
+		}
+	}
+}
+
+func TestFetch_Errors(t *testing.T) {
+	r := Range{Start: day("2026-10-01"), End: day("2026-10-07")}
+	tests := []struct {
+		name    string
+		client  Client
+		dataset string
+	}{
+		{"unknown dataset", Client{}, "ga:bounces"},
+		{"no property", Client{Analytics: &fakeAnalytics{}}, "ga:sessions"},
+		{"no site", Client{Analytics: &fakeAnalytics{}, Property: "1"}, "gsc:clicks"},
+		{"no rows", Client{SearchConsole: &fakeSearchConsole{}, Site: "https://example.com/"}, "gsc:top-queries"},
+	}
+	for _, tt := range tests {
+		if _, err := tt.client.Fetch(context.Background(), tt.dataset, r); err == nil {
+			t.Errorf("%s: want an error", tt.name)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+// Package webhook receives deck requests from Google Forms (through an Apps Script
+// onFormSubmit trigger) or any JSON webhook, runs a build for each, and replies with the
+// deck links, so the agent can serve as a self-service internal tool.
+package webhook
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// maxBodyBytes caps a submission; a brief longer than this is not a form answer.
+const maxBodyBytes = 64 << 10
+
+// Submission is one deck request. Only Subject is required.
+type Submission struct {
+	Subject  string `json:"subject"`
+	Audience string `json:"audience,omitempty"`
+	Tone     string `json:"tone,omitempty"`
+	Brief    string `json:"brief,omitempty"`
+}
+
+// Parse reads a submission from a request body in one of three shapes:
+//
+//   - flat JSON: {"subject": "...", "audience": "...", "tone": "...", "brief": "..."}
+//   - a Forms submit event forwarded as-is: {"namedValues": {"Subject": ["..."], ...}}
+//   - a URL-encoded form: subject=...&audience=...
+//
+// Field names match case-insensitively, so form questions can be titled "Subject",
+// "Audience", "Tone", and "Brief".
+func Parse(r *http.Request) (Submission, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodyBytes+1))
+	if err != nil {
+		return Submission{}, fmt.Errorf("read body: %w", err)
+	}
+	if len(body) > maxBodyBytes {
+		return Submission{}, fmt.Errorf("body exceeds %d bytes", maxBodyBytes)
+	}
+
+	fields := map[string]string{}
+	mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mt == "application/x-www-form-urlencoded" {
+		r.Body = io.NopCloser(strings.NewReader(string(body)))
+		if err := r.ParseForm(); err != nil {
+			return Submission{}, fmt.Errorf("parse form: %w", err)
+		}
+		for k, v := range r.PostForm {
+			fields[strings.ToLower(k)] = strings.Join(v, ", ")
+		}
+	} else {
+		var raw map[string]json.RawMessage
+		if err := json.Unmarshal(body, &raw); err != nil {
+			return Submission{}, fmt.Errorf("parse JSON: %w", err)
+		}
+		if nv, ok := raw["namedValues"]; ok {
+			var named map[string][]string
+			if err := json.Unmarshal(nv, &named); err != nil {
+				return Submission{}, fmt.Errorf("parse namedValues: %w", err)
+			}
+			for k, v := range named {
+				fields[strings.ToLower(strings.TrimSpace(k))] = strings.Join(v, ", ")
+			}
+		} else {
+			for k, v := range raw {
+				var s string
+				if json.Unmarshal(v, &s) == nil {
+					fields[strings.ToLower(k)] = s
+				}
+			}
+		}
+	}
+
+	sub := Submission{
+		Subject:  strings.TrimSpace(fields["subject"]),
+		Audience: strings.TrimSpace(fields["audience"]),
+		Tone:     strings.TrimSpace(fields["tone"]),
+		Brief:    strings.TrimSpace(fields["brief"]),
+	}
+	if sub.Subject == "" {
+		return Submission{}, errors.New("subject is required")
+	}
+	return sub, nil
+}
+
+// BuildFunc builds a deck for the submission and returns its links. Its context isn't
+// cancelled when the client hangs up, so it sets its own time limit.
+type BuildFunc func(ctx context.Context, sub Submission) ([]string, error)
+
+// Reply is the JSON response body.
+type Reply struct {
+	DeckURL  string   `json:"deck_url,omitempty"` // the first of DeckURLs
+	DeckURLs []string `json:"deck_urls,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// Handler accepts POSTed submissions carrying the shared token and runs at most a fixed
+// number of builds at once; further submissions get 503 until one finishes.
+type Handler struct {
+	token string
+	build BuildFunc
+	slots chan struct{}
+}
+
+// NewHandler returns a handler requiring token (sent as "Authorization: Bearer <token>" or
+// an X-Webhook-Token header) and running up to maxBuilds builds at once (at least 1).
+func NewHandler(token string, maxBuilds int, build BuildFunc) *Handler {
+	if maxBuilds < 1 {
+		maxBuilds = 1
+	}
+	return &Handler{token: token, build: build, slots: make(chan struct{}, maxBuilds)}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		reply(w, http.StatusMethodNotAllowed, Reply{Error: "use POST"})
+		return
+	}
+	if !h.authorized(r) {
+		reply(w, http.StatusUnauthorized, Reply{Error: "missing or wrong token"})
+		return
+	}
+	sub, err := Parse(r)
+	if err != nil {
+		reply(w, http.StatusBadRequest, Reply{Error: err.Error()})
+		return
+	}
+
+	select {
+	case h.slots <- struct{}{}:
+		defer func() { <-h.slots }()
+	default:
+		w.Header().Set("Retry-After", "60")
+		reply(w, http.StatusServiceUnavailable, Reply{Error: "another build is running; retry later"})
+		return
+	}
+	// A client that stops waiting (Apps Script gives up after about 6 minutes) must not kill
+	// a build halfway through rewriting the deck; the build finishes and its slot stays taken
+	urls, err := h.build(context.WithoutCancel(r.Context()), sub)
+	if err != nil {
+		reply(w, http.StatusBadGateway, Reply{Error: err.Error()})
+		return
+	}
+	if len(urls) == 0 {
+		reply(w, http.StatusBadGateway, Reply{Error: "the build produced no deck"})
+		return
+	}
+	reply(w, http.StatusOK, Reply{DeckURL: urls[0], DeckURLs: urls})
+}
+
+func (h *Handler) authorized(r *http.Request) bool {
+	got := r.Header.Get("X-Webhook-Token")
+	if auth := r.Header.Get("Authorization"); got == "" && strings.HasPrefix(auth, "Bearer ") {
+		got = strings.TrimPrefix(auth, "Bearer ")
+	}
+	return h.token != "" && subtle.ConstantTimeCompare([]byte(got), []byte(h.token)) == 1
+}
+
+func reply(w http.ResponseWriter, status int, body Reply) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,138 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        Submission
+		wantErr     bool
+	}{
+		{
+			name:        "flat JSON",
+			contentType: "application/json",
+			body:        `{"subject": " Zero trust ", "audience": "IT", "tone": "practical", "brief": "Focus on MFA", "extra": 3}`,
+			want:        Submission{Subject: "Zero trust", Audience: "IT", Tone: "practical", Brief: "Focus on MFA"},
+		},
+		{
+			name:        "forms namedValues with titled questions",
+			contentType: "application/json",
+			body:        `{"namedValues": {"Subject": ["Quarterly KPIs"], "Audience ": ["Leadership"], "Timestamp": ["1/2/2026"]}}`,
+			want:        Submission{Subject: "Quarterly KPIs", Audience: "Leadership"},
+		},
+		{
+			name:        "url-encoded form",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "Subject=Onboarding&tone=friendly",
+			want:        Submission{Subject: "Onboarding", Tone: "friendly"},
+		},
+		{name: "missing subject", contentType: "application/json", body: `{"audience": "IT"}`, wantErr: true},
+		{name: "not JSON", contentType: "application/json", body: `subject=x`, wantErr: true},
+		{name: "too large", contentType: "application/json", body: `{"subject": "` + strings.Repeat("a", maxBodyBytes) + `"}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			got, err := Parse(req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandler(t *testing.T) {
+	const deck = "https://docs.google.com/presentation/d/abc/edit"
+	tests := []struct {
+		name       string
+		method     string
+		header     map[string]string
+		body       string
+		buildErr   error
+		busy       bool
+		wantStatus int
+		wantURL    string
+	}{
+		{name: "bearer token", method: http.MethodPost, header: map[string]string{"Authorization": "Bearer t0ken"}, body: `{"subject": "x"}`, wantStatus: http.StatusOK, wantURL: deck},
+		{name: "token header", method: http.MethodPost, header: map[string]string{"X-Webhook-Token": "t0ken"}, body: `{"subject": "x"}`, wantStatus: http.StatusOK, wantURL: deck},
+		{name: "wrong token", method: http.MethodPost, header: map[string]string{"Authorization": "Bearer nope"}, body: `{"subject": "x"}`, wantStatus: http.StatusUnauthorized},
+		{name: "GET", method: http.MethodGet, wantStatus: http.StatusMethodNotAllowed},
+		{name: "bad submission", method: http.MethodPost, header: map[string]string{"X-Webhook-Token": "t0ken"}, body: `{}`, wantStatus: http.StatusBadRequest},
+		{name: "build fails", method: http.MethodPost, header: map[string]string{"X-Webhook-Token": "t0ken"}, body: `{"subject": "x"}`, buildErr: errors.New("quota"), wantStatus: http.StatusBadGateway},
+		{name: "busy", method: http.MethodPost, header: map[string]string{"X-Webhook-Token": "t0ken"}, body: `{"subject": "x"}`, busy: true, wantStatus: http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler("t0ken", 1, func(ctx context.Context, sub Submission) ([]string, error) {
+				if tt.buildErr != nil {
+					return nil, tt.buildErr
+				}
+				return []string{deck}, nil
+			})
+			if tt.busy {
+				h.slots <- struct{}{}
+			}
+			req := httptest.NewRequest(tt.method, "/", strings.NewReader(tt.body))
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, rec.Body)
+			}
+			var got Reply
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if got.DeckURL != tt.wantURL {
+				t.Errorf("deck_url = %q, want %q", got.DeckURL, tt.wantURL)
+			}
+			if tt.wantStatus != http.StatusOK && got.Error == "" {
+				t.Error("error reply has no error message")
+			}
+		})
+	}
+}
+
+func TestHandler_ClientGone(t *testing.T) {
+	reqCtx, cancel := context.WithCancel(context.Background())
+	var buildErr error
+	h := NewHandler("t0ken", 1, func(ctx context.Context, sub Submission) ([]string, error) {
+		cancel() // the client hangs up mid-build
+		buildErr = ctx.Err()
+		return []string{"u"}, nil
+	})
+	req := httptest.NewRequestWithContext(reqCtx, http.MethodPost, "/", strings.NewReader(`{"subject": "x"}`))
+	req.Header.Set("X-Webhook-Token", "t0ken")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	if buildErr != nil {
+		t.Errorf("build context error = %v after the client left, want the build to go on", buildErr)
+	}
+}
+
+func TestHandler_NoTokenConfigured(t *testing.T) {
+	h := NewHandler("", 1, func(context.Context, Submission) ([]string, error) { return []string{"u"}, nil })
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"subject": "x"}`))
+	req.Header.Set("Authorization", "Bearer ")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401 when no token is configured", rec.Code)
+	}
+}
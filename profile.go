@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/joho/godotenv"
+)
+
+// profileName keeps profile names to what's safe in a file name.
+var profileName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// takeProfile removes --profile NAME (or --profile=NAME) from args, wherever it sits
+// among a command's flags, and returns the name, falling back to $SLIDES_PROFILE. It
+// stops at "--", after which args belong to another command.
+func takeProfile(args []string) (string, []string, error) {
+	name := os.Getenv("SLIDES_PROFILE")
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if a == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		switch {
+		case a == "--profile" || a == "-profile":
+			if i+1 >= len(args) {
+				return "", nil, fmt.Errorf("--profile needs a name")
+			}
+			name = args[i+1]
+			i++
+		case strings.HasPrefix(a, "--profile=") || strings.HasPrefix(a, "-profile="):
+			name = a[strings.IndexByte(a, '=')+1:]
+		default:
+			rest = append(rest, a)
+		}
+	}
+	if name = strings.TrimSpace(name); name != "" && !profileName.MatchString(name) {
+		return "", nil, fmt.Errorf("profile %q: use letters, digits, - and _", name)
+	}
+	return name, rest, nil
+}
+
+// profileVars are the variables the loaded profile file sets; nil without a profile.
+var profileVars map[string]string
+
+// loadProfile loads .env.<name> over the environment, so the profile's credentials and
+// decks win over both the shell and .env, which is loaded afterwards and only fills
+// what's still unset. A missing profile file is an error rather than a silent fall back
+// to whatever deck the shell points at.
+func loadProfile(name string) error {
+	if name == "" {
+		return nil
+	}
+	path := ".env." + name
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("profile %s: %w", name, err)
+	}
+	vars, err := godotenv.Read(path)
+	if err != nil {
+		return fmt.Errorf("profile %s: %w", name, err)
+	}
+	if err := godotenv.Overload(path); err != nil {
+		return fmt.Errorf("profile %s: %w", name, err)
+	}
+	profileVars = vars
+	return os.Setenv("SLIDES_PROFILE", name) // seen by commands that serve and schedule start
+}
+
+// profileValue is what the loaded profile file sets key to. Deck and spreadsheet defaults
+// come only from there: a PRESENTATION_ID left in the shell or a plain .env never picks
+// the deck a bare run rewrites or cleanup wipes.
+func profileValue(key string) string {
+	return profileVars[key]
+}
+
+// profileIDs splits a comma-separated ID list of the loaded profile, e.g. PRESENTATION_ID.
+func profileIDs(key string) []string {
+	var ids []string
+	for _, id := range strings.Split(profileValue(key), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
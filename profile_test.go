@@ -0,0 +1,81 @@
+package main
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestTakeProfile(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      string // $SLIDES_PROFILE
+		args     []string
+		want     string
+		wantRest []string
+		wantErr  bool
+	}{
+		{name: "separate value", args: []string{"--subject", "x", "--profile", "staging"}, want: "staging", wantRest: []string{"--subject", "x"}},
+		{name: "equals", args: []string{"cleanup", "--profile=prod", "--sheet-id", "s"}, want: "prod", wantRest: []string{"cleanup", "--sheet-id", "s"}},
+		{name: "single dash", args: []string{"-profile", "dev"}, want: "dev", wantRest: []string{}},
+		{name: "later wins", args: []string{"--profile", "dev", "--profile=prod"}, want: "prod", wantRest: []string{}},
+		{name: "missing name", args: []string{"--subject", "x", "--profile"}, wantErr: true},
+		{name: "stops at --", args: []string{"serve", "--", "--profile", "prod"}, want: "", wantRest: []string{"serve", "--", "--profile", "prod"}},
+		{name: "invalid name", args: []string{"--profile", "../prod"}, wantErr: true},
+		{name: "empty after equals", args: []string{"--profile="}, want: "", wantRest: []string{}},
+		{name: "from env", env: "staging", args: []string{"--subject", "x"}, want: "staging", wantRest: []string{"--subject", "x"}},
+		{name: "flag over env", env: "staging", args: []string{"--profile", "dev"}, want: "dev", wantRest: []string{}},
+		{name: "invalid env name", env: "prod env", args: nil, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("SLIDES_PROFILE", tt.env)
+			got, rest, err := takeProfile(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("takeProfile(%q) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got != tt.want || !reflect.DeepEqual(rest, tt.wantRest) {
+				t.Errorf("takeProfile(%q) = %q, %q; want %q, %q", tt.args, got, rest, tt.want, tt.wantRest)
+			}
+		})
+	}
+}
+
+func TestLoadProfile(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("SLIDES_PROFILE", "")
+	t.Setenv("PRESENTATION_ID", "prod-deck") // left in the shell
+	t.Setenv("SHEET_ID", "prod-sheet")
+	t.Setenv("GOOGLE_CLOUD_PROJECT", "") // restored after the profile sets it
+	defer func() { profileVars = nil }()
+
+	profileVars = nil
+	if ids := profileIDs("PRESENTATION_ID"); len(ids) != 0 {
+		t.Errorf("profileIDs() without a profile = %q, want none from the shell", ids)
+	}
+	if err := loadProfile("missing"); err == nil {
+		t.Error("loadProfile() of a missing profile: want an error")
+	}
+
+	if err := os.WriteFile(".env.staging", []byte("PRESENTATION_ID=deck-a, deck-b\nGOOGLE_CLOUD_PROJECT=staging-project\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := loadProfile("staging"); err != nil {
+		t.Fatalf("loadProfile() error = %v", err)
+	}
+	if got := profileIDs("PRESENTATION_ID"); !reflect.DeepEqual(got, []string{"deck-a", "deck-b"}) {
+		t.Errorf("profileIDs() = %q, want the profile's decks", got)
+	}
+	if got := profileValue("SHEET_ID"); got != "" {
+		t.Errorf("profileValue(SHEET_ID) = %q, want none: the profile doesn't set it", got)
+	}
+	if got := os.Getenv("GOOGLE_CLOUD_PROJECT"); got != "staging-project" {
+		t.Errorf("$GOOGLE_CLOUD_PROJECT = %q, want the profile's", got)
+	}
+	if got := os.Getenv("SLIDES_PROFILE"); got != "staging" {
+		t.Errorf("$SLIDES_PROFILE = %q, want staging for child runs", got)
+	}
+}
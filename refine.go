@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gogemini-practices/internal/picturegen"
+)
+
+// refinedImage is one edit in the refine command's JSON output.
+type refinedImage struct {
+	Instruction string `json:"instruction"`
+	Path        string `json:"path"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+}
+
+// runRefine implements the refine command: it sends a slide image and each instruction in
+// turn to the image model as one conversation, so every edit builds on the last, and
+// writes the result of each turn. Instructions come from repeated --instruction flags or,
+// without any, one per line of stdin as they are typed.
+func runRefine(args []string) error {
+	fs := flag.NewFlagSet("refine", flag.ExitOnError)
+	imagePath := fs.String("image", "", "Image to refine, e.g. a generated slide image (PNG, JPEG, GIF, or WebP)")
+	var instructions stringList
+	fs.Var(&instructions, "instruction", "Edit to apply, e.g. \"make it blue-toned, add a laptop\" (repeatable; applied in order; default one per line of stdin)")
+	out := fs.String("out", "", "Path prefix of the refined images: turn n is written to <out>-<n>.png (default <image without extension>-refined)")
+	aspect := fs.String("aspect", "", "Crop results to this aspect ratio (16:9|4:3|1:1); empty keeps the model's framing")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *imagePath == "" {
+		return invalidInput("refine needs --image")
+	}
+	data, err := os.ReadFile(*imagePath)
+	if err != nil {
+		return invalidInput("read image: %w", err)
+	}
+	apiKey := firstNonEmpty(os.Getenv("GOOGLE_API_KEY"), os.Getenv("GEMINI_API_KEY"))
+	if apiKey == "" {
+		return fmt.Errorf("%w: GOOGLE_API_KEY or GEMINI_API_KEY not set", ErrAuth)
+	}
+	stem := strings.TrimSuffix(*out, filepath.Ext(*out))
+	if *out == "" {
+		stem = strings.TrimSuffix(*imagePath, filepath.Ext(*imagePath)) + "-refined"
+	}
+
+	ctx := context.Background()
+	session, err := picturegen.NewEditSession(ctx, data, apiKey, picturegen.ImageConfig{AspectRatio: picturegen.AspectRatio(*aspect)})
+	if err != nil {
+		return invalidInput("%w", err)
+	}
+	var results []refinedImage
+	apply := func(instruction string) error {
+		img, err := session.Edit(ctx, instruction)
+		if err != nil {
+			return refineError(err)
+		}
+		path := fmt.Sprintf("%s-%d.png", stem, session.Turns())
+		if err := os.WriteFile(path, img.Data, 0o644); err != nil {
+			return fmt.Errorf("write refined image: %w", err)
+		}
+		log.Printf("turn %d: %s", session.Turns(), path)
+		results = append(results, refinedImage{Instruction: instruction, Path: path, Width: img.Width, Height: img.Height})
+		return nil
+	}
+	if len(instructions) > 0 {
+		for _, in := range instructions {
+			if err := apply(in); err != nil {
+				return err
+			}
+		}
+	} else {
+		log.Printf("type one edit per line; end with Ctrl-D")
+		if err := refineLines(os.Stdin, apply); err != nil {
+			return err
+		}
+	}
+	if len(results) == 0 {
+		return invalidInput("refine needs at least one --instruction or line of stdin")
+	}
+	b, err := json.MarshalIndent(struct {
+		Images []refinedImage `json:"images"`
+	}{results}, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(b))
+	return nil
+}
+
+// refineLines calls apply with each non-empty line of r. In an interactive session a
+// refused or failed edit is logged and the next line edits the same image.
+func refineLines(r io.Reader, apply func(string) error) error {
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		if err := apply(line); err != nil {
+			if errors.Is(err, ErrQuota) {
+				return err
+			}
+			log.Printf("warning: %v; try another edit", err)
+		}
+	}
+	return sc.Err()
+}
+
+// refineError classifies an image model failure for the exit code: quota as ErrQuota,
+// safety blocks and empty answers as ErrModelOutput.
+func refineError(err error) error {
+	switch {
+	case errors.Is(err, picturegen.ErrQuota):
+		return fmt.Errorf("%w: %w", ErrQuota, err)
+	case errors.Is(err, picturegen.ErrSafety), errors.Is(err, picturegen.ErrModel):
+		return fmt.Errorf("%w: %w", ErrModelOutput, err)
+	}
+	return err
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"gogemini-practices/internal/schedule"
+)
+
+// ScheduleConfig is the schedule command's config file.
+type ScheduleConfig struct {
+	Timezone string        `json:"timezone,omitempty"` // IANA name the cron specs are read in; empty is local time
+	Jobs     []ScheduleJob `json:"jobs"`
+}
+
+// ScheduleJob is one recurring build: Args are the build flags (at least --subject, plus the
+// deck to rewrite), run whenever Cron matches.
+type ScheduleJob struct {
+	Name string   `json:"name"`
+	Cron string   `json:"cron"`
+	Args []string `json:"args"`
+}
+
+// scheduledJob is a job with its parsed spec and next run.
+type scheduledJob struct {
+	ScheduleJob
+	spec schedule.Spec
+	next time.Time
+}
+
+// runSchedule implements the schedule command: it rebuilds each configured deck whenever its
+// cron spec matches, passing the run date as --as-of so the slide footers show it, until
+// interrupted. Jobs run one at a time; a run missed while another build was running is
+// skipped, not queued.
+func runSchedule(args []string) error {
+	fs := flag.NewFlagSet("schedule", flag.ExitOnError)
+	configPath := fs.String("config", "", "Path to the schedule JSON (required)")
+	runNow := fs.Bool("run-now", false, "Run every job once at startup, then keep to the schedule")
+	timeout := fs.Duration("timeout", 30*time.Minute, "Time limit for one build")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	jobs, loc, err := loadSchedule(*configPath)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	run := func(j *scheduledJob, at time.Time) {
+		args := append([]string{}, j.Args...)
+		if !hasFlag(args, "as-of") {
+			args = append(args, "--as-of", at.Format(time.DateOnly))
+		}
+		bctx, cancel := context.WithTimeout(ctx, *timeout)
+		defer cancel()
+		if _, err := runBuild(bctx, j.Name, args, ""); err != nil {
+			log.Printf("%s: %v", j.Name, err)
+		}
+	}
+
+	now := time.Now().In(loc)
+	for i := range jobs {
+		if *runNow {
+			run(&jobs[i], now)
+		}
+		jobs[i].next = jobs[i].spec.Next(time.Now().In(loc))
+		log.Printf("%s: next run %s", jobs[i].Name, jobs[i].next.Format(time.RFC1123))
+	}
+	for {
+		var due *scheduledJob
+		for i := range jobs {
+			if !jobs[i].next.IsZero() && (due == nil || jobs[i].next.Before(due.next)) {
+				due = &jobs[i]
+			}
+		}
+		if due == nil {
+			return fmt.Errorf("no job has a future run")
+		}
+		select {
+		case <-ctx.Done():
+			log.Print("schedule stopped")
+			return nil
+		case <-time.After(time.Until(due.next)):
+		}
+		run(due, due.next)
+		due.next = due.spec.Next(time.Now().In(loc))
+		log.Printf("%s: next run %s", due.Name, due.next.Format(time.RFC1123))
+	}
+}
+
+// loadSchedule reads and checks the schedule config.
+func loadSchedule(path string) ([]scheduledJob, *time.Location, error) {
+	if path == "" {
+		return nil, nil, fmt.Errorf("schedule needs --config")
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read schedule: %w", err)
+	}
+	var cfg ScheduleConfig
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return nil, nil, fmt.Errorf("parse schedule %s: %w", path, err)
+	}
+	loc := time.Local
+	if cfg.Timezone != "" {
+		if loc, err = time.LoadLocation(cfg.Timezone); err != nil {
+			return nil, nil, fmt.Errorf("schedule timezone: %w", err)
+		}
+	}
+	if len(cfg.Jobs) == 0 {
+		return nil, nil, fmt.Errorf("schedule %s has no jobs", path)
+	}
+	jobs := make([]scheduledJob, len(cfg.Jobs))
+	for i, j := range cfg.Jobs {
+		if j.Name == "" {
+			j.Name = fmt.Sprintf("job %d", i+1)
+		}
+		spec, err := schedule.Parse(j.Cron)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", j.Name, err)
+		}
+		if !hasFlag(j.Args, "subject") {
+			return nil, nil, fmt.Errorf("%s: args need --subject", j.Name)
+		}
+		if !hasFlag(j.Args, "presentation-id") && !hasFlag(j.Args, "targets") && !hasFlag(j.Args, "template-presentation-id") {
+			return nil, nil, fmt.Errorf("%s: args need a deck (--presentation-id, --targets, or --template-presentation-id)", j.Name)
+		}
+		jobs[i] = scheduledJob{ScheduleJob: j, spec: spec}
+	}
+	return jobs, loc, nil
+}
+
+// hasFlag reports whether args set the named flag, as -name, --name, or --name=value.
+func hasFlag(args []string, name string) bool {
+	for _, a := range args {
+		if strings.SplitN(strings.TrimLeft(a, "-"), "=", 2)[0] == name && strings.HasPrefix(a, "-") {
+			return true
+		}
+	}
+	return false
+}
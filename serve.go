@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"gogemini-practices/internal/webhook"
+)
+
+// deckLink matches the deck links a build logs.
+var deckLink = regexp.MustCompile(`https://docs\.google\.com/presentation/d/[\w-]+/edit`)
+
+// runServe implements the serve command: an HTTP receiver that turns each authorized Forms
+// or webhook submission into a build of this binary. Arguments after "--" are passed to
+// every build (e.g. --template-presentation-id or --presentation-id and --sheet-id), with
+// the submission's subject, audience, tone, and brief added.
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", ":8080", "Address to listen on")
+	token := fs.String("token", "", "Shared secret submissions must send as a bearer token or X-Webhook-Token header (default $WEBHOOK_TOKEN)")
+	maxBuilds := fs.Int("max-builds", 1, "Builds run at once; further submissions get 503 until one finishes")
+	timeout := fs.Duration("timeout", 10*time.Minute, "Time limit for one build")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	buildArgs := fs.Args()
+	secret := firstNonEmpty(*token, os.Getenv("WEBHOOK_TOKEN"))
+	if secret == "" {
+		return fmt.Errorf("serve needs --token or WEBHOOK_TOKEN")
+	}
+	for _, name := range []string{"subject", "audience", "tone", "brief", "plan-only", "regen-topic"} {
+		if hasFlag(buildArgs, name) {
+			return fmt.Errorf("--%s is set per submission or not supported by serve", name)
+		}
+	}
+
+	build := func(ctx context.Context, sub webhook.Submission) ([]string, error) {
+		ctx, cancel := context.WithTimeout(ctx, *timeout)
+		defer cancel()
+		args := append([]string{}, buildArgs...)
+		args = append(args, "--subject", sub.Subject, "--audience", sub.Audience, "--tone", sub.Tone)
+		if sub.Brief != "" {
+			args = append(args, "--brief", "-")
+		}
+		return runBuild(ctx, sub.Subject, args, sub.Brief)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/build", webhook.NewHandler(secret, *maxBuilds, build))
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) { fmt.Fprintln(w, "ok") })
+	srv := &http.Server{Addr: *addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	log.Printf("listening on %s; POST submissions to /build", *addr)
+	return srv.ListenAndServe()
+}
+
+// runBuild runs this binary with args (and stdin, when not empty) and returns the deck links
+// the build logged. The build's log is written to this log only when it fails.
+func runBuild(ctx context.Context, name string, args []string, stdin string) ([]string, error) {
+	self, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("find executable: %w", err)
+	}
+	cmd := exec.CommandContext(ctx, self, args...)
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	started := time.Now()
+	log.Printf("building %q", name)
+	err = cmd.Run()
+	logs := stderr.String()
+	if err != nil {
+		log.Printf("build %q failed after %s: %v\n%s", name, time.Since(started).Round(time.Second), err, logs)
+		var exit *exec.ExitError
+		if errors.As(err, &exit) {
+			return nil, fmt.Errorf("build failed (%s): %v", exitClass(exit.ExitCode()), err)
+		}
+		return nil, fmt.Errorf("build failed: %v", err)
+	}
+	var urls []string
+	seen := map[string]bool{}
+	for _, u := range deckLink.FindAllString(logs, -1) {
+		if !seen[u] {
+			seen[u] = true
+			urls = append(urls, u)
+		}
+	}
+	log.Printf("built %q in %s: %s", name, time.Since(started).Round(time.Second), strings.Join(urls, " "))
+	return urls, nil
+}
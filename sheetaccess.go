@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"gogemini-practices/internal/presentation"
+	"gogemini-practices/internal/sharing"
+)
+
+// alignSheetAccess runs the --sheet-access step for a target before its deck is written:
+// it finds the deck's viewers who can't open the target's spreadsheet and, by mode, reports
+// them, grants them reader access, or switches the deck to unlinked chart images. A failed
+// check is logged and the deck is written as usual.
+func alignSheetAccess(ctx context.Context, api sharing.PermissionsAPI, mode string, tg Target, deckOpts *presentation.DeckOptions) {
+	missing, err := sharing.CheckAccess(ctx, api, tg.PresentationID, tg.SheetID)
+	if err != nil {
+		log.Printf("warning: %s: sheet access check: %v", tg.PresentationID, err)
+		return
+	}
+	if len(missing) == 0 {
+		return
+	}
+	names := make([]string, len(missing))
+	for i, p := range missing {
+		names[i] = sharing.Describe(p)
+	}
+	who := strings.Join(names, ", ")
+	switch mode {
+	case sharing.Check:
+		log.Printf("warning: %s: linked charts won't load for viewers without access to spreadsheet %s: %s (use --sheet-access=grant or --sheet-access=image)", tg.PresentationID, tg.SheetID, who)
+	case sharing.Grant:
+		if err := sharing.GrantAccess(ctx, api, tg.SheetID, missing); err != nil {
+			log.Printf("warning: %s: %v; embedding chart images instead", tg.PresentationID, err)
+			deckOpts.UnlinkedCharts = true
+			return
+		}
+		log.Printf("%s: gave %s read access to spreadsheet %s", tg.PresentationID, who, tg.SheetID)
+	case sharing.Image:
+		log.Printf("%s: embedding chart images, since %s can't open spreadsheet %s", tg.PresentationID, who, tg.SheetID)
+		deckOpts.UnlinkedCharts = true
+	}
+}
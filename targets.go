@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"gogemini-practices/internal/imagesearch"
+	"gogemini-practices/internal/palette"
+)
+
+// Target is one deck the plan is written to. Empty override fields fall back to the
+// matching command-line flag.
+type Target struct {
+	PresentationID  string           `json:"presentation_id"`
+	SheetID         string           `json:"sheet_id,omitempty"`
+	ImageQuery      string           `json:"image_query,omitempty"` // appended to each topic's image query, e.g. a region
+	ImgDominant     string           `json:"img_dominant,omitempty"`
+	Region          string           `json:"region,omitempty"`   // image search country, e.g. for a regional edition
+	Language        string           `json:"language,omitempty"` // image search language
+	DefaultImageURL string           `json:"default_image_url,omitempty"`
+	WatermarkLogo   string           `json:"watermark_logo,omitempty"`
+	WatermarkText   string           `json:"watermark_text,omitempty"`
+	Palette         *palette.Palette `json:"palette,omitempty"` // replaces the generated palette
+}
+
+// stringList is a repeatable string flag.
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+// loadTargets combines repeated --presentation-id values with the decks listed in the
+// --targets JSON file (an array of Target). Flag IDs use the --sheet-id spreadsheet; a
+// deck listed twice is an error, since the second write would wipe the first.
+func loadTargets(ids []string, path, sheetID string) ([]Target, error) {
+	var targets []Target
+	for _, id := range ids {
+		if id = strings.TrimSpace(id); id != "" {
+			targets = append(targets, Target{PresentationID: id, SheetID: sheetID})
+		}
+	}
+	if path != "" {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read targets: %w", err)
+		}
+		var fromFile []Target
+		if err := json.Unmarshal(b, &fromFile); err != nil {
+			return nil, fmt.Errorf("parse targets %s: %w", path, err)
+		}
+		for i, t := range fromFile {
+			t.PresentationID = strings.TrimSpace(t.PresentationID)
+			if t.PresentationID == "" {
+				return nil, fmt.Errorf("targets %s: entry %d has no presentation_id", path, i+1)
+			}
+			if err := imagesearch.CheckLocale(t.Region, t.Language); err != nil {
+				return nil, fmt.Errorf("targets %s: entry %d: %w", path, i+1, err)
+			}
+			if t.SheetID == "" {
+				t.SheetID = sheetID
+			}
+			if t.Palette != nil {
+				t.Palette.Normalize()
+			}
+			targets = append(targets, t)
+		}
+	}
+	seen := map[string]bool{}
+	for _, t := range targets {
+		if seen[t.PresentationID] {
+			return nil, fmt.Errorf("presentation %s is targeted twice", t.PresentationID)
+		}
+		seen[t.PresentationID] = true
+	}
+	return targets, nil
+}
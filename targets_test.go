@@ -0,0 +1,89 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gogemini-practices/internal/palette"
+)
+
+func TestLoadTargets(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	tests := []struct {
+		name    string
+		ids     []string
+		file    string // targets file content; empty for no file
+		want    []Target
+		wantErr bool
+	}{
+		{name: "flag IDs use --sheet-id", ids: []string{" deck-a ", "", "deck-b"}, want: []Target{{PresentationID: "deck-a", SheetID: "sheet"}, {PresentationID: "deck-b", SheetID: "sheet"}}},
+		{
+			name: "file entries keep overrides and default the sheet",
+			ids:  []string{"deck-a"},
+			file: `[{"presentation_id": " deck-eu ", "region": "de", "language": "de", "image_query": "Berlin"}, {"presentation_id": "deck-us", "sheet_id": "us-sheet", "default_image_url": "https://example.com/us.png"}]`,
+			want: []Target{
+				{PresentationID: "deck-a", SheetID: "sheet"},
+				{PresentationID: "deck-eu", SheetID: "sheet", Region: "de", Language: "de", ImageQuery: "Berlin"},
+				{PresentationID: "deck-us", SheetID: "us-sheet", DefaultImageURL: "https://example.com/us.png"},
+			},
+		},
+		{name: "empty file", file: `[]`, want: nil},
+		{name: "malformed JSON", file: `[{"presentation_id": "deck-a"`, wantErr: true},
+		{name: "not an array", file: `{"presentation_id": "deck-a"}`, wantErr: true},
+		{name: "entry without a deck", file: `[{"sheet_id": "s"}]`, wantErr: true},
+		{name: "blank deck", file: `[{"presentation_id": "  "}]`, wantErr: true},
+		{name: "bad region", file: `[{"presentation_id": "deck-a", "region": "Germany"}]`, wantErr: true},
+		{name: "bad language", file: `[{"presentation_id": "deck-a", "language": "de_DE"}]`, wantErr: true},
+		{name: "same deck twice in the file", file: `[{"presentation_id": "deck-a"}, {"presentation_id": "deck-a", "sheet_id": "other"}]`, wantErr: true},
+		{name: "same deck in flag and file", ids: []string{"deck-a"}, file: `[{"presentation_id": " deck-a"}]`, wantErr: true},
+		{name: "same deck twice by flag", ids: []string{"deck-a", "deck-a "}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := ""
+			if tt.file != "" {
+				path = write(filepath.Base(t.Name())+".json", tt.file)
+			}
+			got, err := loadTargets(tt.ids, path, "sheet")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadTargets() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("loadTargets() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadTargets_MissingFile(t *testing.T) {
+	if _, err := loadTargets(nil, filepath.Join(t.TempDir(), "missing.json"), ""); err == nil {
+		t.Error("loadTargets() of a missing file: want an error")
+	}
+}
+
+func TestLoadTargets_Palette(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "targets.json")
+	if err := os.WriteFile(path, []byte(`[{"presentation_id": "deck-a", "palette": {"primary": "1a73e8", "background": "not a color"}}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := loadTargets(nil, path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := got[0].Palette
+	if p == nil || p.Background != palette.Default().Background {
+		t.Fatalf("palette = %+v, want an invalid background replaced by the default's", p)
+	}
+	if _, _, _, err := palette.RGB(p.Primary); err != nil {
+		t.Errorf("primary %q isn't a normalized color: %v", p.Primary, err)
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"gogemini-practices/internal/charts"
+)
+
+// UnmarshalJSON accepts each value as a number or as text such as "$1.2B" or "1200 million",
+// which normalizeUnits converts once the dataset's unit is known.
+func (p *DataPoint) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Label  string            `json:"label"`
+		Value  json.RawMessage   `json:"value"`
+		Values []json.RawMessage `json:"values"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	*p = DataPoint{Label: raw.Label}
+	var err error
+	if p.Value, p.text, err = rawNumber(raw.Value); err != nil {
+		return fmt.Errorf("point %q value: %w", raw.Label, err)
+	}
+	for i, r := range raw.Values {
+		v, text, err := rawNumber(r)
+		if err != nil {
+			return fmt.Errorf("point %q value %d: %w", raw.Label, i+1, err)
+		}
+		p.Values = append(p.Values, v)
+		if text != "" {
+			if p.texts == nil {
+				p.texts = make([]string, len(raw.Values))
+			}
+			p.texts[i] = text
+		}
+	}
+	return nil
+}
+
+// rawNumber reads a JSON number, or keeps a string for normalizeUnits and returns NaN for
+// it so a value never normalized is dropped. A missing value or null is 0.
+func rawNumber(r json.RawMessage) (float64, string, error) {
+	r = bytes.TrimSpace(r)
+	if len(r) == 0 || string(r) == "null" {
+		return 0, "", nil
+	}
+	if r[0] == '"' {
+		var s string
+		if err := json.Unmarshal(r, &s); err != nil {
+			return 0, "", err
+		}
+		return math.NaN(), s, nil
+	}
+	var v float64
+	if err := json.Unmarshal(r, &v); err != nil {
+		return 0, "", err
+	}
+	return v, "", nil
+}
+
+// normalizeUnits brings a dataset's values to one scale. Each value is converted to base
+// units: text values by their own magnitude ("$1.2B", "1200 million"), and numbers, or text
+// without one, by the magnitude in the dataset unit ("billion USD"). Then one magnitude is
+// picked for all of them (see charts.Scale) and written into the unit, which titles the
+// chart's value axis: 1.2 and 0.8 "billion USD". AxisMin is scaled with them. The unit is
+// taken from the values when the dataset has none. Text that isn't a figure becomes NaN and
+// is dropped by sanitizeDataset, which cuts the points to those charted first, so points
+// past the cut don't pick the scale. Datasets charted from a --sheet-range keep the
+// spreadsheet's values.
+func normalizeUnits(d *Dataset) {
+	if d == nil || d.Range != "" {
+		return
+	}
+	factor, unit := charts.SplitScale(d.Unit)
+	base := func(v float64, text string) float64 {
+		if text == "" {
+			return v * factor
+		}
+		n, scale, u, ok := charts.ParseQuantity(text)
+		if !ok {
+			return math.NaN()
+		}
+		if unit == "" {
+			unit = u
+		}
+		if scale == 1 {
+			scale = factor
+		}
+		return n * scale
+	}
+	var all []float64
+	for i := range d.Points {
+		p := &d.Points[i]
+		p.Value = base(p.Value, p.text)
+		all = append(all, p.Value)
+		for j := range p.Values {
+			text := ""
+			if j < len(p.texts) {
+				text = p.texts[j]
+			}
+			p.Values[j] = base(p.Values[j], text)
+			all = append(all, p.Values[j])
+		}
+		p.text, p.texts = "", nil
+	}
+	if d.AxisMin != nil {
+		m := *d.AxisMin * factor
+		d.AxisMin = &m
+	}
+	var finite []float64
+	for _, v := range all {
+		if !math.IsNaN(v) && !math.IsInf(v, 0) {
+			finite = append(finite, v)
+		}
+	}
+	scale, word := charts.Scale(finite)
+	if scale == 1 && factor == 1 && unit == strings.TrimSpace(d.Unit) {
+		return
+	}
+	round := func(v float64) float64 { return math.Round(v/scale*1e4) / 1e4 }
+	for i := range d.Points {
+		p := &d.Points[i]
+		p.Value = round(p.Value)
+		for j := range p.Values {
+			p.Values[j] = round(p.Values[j])
+		}
+	}
+	if d.AxisMin != nil {
+		m := round(*d.AxisMin)
+		d.AxisMin = &m
+	}
+	d.Unit = charts.ScaledUnit(word, unit)
+}
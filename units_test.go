@@ -0,0 +1,30 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// TestSanitizeDataset_ScaleFromChartedPoints checks that the points cut past the 20 charted
+// ones don't pick the dataset's scale.
+func TestSanitizeDataset_ScaleFromChartedPoints(t *testing.T) {
+	var points []string
+	for i := 1; i <= 20; i++ {
+		points = append(points, fmt.Sprintf(`{"label":"m%d","value":"$%d million"}`, i, i))
+	}
+	points = append(points, `{"label":"dropped","value":"$900 billion"}`)
+	var topic TopicSummary
+	if err := json.Unmarshal([]byte(`{"topic":"Revenue","dataset":{"title":"Revenue","points":[`+strings.Join(points, ",")+`]}}`), &topic); err != nil {
+		t.Fatal(err)
+	}
+	sanitizeDataset(&topic)
+	d := topic.Dataset
+	if d == nil || len(d.Points) != 20 {
+		t.Fatalf("dataset = %+v, want 20 points", d)
+	}
+	if !strings.Contains(d.Unit, "million") || d.Points[19].Value != 20 {
+		t.Errorf("unit %q, last value %g; want millions, 20", d.Unit, d.Points[19].Value)
+	}
+}
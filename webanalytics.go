@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gogemini-practices/internal/debugdump"
+	"gogemini-practices/internal/pipeline"
+	"gogemini-practices/internal/webdata"
+
+	"google.golang.org/api/analyticsdata/v1beta"
+	"google.golang.org/api/searchconsole/v1"
+)
+
+// loadWebData fetches the --web-data datasets from Google Analytics and Search Console over
+// the --web-range days. Like warehouse queries, any failure fails the run.
+func loadWebData(ctx context.Context, property, site string, names []string, rangeSpec string, dumper *debugdump.Dumper, workers int) ([]sourceData, error) {
+	var wanted []string
+	for _, n := range names {
+		for _, name := range strings.Split(n, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				wanted = append(wanted, name)
+			}
+		}
+	}
+	if len(wanted) == 0 {
+		return nil, nil
+	}
+	r, err := webdata.ParseRange(rangeSpec, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	needs := map[string]bool{}
+	for _, name := range wanted {
+		source, err := webdata.Source(name)
+		if err != nil {
+			return nil, err
+		}
+		needs[source] = true
+	}
+	if needs[webdata.Analytics] && property == "" {
+		return nil, fmt.Errorf("--web-data ga:* datasets need --ga-property")
+	}
+	if needs[webdata.SearchConsole] && site == "" {
+		return nil, fmt.Errorf("--web-data gsc:* datasets need --gsc-site")
+	}
+
+	credsPath := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	if credsPath == "" {
+		return nil, fmt.Errorf("web analytics datasets need GOOGLE_APPLICATION_CREDENTIALS")
+	}
+	credsBytes, err := os.ReadFile(credsPath)
+	if err != nil {
+		return nil, fmt.Errorf("read creds: %w", err)
+	}
+	client := webdata.Client{Property: property, Site: site}
+	if needs[webdata.Analytics] {
+		opts, err := clientOptions(ctx, credsBytes, os.Getenv("GOOGLE_IMPERSONATE_USER"), dumper, analyticsdata.AnalyticsReadonlyScope)
+		if err != nil {
+			return nil, err
+		}
+		svc, err := analyticsdata.NewService(ctx, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("analyticsdata.NewService: %w", err)
+		}
+		client.Analytics = webdata.NewAnalyticsAPI(svc)
+	}
+	if needs[webdata.SearchConsole] {
+		opts, err := clientOptions(ctx, credsBytes, os.Getenv("GOOGLE_IMPERSONATE_USER"), dumper, searchconsole.WebmastersReadonlyScope)
+		if err != nil {
+			return nil, err
+		}
+		svc, err := searchconsole.NewService(ctx, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("searchconsole.NewService: %w", err)
+		}
+		client.SearchConsole = webdata.NewSearchConsoleAPI(svc)
+	}
+
+	return pipeline.Map(ctx, len(wanted), workers, func(ctx context.Context, i int) (sourceData, error) {
+		ds, err := client.Fetch(ctx, wanted[i], r)
+		if err != nil {
+			return sourceData{}, err
+		}
+		ds.Source = "Google Analytics"
+		if source, _ := webdata.Source(wanted[i]); source == webdata.SearchConsole {
+			ds.Source = "Google Search Console"
+		}
+		return specSource(ds), nil
+	})
+}